@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"io/fs"
+	"path/filepath"
 	"bufio"
 	"strings"
 	"strconv"
@@ -17,19 +18,62 @@ type settingsManager struct {
 
 	configFile string
 
+	// bitcoin-core or esplora
+	nodeType string
+
+	// the base URL of the Esplora API, only used if the node type is esplora
+	esploraUrl string
+
 	bitcoinCoreAddr string
 	bitcoinCorePort uint16
 	bitcoinCoreUsername string
 	bitcoinCorePassword string
 
+	// used instead of the username and password if they are not provided
+	// if empty, the .cookie file in the default bitcoin core data directory for the network is used
+	bitcoinCoreCookieFile string
+
+	// for a node behind a TLS proxy, the CA file is only needed if the proxy certificate is not signed by a system root
+	bitcoinCoreTls bool
+	bitcoinCoreCaFile string
+
+	// the URL path of the rpc requests, for example /wallet/name for a multiwallet node
+	bitcoinCorePath string
+
+	// the addresses bitcoin core publishes new blocks and transactions to, if any
+	bitcoinCoreZmqRawBlock string
+	bitcoinCoreZmqRawTx string
+	bitcoinCoreZmqHashBlock string
+
 	nodeVersionStr string
 
+	// if empty, the network is taken from the node
+	network string
+
 	addr string
 	port uint16
 
+	// the grpc server is only started if it has a port, and it uses the same address as the web server
+	grpcPort uint16
+
 	noWeb bool
 	caching bool
 
+	// if empty, the job-data directory next to the executable is used
+	jobsDir string
+
+	// if empty, the export-data directory next to the executable is used
+	exportsDir string
+
+	// if empty, the watch-data directory next to the executable is used
+	watchDir string
+
+	// the index is only built if it is turned on
+	// if the index file is empty, index.db next to the executable is used
+	index bool
+	indexFile string
+	indexStartHeight uint32
+
 //	testMode string
 //	testVerifiedDir string
 //	testUnverifiedDir string
@@ -56,16 +100,37 @@ func (s *settingsManager) GetConfigFileLocation () string {
 	return s.configFile
 }
 
-func (s *settingsManager) GetNodeType () string {
-	if len (s.bitcoinCoreAddr) > 0 && s.bitcoinCorePort != 0 && len (s.bitcoinCoreUsername) > 0 && len (s.bitcoinCorePassword) > 0 {
-		return "Bitcoin Core"
+var nodeTypes = [] string { "bitcoin-core", "esplora" }
+
+// the same names bitcoin core uses for its chains
+var networks = [] string { "main", "test", "testnet4", "signet", "regtest" }
+
+var networkRpcPorts = map [string] uint16 { "main": 8332, "test": 18332, "testnet4": 48332, "signet": 38332, "regtest": 18443 }
+var networkDataDirs = map [string] string { "main": "", "test": "testnet3", "testnet4": "testnet4", "signet": "signet", "regtest": "regtest" }
+
+// without a username and password, cookie authentication is used for Bitcoin Core
+// returns an empty string if the settings for the node are incomplete, or an error if the node-type setting is not supported
+func (s *settingsManager) GetNodeType () (string, error) {
+	switch s.nodeType {
+		case "bitcoin-core":
+			if len (s.bitcoinCoreAddr) > 0 && s.getNodePort () != 0 && (len (s.bitcoinCoreUsername) > 0) == (len (s.bitcoinCorePassword) > 0) {
+				return "Bitcoin Core", nil
+			}
+		case "esplora":
+			if len (s.esploraUrl) > 0 { return "Esplora", nil }
+		default:
+			return "", fmt.Errorf ("node-type %s is not supported, it must be one of: %s", s.nodeType, strings.Join (nodeTypes, ", "))
 	}
 
-	return ""
+	return "", nil
 }
 
 func (s *settingsManager) GetNodeFullUrl () string {
-	return s.bitcoinCoreAddr + ":" + strconv.FormatUint (uint64 (s.bitcoinCorePort), 10)
+	if s.nodeType == "esplora" { return s.esploraUrl }
+
+	scheme := "http"; if s.bitcoinCoreTls || len (s.bitcoinCoreCaFile) > 0 { scheme = "https" }
+	path := s.bitcoinCorePath; if !strings.HasPrefix (path, "/") { path = "/" + path }
+	return scheme + "://" + s.bitcoinCoreAddr + ":" + strconv.FormatUint (uint64 (s.getNodePort ()), 10) + path
 }
 
 func (s *settingsManager) GetNodeUsername () string {
@@ -76,6 +141,81 @@ func (s *settingsManager) GetNodePassword () string {
 	return s.bitcoinCorePassword
 }
 
+func (s *settingsManager) GetNodeCookieFile () string {
+	if len (s.bitcoinCoreCookieFile) > 0 { return s.bitcoinCoreCookieFile }
+	return getDefaultCookieFile (s.getNodeNetwork ())
+}
+
+// the .cookie file in the data directory bitcoin core uses for the network, or an empty string if there is no home directory
+func getDefaultCookieFile (network string) string {
+
+	homeDir, err := os.UserHomeDir ()
+	if err != nil { return "" }
+
+	return filepath.Join (homeDir, ".bitcoin", networkDataDirs [network], ".cookie")
+}
+
+// the network is needed to find the node before the node can be asked which network it is on
+// without a network setting, it is the network the port belongs to, or the first network with a cookie file
+func (s *settingsManager) getNodeNetwork () string {
+	if len (s.network) > 0 { return s.network }
+
+	for _, network := range networks {
+		if s.bitcoinCorePort != 0 && s.bitcoinCorePort == networkRpcPorts [network] { return network }
+	}
+
+	if s.bitcoinCorePort == 0 && len (s.bitcoinCoreUsername) == 0 && len (s.bitcoinCorePassword) == 0 && len (s.bitcoinCoreCookieFile) == 0 {
+		for _, network := range networks {
+			cookieFile := getDefaultCookieFile (network)
+			if _, err := os.Stat (cookieFile); len (cookieFile) > 0 && err == nil { return network }
+		}
+	}
+
+	return "main"
+}
+
+// the default rpc port of the network, unless the port is set
+func (s *settingsManager) getNodePort () uint16 {
+	if s.bitcoinCorePort != 0 { return s.bitcoinCorePort }
+	return networkRpcPorts [s.getNodeNetwork ()]
+}
+
+func (s *settingsManager) GetNodeCaFile () string {
+	return s.bitcoinCoreCaFile
+}
+
+func (s *settingsManager) GetZmqRawBlockAddr () string {
+	return s.bitcoinCoreZmqRawBlock
+}
+
+func (s *settingsManager) GetZmqRawTxAddr () string {
+	return s.bitcoinCoreZmqRawTx
+}
+
+func (s *settingsManager) GetZmqHashBlockAddr () string {
+	return s.bitcoinCoreZmqHashBlock
+}
+
+func (s *settingsManager) IsZmqOn () bool {
+	return len (s.bitcoinCoreZmqRawBlock) > 0 || len (s.bitcoinCoreZmqRawTx) > 0 || len (s.bitcoinCoreZmqHashBlock) > 0
+}
+
+// returns an empty string if the network is taken from the node
+func (s *settingsManager) GetNetwork () string {
+	return s.network
+}
+
+// returns an error if the network setting is not one of the networks
+func (s *settingsManager) CheckNetwork () error {
+	if len (s.network) == 0 { return nil }
+
+	for _, network := range networks {
+		if s.network == network { return nil }
+	}
+
+	return fmt.Errorf ("network %s is not supported, it must be one of: %s", s.network, strings.Join (networks, ", "))
+}
+
 func (s *settingsManager) GetBaseUrl (alwaysIncludePort bool) string {
 	if s.port != 80 || alwaysIncludePort {
 		return fmt.Sprintf ("%s:%d", s.addr, s.port)
@@ -96,6 +236,14 @@ func (s *settingsManager) GetPort () uint16 {
 	return s.port
 }
 
+func (s *settingsManager) IsGrpcOn () bool {
+	return s.grpcPort > 0
+}
+
+func (s *settingsManager) GetGrpcAddr () string {
+	return fmt.Sprintf ("%s:%d", s.addr, s.grpcPort)
+}
+
 /*
 func (s *settingsManager) GetTestMode () string {
 	return s.testMode
@@ -123,6 +271,30 @@ func (s *settingsManager) IsCachingOn () bool {
 	return s.caching
 }
 
+func (s *settingsManager) GetJobsDir () string {
+	return s.jobsDir
+}
+
+func (s *settingsManager) GetExportsDir () string {
+	return s.exportsDir
+}
+
+func (s *settingsManager) GetWatchDir () string {
+	return s.watchDir
+}
+
+func (s *settingsManager) IsIndexOn () bool {
+	return s.index
+}
+
+func (s *settingsManager) GetIndexFile () string {
+	return s.indexFile
+}
+
+func (s *settingsManager) GetIndexStartHeight () uint32 {
+	return s.indexStartHeight
+}
+
 func getBoolValue (setting string) bool {
 	lower := strings.ToLower (setting)
 	intVal, err := strconv.Atoi (setting)
@@ -135,6 +307,9 @@ func (s *settingsManager) setSettings (settings map [string] string) {
 		switch k {
 			case "config-file": s.configFile = v
 
+			case "node-type": s.nodeType = v
+			case "esplora-url": s.esploraUrl = v
+
 			// bitcoin core settings
 			case "bitcoin-core-addr": s.bitcoinCoreAddr = v
 			case "bitcoin-core-port":
@@ -143,6 +318,15 @@ func (s *settingsManager) setSettings (settings map [string] string) {
 				s.bitcoinCorePort = uint16 (port)
 			case "bitcoin-core-username": s.bitcoinCoreUsername = v
 			case "bitcoin-core-password": s.bitcoinCorePassword = v
+			case "bitcoin-core-cookie-file": s.bitcoinCoreCookieFile = v
+			case "bitcoin-core-tls":
+				s.bitcoinCoreTls = getBoolValue (v)
+			case "bitcoin-core-ca-file": s.bitcoinCoreCaFile = v
+			case "bitcoin-core-path": s.bitcoinCorePath = v
+			case "bitcoin-core-zmq-rawblock": s.bitcoinCoreZmqRawBlock = v
+			case "bitcoin-core-zmq-rawtx": s.bitcoinCoreZmqRawTx = v
+			case "bitcoin-core-zmq-hashblock": s.bitcoinCoreZmqHashBlock = v
+			case "network": s.network = v
 
 			// scantool settings
 			case "addr": s.addr = v
@@ -150,10 +334,24 @@ func (s *settingsManager) setSettings (settings map [string] string) {
 				port, err := strconv.Atoi (v)
 				if err != nil { panic (err.Error ()) }
 				s.port = uint16 (port)
+			case "grpc-port":
+				port, err := strconv.Atoi (v)
+				if err != nil { panic (err.Error ()) }
+				s.grpcPort = uint16 (port)
 			case "caching":
 				s.caching = getBoolValue (v)
 			case "no-web":
 				s.noWeb = getBoolValue (v)
+			case "jobs-dir": s.jobsDir = v
+			case "exports-dir": s.exportsDir = v
+			case "watch-dir": s.watchDir = v
+			case "index":
+				s.index = getBoolValue (v)
+			case "index-file": s.indexFile = v
+			case "index-start-height":
+				height, err := strconv.Atoi (v)
+				if err != nil || height < 0 { panic ("index-start-height must be a block height.") }
+				s.indexStartHeight = uint32 (height)
 
 			// test
 //			case "test-mode": s.testMode = v
@@ -173,8 +371,10 @@ func getDefaultSettings () settingsManager {
 
 //								configFile: "",
 
+								nodeType: "bitcoin-core",
+
+								// without a port setting, the default port of the network is used
 								bitcoinCoreAddr: "127.0.0.1",
-								bitcoinCorePort: 8332,
 //								bitcoinCoreUsername: "",
 //								bitcoinCorePassword: "",
 
@@ -303,8 +503,8 @@ func checkFile (fileName string, requiredPermissions byte) bool {
 	// make sure the user has the correct permissions
 	hasPermission := true
 	if requiredPermissions != 0 {
-		hasPermission = fileInfo.Mode ().Perm () & fs.FileMode (requiredPermissions << 8) != 0 ||
-						fileInfo.Mode ().Perm () & fs.FileMode (requiredPermissions << 4) != 0 ||
+		hasPermission = fileInfo.Mode ().Perm () & (fs.FileMode (requiredPermissions) << 6) != 0 ||
+						fileInfo.Mode ().Perm () & (fs.FileMode (requiredPermissions) << 3) != 0 ||
 						fileInfo.Mode ().Perm () & fs.FileMode (requiredPermissions) != 0
 	}
 	if !hasPermission {
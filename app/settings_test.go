@@ -0,0 +1,82 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"path/filepath"
+)
+
+func TestGetNodeType (t *testing.T) {
+
+	s := getDefaultSettings ()
+	if nodeType, err := s.GetNodeType (); nodeType != "Bitcoin Core" || err != nil { t.Errorf ("default node type %s, error %v", nodeType, err) }
+
+	// esplora needs a url
+	s.setSettings (map [string] string { "node-type": "esplora" })
+	if nodeType, err := s.GetNodeType (); nodeType != "" || err != nil { t.Errorf ("esplora without a url %s, error %v", nodeType, err) }
+
+	s.setSettings (map [string] string { "esplora-url": "http://127.0.0.1:3000" })
+	if nodeType, err := s.GetNodeType (); nodeType != "Esplora" || err != nil { t.Errorf ("esplora node type %s, error %v", nodeType, err) }
+
+	// the error lists the node types that are supported
+	s.setSettings (map [string] string { "node-type": "esplorra" })
+	nodeType, err := s.GetNodeType ()
+	if nodeType != "" || err == nil { t.Fatalf ("unknown node type %s, error %v", nodeType, err) }
+	if !strings.Contains (err.Error (), "esplorra") || !strings.Contains (err.Error (), "bitcoin-core, esplora") { t.Errorf ("error %s", err.Error ()) }
+}
+
+func TestCheckNetwork (t *testing.T) {
+
+	s := getDefaultSettings ()
+	if err := s.CheckNetwork (); err != nil { t.Errorf ("no network setting: %s", err.Error ()) }
+
+	for _, network := range networks {
+		s.setSettings (map [string] string { "network": network })
+		if err := s.CheckNetwork (); err != nil { t.Errorf ("%s: %s", network, err.Error ()) }
+	}
+
+	// the error lists the networks that are supported
+	s.setSettings (map [string] string { "network": "mainnet" })
+	err := s.CheckNetwork ()
+	if err == nil { t.Fatal ("no error for an unknown network") }
+	if !strings.Contains (err.Error (), "mainnet") || !strings.Contains (err.Error (), "main, test, testnet4, signet, regtest") { t.Errorf ("error %s", err.Error ()) }
+}
+
+func TestNodePortAndCookieFile (t *testing.T) {
+
+	homeDir := t.TempDir ()
+	t.Setenv ("HOME", homeDir)
+	dataDir := filepath.Join (homeDir, ".bitcoin")
+
+	for name, test := range map [string] struct {
+		settings map [string] string
+		cookieDirs [] string
+		url string
+		cookieFile string
+	} {	"defaults": { map [string] string {}, nil, "http://127.0.0.1:8332/", filepath.Join (dataDir, ".cookie") },
+		"test": { map [string] string { "network": "test" }, nil, "http://127.0.0.1:18332/", filepath.Join (dataDir, "testnet3", ".cookie") },
+		"testnet4": { map [string] string { "network": "testnet4" }, nil, "http://127.0.0.1:48332/", filepath.Join (dataDir, "testnet4", ".cookie") },
+		"signet": { map [string] string { "network": "signet" }, nil, "http://127.0.0.1:38332/", filepath.Join (dataDir, "signet", ".cookie") },
+		"regtest": { map [string] string { "network": "regtest" }, nil, "http://127.0.0.1:18443/", filepath.Join (dataDir, "regtest", ".cookie") },
+		"port setting": { map [string] string { "network": "regtest", "bitcoin-core-port": "9000" }, nil, "http://127.0.0.1:9000/", filepath.Join (dataDir, "regtest", ".cookie") },
+		"cookie file setting": { map [string] string { "network": "signet", "bitcoin-core-cookie-file": "/tmp/.cookie" }, nil, "http://127.0.0.1:38332/", "/tmp/.cookie" },
+
+		// without a network setting, the network is found from the port or the cookie files
+		"port of a network": { map [string] string { "bitcoin-core-port": "38332" }, [] string { "regtest" }, "http://127.0.0.1:38332/", filepath.Join (dataDir, "signet", ".cookie") },
+		"only cookie file": { map [string] string {}, [] string { "regtest" }, "http://127.0.0.1:18443/", filepath.Join (dataDir, "regtest", ".cookie") },
+		"first cookie file": { map [string] string {}, [] string { "signet", "testnet4" }, "http://127.0.0.1:48332/", filepath.Join (dataDir, "testnet4", ".cookie") },
+		"other port": { map [string] string { "bitcoin-core-port": "9000" }, [] string { "regtest" }, "http://127.0.0.1:9000/", filepath.Join (dataDir, ".cookie") } } {
+
+		os.RemoveAll (dataDir)
+		for _, cookieDir := range test.cookieDirs {
+			if err := os.MkdirAll (filepath.Join (dataDir, cookieDir), 0755); err != nil { t.Fatal (err) }
+			if err := os.WriteFile (filepath.Join (dataDir, cookieDir, ".cookie"), [] byte ("__cookie__:password"), 0600); err != nil { t.Fatal (err) }
+		}
+
+		s := getDefaultSettings ()
+		s.setSettings (test.settings)
+		if url := s.GetNodeFullUrl (); url != test.url { t.Errorf ("%s: url %s, expected %s", name, url, test.url) }
+		if cookieFile := s.GetNodeCookieFile (); cookieFile != test.cookieFile { t.Errorf ("%s: cookie file %s, expected %s", name, cookieFile, test.cookieFile) }
+	}
+}
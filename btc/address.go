@@ -0,0 +1,224 @@
+package btc
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"math/big"
+	"crypto/sha256"
+)
+
+// addresses are normally provided by the node
+// these functions are used when an output script did not come from the node, for example when a transaction is decoded locally
+// the address format depends on the current network
+// addresses are also decoded into output scripts so that they can be looked up in the index
+
+func GetAddress (script Script) string {
+
+	scriptBytes := script.AsBytes ()
+	network := GetNetwork ()
+
+	if script.IsP2pkhOutput () { return encodeBase58Check (network.p2pkhVersionByte, scriptBytes [3 : 23]) }
+	if script.IsP2shOutput () { return encodeBase58Check (network.p2shVersionByte, scriptBytes [2 : 22]) }
+
+	if script.IsP2wpkhOutput () || script.IsP2wshOutput () || script.IsTaprootOutput () || script.IsWitnessUnknownOutput () {
+		witnessVersion := scriptBytes [0]
+		if witnessVersion >= 0x51 { witnessVersion -= 0x50 }
+		fields := script.GetFields ()
+		return encodeSegwitAddress (network.segwitHrp, witnessVersion, fields [1].AsBytes ())
+	}
+
+	return ""
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func encodeBase58Check (version byte, payload [] byte) string {
+
+	data := append ([] byte { version }, payload...)
+	checksum := sha256.Sum256 (data)
+	checksum = sha256.Sum256 (checksum [:])
+	data = append (data, checksum [0 : 4]...)
+
+	// each leading zero byte is represented by a 1
+	leadingZeros := 0
+	for leadingZeros < len (data) && data [leadingZeros] == 0 { leadingZeros++ }
+
+	num := new (big.Int).SetBytes (data)
+	base := big.NewInt (58)
+	mod := new (big.Int)
+	encoded := make ([] byte, 0, len (data) * 2)
+	for num.Sign () > 0 {
+		num.DivMod (num, base, mod)
+		encoded = append (encoded, base58Alphabet [mod.Int64 ()])
+	}
+	for z := 0; z < leadingZeros; z++ {
+		encoded = append (encoded, base58Alphabet [0])
+	}
+
+	return string (ReverseBytes (encoded))
+}
+
+// BIP 173 and BIP 350
+const bech32Alphabet = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+const bech32Constant = uint32 (1)
+const bech32mConstant = uint32 (0x2bc830a3)
+
+func bech32Polymod (values [] byte) uint32 {
+	generator := [] uint32 { 0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3 }
+	chk := uint32 (1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk & 0x1ffffff) << 5 ^ uint32 (v)
+		for i := 0; i < 5; i++ {
+			if (top >> uint (i)) & 1 == 1 { chk ^= generator [i] }
+		}
+	}
+	return chk
+}
+
+func bech32HrpExpand (hrp string) [] byte {
+	expanded := make ([] byte, 0, len (hrp) * 2 + 1)
+	for i := 0; i < len (hrp); i++ { expanded = append (expanded, hrp [i] >> 5) }
+	expanded = append (expanded, 0)
+	for i := 0; i < len (hrp); i++ { expanded = append (expanded, hrp [i] & 31) }
+	return expanded
+}
+
+// regroups 8-bit bytes into 5-bit groups
+func convertTo5Bit (data [] byte) [] byte {
+	result := make ([] byte, 0, len (data) * 8 / 5 + 1)
+	acc := uint32 (0)
+	bits := uint (0)
+	for _, b := range data {
+		acc = acc << 8 | uint32 (b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			result = append (result, byte ((acc >> bits) & 31))
+		}
+	}
+	if bits > 0 { result = append (result, byte ((acc << (5 - bits)) & 31)) }
+	return result
+}
+
+func encodeSegwitAddress (hrp string, witnessVersion byte, witnessProgram [] byte) string {
+
+	data := append ([] byte { witnessVersion }, convertTo5Bit (witnessProgram)...)
+
+	// version 0 uses bech32, all later versions use bech32m
+	constant := bech32Constant
+	if witnessVersion > 0 { constant = bech32mConstant }
+
+	values := append (bech32HrpExpand (hrp), data...)
+	values = append (values, 0, 0, 0, 0, 0, 0)
+	polymod := bech32Polymod (values) ^ constant
+
+	address := hrp + "1"
+	for _, d := range data { address += string (bech32Alphabet [d]) }
+	for i := 0; i < 6; i++ { address += string (bech32Alphabet [(polymod >> uint (5 * (5 - i))) & 31]) }
+
+	return address
+}
+
+// returns the output script for an address on the current network
+func GetOutputScriptFromAddress (address string) ([] byte, error) {
+
+	network := GetNetwork ()
+
+	// segwit addresses start with the human-readable part
+	lower := strings.ToLower (address)
+	if strings.HasPrefix (lower, network.segwitHrp + "1") {
+		witnessVersion, witnessProgram, err := decodeSegwitAddress (network.segwitHrp, lower)
+		if err != nil { return nil, err }
+
+		opcode := witnessVersion
+		if witnessVersion > 0 { opcode += 0x50 }
+		return append ([] byte { opcode, byte (len (witnessProgram)) }, witnessProgram...), nil
+	}
+
+	version, payload, err := decodeBase58Check (address)
+	if err != nil { return nil, err }
+	if len (payload) != 20 { return nil, errors.New ("address has an invalid length") }
+
+	if version == network.p2pkhVersionByte {
+		script := append ([] byte { 0x76, 0xa9, 0x14 }, payload...)
+		return append (script, 0x88, 0xac), nil
+	}
+
+	if version == network.p2shVersionByte {
+		script := append ([] byte { 0xa9, 0x14 }, payload...)
+		return append (script, 0x87), nil
+	}
+
+	return nil, errors.New ("address is not valid on the " + network.GetDisplayName () + " network")
+}
+
+func decodeBase58Check (encoded string) (byte, [] byte, error) {
+
+	num := big.NewInt (0)
+	base := big.NewInt (58)
+	for _, c := range encoded {
+		digit := strings.IndexRune (base58Alphabet, c)
+		if digit < 0 { return 0, nil, errors.New ("address contains an invalid character") }
+		num.Mul (num, base)
+		num.Add (num, big.NewInt (int64 (digit)))
+	}
+
+	// each leading 1 represents a zero byte
+	leadingZeros := 0
+	for leadingZeros < len (encoded) && encoded [leadingZeros] == base58Alphabet [0] { leadingZeros++ }
+
+	data := append (make ([] byte, leadingZeros), num.Bytes ()...)
+	if len (data) < 5 { return 0, nil, errors.New ("address is too short") }
+
+	checksum := sha256.Sum256 (data [: len (data) - 4])
+	checksum = sha256.Sum256 (checksum [:])
+	if !bytes.Equal (checksum [0 : 4], data [len (data) - 4 :]) { return 0, nil, errors.New ("address checksum is not valid") }
+
+	return data [0], data [1 : len (data) - 4], nil
+}
+
+// regroups 5-bit groups into 8-bit bytes, any padding must be zeros
+func convertFrom5Bit (data [] byte) ([] byte, error) {
+	result := make ([] byte, 0, len (data) * 5 / 8)
+	acc := uint32 (0)
+	bits := uint (0)
+	for _, b := range data {
+		acc = acc << 5 | uint32 (b)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			result = append (result, byte ((acc >> bits) & 0xff))
+		}
+	}
+	if bits >= 5 || (acc << (8 - bits)) & 0xff != 0 { return nil, errors.New ("address has invalid padding") }
+	return result, nil
+}
+
+func decodeSegwitAddress (hrp string, address string) (byte, [] byte, error) {
+
+	dataPart := address [len (hrp) + 1 :]
+	if len (dataPart) < 7 { return 0, nil, errors.New ("address is too short") }
+
+	data := make ([] byte, len (dataPart))
+	for i, c := range dataPart {
+		value := strings.IndexRune (bech32Alphabet, c)
+		if value < 0 { return 0, nil, errors.New ("address contains an invalid character") }
+		data [i] = byte (value)
+	}
+
+	witnessVersion := data [0]
+	if witnessVersion > 16 { return 0, nil, errors.New ("address has an invalid witness version") }
+
+	// version 0 uses bech32, all later versions use bech32m
+	constant := bech32Constant
+	if witnessVersion > 0 { constant = bech32mConstant }
+	if bech32Polymod (append (bech32HrpExpand (hrp), data...)) != constant { return 0, nil, errors.New ("address checksum is not valid") }
+
+	witnessProgram, err := convertFrom5Bit (data [1 : len (data) - 6])
+	if err != nil { return 0, nil, err }
+	if len (witnessProgram) < 2 || len (witnessProgram) > 40 { return 0, nil, errors.New ("address has an invalid witness program length") }
+
+	return witnessVersion, witnessProgram, nil
+}
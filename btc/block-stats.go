@@ -0,0 +1,161 @@
+package btc
+
+import (
+	"strings"
+)
+
+// the number of times a type appears and the total value of the inputs or outputs it appears in
+type TypeStats struct {
+	count uint32
+	value uint64
+}
+
+func (ts *TypeStats) GetCount () uint32 {
+	return ts.count
+}
+
+func (ts *TypeStats) GetValue () uint64 {
+	return ts.value
+}
+
+// aggregates for all transactions in a block
+// inputs must have their previous outputs for the spend types and input value to be complete
+type BlockStats struct {
+	txCount uint32
+	inputCount uint32
+	outputCount uint32
+	inputValue uint64
+	outputValue uint64
+
+	spendTypes map [string] TypeStats
+	outputTypes map [string] TypeStats
+	serializedScriptTypes map [string] TypeStats
+	dataTypes map [string] TypeStats
+}
+
+func NewBlockStats () BlockStats {
+	return BlockStats {	spendTypes: make (map [string] TypeStats),
+						outputTypes: make (map [string] TypeStats),
+						serializedScriptTypes: make (map [string] TypeStats),
+						dataTypes: make (map [string] TypeStats) }
+}
+
+func (bs *BlockStats) AddTx (tx Tx) {
+
+	bs.txCount++
+
+	for _, input := range tx.GetInputs () {
+
+		bs.inputCount++
+		if input.IsCoinbase () { continue }
+
+		previousOutput := input.GetPreviousOutput ()
+		value := previousOutput.GetValue ()
+		bs.inputValue += value
+
+		addTypeStats (bs.spendTypes, input.GetSpendType (), value)
+
+		// serialized scripts
+		if input.HasRedeemScript () {
+			redeemScript := input.GetRedeemScript ()
+			addTypeStats (bs.serializedScriptTypes, getSerializedScriptType ("Redeem Script", redeemScript, input.GetSpendType ()), value)
+			bs.addScriptDataTypes (redeemScript)
+		}
+
+		segwit := input.GetSegwit ()
+		witnessScript := segwit.GetWitnessScript ()
+		if !witnessScript.IsNil () {
+			addTypeStats (bs.serializedScriptTypes, getSerializedScriptType ("Witness Script", witnessScript, input.GetSpendType ()), value)
+			bs.addScriptDataTypes (witnessScript)
+		}
+
+		tapScript, _ := segwit.GetTapScript ()
+		if !tapScript.IsNil () {
+			addTypeStats (bs.serializedScriptTypes, getSerializedScriptType ("Tap Script", tapScript, input.GetSpendType ()), value)
+			bs.addScriptDataTypes (tapScript)
+		}
+
+		// data pushed by the input script and the witness
+		bs.addScriptDataTypes (input.GetInputScript ())
+		for _, field := range segwit.GetFields () {
+			addTypeStats (bs.dataTypes, getDataTypeName (field.AsType ()), uint64 (len (field.AsBytes ())))
+		}
+	}
+
+	for _, output := range tx.GetOutputs () {
+		bs.outputCount++
+		bs.outputValue += output.GetValue ()
+		addTypeStats (bs.outputTypes, output.GetOutputType (), output.GetValue ())
+
+		bs.addScriptDataTypes (output.GetOutputScript ())
+	}
+}
+
+// for data types, the value is the total number of bytes
+func (bs *BlockStats) addScriptDataTypes (script Script) {
+	for _, field := range script.GetFields () {
+		if field.IsOpcode () { continue }
+		addTypeStats (bs.dataTypes, getDataTypeName (field.AsType ()), uint64 (len (field.AsBytes ())))
+	}
+}
+
+func addTypeStats (typeMap map [string] TypeStats, typeName string, value uint64) {
+	if len (typeName) == 0 { return }
+
+	stats := typeMap [typeName]
+	stats.count++
+	stats.value += value
+	typeMap [typeName] = stats
+}
+
+// sizes and other details are removed so that fields of the same type are counted together
+func getDataTypeName (fieldType string) string {
+	detailStart := strings.Index (fieldType, " (")
+	if detailStart > -1 { return fieldType [: detailStart] }
+	return fieldType
+}
+
+func getSerializedScriptType (scriptName string, script Script, spendType string) string {
+	if spendType == SPEND_TYPE_P2SH_P2WPKH || spendType == SPEND_TYPE_P2SH_P2WSH { return scriptName + " (Witness Program)" }
+	if script.IsEmpty () { return scriptName + " (Empty)" }
+	if script.IsOrdinal () { return scriptName + " (Ordinal)" }
+	if script.IsMultiSigOutput () { return scriptName + " (MultiSig)" }
+	return scriptName
+}
+
+func (bs *BlockStats) GetTxCount () uint32 {
+	return bs.txCount
+}
+
+// includes the coinbase input
+func (bs *BlockStats) GetInputCount () uint32 {
+	return bs.inputCount
+}
+
+func (bs *BlockStats) GetOutputCount () uint32 {
+	return bs.outputCount
+}
+
+func (bs *BlockStats) GetInputValue () uint64 {
+	return bs.inputValue
+}
+
+func (bs *BlockStats) GetOutputValue () uint64 {
+	return bs.outputValue
+}
+
+func (bs *BlockStats) GetSpendTypes () map [string] TypeStats {
+	return bs.spendTypes
+}
+
+func (bs *BlockStats) GetOutputTypes () map [string] TypeStats {
+	return bs.outputTypes
+}
+
+func (bs *BlockStats) GetSerializedScriptTypes () map [string] TypeStats {
+	return bs.serializedScriptTypes
+}
+
+func (bs *BlockStats) GetDataTypes () map [string] TypeStats {
+	return bs.dataTypes
+}
@@ -1,6 +1,7 @@
 package btc
 
 import (
+	"encoding/hex"
 )
 
 type Block struct {
@@ -11,10 +12,25 @@ type Block struct {
 	version int32
 	timestamp int64
 	txIds [] string
+
+	// header
+	merkleRoot string
+	bits string
+	nonce uint32
+	difficulty float64
+	chainwork string
+	medianTime int64
+
+	// sizes
+	size uint32
+	strippedSize uint32
+	weight uint32
 }
 
-func NewBlock (hash string, previous string, next string, height uint32, version int32, timestamp int64, txIds [] string) Block {
-	return Block { hash: hash, previousHash: previous, nextHash: next, height: height, version: version, timestamp: timestamp, txIds: txIds }
+func NewBlock (hash string, previous string, next string, height uint32, version int32, timestamp int64, txIds [] string, merkleRoot string, bits string, nonce uint32, difficulty float64, chainwork string, medianTime int64, size uint32, strippedSize uint32, weight uint32) Block {
+	return Block {	hash: hash, previousHash: previous, nextHash: next, height: height, version: version, timestamp: timestamp, txIds: txIds,
+					merkleRoot: merkleRoot, bits: bits, nonce: nonce, difficulty: difficulty, chainwork: chainwork, medianTime: medianTime,
+					size: size, strippedSize: strippedSize, weight: weight }
 }
 
 func (b *Block) IsNil () bool {
@@ -45,7 +61,84 @@ func (b *Block) GetTxIds () [] string {
 	return b.txIds
 }
 
+func (b *Block) GetTxCount () uint32 {
+	return uint32 (len (b.txIds))
+}
+
 func (b *Block) GetTimestamp () int64 {
 	return b.timestamp
 }
 
+func (b *Block) GetMerkleRoot () string {
+	return b.merkleRoot
+}
+
+// the compact representation of the target
+func (b *Block) GetBits () string {
+	return b.bits
+}
+
+func (b *Block) GetNonce () uint32 {
+	return b.nonce
+}
+
+func (b *Block) GetDifficulty () float64 {
+	return b.difficulty
+}
+
+// the total amount of work in the chain up to and including this block, as hex
+func (b *Block) GetChainwork () string {
+	return b.chainwork
+}
+
+// the median timestamp of the previous 11 blocks
+func (b *Block) GetMedianTime () int64 {
+	return b.medianTime
+}
+
+func (b *Block) GetSize () uint32 {
+	return b.size
+}
+
+// the size without the segwit data
+func (b *Block) GetStrippedSize () uint32 {
+	return b.strippedSize
+}
+
+func (b *Block) GetWeight () uint32 {
+	return b.weight
+}
+
+// recomputes the merkle root from the transaction ids
+// returns an empty string if any of the ids are invalid
+func (b *Block) ComputeMerkleRoot () string {
+
+	txCount := len (b.txIds)
+	if txCount == 0 { return "" }
+
+	// the hashes are calculated with the bytes in the opposite order from how the ids are displayed
+	level := make ([] [] byte, txCount)
+	for t, txId := range b.txIds {
+		txIdBytes, err := hex.DecodeString (txId)
+		if err != nil || len (txIdBytes) != 32 { return "" }
+		level [t] = ReverseBytes (txIdBytes)
+	}
+
+	for len (level) > 1 {
+
+		// when there is an odd number of hashes, the last one is paired with itself
+		if len (level) % 2 != 0 { level = append (level, level [len (level) - 1]) }
+
+		nextLevel := make ([] [] byte, len (level) / 2)
+		for h := 0; h < len (level); h += 2 {
+			nextLevel [h / 2] = doubleSha256 (append (append ([] byte {}, level [h]...), level [h + 1]...))
+		}
+		level = nextLevel
+	}
+
+	return hex.EncodeToString (ReverseBytes (level [0]))
+}
+
+func (b *Block) IsMerkleRootValid () bool {
+	return len (b.merkleRoot) > 0 && b.ComputeMerkleRoot () == b.merkleRoot
+}
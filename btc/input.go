@@ -133,6 +133,14 @@ func (i *Input) SetPreviousOutput (previousOutput Output) {
 	}
 }
 
+func (i *Input) copy () Input {
+	c := *i
+	c.inputScript = i.inputScript.copy ()
+	c.redeemScript = i.redeemScript.copy ()
+	c.segwit = i.segwit.copy ()
+	return c
+}
+
 func (i *Input) SetRedeemScript (redeemScript Script) {
 	i.redeemScript = redeemScript
 }
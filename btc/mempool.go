@@ -0,0 +1,78 @@
+package btc
+
+import (
+)
+
+// what the node knows about a transaction that has not been included in a block yet
+type MempoolEntry struct {
+	timeFirstSeen int64
+	fee uint64
+	vsize uint32
+	ancestorCount uint32
+	ancestorSize uint32
+	descendantCount uint32
+	descendantSize uint32
+	depends [] string
+	spentBy [] string
+}
+
+func NewMempoolEntry (timeFirstSeen int64, fee uint64, vsize uint32, ancestorCount uint32, ancestorSize uint32, descendantCount uint32, descendantSize uint32, depends [] string, spentBy [] string) MempoolEntry {
+	return MempoolEntry {	timeFirstSeen: timeFirstSeen,
+							fee: fee,
+							vsize: vsize,
+							ancestorCount: ancestorCount,
+							ancestorSize: ancestorSize,
+							descendantCount: descendantCount,
+							descendantSize: descendantSize,
+							depends: depends,
+							spentBy: spentBy }
+}
+
+func (me *MempoolEntry) IsNil () bool {
+	return me.timeFirstSeen == 0
+}
+
+func (me *MempoolEntry) GetTimeFirstSeen () int64 {
+	return me.timeFirstSeen
+}
+
+func (me *MempoolEntry) GetFee () uint64 {
+	return me.fee
+}
+
+func (me *MempoolEntry) GetVsize () uint32 {
+	return me.vsize
+}
+
+// fee rate in satoshis per virtual byte
+func (me *MempoolEntry) GetFeeRate () float64 {
+	if me.vsize == 0 { return 0 }
+	return float64 (me.fee) / float64 (me.vsize)
+}
+
+// the ancestor and descendant counts and sizes include the transaction itself
+func (me *MempoolEntry) GetAncestorCount () uint32 {
+	return me.ancestorCount
+}
+
+func (me *MempoolEntry) GetAncestorSize () uint32 {
+	return me.ancestorSize
+}
+
+func (me *MempoolEntry) GetDescendantCount () uint32 {
+	return me.descendantCount
+}
+
+func (me *MempoolEntry) GetDescendantSize () uint32 {
+	return me.descendantSize
+}
+
+// unconfirmed parent transactions
+func (me *MempoolEntry) GetDepends () [] string {
+	return me.depends
+}
+
+// unconfirmed child transactions
+func (me *MempoolEntry) GetSpentBy () [] string {
+	return me.spentBy
+}
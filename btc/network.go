@@ -0,0 +1,138 @@
+package btc
+
+import (
+	"errors"
+)
+
+// the settings that differ between mainnet and the test networks
+// network names are the chain names used by Bitcoin Core
+type Network struct {
+	name string
+	displayName string
+
+	p2pkhVersionByte byte
+	p2shVersionByte byte
+	segwitHrp string
+
+	// Bitcoin Core does not return the genesis transaction, so it has to be known in advance
+	genesisBlockHash string
+	genesisBlockTime int64
+	genesisTxId string
+	genesisTxHex string
+
+	segwitHeight uint32
+	taprootHeight uint32
+}
+
+// every network except testnet4 uses the same genesis transaction
+const genesisTxId = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+const genesisTxHex = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+
+var networks = map [string] Network {
+	"main": Network {	name: "main", displayName: "Mainnet",
+						p2pkhVersionByte: 0x00, p2shVersionByte: 0x05, segwitHrp: "bc",
+						genesisBlockHash: "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f", genesisBlockTime: 1231006505,
+						genesisTxId: genesisTxId, genesisTxHex: genesisTxHex,
+						segwitHeight: 481824, taprootHeight: 709632 },
+
+	// taproot was activated on testnet with BIP 9, so its height is expected to come from the node
+	"test": Network {	name: "test", displayName: "Testnet",
+						p2pkhVersionByte: 0x6f, p2shVersionByte: 0xc4, segwitHrp: "tb",
+						genesisBlockHash: "000000000933ea01ad0ee984209779baaec3ced90fa3f408719526f8d77f4943", genesisBlockTime: 1296688602,
+						genesisTxId: genesisTxId, genesisTxHex: genesisTxHex,
+						segwitHeight: 834624, taprootHeight: 0 },
+
+	"testnet4": Network {	name: "testnet4", displayName: "Testnet4",
+							p2pkhVersionByte: 0x6f, p2shVersionByte: 0xc4, segwitHrp: "tb",
+							genesisBlockHash: "00000000da84f2bafbbc53dee25a72ae507ff4914b867c565be350b0da8bf043", genesisBlockTime: 1714777860,
+							genesisTxId: "7aa0a7ae1e223414cb807e40cd57e667b718e42aaf9306db9102fe28912b7b4e",
+							genesisTxHex: "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff5504ffff001d01044c4c30332f4d61792f323032342030303030303030303030303030303030303030303165626435386332343439373062336161396437383362623030313031316662653865613865393865303065ffffffff0100f2052a010000002321000000000000000000000000000000000000000000000000000000000000000000ac00000000",
+							segwitHeight: 1, taprootHeight: 1 },
+
+	"signet": Network {	name: "signet", displayName: "Signet",
+						p2pkhVersionByte: 0x6f, p2shVersionByte: 0xc4, segwitHrp: "tb",
+						genesisBlockHash: "00000008819873e925422c1ff0f99f7cc9bbb232af63a077a480a3633bee1ef6", genesisBlockTime: 1598918400,
+						genesisTxId: genesisTxId, genesisTxHex: genesisTxHex,
+						segwitHeight: 1, taprootHeight: 1 },
+
+	"regtest": Network {	name: "regtest", displayName: "Regtest",
+							p2pkhVersionByte: 0x6f, p2shVersionByte: 0xc4, segwitHrp: "bcrt",
+							genesisBlockHash: "0f9188f13cb7b2c71f2a335e3a4fc328bf5beb436012afca590b1a11466e2206", genesisBlockTime: 1296688602,
+							genesisTxId: genesisTxId, genesisTxHex: genesisTxHex,
+							segwitHeight: 0, taprootHeight: 0 } }
+
+// mainnet is used until the node has been asked which network it is on
+var currentNetwork = networks ["main"]
+
+// this should only be called during startup, before any requests are handled
+func SetNetwork (name string) error {
+	network, exists := networks [name]
+	if !exists { return errors.New ("Unknown network " + name + ". Valid networks are main, test, testnet4, signet and regtest.") }
+
+	currentNetwork = network
+	return nil
+}
+
+func GetNetwork () Network {
+	return currentNetwork
+}
+
+// for nodes that can not say which network they are on, returns an empty string if the genesis block is unknown
+func GetNetworkNameFromGenesisBlock (genesisBlockHash string) string {
+	for name, network := range networks {
+		if network.genesisBlockHash == genesisBlockHash { return name }
+	}
+
+	return ""
+}
+
+// the node knows the actual activation heights, which override the defaults
+// this should only be called during startup, before any requests are handled
+func SetActivationHeights (segwitHeight uint32, taprootHeight uint32) {
+	currentNetwork.segwitHeight = segwitHeight
+	currentNetwork.taprootHeight = taprootHeight
+}
+
+func (n *Network) GetName () string {
+	return n.name
+}
+
+func (n *Network) GetDisplayName () string {
+	return n.displayName
+}
+
+func (n *Network) IsMainnet () bool {
+	return n.name == "main"
+}
+
+func (n *Network) GetGenesisBlockHash () string {
+	return n.genesisBlockHash
+}
+
+func (n *Network) GetGenesisBlockTime () int64 {
+	return n.genesisBlockTime
+}
+
+func (n *Network) GetGenesisTxId () string {
+	return n.genesisTxId
+}
+
+func (n *Network) GetGenesisTxHex () string {
+	return n.genesisTxHex
+}
+
+func (n *Network) GetSegwitHeight () uint32 {
+	return n.segwitHeight
+}
+
+func (n *Network) GetTaprootHeight () uint32 {
+	return n.taprootHeight
+}
+
+func (n *Network) IsSegwitActive (blockHeight uint32) bool {
+	return blockHeight >= n.segwitHeight
+}
+
+func (n *Network) IsTaprootActive (blockHeight uint32) bool {
+	return blockHeight >= n.taprootHeight
+}
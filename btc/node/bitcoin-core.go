@@ -8,17 +8,26 @@ import (
 	"strings"
 	"encoding/json"
 	"net/http"
+	"encoding/hex"
 
 	"github.com/btc-script-explorer/scantool/app"
+	"github.com/btc-script-explorer/scantool/btc"
 )
 
 type BitcoinCore struct {
 	version string
+
+	url string
+	client *http.Client
+	credentials *rpcCredentials
 }
 
 func NewBitcoinCore () (*BitcoinCore, error) {
-	
-	bc := BitcoinCore {}
+
+	client, err := newRpcClient ()
+	if err != nil { return nil, err }
+
+	bc := BitcoinCore { url: app.Settings.GetNodeFullUrl (), client: client, credentials: newRpcCredentials () }
 	bc.version = bc.getVersionStr ()
 	if len (bc.version) == 0 { return nil, errors.New ("Failed to connect to Bitcoin Node.") }
 	return &bc, nil
@@ -69,6 +78,20 @@ func (bc *BitcoinCore) getBlock (blockHash string, withTxData bool) (map [string
 	return rawResponse ["result"].(map [string] interface {}), nil
 }
 
+func (bc *BitcoinCore) getBlockHeader (blockHash string) (map [string] interface {}, error) {
+
+	jsonResult := bc.getJson ("getblockheader", [] interface {} { blockHash, true })
+
+	var rawResponse map [string] interface {}
+	err := json.Unmarshal (jsonResult, &rawResponse)
+	if err != nil { return nil, errors.New ("JSON ERROR: " + err.Error ()) }
+
+	if rawResponse ["error"] != nil { return nil, errors.New ("BITCOIN CORE ERROR: " + rawResponse ["error"].(map [string] interface {}) ["message"].(string)) }
+	if rawResponse ["result"] == nil { return nil, errors.New ("BITCOIN CORE ERROR: No response from node.") }
+
+	return rawResponse ["result"].(map [string] interface {}), nil
+}
+
 func (bc *BitcoinCore) getBestBlockHash () string {
 	jsonResult := bc.getJson ("getbestblockhash", [] interface {} {})
 	if len (jsonResult) == 0 { return "" }
@@ -104,47 +127,12 @@ func (bc *BitcoinCore) getBlockHash (blockHeight uint32) string {
 
 func (bc *BitcoinCore) getTx (txId string) (map [string] interface {}, error) {
 
-	jsonResult := [] byte (nil)
-
-	if txId != "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b" {
-		jsonResult = bc.getJson ("getrawtransaction", [] interface {} { txId, true })
-	} else {
-		// the genesis transaction is a special case
-		// Bitcoin Core won't return it with this API so we handle that case here
-		// if other raw transaction JSON fields are used in the future, they might need to be added here
-		rawJson := `{
-						"result": {
-							"txid": "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
-							"version": 1,
-							"size": 204,
-							"vsize": 204,
-							"weight": 816,
-							"locktime": 0,
-							"vin": [
-								{
-									"coinbase": "04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73",
-									"sequence": 4294967295
-								}
-							],
-							"vout": [
-								{
-									"value": 50.00000000,
-									"n": 0,
-									"scriptPubKey": {
-										"hex": "4104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac",
-										"type": "pubkey"
-									}
-								}
-							],
-							"hex": "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000",
-							"blockhash":"000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
-							"blocktime": 1231006505
-						},
-						"error": null
-					}`
-		jsonResult = make ([] byte, len (rawJson))
-		jsonResult = [] byte (rawJson)
-	}
+	// the genesis transaction is a special case
+	// Bitcoin Core won't return it with this API so we handle that case separately
+	network := btc.GetNetwork ()
+	if txId == network.GetGenesisTxId () { return bc.getGenesisTx (network) }
+
+	jsonResult := bc.getJson ("getrawtransaction", [] interface {} { txId, true })
 
 	if len (jsonResult) == 0 {
 		return nil, errors.New ("No result from node.")
@@ -164,6 +152,146 @@ func (bc *BitcoinCore) getTx (txId string) (map [string] interface {}, error) {
 	return rawResponse ["result"].(map [string] interface {}), nil
 }
 
+// creates the same JSON that getrawtransaction would return for the genesis transaction
+// if other raw transaction JSON fields are used in the future, they might need to be added here
+func (bc *BitcoinCore) getGenesisTx (network btc.Network) (map [string] interface {}, error) {
+
+	rawBytes, err := hex.DecodeString (network.GetGenesisTxHex ())
+	if err != nil { return nil, err }
+
+	tx, err := btc.DecodeRawTx (rawBytes)
+	if err != nil { return nil, err }
+
+	coinbaseInput := tx.GetInput (0)
+	inputScript := coinbaseInput.GetInputScript ()
+	output := tx.GetOutput (0)
+	outputScript := output.GetOutputScript ()
+
+	rawInput := map [string] interface {} { "coinbase": inputScript.AsHex (), "sequence": float64 (coinbaseInput.GetSequence ()) }
+	rawOutput := map [string] interface {} { "value": float64 (output.GetValue ()) / 100000000, "n": float64 (0), "scriptPubKey": map [string] interface {} { "hex": outputScript.AsHex () } }
+
+	return map [string] interface {} {	"txid": tx.GetTxId (),
+										"version": float64 (tx.GetVersion ()),
+										"locktime": float64 (tx.GetLockTime ()),
+										"vin": [] interface {} { rawInput },
+										"vout": [] interface {} { rawOutput },
+										"hex": network.GetGenesisTxHex (),
+										"blockhash": network.GetGenesisBlockHash (),
+										"blocktime": float64 (network.GetGenesisBlockTime ()) }, nil
+}
+
+func (bc *BitcoinCore) getBlockchainInfo () (map [string] interface {}, error) {
+
+	jsonResult := bc.getJson ("getblockchaininfo", [] interface {} {})
+	if len (jsonResult) == 0 { return nil, errors.New ("No result from node.") }
+
+	var rawResponse map [string] interface {}
+	err := json.Unmarshal (jsonResult, &rawResponse)
+	if err != nil { return nil, errors.New ("JSON ERROR: " + err.Error ()) }
+
+	if rawResponse ["error"] != nil { return nil, errors.New ("BITCOIN CORE ERROR: " + rawResponse ["error"].(map [string] interface {}) ["message"].(string)) }
+	if rawResponse ["result"] == nil { return nil, errors.New ("BITCOIN CORE ERROR: No response from node.") }
+
+	return rawResponse ["result"].(map [string] interface {}), nil
+}
+
+// returns a map of deployment name -> deployment info
+// older versions of Bitcoin Core do not have getdeploymentinfo, but they include the same data in getblockchaininfo
+func (bc *BitcoinCore) getDeployments () (map [string] interface {}, error) {
+
+	jsonResult := bc.getJson ("getdeploymentinfo", [] interface {} {})
+	if len (jsonResult) == 0 { return nil, errors.New ("No result from node.") }
+
+	var rawResponse map [string] interface {}
+	err := json.Unmarshal (jsonResult, &rawResponse)
+	if err != nil { return nil, errors.New ("JSON ERROR: " + err.Error ()) }
+
+	if rawResponse ["error"] == nil && rawResponse ["result"] != nil {
+		deploymentInfo := rawResponse ["result"].(map [string] interface {})
+		if deploymentInfo ["deployments"] != nil { return deploymentInfo ["deployments"].(map [string] interface {}), nil }
+	}
+
+	blockchainInfo, err := bc.getBlockchainInfo ()
+	if err != nil { return nil, err }
+	if blockchainInfo ["softforks"] == nil { return nil, errors.New ("BITCOIN CORE ERROR: No deployment info from node.") }
+
+	// very old versions return an array without activation heights
+	softForks, isMap := blockchainInfo ["softforks"].(map [string] interface {})
+	if !isMap { return nil, errors.New ("BITCOIN CORE ERROR: No deployment info from node.") }
+
+	return softForks, nil
+}
+
+func (bc *BitcoinCore) getMempoolEntry (txId string) (map [string] interface {}, error) {
+
+	jsonResult := bc.getJson ("getmempoolentry", [] interface {} { txId })
+	if len (jsonResult) == 0 { return nil, errors.New ("No result from node.") }
+
+	var rawResponse map [string] interface {}
+	err := json.Unmarshal (jsonResult, &rawResponse)
+	if err != nil { return nil, errors.New ("JSON ERROR: " + err.Error ()) }
+
+	if rawResponse ["error"] != nil { return nil, errors.New ("BITCOIN CORE ERROR: " + rawResponse ["error"].(map [string] interface {}) ["message"].(string)) }
+	if rawResponse ["result"] == nil { return nil, errors.New ("BITCOIN CORE ERROR: No response from node.") }
+
+	return rawResponse ["result"].(map [string] interface {}), nil
+}
+
+// returns a map of tx id -> mempool entry for every transaction in the mempool
+func (bc *BitcoinCore) getRawMempool () (map [string] interface {}, error) {
+
+	jsonResult := bc.getJson ("getrawmempool", [] interface {} { true })
+	if len (jsonResult) == 0 { return nil, errors.New ("No result from node.") }
+
+	var rawResponse map [string] interface {}
+	err := json.Unmarshal (jsonResult, &rawResponse)
+	if err != nil { return nil, errors.New ("JSON ERROR: " + err.Error ()) }
+
+	if rawResponse ["error"] != nil { return nil, errors.New ("BITCOIN CORE ERROR: " + rawResponse ["error"].(map [string] interface {}) ["message"].(string)) }
+	if rawResponse ["result"] == nil { return nil, errors.New ("BITCOIN CORE ERROR: No response from node.") }
+
+	return rawResponse ["result"].(map [string] interface {}), nil
+}
+
+// returns nil without an error if the output has been spent, including by a transaction in the mempool
+func (bc *BitcoinCore) getTxOut (txId string, outputIndex uint32) (map [string] interface {}, error) {
+
+	jsonResult := bc.getJson ("gettxout", [] interface {} { txId, outputIndex, true })
+	if len (jsonResult) == 0 { return nil, errors.New ("No result from node.") }
+
+	var rawResponse map [string] interface {}
+	err := json.Unmarshal (jsonResult, &rawResponse)
+	if err != nil { return nil, errors.New ("JSON ERROR: " + err.Error ()) }
+
+	if rawResponse ["error"] != nil { return nil, errors.New ("BITCOIN CORE ERROR: " + rawResponse ["error"].(map [string] interface {}) ["message"].(string)) }
+	if rawResponse ["result"] == nil { return nil, nil }
+
+	return rawResponse ["result"].(map [string] interface {}), nil
+}
+
+// only finds spending transactions in the mempool, requires Bitcoin Core 24 or later
+// returns an empty string if no transaction in the mempool spends the output
+func (bc *BitcoinCore) getTxSpendingPrevout (txId string, outputIndex uint32) (string, error) {
+
+	outpoints := [] interface {} { map [string] interface {} { "txid": txId, "vout": outputIndex } }
+	jsonResult := bc.getJson ("gettxspendingprevout", [] interface {} { outpoints })
+	if len (jsonResult) == 0 { return "", errors.New ("No result from node.") }
+
+	var rawResponse map [string] interface {}
+	err := json.Unmarshal (jsonResult, &rawResponse)
+	if err != nil { return "", errors.New ("JSON ERROR: " + err.Error ()) }
+
+	if rawResponse ["error"] != nil { return "", errors.New ("BITCOIN CORE ERROR: " + rawResponse ["error"].(map [string] interface {}) ["message"].(string)) }
+	if rawResponse ["result"] == nil { return "", errors.New ("BITCOIN CORE ERROR: No response from node.") }
+
+	for _, rawOutpoint := range rawResponse ["result"].([] interface {}) {
+		spendingTxId := rawOutpoint.(map [string] interface {}) ["spendingtxid"]
+		if spendingTxId != nil { return spendingTxId.(string), nil }
+	}
+
+	return "", nil
+}
+
 func (bc *BitcoinCore) getNetworkInfo () map [string] interface {} {
 	jsonResult := bc.getJson ("getnetworkinfo", [] interface {} {})
 	if len (jsonResult) == 0 { return map [string] interface {} {} }
@@ -194,27 +322,42 @@ func (bc *BitcoinCore) getJson (function string, params [] interface {}) [] byte
 	requestJsonBytes, err := json.Marshal (requestObject)
 	if err != nil { fmt.Println (err.Error ()) }
 
-	// create the HTTP request
-	requestUrl := "http://" + app.Settings.GetNodeFullUrl () + "/"
-	req, err := http.NewRequest (http.MethodPost, requestUrl, bytes.NewBuffer (requestJsonBytes))
-	if err != nil { fmt.Println (err.Error ()) }
-
-	req.SetBasicAuth (app.Settings.GetNodeUsername (), app.Settings.GetNodePassword ())
+	// a rejected cookie is read again once, in case the node was restarted and wrote a new one
+	for attempt := 1; ; attempt++ {
 
-	// get the HTTP response
-	client := &http.Client {}
-	response, err := client.Do (req)
-	if err != nil { fmt.Println (err.Error ()) }
-	if response == nil {
-		fmt.Println ("Node returned empty response.")
+		// create the HTTP request
+		req, err := http.NewRequest (http.MethodPost, bc.url, bytes.NewBuffer (requestJsonBytes))
 		if err != nil { fmt.Println (err.Error ()) }
-		return [] byte {}
-	}
 
-	// return the JSON response
-	json, err := io.ReadAll (response.Body)
-	if err != nil { fmt.Println (err.Error ()) }
+		req.SetBasicAuth (bc.credentials.get ())
+
+		// get the HTTP response
+		response, err := bc.client.Do (req)
+		if err != nil { fmt.Println (err.Error ()) }
+		if response == nil {
+			fmt.Println ("Node returned empty response.")
+			return [] byte {}
+		}
+
+		if response.StatusCode == http.StatusUnauthorized {
+			response.Body.Close ()
+
+			if bc.credentials.usesCookie () && attempt == 1 {
+				changed, err := bc.credentials.reloadCookie ()
+				if err != nil { fmt.Println (err.Error ()) }
+				if changed { continue }
+			}
+
+			fmt.Println ("Node rejected the RPC credentials.")
+			return [] byte {}
+		}
+
+		// return the JSON response
+		json, err := io.ReadAll (response.Body)
+		if err != nil { fmt.Println (err.Error ()) }
+		response.Body.Close ()
 
-	return json
+		return json
+	}
 }
 
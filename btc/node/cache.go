@@ -5,6 +5,7 @@ import (
 	"errors"
 	"encoding/hex"
 	"strconv"
+	"sort"
 	"sync"
 	"time"
 //	"runtime"
@@ -22,22 +23,72 @@ type nodeClient interface {
 	getVersionStr () string
 
 	getBlock (blockHash string, withTxData bool) (map [string] interface {}, error)
+	getBlockHeader (blockHash string) (map [string] interface {}, error)
 	getTx (txId string) (map [string] interface {}, error)
 	getBlockHash (blockHeight uint32) string
 	getBestBlockHash () string
+	getMempoolEntry (txId string) (map [string] interface {}, error)
+	getRawMempool () (map [string] interface {}, error)
+	getTxOut (txId string, outputIndex uint32) (map [string] interface {}, error)
+	getTxSpendingPrevout (txId string, outputIndex uint32) (string, error)
+	getBlockchainInfo () (map [string] interface {}, error)
+	getDeployments () (map [string] interface {}, error)
 }
 
 func getNode () (nodeClient, error) {
 
-	nodeType := app.Settings.GetNodeType ()
+	nodeType, err := app.Settings.GetNodeType ()
+	if err != nil { return nil, err }
 
 	switch nodeType {
 		case "Bitcoin Core":
 			bitcoinCore, err := NewBitcoinCore ()
 			return bitcoinCore, err
+		case "Esplora":
+			esplora, err := NewEsplora ()
+			return esplora, err
 	}
 
-	return nil, errors.New (fmt.Sprintf ("Incorrect node credentials or unsupported node type %s", nodeType))
+	return nil, errors.New ("Incorrect node credentials or incomplete node settings.")
+}
+
+// the network setting is optional, if it is not provided the network is taken from the node
+func initNetwork (btcNode nodeClient, nodeConnected bool) {
+
+	network := app.Settings.GetNetwork ()
+
+	nodeNetwork := ""
+	if nodeConnected {
+		blockchainInfo, err := btcNode.getBlockchainInfo ()
+		if err != nil { fmt.Println (err.Error ()) }
+		if blockchainInfo != nil && blockchainInfo ["chain"] != nil { nodeNetwork = blockchainInfo ["chain"].(string) }
+	}
+
+	if len (network) == 0 {
+		network = nodeNetwork
+		if len (network) == 0 { network = "main" }
+	} else if len (nodeNetwork) > 0 && network != nodeNetwork {
+		fmt.Println (fmt.Sprintf ("WARNING: network setting is %s but the node is on %s.", network, nodeNetwork))
+	}
+
+	if err := btc.SetNetwork (network); err != nil { panic (err.Error ()) }
+
+	// the activation heights known by the node override the defaults
+	if len (nodeNetwork) == 0 { return }
+
+	deployments, err := btcNode.getDeployments ()
+	if err == errNotSupportedByEsplora { return }
+	if err != nil { fmt.Println (err.Error ()); return }
+
+	getActivationHeight := func (deploymentName string, defaultHeight uint32) uint32 {
+		if deployments [deploymentName] == nil { return defaultHeight }
+		deployment := deployments [deploymentName].(map [string] interface {})
+		if deployment ["active"] == nil || !deployment ["active"].(bool) || deployment ["height"] == nil { return defaultHeight }
+		return uint32 (deployment ["height"].(float64))
+	}
+
+	currentNetwork := btc.GetNetwork ()
+	btc.SetActivationHeights (getActivationHeight ("segwit", currentNetwork.GetSegwitHeight ()), getActivationHeight ("taproot", currentNetwork.GetTaprootHeight ()))
 }
 
 ///////////////////////////////////////////////////////////////////////////////////////////////
@@ -86,9 +137,11 @@ func initCache () {
 
 	cachingOn := app.Settings.IsCachingOn ()
 
-	btcNode, _ := getNode ()
+	btcNode, err := getNode ()
 	cache = &btcCache {	btcNode: btcNode, caching: cachingOn }
 
+	initNetwork (btcNode, err == nil)
+
 	if cache.caching {
 
 		blockMap = make (map [uint32] cachedBlock)
@@ -124,12 +177,43 @@ func makeBlock (rawBlock map [string] interface {}) btc.Block {
 		txIds [t] = rawTx ["txid"].(string)
 	}
 
-	return btc.NewBlock (rawBlock ["hash"].(string), previousHash, nextHash, uint32 (rawBlock ["height"].(float64)), int32 (rawBlock ["version"].(float64)), int64 (rawBlock ["time"].(float64)), txIds)
+	// header and size fields
+	getNumber := func (field string) float64 {
+		if rawBlock [field] == nil { return 0 }
+		return rawBlock [field].(float64)
+	}
+	getString := func (field string) string {
+		if rawBlock [field] == nil { return "" }
+		return rawBlock [field].(string)
+	}
+
+	return btc.NewBlock (	rawBlock ["hash"].(string),
+							previousHash,
+							nextHash,
+							uint32 (rawBlock ["height"].(float64)),
+							int32 (rawBlock ["version"].(float64)),
+							int64 (rawBlock ["time"].(float64)),
+							txIds,
+							getString ("merkleroot"),
+							getString ("bits"),
+							uint32 (getNumber ("nonce")),
+							getNumber ("difficulty"),
+							getString ("chainwork"),
+							int64 (getNumber ("mediantime")),
+							uint32 (getNumber ("size")),
+							uint32 (getNumber ("strippedsize")),
+							uint32 (getNumber ("weight")))
 }
 
 func makeTx (rawTx map [string] interface {}) btc.Tx {
 
-	isBip141 := rawTx ["hex"].(string) [8:10] == "00"
+	// transactions that were decoded by scantool have a bip141 field instead of the hex
+	isBip141 := false
+	if rawTx ["hex"] != nil {
+		isBip141 = rawTx ["hex"].(string) [8:10] == "00"
+	} else if rawTx ["bip141"] != nil {
+		isBip141 = rawTx ["bip141"].(bool)
+	}
 
 	// outputs
 	vout := rawTx ["vout"].([] interface {})
@@ -176,19 +260,18 @@ func makeTx (rawTx map [string] interface {}) btc.Tx {
 			previousOutputIndex = uint16 (rawInput ["vout"].(float64))
 		}
 
+		// like decoded transactions, inputs with no segwit fields have no segwit
 		segwit := btc.Segwit {}
-		if isBip141 {
+		if isBip141 && rawInput ["txinwitness"] != nil {
 			segwitFields := make ([] [] byte, 0)
-			if rawInput ["txinwitness"] != nil {
-				rawSegwitFields := rawInput ["txinwitness"].([] interface {})
-				segwitFieldCount := len (rawSegwitFields)
-				for s := 0; s < segwitFieldCount; s++ {
-					segwitField, _ := hex.DecodeString (rawSegwitFields [s].(string))
-					segwitFields = append (segwitFields, segwitField)
-				}
+			rawSegwitFields := rawInput ["txinwitness"].([] interface {})
+			segwitFieldCount := len (rawSegwitFields)
+			for s := 0; s < segwitFieldCount; s++ {
+				segwitField, _ := hex.DecodeString (rawSegwitFields [s].(string))
+				segwitFields = append (segwitFields, segwitField)
 			}
 
-			segwit = btc.NewSegwit (segwitFields)
+			if len (segwitFields) > 0 { segwit = btc.NewSegwit (segwitFields) }
 		}
 
 		previousOutput := btc.Output {}
@@ -207,6 +290,12 @@ func makeTx (rawTx map [string] interface {}) btc.Tx {
 									previousOutput)
 	}
 
+	// unconfirmed transactions have no block
+	blockHash := ""
+	blockTime := int64 (0)
+	if rawTx ["blockhash"] != nil { blockHash = rawTx ["blockhash"].(string) }
+	if rawTx ["blocktime"] != nil { blockTime = int64 (rawTx ["blocktime"].(float64)) }
+
 	return btc.NewTx (	rawTx ["txid"].(string),
 						uint32 (rawTx ["version"].(float64)),
 						inputs,
@@ -214,8 +303,39 @@ func makeTx (rawTx map [string] interface {}) btc.Tx {
 						uint32 (rawTx ["locktime"].(float64)),
 						inputs [0].IsCoinbase (),
 						isBip141,
-						rawTx ["blockhash"].(string),
-						int64 (rawTx ["blocktime"].(float64)))
+						blockHash,
+						blockTime)
+}
+
+func makeMempoolEntry (rawEntry map [string] interface {}) btc.MempoolEntry {
+
+	// older versions of Bitcoin Core only have the fee field
+	fee := uint64 (0)
+	rawFee := rawEntry ["fee"]
+	if rawEntry ["fees"] != nil { rawFee = rawEntry ["fees"].(map [string] interface {}) ["base"] }
+	if rawFee != nil {
+		dFee := decimal.NewFromFloat (rawFee.(float64))
+		fee = uint64 (dFee.Mul (decimal.NewFromInt (100000000)).IntPart ())
+	}
+
+	getTxIds := func (field string) [] string {
+		txIds := make ([] string, 0)
+		if rawEntry [field] == nil { return txIds }
+		for _, txId := range rawEntry [field].([] interface {}) {
+			txIds = append (txIds, txId.(string))
+		}
+		return txIds
+	}
+
+	return btc.NewMempoolEntry (	int64 (rawEntry ["time"].(float64)),
+									fee,
+									uint32 (rawEntry ["vsize"].(float64)),
+									uint32 (rawEntry ["ancestorcount"].(float64)),
+									uint32 (rawEntry ["ancestorsize"].(float64)),
+									uint32 (rawEntry ["descendantcount"].(float64)),
+									uint32 (rawEntry ["descendantsize"].(float64)),
+									getTxIds ("depends"),
+									getTxIds ("spentby"))
 }
 
 // this is a pass-through function
@@ -345,6 +465,40 @@ func (c *btcCache) getBlock (blockKey string) btc.Block {
 	return <- r
 }
 
+// blocks published by the node are decoded by scantool, so only the fields that depend on the rest of the chain are requested
+// the block and its transactions are cached before anyone asks for them
+func (c *btcCache) addRawBlock (rawBlock btc.RawBlock) btc.Block {
+
+	header, err := c.btcNode.getBlockHeader (rawBlock.GetHash ())
+	if err != nil {
+		fmt.Println (fmt.Sprintf ("NODE ERROR: %s", err.Error ()))
+		return btc.Block {}
+	}
+
+	getNumber := func (field string) float64 {
+		if header [field] == nil { return 0 }
+		return header [field].(float64)
+	}
+	getString := func (field string) string {
+		if header [field] == nil { return "" }
+		return header [field].(string)
+	}
+
+	block := rawBlock.ToBlock (uint32 (getNumber ("height")), getString ("nextblockhash"), getNumber ("difficulty"), getString ("chainwork"), int64 (getNumber ("mediantime")))
+
+	if c.caching {
+		c.channel.block <- block
+		for _, tx := range rawBlock.GetTxs () { c.channel.tx <- tx }
+	}
+
+	return block
+}
+
+// mempool transactions published by the node are cached before anyone asks for them
+func (c *btcCache) addMempoolTx (tx btc.Tx) {
+	if c.caching { c.channel.tx <- tx }
+}
+
 func (c *btcCache) threadTxFromNode (txId string, withPreviousOutputs bool, r chan<- btc.Tx) {
 
 	rawTx, err := c.btcNode.getTx (txId)
@@ -395,7 +549,18 @@ func (c *btcCache) threadTxFromNode (txId string, withPreviousOutputs bool, r ch
 		// create the tx and cache it
 		tx = makeTx (rawTx)
 
-		if c.caching { c.channel.tx <- tx }
+		if tx.IsConfirmed () {
+			if c.caching { c.channel.tx <- tx }
+		} else {
+			// mempool transactions requested from the node are not cached, only the ones the node publishes are
+			// Esplora has no mempool entries, so its transactions do not have one
+			rawEntry, err := c.btcNode.getMempoolEntry (txId)
+			if err == nil {
+				tx.SetMempoolEntry (makeMempoolEntry (rawEntry))
+			} else if err != errNotSupportedByEsplora {
+				fmt.Println ("NODE ERROR: " + err.Error ())
+			}
+		}
 	}
 
 	// return it to the caller
@@ -416,6 +581,13 @@ func (c *btcCache) getTx (txId string, withPreviousOutputs bool) btc.Tx {
 		found = !t.tx.IsNil ()
 	}
 
+	// a cached mempool transaction is only used while it is in the mempool, otherwise the node has it with its block
+	if found && !t.tx.IsConfirmed () {
+		rawEntry, err := c.btcNode.getMempoolEntry (txId)
+		found = err == nil
+		if found { t.tx.SetMempoolEntry (makeMempoolEntry (rawEntry)) }
+	}
+
 	if found {
 
 		txCacheObj := t
@@ -463,6 +635,66 @@ func (c *btcCache) getOutput (txId string, outputIndex uint16) btc.Output {
 	return tx.GetOutput (outputIndex)
 }
 
+// this is a pass-through function
+// the mempool is never cached
+// returns the ids of every transaction in the mempool, most recently seen first
+func (c *btcCache) getMempoolTxIds () [] string {
+
+	rawMempool, err := c.btcNode.getRawMempool ()
+	if err != nil {
+		fmt.Println (err.Error ())
+		return [] string {}
+	}
+
+	// entries without a time were seen before any of the others, so they are listed last
+	txIds := make ([] string, 0, len (rawMempool))
+	timesFirstSeen := make (map [string] float64, len (rawMempool))
+	for txId, rawEntry := range rawMempool {
+		txIds = append (txIds, txId)
+		if timeFirstSeen, hasTime := rawEntry.(map [string] interface {}) ["time"].(float64); hasTime { timesFirstSeen [txId] = timeFirstSeen }
+	}
+
+	sort.Slice (txIds, func (a, b int) bool {
+		if timesFirstSeen [txIds [a]] != timesFirstSeen [txIds [b]] { return timesFirstSeen [txIds [a]] > timesFirstSeen [txIds [b]] }
+		return txIds [a] < txIds [b]
+	})
+
+	return txIds
+}
+
+// this is a pass-through function
+// spends are never cached because an unspent output can be spent at any time
+// blocks are not searched, so the spending transaction is only found when it is in the mempool
+func (c *btcCache) getOutputSpend (txId string, outputIndex uint16) btc.OutputSpend {
+
+	txOut, err := c.btcNode.getTxOut (txId, uint32 (outputIndex))
+	if err != nil {
+		fmt.Println (err.Error ())
+		return btc.OutputSpend {}
+	}
+	if txOut != nil { return btc.NewOutputSpend (btc.OUTPUT_UNSPENT, "", 0, "", 0) }
+
+	// older nodes do not support this, in which case the spending transaction is unknown
+	spendingTxId, err := c.btcNode.getTxSpendingPrevout (txId, uint32 (outputIndex))
+	if err != nil || len (spendingTxId) == 0 {
+
+		// gettxout also returns nothing for outputs that do not exist
+		tx := c.getTx (txId, false)
+		if tx.IsNil () || outputIndex >= tx.GetOutputCount () { return btc.OutputSpend {} }
+
+		return btc.NewOutputSpend (btc.OUTPUT_SPENT, "", 0, "", 0)
+	}
+
+	spendingTx := c.getTx (spendingTxId, true)
+	for i, input := range spendingTx.GetInputs () {
+		if input.GetPreviousOutputTxId () == txId && input.GetPreviousOutputIndex () == outputIndex {
+			return btc.NewOutputSpend (btc.OUTPUT_SPENT, spendingTxId, uint32 (i), input.GetSpendType (), -1)
+		}
+	}
+
+	return btc.NewOutputSpend (btc.OUTPUT_SPENT, "", 0, "", 0)
+}
+
 func (c *btcCache) GetNodeVersionStr () string {
 	return c.btcNode.GetVersionString ()
 }
@@ -533,7 +765,8 @@ func run (channel cacheThreadChannelPack) {
 					t := txMap [txId]
 					txCacheMutex.Unlock ()
 
-					found := !t.tx.IsNil ()
+					// a mempool transaction is replaced once it is in a block
+					found := !t.tx.IsNil () && (t.tx.IsConfirmed () || !tx.IsConfirmed ())
 					if found {
 
 						if t.timestampLastUsed == now { return }
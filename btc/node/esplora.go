@@ -0,0 +1,356 @@
+package node
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"errors"
+	"strings"
+	"strconv"
+	"net/http"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/btc-script-explorer/scantool/app"
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// a node client for the Esplora REST API, which is also served by electrs
+// blocks and transactions are requested raw and decoded by scantool, then returned as the same JSON Bitcoin Core returns
+// Esplora does not have everything Bitcoin Core has:
+//     there are no mempool entries, and the time a mempool transaction was first seen is the time scantool first found it
+//     blocks have no chainwork
+//     the network is found from the genesis block and the default activation heights are used
+
+const ESPLORA_TIMEOUT = 30 * time.Second
+
+var errEsploraNotFound = errors.New ("ESPLORA ERROR: Not found.")
+
+// returned for the requests that Esplora has nothing for, the callers can do without them
+var errNotSupportedByEsplora = errors.New ("ESPLORA ERROR: Not supported by Esplora.")
+
+type Esplora struct {
+	url string
+	client *http.Client
+
+	// tx id -> time first seen, or nil if the transaction was already in the mempool the first time it was requested
+	mempoolTimes map [string] interface {}
+	mempoolMutex sync.Mutex
+}
+
+func NewEsplora () (*Esplora, error) {
+
+	e := Esplora { url: strings.TrimSuffix (app.Settings.GetNodeFullUrl (), "/"), client: &http.Client { Timeout: ESPLORA_TIMEOUT } }
+	if len (e.getBestBlockHash ()) == 0 { return nil, errors.New ("Failed to connect to Esplora.") }
+	return &e, nil
+}
+
+func (e *Esplora) getNodeType () string {
+	return "Esplora"
+}
+
+func (e *Esplora) GetVersionString () string {
+	return e.getNodeType ()
+}
+
+// the Esplora API does not have a version
+func (e *Esplora) getVersionStr () string {
+	return ""
+}
+
+// API functions
+
+// the block is returned with the fields of the Bitcoin Core getblock JSON
+// with the tx data, the block is decoded from its raw bytes, otherwise only the tx ids are included
+func (e *Esplora) getBlock (blockHash string, withTxData bool) (map [string] interface {}, error) {
+
+	block, err := e.getBlockHeader (blockHash)
+	if err != nil { return nil, err }
+
+	if !withTxData {
+		var txIds [] interface {}
+		if err := e.getJson ("/block/" + blockHash + "/txids", &txIds); err != nil { return nil, err }
+		block ["tx"] = txIds
+		return block, nil
+	}
+
+	rawBytes, err := e.get ("/block/" + blockHash + "/raw")
+	if err != nil { return nil, err }
+
+	rawBlock, err := btc.DecodeRawBlock (rawBytes)
+	if err != nil { return nil, errors.New ("ESPLORA ERROR: " + err.Error ()) }
+	if rawBlock.GetHash () != blockHash { return nil, errors.New ("ESPLORA ERROR: Block " + blockHash + " has the wrong hash.") }
+
+	rawTxs := rawBlock.GetTxs ()
+	txs := make ([] interface {}, len (rawTxs))
+	for t, tx := range rawTxs { txs [t] = makeRawTxJson (tx) }
+	block ["tx"] = txs
+
+	return block, nil
+}
+
+func (e *Esplora) getBlockHeader (blockHash string) (map [string] interface {}, error) {
+
+	if !isEsploraHash (blockHash) { return nil, errors.New ("ESPLORA ERROR: " + blockHash + " is not a valid block hash.") }
+
+	var esploraBlock map [string] interface {}
+	if err := e.getJson ("/block/" + blockHash, &esploraBlock); err != nil { return nil, err }
+
+	var blockStatus map [string] interface {}
+	if err := e.getJson ("/block/" + blockHash + "/status", &blockStatus); err != nil { return nil, err }
+
+	// bits is a number in Esplora and a hex string in Bitcoin Core
+	bits := ""
+	if esploraBlock ["bits"] != nil { bits = fmt.Sprintf ("%08x", uint32 (esploraBlock ["bits"].(float64))) }
+
+	header := map [string] interface {} {	"hash": esploraBlock ["id"],
+											"height": esploraBlock ["height"],
+											"version": esploraBlock ["version"],
+											"time": esploraBlock ["timestamp"],
+											"mediantime": esploraBlock ["mediantime"],
+											"merkleroot": esploraBlock ["merkle_root"],
+											"bits": bits,
+											"nonce": esploraBlock ["nonce"],
+											"difficulty": esploraBlock ["difficulty"],
+											"size": esploraBlock ["size"],
+											"weight": esploraBlock ["weight"],
+											"nTx": esploraBlock ["tx_count"] }
+
+	if esploraBlock ["size"] != nil && esploraBlock ["weight"] != nil {
+		header ["strippedsize"] = (esploraBlock ["weight"].(float64) - esploraBlock ["size"].(float64)) / 3
+	}
+
+	if esploraBlock ["previousblockhash"] != nil { header ["previousblockhash"] = esploraBlock ["previousblockhash"] }
+	if blockStatus ["next_best"] != nil { header ["nextblockhash"] = blockStatus ["next_best"] }
+
+	return header, nil
+}
+
+func (e *Esplora) getBestBlockHash () string {
+	blockHash, err := e.get ("/blocks/tip/hash")
+	if err != nil { fmt.Println (err.Error ()); return "" }
+
+	return strings.TrimSpace (string (blockHash))
+}
+
+func (e *Esplora) getBlockHash (blockHeight uint32) string {
+	blockHash, err := e.get ("/block-height/" + strconv.FormatUint (uint64 (blockHeight), 10))
+	if err != nil { fmt.Println (err.Error ()); return "" }
+
+	return strings.TrimSpace (string (blockHash))
+}
+
+func (e *Esplora) getTx (txId string) (map [string] interface {}, error) {
+
+	if !isEsploraHash (txId) { return nil, errors.New ("ESPLORA ERROR: " + txId + " is not a valid tx id.") }
+
+	// like Bitcoin Core, the genesis transaction is not returned by every Esplora server
+	network := btc.GetNetwork ()
+	txHex := network.GetGenesisTxHex ()
+	if txId != network.GetGenesisTxId () {
+		rawHex, err := e.get ("/tx/" + txId + "/hex")
+		if err != nil { return nil, err }
+		txHex = strings.TrimSpace (string (rawHex))
+	}
+
+	rawBytes, err := hex.DecodeString (txHex)
+	if err != nil { return nil, errors.New ("ESPLORA ERROR: " + err.Error ()) }
+
+	tx, err := btc.DecodeRawTx (rawBytes)
+	if err != nil { return nil, errors.New ("ESPLORA ERROR: " + err.Error ()) }
+	if tx.GetTxId () != txId { return nil, errors.New ("ESPLORA ERROR: Transaction " + txId + " has the wrong id.") }
+
+	rawTx := makeRawTxJson (tx)
+	rawTx ["hex"] = txHex
+
+	if txId == network.GetGenesisTxId () {
+		rawTx ["blockhash"] = network.GetGenesisBlockHash ()
+		rawTx ["blocktime"] = float64 (network.GetGenesisBlockTime ())
+		return rawTx, nil
+	}
+
+	var txStatus map [string] interface {}
+	if err := e.getJson ("/tx/" + txId + "/status", &txStatus); err != nil { return nil, err }
+
+	if txStatus ["confirmed"] != nil && txStatus ["confirmed"].(bool) {
+		rawTx ["blockhash"] = txStatus ["block_hash"]
+		rawTx ["blocktime"] = txStatus ["block_time"]
+	}
+
+	return rawTx, nil
+}
+
+// Esplora only knows the chain by its blocks, so the network is found from the genesis block
+func (e *Esplora) getBlockchainInfo () (map [string] interface {}, error) {
+
+	genesisBlockHash := e.getBlockHash (0)
+	if len (genesisBlockHash) == 0 { return nil, errors.New ("ESPLORA ERROR: No genesis block.") }
+
+	chain := btc.GetNetworkNameFromGenesisBlock (genesisBlockHash)
+	if len (chain) == 0 { return nil, errors.New ("ESPLORA ERROR: Unknown genesis block " + genesisBlockHash + ".") }
+
+	return map [string] interface {} { "chain": chain }, nil
+}
+
+// Esplora does not have the deployments, so the default activation heights are used
+func (e *Esplora) getDeployments () (map [string] interface {}, error) {
+	return nil, errNotSupportedByEsplora
+}
+
+// Esplora does not have the fee, size and ancestor details of mempool transactions
+func (e *Esplora) getMempoolEntry (txId string) (map [string] interface {}, error) {
+	return nil, errNotSupportedByEsplora
+}
+
+// returns a map of tx id -> mempool entry for every transaction in the mempool
+// Esplora does not say when a transaction was first seen, so the time is when it was first found here
+// nothing is known about the transactions that were already in the mempool the first time, so their entries have no time
+func (e *Esplora) getRawMempool () (map [string] interface {}, error) {
+
+	var txIds [] string
+	if err := e.getJson ("/mempool/txids", &txIds); err != nil { return nil, err }
+
+	fetchTime := float64 (time.Now ().Unix ())
+
+	e.mempoolMutex.Lock ()
+	defer e.mempoolMutex.Unlock ()
+
+	firstRequest := e.mempoolTimes == nil
+
+	// transactions that are no longer in the mempool are forgotten
+	mempoolTimes := make (map [string] interface {}, len (txIds))
+	rawMempool := make (map [string] interface {}, len (txIds))
+	for _, txId := range txIds {
+		timeFirstSeen, found := e.mempoolTimes [txId]
+		if !found && !firstRequest { timeFirstSeen = fetchTime }
+		mempoolTimes [txId] = timeFirstSeen
+
+		rawEntry := make (map [string] interface {})
+		if timeFirstSeen != nil { rawEntry ["time"] = timeFirstSeen }
+		rawMempool [txId] = rawEntry
+	}
+	e.mempoolTimes = mempoolTimes
+
+	return rawMempool, nil
+}
+
+// returns nil without an error if the output has been spent, including by a transaction in the mempool
+func (e *Esplora) getTxOut (txId string, outputIndex uint32) (map [string] interface {}, error) {
+
+	outspend, err := e.getOutspend (txId, outputIndex)
+	if err == errEsploraNotFound { return nil, nil }
+	if err != nil { return nil, err }
+
+	if outspend ["spent"] != nil && outspend ["spent"].(bool) { return nil, nil }
+
+	return outspend, nil
+}
+
+// unlike Bitcoin Core, this also finds spending transactions that are in blocks
+// returns an empty string if no transaction spends the output
+func (e *Esplora) getTxSpendingPrevout (txId string, outputIndex uint32) (string, error) {
+
+	outspend, err := e.getOutspend (txId, outputIndex)
+	if err != nil { return "", err }
+
+	if outspend ["txid"] == nil { return "", nil }
+
+	return outspend ["txid"].(string), nil
+}
+
+func (e *Esplora) getOutspend (txId string, outputIndex uint32) (map [string] interface {}, error) {
+
+	if !isEsploraHash (txId) { return nil, errors.New ("ESPLORA ERROR: " + txId + " is not a valid tx id.") }
+
+	var outspend map [string] interface {}
+	err := e.getJson ("/tx/" + txId + "/outspend/" + strconv.FormatUint (uint64 (outputIndex), 10), &outspend)
+
+	return outspend, err
+}
+
+func (e *Esplora) getJson (path string, result interface {}) error {
+
+	body, err := e.get (path)
+	if err != nil { return err }
+
+	if err := json.Unmarshal (body, result); err != nil { return errors.New ("JSON ERROR: " + err.Error ()) }
+
+	return nil
+}
+
+// returns the body of a successful response, Esplora puts the error message in the body of every other response
+func (e *Esplora) get (path string) ([] byte, error) {
+
+	response, err := e.client.Get (e.url + path)
+	if err != nil { return nil, errors.New ("ESPLORA ERROR: " + err.Error ()) }
+	defer response.Body.Close ()
+
+	body, err := io.ReadAll (response.Body)
+	if err != nil { return nil, errors.New ("ESPLORA ERROR: " + err.Error ()) }
+
+	if response.StatusCode == http.StatusNotFound { return nil, errEsploraNotFound }
+	if response.StatusCode != http.StatusOK { return nil, errors.New ("ESPLORA ERROR: " + strings.TrimSpace (string (body))) }
+
+	return body, nil
+}
+
+// block hashes and tx ids are part of the request paths, so anything else is rejected before a request is made
+func isEsploraHash (hash string) bool {
+	if len (hash) != 64 { return false }
+	_, err := hex.DecodeString (hash)
+	return err == nil
+}
+
+// creates the same JSON that getrawtransaction would return for a transaction decoded by scantool
+// instead of the hex, there is a bip141 field, and there is no block unless the transaction came from a decoded block
+func makeRawTxJson (tx btc.Tx) map [string] interface {} {
+
+	vin := make ([] interface {}, tx.GetInputCount ())
+	for i, input := range tx.GetInputs () {
+
+		inputScript := input.GetInputScript ()
+		rawInput := map [string] interface {} { "sequence": float64 (input.GetSequence ()) }
+		if input.IsCoinbase () {
+			rawInput ["coinbase"] = inputScript.AsHex ()
+		} else {
+			rawInput ["txid"] = input.GetPreviousOutputTxId ()
+			rawInput ["vout"] = float64 (input.GetPreviousOutputIndex ())
+			rawInput ["scriptSig"] = map [string] interface {} { "hex": inputScript.AsHex () }
+		}
+
+		// like Bitcoin Core, inputs without segwit fields do not have a txinwitness
+		segwit := input.GetSegwit ()
+		if segwit.GetFieldCount () > 0 {
+			segwitFields := make ([] interface {}, segwit.GetFieldCount ())
+			for f, field := range segwit.GetFields () { segwitFields [f] = field.AsHex () }
+			rawInput ["txinwitness"] = segwitFields
+		}
+
+		vin [i] = rawInput
+	}
+
+	vout := make ([] interface {}, tx.GetOutputCount ())
+	for o, output := range tx.GetOutputs () {
+
+		outputScript := output.GetOutputScript ()
+		scriptPubKey := map [string] interface {} { "hex": outputScript.AsHex () }
+		if len (output.GetAddress ()) > 0 { scriptPubKey ["address"] = output.GetAddress () }
+
+		vout [o] = map [string] interface {} { "value": float64 (output.GetValue ()) / 100000000, "n": float64 (o), "scriptPubKey": scriptPubKey }
+	}
+
+	rawTx := map [string] interface {} {	"txid": tx.GetTxId (),
+											"version": float64 (tx.GetVersion ()),
+											"locktime": float64 (tx.GetLockTime ()),
+											"vin": vin,
+											"vout": vout,
+											"bip141": tx.SupportsBip141 () }
+
+	if tx.IsConfirmed () {
+		rawTx ["blockhash"] = tx.GetBlockHash ()
+		rawTx ["blocktime"] = float64 (tx.GetBlockTime ())
+	}
+
+	return rawTx
+}
@@ -0,0 +1,256 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"strings"
+	"testing"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// a p2wpkh spend with one p2wpkh output
+const testSegwitTxHex = "02000000000101" + "1111111111111111111111111111111111111111111111111111111111111111" + "00000000" + "00" + "ffffffff" +
+						"01" + "e803000000000000" + "160014" + "2222222222222222222222222222222222222222" +
+						"02" + "47" + "3044022011111111111111111111111111111111111111111111111111111111111111110220222222222222222222222222222222222222222222222222222222222222222201" +
+						"21" + "023333333333333333333333333333333333333333333333333333333333333333" + "00000000"
+
+const testNextBlockHash = "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048"
+
+// a stand-in for an Esplora server that returns the responses it is given, and 404 for every other path
+type testEsploraServer struct {
+	server *httptest.Server
+	responses map [string] string
+	statusCodes map [string] int
+	requests [] string
+	mutex sync.Mutex
+}
+
+func newTestEsplora (t *testing.T) (*Esplora, *testEsploraServer) {
+
+	s := &testEsploraServer { responses: make (map [string] string), statusCodes: make (map [string] int) }
+	s.server = httptest.NewServer (http.HandlerFunc (func (response http.ResponseWriter, request *http.Request) {
+		s.mutex.Lock ()
+		defer s.mutex.Unlock ()
+
+		s.requests = append (s.requests, request.URL.Path)
+		if statusCode, found := s.statusCodes [request.URL.Path]; found { response.WriteHeader (statusCode) }
+
+		body, found := s.responses [request.URL.Path]
+		if !found { http.Error (response, "not found", http.StatusNotFound); return }
+		fmt.Fprint (response, body)
+	}))
+	t.Cleanup (s.server.Close)
+
+	return &Esplora { url: s.server.URL, client: s.server.Client () }, s
+}
+
+func (s *testEsploraServer) set (path string, body string) {
+	s.mutex.Lock ()
+	s.responses [path] = body
+	s.mutex.Unlock ()
+}
+
+func (s *testEsploraServer) setError (path string, statusCode int, body string) {
+	s.mutex.Lock ()
+	s.responses [path] = body
+	s.statusCodes [path] = statusCode
+	s.mutex.Unlock ()
+}
+
+func (s *testEsploraServer) getRequestCount () int {
+	s.mutex.Lock ()
+	defer s.mutex.Unlock ()
+	return len (s.requests)
+}
+
+// the genesis block the way Esplora returns it
+func setTestGenesisBlock (t *testing.T, s *testEsploraServer) string {
+
+	network := btc.GetNetwork ()
+	genesisHash := network.GetGenesisBlockHash ()
+
+	s.set ("/block/" + genesisHash, fmt.Sprintf (`{ "id": "%s", "height": 0, "version": 1, "timestamp": %d, "mediantime": %d, "tx_count": 1, "size": 285, "weight": 1140,
+												"merkle_root": "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b", "nonce": 2083236893, "bits": 486604799, "difficulty": 1 }`,
+												genesisHash, network.GetGenesisBlockTime (), network.GetGenesisBlockTime ()))
+	s.set ("/block/" + genesisHash + "/status", `{ "in_best_chain": true, "height": 0, "next_best": "` + testNextBlockHash + `" }`)
+	s.set ("/block/" + genesisHash + "/txids", `[ "` + network.GetGenesisTxId () + `" ]`)
+	s.set ("/block/" + genesisHash + "/raw", string (decodeTestHex (t, testGenesisHeaderHex + "01" + network.GetGenesisTxHex ())))
+
+	return genesisHash
+}
+
+func TestEsploraBlock (t *testing.T) {
+
+	e, s := newTestEsplora (t)
+	genesisHash := setTestGenesisBlock (t, s)
+	network := btc.GetNetwork ()
+
+	rawBlock, err := e.getBlock (genesisHash, true)
+	if err != nil { t.Fatal (err) }
+
+	// the fields are converted to the ones Bitcoin Core has
+	block := makeBlock (rawBlock)
+	if block.GetHash () != genesisHash || block.GetHeight () != 0 || block.GetNextHash () != testNextBlockHash || block.GetBits () != "1d00ffff" { t.Errorf ("block %s %d %s %s", block.GetHash (), block.GetHeight (), block.GetNextHash (), block.GetBits ()) }
+	if block.GetSize () != 285 || block.GetStrippedSize () != 285 || block.GetWeight () != 1140 || block.GetTimestamp () != network.GetGenesisBlockTime () { t.Errorf ("size %d, stripped size %d, weight %d", block.GetSize (), block.GetStrippedSize (), block.GetWeight ()) }
+	if !block.IsMerkleRootValid () { t.Error ("the merkle root is not valid") }
+
+	// the transactions are decoded from the raw block
+	rawTxs := rawBlock ["tx"].([] interface {})
+	if len (rawTxs) != 1 { t.Fatalf ("%d transactions", len (rawTxs)) }
+	tx := makeTx (rawTxs [0].(map [string] interface {}))
+	output := tx.GetOutput (0)
+	if tx.GetTxId () != network.GetGenesisTxId () || !tx.IsCoinbase () || tx.SupportsBip141 () || output.GetValue () != 5000000000 || tx.GetBlockHash () != genesisHash { t.Errorf ("tx %s", tx.GetTxId ()) }
+
+	// without the tx data, there are only the tx ids
+	rawBlock, err = e.getBlock (genesisHash, false)
+	if err != nil { t.Fatal (err) }
+	if txIds := rawBlock ["tx"].([] interface {}); len (txIds) != 1 || txIds [0] != network.GetGenesisTxId () { t.Errorf ("tx ids %v", txIds) }
+
+	// a raw block that does not have the hash that was requested
+	otherHash := strings.Repeat ("ab", 32)
+	s.set ("/block/" + otherHash, `{ "id": "` + otherHash + `", "height": 1 }`)
+	s.set ("/block/" + otherHash + "/status", `{ "in_best_chain": true }`)
+	s.set ("/block/" + otherHash + "/raw", string (decodeTestHex (t, testGenesisHeaderHex + "01" + network.GetGenesisTxHex ())))
+	if _, err := e.getBlock (otherHash, true); err == nil || !strings.Contains (err.Error (), "wrong hash") { t.Errorf ("error %v", err) }
+}
+
+func TestEsploraTx (t *testing.T) {
+
+	e, s := newTestEsplora (t)
+	genesisHash := setTestGenesisBlock (t, s)
+
+	segwitTx := decodeTestTx (t, testSegwitTxHex)
+	txId := segwitTx.GetTxId ()
+	s.set ("/tx/" + txId + "/hex", testSegwitTxHex + "\n")
+	s.set ("/tx/" + txId + "/status", `{ "confirmed": true, "block_hash": "` + genesisHash + `", "block_height": 0, "block_time": 1231006505 }`)
+
+	rawTx, err := e.getTx (txId)
+	if err != nil { t.Fatal (err) }
+
+	tx := makeTx (rawTx)
+	input := tx.GetInput (0)
+	segwit := input.GetSegwit ()
+	output := tx.GetOutput (0)
+	if tx.GetTxId () != txId || !tx.SupportsBip141 () || tx.GetBlockHash () != genesisHash || tx.GetBlockTime () != 1231006505 { t.Errorf ("tx %s in block %s", tx.GetTxId (), tx.GetBlockHash ()) }
+	if segwit.GetFieldCount () != 2 || input.GetPreviousOutputTxId () != strings.Repeat ("11", 32) || output.GetValue () != 1000 { t.Error ("the inputs and outputs do not match the raw transaction") }
+
+	// unconfirmed
+	s.set ("/tx/" + txId + "/status", `{ "confirmed": false }`)
+	rawTx, err = e.getTx (txId)
+	if err != nil { t.Fatal (err) }
+	if rawTx ["blockhash"] != nil { t.Errorf ("an unconfirmed transaction is in block %v", rawTx ["blockhash"]) }
+
+	// the genesis transaction is not requested
+	requestCount := s.getRequestCount ()
+	network := btc.GetNetwork ()
+	rawTx, err = e.getTx (network.GetGenesisTxId ())
+	if err != nil || rawTx ["blockhash"] != genesisHash || s.getRequestCount () != requestCount { t.Errorf ("genesis tx %v, error %v", rawTx, err) }
+
+	// a transaction that does not have the id that was requested
+	otherTxId := strings.Repeat ("ab", 32)
+	s.set ("/tx/" + otherTxId + "/hex", testSegwitTxHex)
+	if _, err := e.getTx (otherTxId); err == nil || !strings.Contains (err.Error (), "wrong id") { t.Errorf ("error %v", err) }
+}
+
+func TestEsploraTip (t *testing.T) {
+
+	e, s := newTestEsplora (t)
+	genesisHash := setTestGenesisBlock (t, s)
+
+	s.set ("/blocks/tip/hash", genesisHash + "\n")
+	s.set ("/block-height/0", genesisHash)
+	if e.getBestBlockHash () != genesisHash || e.getBlockHash (0) != genesisHash { t.Error ("the tip is not the genesis block") }
+	if e.getBlockHash (1) != "" { t.Error ("a block that does not exist has a hash") }
+
+	blockchainInfo, err := e.getBlockchainInfo ()
+	if err != nil || blockchainInfo ["chain"] != "main" { t.Errorf ("chain %v, error %v", blockchainInfo, err) }
+
+	s.set ("/block-height/0", strings.Repeat ("00", 32))
+	if _, err := e.getBlockchainInfo (); err == nil { t.Error ("an unknown genesis block has a chain") }
+}
+
+func TestEsploraMempool (t *testing.T) {
+
+	e, s := newTestEsplora (t)
+	a, b, c := strings.Repeat ("a", 64), strings.Repeat ("b", 64), strings.Repeat ("c", 64)
+
+	// nothing is known about the transactions that were already there
+	s.set ("/mempool/txids", `[ "` + a + `", "` + b + `" ]`)
+	rawMempool, err := e.getRawMempool ()
+	if err != nil { t.Fatal (err) }
+	if len (rawMempool) != 2 || rawMempool [a].(map [string] interface {}) ["time"] != nil { t.Errorf ("mempool %v", rawMempool) }
+
+	s.set ("/mempool/txids", `[ "` + b + `", "` + c + `" ]`)
+	rawMempool, err = e.getRawMempool ()
+	if err != nil { t.Fatal (err) }
+	if len (rawMempool) != 2 || rawMempool [b].(map [string] interface {}) ["time"] != nil || rawMempool [c].(map [string] interface {}) ["time"] == nil { t.Errorf ("mempool %v", rawMempool) }
+
+	// the most recent transactions are listed first
+	cache := btcCache { btcNode: e }
+	if txIds := cache.getMempoolTxIds (); strings.Join (txIds, ",") != strings.Join ([] string { c, b }, ",") { t.Errorf ("tx ids %v", txIds) }
+	if _, found := e.mempoolTimes [a]; found { t.Error ("a transaction that left the mempool was not forgotten") }
+
+	// there are no mempool entries, and the transactions do not get one
+	if _, err := e.getMempoolEntry (c); err != errNotSupportedByEsplora { t.Errorf ("error %v", err) }
+
+	segwitTx := decodeTestTx (t, testSegwitTxHex)
+	s.set ("/tx/" + segwitTx.GetTxId () + "/hex", testSegwitTxHex)
+	s.set ("/tx/" + segwitTx.GetTxId () + "/status", `{ "confirmed": false }`)
+	tx := cache.getTx (segwitTx.GetTxId (), false)
+	mempoolEntry := tx.GetMempoolEntry ()
+	if tx.IsNil () || tx.IsConfirmed () || !mempoolEntry.IsNil () { t.Errorf ("tx %s", tx.GetTxId ()) }
+}
+
+func TestEsploraOutspends (t *testing.T) {
+
+	e, s := newTestEsplora (t)
+	txId := strings.Repeat ("ab", 32)
+	spendingTxId := strings.Repeat ("cd", 32)
+
+	s.set ("/tx/" + txId + "/outspend/0", `{ "spent": false }`)
+	s.set ("/tx/" + txId + "/outspend/1", `{ "spent": true, "txid": "` + spendingTxId + `", "vin": 0 }`)
+
+	if txOut, err := e.getTxOut (txId, 0); err != nil || txOut == nil { t.Errorf ("unspent output %v, error %v", txOut, err) }
+	if txOut, err := e.getTxOut (txId, 1); err != nil || txOut != nil { t.Errorf ("spent output %v, error %v", txOut, err) }
+	if txOut, err := e.getTxOut (txId, 2); err != nil || txOut != nil { t.Errorf ("missing output %v, error %v", txOut, err) }
+
+	if spender, err := e.getTxSpendingPrevout (txId, 1); err != nil || spender != spendingTxId { t.Errorf ("spending tx %s, error %v", spender, err) }
+	if spender, err := e.getTxSpendingPrevout (txId, 0); err != nil || spender != "" { t.Errorf ("spending tx %s, error %v", spender, err) }
+}
+
+func TestEsploraErrors (t *testing.T) {
+
+	e, s := newTestEsplora (t)
+	txId := strings.Repeat ("ab", 32)
+
+	if _, err := e.getTx (txId); err != errEsploraNotFound { t.Errorf ("missing tx error %v", err) }
+	if _, err := e.getBlock (txId, true); err != errEsploraNotFound { t.Errorf ("missing block error %v", err) }
+
+	// the message is in the body
+	s.setError ("/tx/" + txId + "/hex", http.StatusBadRequest, "Invalid hex string\n")
+	if _, err := e.getTx (txId); err == nil || err.Error () != "ESPLORA ERROR: Invalid hex string" { t.Errorf ("error %v", err) }
+
+	s.set ("/mempool/txids", "not json")
+	if _, err := e.getRawMempool (); err == nil || !strings.HasPrefix (err.Error (), "JSON ERROR") { t.Errorf ("error %v", err) }
+
+	s.setError ("/blocks/tip/hash", http.StatusServiceUnavailable, "unavailable")
+	if e.getBestBlockHash () != "" { t.Error ("there is a tip without a server") }
+
+	// nothing that is not a hash is put in a path
+	requestCount := s.getRequestCount ()
+	for _, invalid := range [] string { "../../mempool", strings.Repeat ("zz", 32), txId + "/hex", "" } {
+		if _, err := e.getTx (invalid); err == nil { t.Errorf ("tx %s: no error", invalid) }
+		if _, err := e.getBlock (invalid, true); err == nil { t.Errorf ("block %s: no error", invalid) }
+		if _, err := e.getTxOut (invalid, 0); err == nil { t.Errorf ("output %s: no error", invalid) }
+	}
+	if s.getRequestCount () != requestCount { t.Error ("a request was made for an invalid hash") }
+
+	if _, err := e.getDeployments (); err != errNotSupportedByEsplora { t.Errorf ("deployments error %v", err) }
+
+	// the server is gone
+	s.server.Close ()
+	if _, err := e.getTx (txId); err == nil || err == errEsploraNotFound { t.Errorf ("error %v", err) }
+}
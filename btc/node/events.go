@@ -0,0 +1,106 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// new blocks and transactions announced by the node are passed to every consumer that subscribed to them
+// a consumer that falls behind misses events instead of holding up the others, so consumers should still check the node now and then
+
+const NODE_EVENT_BLOCK = "block"
+const NODE_EVENT_TX = "tx"
+
+const NODE_EVENT_BUFFER_SIZE = 1000
+
+// bitcoin core announces transactions again when they are added to a block, so recent transactions are only passed on once
+const MAX_RECENT_TX_IDS = 100000
+
+type NodeEvent struct {
+	eventType string
+	block btc.Block
+	rawTx [] byte
+}
+
+func (ne *NodeEvent) GetEventType () string {
+	return ne.eventType
+}
+
+// only set for block events
+func (ne *NodeEvent) GetBlock () btc.Block {
+	return ne.block
+}
+
+// only set for transaction events
+// the inputs do not have previous outputs, and the transaction is decoded every time so that every consumer can set them on its own copy
+func (ne *NodeEvent) GetTx () btc.Tx {
+	tx, err := btc.DecodeRawTx (ne.rawTx)
+	if err != nil { return btc.Tx {} }
+	return tx
+}
+
+type eventSubscriber struct {
+	events chan NodeEvent
+	includeTxs bool
+}
+
+var eventSubscribers [] eventSubscriber
+var eventSubscribersMutex sync.Mutex
+
+var lastBlockHash string
+var recentTxIds = make (map [string] bool)
+var recentTxIdOrder [] string
+
+// transaction events are only sent to subscribers that ask for them
+func SubscribeToNodeEvents (includeTxs bool) <-chan NodeEvent {
+
+	events := make (chan NodeEvent, NODE_EVENT_BUFFER_SIZE)
+
+	eventSubscribersMutex.Lock ()
+	eventSubscribers = append (eventSubscribers, eventSubscriber { events: events, includeTxs: includeTxs })
+	eventSubscribersMutex.Unlock ()
+
+	return events
+}
+
+// blocks can be announced by more than one topic, so each block is only passed on once
+func publishBlockEvent (block btc.Block) {
+
+	eventSubscribersMutex.Lock ()
+	defer eventSubscribersMutex.Unlock ()
+
+	if block.GetHash () == lastBlockHash { return }
+	lastBlockHash = block.GetHash ()
+
+	for _, s := range eventSubscribers {
+		select {
+			case s.events <- NodeEvent { eventType: NODE_EVENT_BLOCK, block: block }:
+			default:
+		}
+	}
+}
+
+func publishTxEvent (tx btc.Tx, rawTx [] byte) {
+
+	eventSubscribersMutex.Lock ()
+	defer eventSubscribersMutex.Unlock ()
+
+	txId := tx.GetTxId ()
+	if recentTxIds [txId] { return }
+
+	recentTxIds [txId] = true
+	recentTxIdOrder = append (recentTxIdOrder, txId)
+	if len (recentTxIdOrder) > MAX_RECENT_TX_IDS {
+		delete (recentTxIds, recentTxIdOrder [0])
+		recentTxIdOrder = recentTxIdOrder [1:]
+	}
+
+	for _, s := range eventSubscribers {
+		if !s.includeTxs { continue }
+		select {
+			case s.events <- NodeEvent { eventType: NODE_EVENT_TX, rawTx: rawTx }:
+			default:
+		}
+	}
+}
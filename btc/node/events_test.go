@@ -0,0 +1,102 @@
+package node
+
+import (
+	"fmt"
+	"time"
+	"testing"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// the subscribers and the recent blocks and transactions are shared by every test in the package
+func resetNodeEvents () {
+	eventSubscribersMutex.Lock ()
+	defer eventSubscribersMutex.Unlock ()
+
+	eventSubscribers = nil
+	lastBlockHash = ""
+	recentTxIds = make (map [string] bool)
+	recentTxIdOrder = nil
+}
+
+func newTestBlock (hash string) btc.Block {
+	return btc.NewBlock (hash, "", "", 1, 1, 0, [] string {}, "", "", 0, 1, "", 0, 0, 0, 0)
+}
+
+func newTestTx (txId string) btc.Tx {
+	return btc.NewTx (txId, 1, [] btc.Input {}, [] btc.Output {}, 0, false, false, "", 0)
+}
+
+func waitForNodeEvent (t *testing.T, events <-chan NodeEvent) NodeEvent {
+	t.Helper ()
+
+	select {
+		case event := <-events:
+			return event
+		case <-time.After (5 * time.Second):
+			t.Fatal ("no event")
+	}
+
+	return NodeEvent {}
+}
+
+func TestPublishBlockEventDedupes (t *testing.T) {
+
+	resetNodeEvents ()
+	events := SubscribeToNodeEvents (false)
+
+	// the same block can be announced by rawblock and hashblock
+	publishBlockEvent (newTestBlock ("aa"))
+	publishBlockEvent (newTestBlock ("aa"))
+	publishBlockEvent (newTestBlock ("bb"))
+	publishBlockEvent (newTestBlock ("aa"))
+
+	if len (events) != 3 { t.Fatalf ("%d events", len (events)) }
+	for _, expectedHash := range [] string { "aa", "bb", "aa" } {
+		event := <-events
+		block := event.GetBlock ()
+		if event.GetEventType () != NODE_EVENT_BLOCK || block.GetHash () != expectedHash { t.Errorf ("%s event for block %s", event.GetEventType (), block.GetHash ()) }
+	}
+}
+
+func TestPublishTxEventOnlyToTxSubscribers (t *testing.T) {
+
+	resetNodeEvents ()
+	blockEvents := SubscribeToNodeEvents (false)
+	txEvents := SubscribeToNodeEvents (true)
+
+	network := btc.GetNetwork ()
+	genesisTx := newTestTx (network.GetGenesisTxId ())
+	publishTxEvent (genesisTx, nil)
+	publishTxEvent (genesisTx, nil)
+
+	if len (blockEvents) != 0 { t.Errorf ("%d events for the block subscriber", len (blockEvents)) }
+	if len (txEvents) != 1 { t.Errorf ("%d events for the tx subscriber", len (txEvents)) }
+}
+
+func TestRecentTxIdsAreCapped (t *testing.T) {
+
+	resetNodeEvents ()
+
+	txId := func (i int) string { return fmt.Sprintf ("%064x", i) }
+	for i := 0; i <= MAX_RECENT_TX_IDS; i++ { publishTxEvent (newTestTx (txId (i)), nil) }
+
+	if len (recentTxIds) != MAX_RECENT_TX_IDS || len (recentTxIdOrder) != MAX_RECENT_TX_IDS { t.Fatalf ("%d recent tx ids, %d in order", len (recentTxIds), len (recentTxIdOrder)) }
+	if recentTxIds [txId (0)] { t.Error ("the oldest tx id was not removed") }
+	if !recentTxIds [txId (1)] || !recentTxIds [txId (MAX_RECENT_TX_IDS)] { t.Error ("a recent tx id was removed") }
+
+	// a transaction that is no longer recent is passed on again
+	events := SubscribeToNodeEvents (true)
+	publishTxEvent (newTestTx (txId (0)), nil)
+	publishTxEvent (newTestTx (txId (MAX_RECENT_TX_IDS)), nil)
+	if len (events) != 1 { t.Errorf ("%d events", len (events)) }
+}
+
+func TestSlowSubscriberDoesNotBlock (t *testing.T) {
+
+	resetNodeEvents ()
+	events := SubscribeToNodeEvents (false)
+
+	for i := 0; i < NODE_EVENT_BUFFER_SIZE + 10; i++ { publishBlockEvent (newTestBlock (fmt.Sprintf ("%064x", i))) }
+	if len (events) != NODE_EVENT_BUFFER_SIZE { t.Errorf ("%d events", len (events)) }
+}
@@ -67,6 +67,72 @@ func (np *NodeProxy) GetOutput (outputRequest OutputRequest) btc.Output {
 	return np.cache.getOutput (outputRequest.TxId, outputRequest.OutputIndex)
 }
 
+// the output is assumed to exist
+func (np *NodeProxy) GetOutputSpend (outputRequest OutputRequest) btc.OutputSpend {
+	if len (outputRequest.TxId) != 64 { return btc.OutputSpend {} }
+	return np.cache.getOutputSpend (outputRequest.TxId, outputRequest.OutputIndex)
+}
+
+// requests every transaction in the block with its previous outputs, which can take a while for large blocks
+func (np *NodeProxy) GetBlockStats (block btc.Block) btc.BlockStats {
+
+	blockStats := btc.NewBlockStats ()
+	for _, txId := range block.GetTxIds () {
+		tx := np.GetTx (TxRequest { TxId: txId, IncludeInputDetail: true })
+		if tx.IsNil () { continue }
+		blockStats.AddTx (tx)
+	}
+
+	return blockStats
+}
+
+// like the block statistics, this requests every transaction in the block with its previous outputs
+func (np *NodeProxy) GetOpcodeStats (block btc.Block) btc.OpcodeStats {
+
+	opcodeStats := btc.NewOpcodeStats ()
+	for _, txId := range block.GetTxIds () {
+		tx := np.GetTx (TxRequest { TxId: txId, IncludeInputDetail: true })
+		if tx.IsNil () { continue }
+		opcodeStats.AddTx (tx)
+	}
+
+	return opcodeStats
+}
+
+// returns the blocks after the last block up to the tip, oldest first, or only the tip if there is no last block
+// after a reorg, the blocks are followed back until the last block or the limit is reached
+func (np *NodeProxy) GetNewBlocks (tipHash string, lastHash string, maxBlocks int) [] btc.Block {
+
+	tip := np.GetBlock (BlockRequest { BlockKey: tipHash })
+	if tip.IsNil () { return nil }
+
+	blocks := [] btc.Block { tip }
+	if len (lastHash) == 0 { return blocks }
+
+	for len (blocks) < maxBlocks && blocks [0].GetPreviousHash () != lastHash && blocks [0].GetHeight () > 0 {
+		previousBlock := np.GetBlock (BlockRequest { BlockKey: blocks [0].GetPreviousHash () })
+		if previousBlock.IsNil () { break }
+		blocks = append ([] btc.Block { previousBlock }, blocks...)
+	}
+
+	return blocks
+}
+
+// transactions decoded from raw bytes do not have their previous outputs, which are needed to classify the inputs
+func (np *NodeProxy) SetPreviousOutputs (tx *btc.Tx) {
+
+	if tx.IsCoinbase () { return }
+
+	for i, input := range tx.GetInputs () {
+		tx.SetPreviousOutput (uint16 (i), np.GetOutput (OutputRequest { TxId: input.GetPreviousOutputTxId (), OutputIndex: input.GetPreviousOutputIndex () }))
+	}
+}
+
+// returns the ids of every transaction in the mempool, most recently seen first
+func (np *NodeProxy) GetMempoolTxIds () [] string {
+	return np.cache.getMempoolTxIds ()
+}
+
 func (np *NodeProxy) GetCurrentBlockHash () string {
 	return <- np.cache.getCurrentBlockHash ()
 }
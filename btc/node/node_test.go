@@ -0,0 +1,92 @@
+package node
+
+import (
+	"sync"
+	"time"
+	"errors"
+	"testing"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// a node that only knows the blocks, headers and transactions it is given
+type testNode struct {
+	bestBlockHash string
+	blocks map [string] map [string] interface {}
+	headers map [string] map [string] interface {}
+	txs map [string] map [string] interface {}
+	mempool map [string] map [string] interface {}
+
+	txRequests int
+}
+
+func (n *testNode) GetVersionString () string { return "" }
+func (n *testNode) getNodeType () string { return "Test" }
+func (n *testNode) getVersionStr () string { return "" }
+
+func (n *testNode) getBlock (blockHash string, withTxData bool) (map [string] interface {}, error) {
+	if n.blocks [blockHash] == nil { return nil, errors.New ("Block not found.") }
+	return n.blocks [blockHash], nil
+}
+
+func (n *testNode) getBlockHeader (blockHash string) (map [string] interface {}, error) {
+	if n.headers [blockHash] == nil { return nil, errors.New ("Block not found.") }
+	return n.headers [blockHash], nil
+}
+
+func (n *testNode) getTx (txId string) (map [string] interface {}, error) {
+	n.txRequests++
+	if n.txs [txId] == nil { return nil, errors.New ("Transaction not found.") }
+	return n.txs [txId], nil
+}
+
+func (n *testNode) getBlockHash (blockHeight uint32) string { return "" }
+func (n *testNode) getBestBlockHash () string { return n.bestBlockHash }
+func (n *testNode) getMempoolEntry (txId string) (map [string] interface {}, error) {
+	if n.mempool [txId] == nil { return nil, errors.New ("Transaction not found.") }
+	return n.mempool [txId], nil
+}
+
+func (n *testNode) getRawMempool () (map [string] interface {}, error) { return map [string] interface {} {}, nil }
+func (n *testNode) getTxOut (txId string, outputIndex uint32) (map [string] interface {}, error) { return nil, nil }
+func (n *testNode) getTxSpendingPrevout (txId string, outputIndex uint32) (string, error) { return "", nil }
+func (n *testNode) getBlockchainInfo () (map [string] interface {}, error) { return map [string] interface {} {}, nil }
+func (n *testNode) getDeployments () (map [string] interface {}, error) { return map [string] interface {} {}, nil }
+
+func newTestNodeProxy (node *testNode) *NodeProxy {
+	return &NodeProxy { cache: btcCache { btcNode: node } }
+}
+
+// the cache thread is started once, every test that caches shares it
+var testCacheChannels cacheClientChannelPack
+var startTestCacheOnce sync.Once
+
+func newTestCachingNodeProxy (node *testNode) *NodeProxy {
+
+	startTestCacheOnce.Do (func () {
+		blockMap = make (map [uint32] cachedBlock)
+		txMap = make (map [string] cachedTx)
+
+		blockChan := make (chan btc.Block, 50)
+		txChan := make (chan btc.Tx, 50)
+		testCacheChannels = cacheClientChannelPack { block: blockChan, tx: txChan }
+		go run (cacheThreadChannelPack { block: blockChan, tx: txChan })
+	})
+
+	return &NodeProxy { cache: btcCache { btcNode: node, caching: true, channel: testCacheChannels } }
+}
+
+// the cache thread stores transactions in the background
+func waitForCachedTx (t *testing.T, txId string, confirmed bool) {
+	t.Helper ()
+
+	for tries := 0; tries < 100; tries++ {
+		txCacheMutex.Lock ()
+		cached := txMap [txId]
+		txCacheMutex.Unlock ()
+
+		if !cached.tx.IsNil () && cached.tx.IsConfirmed () == confirmed { return }
+		time.Sleep (10 * time.Millisecond)
+	}
+	t.Fatalf ("transaction %s was not cached", txId)
+}
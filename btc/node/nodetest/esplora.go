@@ -0,0 +1,378 @@
+package nodetest
+
+import (
+	"os"
+	"fmt"
+	"sync"
+	"strconv"
+	"strings"
+	"net/http"
+	"net/http/httptest"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/binary"
+
+	"github.com/btc-script-explorer/scantool/app"
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+// an Esplora stand-in for the tests of packages that get their data through node.GetNodeProxy
+// the node proxy is created once per process, so every test in a package shares one chain, which begins with the genesis block
+// blocks are added and removed by the tests, the hashes and merkle roots are real but there is no proof of work
+
+const genesisHeaderHex = "01000000" + "0000000000000000000000000000000000000000000000000000000000000000" +
+							"3ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a" + "29ab5f49" + "ffff001d" + "1dac2b7c"
+
+const BLOCK_INTERVAL = 600
+
+type Outpoint struct {
+	TxId string
+	Index uint32
+}
+
+type TxOutput struct {
+	Value uint64
+	ScriptHex string
+}
+
+type chainBlock struct {
+	hash string
+	previousHash string
+	height uint32
+	timestamp int64
+	merkleRoot string
+	txIds [] string
+	raw [] byte
+	size uint32
+	weight uint32
+}
+
+type chainTx struct {
+	txHex string
+	tx btc.Tx
+	blockHash string
+}
+
+type Chain struct {
+	bestChain [] string
+	blocks map [string] chainBlock
+	txs map [string] chainTx
+	mempool [] string
+	mutex sync.Mutex
+}
+
+var chain *Chain
+var nodeProxy *node.NodeProxy
+var startOnce sync.Once
+
+// starts the server and creates the node proxy with it, the settings are only parsed by the first call
+// args are added to the command line after the node settings
+func Start (args ...string) (*Chain, *node.NodeProxy) {
+
+	startOnce.Do (func () {
+		chain = &Chain { blocks: make (map [string] chainBlock), txs: make (map [string] chainTx) }
+
+		network := btc.GetNetwork ()
+		header, _ := hex.DecodeString (genesisHeaderHex)
+		genesisTx, _ := hex.DecodeString (network.GetGenesisTxHex ())
+		chain.addBlock (header, [] [] byte { genesisTx })
+
+		server := httptest.NewServer (http.HandlerFunc (chain.serve))
+
+		os.Args = append ([] string { "scantool", "--node-type=esplora", "--esplora-url=" + server.URL }, args...)
+		app.ParseSettings ("test")
+
+		var err error
+		nodeProxy, err = node.GetNodeProxy ()
+		if err != nil { panic (err.Error ()) }
+	})
+
+	return chain, nodeProxy
+}
+
+// returns a transaction without input scripts or witnesses, nothing here checks the signatures
+func NewTxHex (inputs [] Outpoint, outputs [] TxOutput) string {
+
+	raw := binary.LittleEndian.AppendUint32 (nil, 2)
+
+	raw = appendVarInt (raw, uint64 (len (inputs)))
+	for _, input := range inputs {
+		txIdBytes, err := hex.DecodeString (input.TxId)
+		if err != nil || len (txIdBytes) != 32 { panic (input.TxId + " is not a valid tx id.") }
+
+		raw = append (raw, btc.ReverseBytes (txIdBytes)...)
+		raw = binary.LittleEndian.AppendUint32 (raw, input.Index)
+		raw = append (raw, 0x00)
+		raw = binary.LittleEndian.AppendUint32 (raw, 0xffffffff)
+	}
+
+	raw = appendOutputs (raw, outputs)
+	raw = binary.LittleEndian.AppendUint32 (raw, 0)
+
+	return hex.EncodeToString (raw)
+}
+
+// the height in the input script makes every coinbase unique
+func NewCoinbaseTxHex (height uint32, outputs [] TxOutput) string {
+
+	raw := binary.LittleEndian.AppendUint32 (nil, 2)
+	raw = append (raw, 0x01)
+	raw = append (raw, make ([] byte, 32)...)
+	raw = binary.LittleEndian.AppendUint32 (raw, 0xffffffff)
+	raw = append (raw, 0x05, 0x04)
+	raw = binary.LittleEndian.AppendUint32 (raw, height)
+	raw = binary.LittleEndian.AppendUint32 (raw, 0xffffffff)
+
+	raw = appendOutputs (raw, outputs)
+	raw = binary.LittleEndian.AppendUint32 (raw, 0)
+
+	return hex.EncodeToString (raw)
+}
+
+// returns the tx id of a transaction created by NewTxHex or NewCoinbaseTxHex
+func GetTxId (txHex string) string {
+	tx := decodeTx (txHex)
+	return tx.GetTxId ()
+}
+
+// adds a block on top of the best chain and returns its hash
+// the transactions come after a coinbase that pays 50 btc to OP_TRUE, and are removed from the mempool
+func (c *Chain) AddBlock (txHexes ...string) string {
+	c.mutex.Lock ()
+	defer c.mutex.Unlock ()
+
+	height := uint32 (len (c.bestChain))
+	coinbaseHex := NewCoinbaseTxHex (height, [] TxOutput { { Value: 5000000000, ScriptHex: "51" } })
+
+	rawTxs := make ([] [] byte, 0, len (txHexes) + 1)
+	txIds := make ([] string, 0, len (txHexes) + 1)
+	for _, txHex := range append ([] string { coinbaseHex }, txHexes...) {
+		rawTx, _ := hex.DecodeString (txHex)
+		rawTxs = append (rawTxs, rawTx)
+		tx := decodeTx (txHex)
+		txIds = append (txIds, tx.GetTxId ())
+	}
+
+	previousHash, _ := hex.DecodeString (c.bestChain [height - 1])
+	merkleBlock := btc.NewBlock ("", "", "", 0, 0, 0, txIds, "", "", 0, 0, "", 0, 0, 0, 0)
+	merkleRoot, _ := hex.DecodeString (merkleBlock.ComputeMerkleRoot ())
+
+	network := btc.GetNetwork ()
+	header := binary.LittleEndian.AppendUint32 (nil, 0x20000000)
+	header = append (header, btc.ReverseBytes (previousHash)...)
+	header = append (header, btc.ReverseBytes (merkleRoot)...)
+	header = binary.LittleEndian.AppendUint32 (header, uint32 (network.GetGenesisBlockTime () + int64 (height) * BLOCK_INTERVAL))
+	header = binary.LittleEndian.AppendUint32 (header, 0x1d00ffff)
+	header = binary.LittleEndian.AppendUint32 (header, 0)
+
+	return c.addBlock (header, rawTxs)
+}
+
+// removes the block at the tip of the best chain, its transactions are no longer confirmed
+func (c *Chain) RemoveTip () {
+	c.mutex.Lock ()
+	defer c.mutex.Unlock ()
+
+	if len (c.bestChain) <= 1 { panic ("The genesis block can not be removed.") }
+	c.bestChain = c.bestChain [: len (c.bestChain) - 1]
+}
+
+func (c *Chain) AddMempoolTx (txHex string) string {
+	c.mutex.Lock ()
+	defer c.mutex.Unlock ()
+
+	tx := decodeTx (txHex)
+	c.txs [tx.GetTxId ()] = chainTx { txHex: txHex, tx: tx }
+	c.mempool = append (c.mempool, tx.GetTxId ())
+
+	return tx.GetTxId ()
+}
+
+func (c *Chain) GetHeight () uint32 {
+	c.mutex.Lock ()
+	defer c.mutex.Unlock ()
+
+	return uint32 (len (c.bestChain) - 1)
+}
+
+func (c *Chain) GetBlockHash (height uint32) string {
+	c.mutex.Lock ()
+	defer c.mutex.Unlock ()
+
+	if height >= uint32 (len (c.bestChain)) { return "" }
+	return c.bestChain [height]
+}
+
+// returns the ids of the transactions in a block, the coinbase first
+func (c *Chain) GetTxIds (blockHash string) [] string {
+	c.mutex.Lock ()
+	defer c.mutex.Unlock ()
+
+	return append ([] string {}, c.blocks [blockHash].txIds...)
+}
+
+// the caller has the lock
+func (c *Chain) addBlock (header [] byte, rawTxs [] [] byte) string {
+
+	raw := append (append ([] byte {}, header...), appendVarInt (nil, uint64 (len (rawTxs)))...)
+	for _, rawTx := range rawTxs { raw = append (raw, rawTx...) }
+
+	rawBlock, err := btc.DecodeRawBlock (raw)
+	if err != nil { panic (err.Error ()) }
+
+	height := uint32 (len (c.bestChain))
+	block := rawBlock.ToBlock (height, "", 1, "", 0)
+	b := chainBlock { hash: rawBlock.GetHash (), previousHash: rawBlock.GetPreviousHash (), height: height, timestamp: block.GetTimestamp (), merkleRoot: block.GetMerkleRoot (),
+						txIds: block.GetTxIds (), raw: raw, size: block.GetSize (), weight: block.GetWeight () }
+	c.blocks [b.hash] = b
+	c.bestChain = append (c.bestChain, b.hash)
+
+	confirmed := make (map [string] bool)
+	for t, tx := range rawBlock.GetTxs () {
+		c.txs [tx.GetTxId ()] = chainTx { txHex: hex.EncodeToString (rawTxs [t]), tx: tx, blockHash: b.hash }
+		confirmed [tx.GetTxId ()] = true
+	}
+
+	mempool := make ([] string, 0, len (c.mempool))
+	for _, txId := range c.mempool {
+		if !confirmed [txId] { mempool = append (mempool, txId) }
+	}
+	c.mempool = mempool
+
+	return b.hash
+}
+
+// the caller has the lock
+func (c *Chain) isInBestChain (blockHash string) bool {
+	block, found := c.blocks [blockHash]
+	return found && block.height < uint32 (len (c.bestChain)) && c.bestChain [block.height] == blockHash
+}
+
+// the caller has the lock
+// returns the confirmed or mempool transaction that spends an output, or an empty string
+func (c *Chain) getSpendingTxId (txId string, outputIndex uint32) string {
+	for spendingTxId, spendingTx := range c.txs {
+		if len (spendingTx.blockHash) > 0 && !c.isInBestChain (spendingTx.blockHash) { continue }
+		if len (spendingTx.blockHash) == 0 && !c.isInMempool (spendingTxId) { continue }
+
+		for _, input := range spendingTx.tx.GetInputs () {
+			if input.GetPreviousOutputTxId () == txId && uint32 (input.GetPreviousOutputIndex ()) == outputIndex { return spendingTxId }
+		}
+	}
+	return ""
+}
+
+// the caller has the lock
+func (c *Chain) isInMempool (txId string) bool {
+	for _, mempoolTxId := range c.mempool {
+		if mempoolTxId == txId { return true }
+	}
+	return false
+}
+
+func (c *Chain) serve (response http.ResponseWriter, request *http.Request) {
+	c.mutex.Lock ()
+	defer c.mutex.Unlock ()
+
+	parts := strings.Split (strings.Trim (request.URL.Path, "/"), "/")
+	notFound := func () { http.Error (response, "not found", http.StatusNotFound) }
+	writeJson := func (value interface {}) { json.NewEncoder (response).Encode (value) }
+
+	switch {
+		case len (parts) == 3 && parts [0] == "blocks" && parts [1] == "tip" && parts [2] == "hash":
+			fmt.Fprint (response, c.bestChain [len (c.bestChain) - 1])
+
+		case len (parts) == 2 && parts [0] == "block-height":
+			height, err := strconv.Atoi (parts [1])
+			if err != nil || height < 0 || height >= len (c.bestChain) { notFound (); return }
+			fmt.Fprint (response, c.bestChain [height])
+
+		case len (parts) >= 2 && parts [0] == "block":
+			block, found := c.blocks [parts [1]]
+			if !found { notFound (); return }
+
+			if len (parts) == 2 {
+				blockJson := map [string] interface {} {	"id": block.hash, "height": block.height, "version": 0x20000000, "timestamp": block.timestamp, "mediantime": block.timestamp,
+															"tx_count": len (block.txIds), "size": block.size, "weight": block.weight, "merkle_root": block.merkleRoot,
+															"nonce": 0, "bits": 0x1d00ffff, "difficulty": 1 }
+				if block.height > 0 { blockJson ["previousblockhash"] = block.previousHash }
+				writeJson (blockJson)
+				return
+			}
+
+			switch parts [2] {
+				case "status":
+					status := map [string] interface {} { "in_best_chain": c.isInBestChain (block.hash), "height": block.height }
+					if c.isInBestChain (block.hash) && int (block.height) + 1 < len (c.bestChain) { status ["next_best"] = c.bestChain [block.height + 1] }
+					writeJson (status)
+				case "txids":
+					writeJson (block.txIds)
+				case "raw":
+					response.Write (block.raw)
+				default:
+					notFound ()
+			}
+
+		case len (parts) >= 3 && parts [0] == "tx":
+			tx, found := c.txs [parts [1]]
+			if !found { notFound (); return }
+
+			switch {
+				case parts [2] == "hex":
+					fmt.Fprint (response, tx.txHex)
+				case parts [2] == "status":
+					if !c.isInBestChain (tx.blockHash) { writeJson (map [string] interface {} { "confirmed": false }); return }
+					writeJson (map [string] interface {} { "confirmed": true, "block_hash": tx.blockHash, "block_height": c.blocks [tx.blockHash].height, "block_time": c.blocks [tx.blockHash].timestamp })
+				case parts [2] == "outspend" && len (parts) == 4:
+					outputIndex, err := strconv.ParseUint (parts [3], 10, 32)
+					if err != nil || outputIndex >= uint64 (tx.tx.GetOutputCount ()) { notFound (); return }
+
+					spendingTxId := c.getSpendingTxId (parts [1], uint32 (outputIndex))
+					if len (spendingTxId) == 0 { writeJson (map [string] interface {} { "spent": false }); return }
+					writeJson (map [string] interface {} { "spent": true, "txid": spendingTxId })
+				default:
+					notFound ()
+			}
+
+		case len (parts) == 2 && parts [0] == "mempool" && parts [1] == "txids":
+			writeJson (append ([] string {}, c.mempool...))
+
+		default:
+			notFound ()
+	}
+}
+
+func decodeTx (txHex string) btc.Tx {
+	rawTx, err := hex.DecodeString (txHex)
+	if err != nil { panic (err.Error ()) }
+
+	tx, err := btc.DecodeRawTx (rawTx)
+	if err != nil { panic (err.Error ()) }
+
+	return tx
+}
+
+func appendOutputs (raw [] byte, outputs [] TxOutput) [] byte {
+
+	raw = appendVarInt (raw, uint64 (len (outputs)))
+	for _, output := range outputs {
+		script, err := hex.DecodeString (output.ScriptHex)
+		if err != nil { panic (err.Error ()) }
+
+		raw = binary.LittleEndian.AppendUint64 (raw, output.Value)
+		raw = appendVarInt (raw, uint64 (len (script)))
+		raw = append (raw, script...)
+	}
+
+	return raw
+}
+
+func appendVarInt (data [] byte, value uint64) [] byte {
+	switch {
+		case value < 0xfd: return append (data, byte (value))
+		case value <= 0xffff: return binary.LittleEndian.AppendUint16 (append (data, 0xfd), uint16 (value))
+		case value <= 0xffffffff: return binary.LittleEndian.AppendUint32 (append (data, 0xfe), uint32 (value))
+		default: return binary.LittleEndian.AppendUint64 (append (data, 0xff), value)
+	}
+}
@@ -0,0 +1,90 @@
+package node
+
+import (
+	"os"
+	"fmt"
+	"sync"
+	"errors"
+	"strings"
+	"net/http"
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/btc-script-explorer/scantool/app"
+)
+
+// the rpc credentials are either the username and password settings, which work for both rpcuser and rpcauth users,
+// or the contents of the cookie file bitcoin core writes when it starts
+// the node writes a new cookie every time it restarts, so the cookie file is read again whenever the node rejects the cookie
+
+type rpcCredentials struct {
+	username string
+	password string
+
+	// empty unless cookie authentication is used
+	cookieFile string
+
+	mutex sync.Mutex
+}
+
+func newRpcCredentials () *rpcCredentials {
+
+	credentials := rpcCredentials { username: app.Settings.GetNodeUsername (), password: app.Settings.GetNodePassword () }
+	if len (credentials.username) == 0 && len (credentials.password) == 0 {
+		credentials.cookieFile = app.Settings.GetNodeCookieFile ()
+		if _, err := credentials.reloadCookie (); err != nil { fmt.Println (err.Error ()) }
+	}
+
+	return &credentials
+}
+
+func (c *rpcCredentials) usesCookie () bool {
+	return len (c.cookieFile) > 0
+}
+
+func (c *rpcCredentials) get () (string, string) {
+	c.mutex.Lock ()
+	defer c.mutex.Unlock ()
+
+	return c.username, c.password
+}
+
+// returns true if the cookie is not the one that was used before
+func (c *rpcCredentials) reloadCookie () (bool, error) {
+
+	cookie, err := os.ReadFile (c.cookieFile)
+	if err != nil { return false, errors.New ("Failed to read cookie file: " + err.Error ()) }
+
+	// the cookie is a single line, __cookie__:password
+	parts := strings.SplitN (strings.TrimSpace (string (cookie)), ":", 2)
+	if len (parts) != 2 { return false, errors.New ("Cookie file " + c.cookieFile + " is improperly formatted.") }
+
+	c.mutex.Lock ()
+	defer c.mutex.Unlock ()
+
+	changed := parts [0] != c.username || parts [1] != c.password
+	c.username = parts [0]
+	c.password = parts [1]
+
+	return changed, nil
+}
+
+// the client is shared by every request so that connections are reused
+func newRpcClient () (*http.Client, error) {
+
+	transport := http.DefaultTransport.(*http.Transport).Clone ()
+
+	// a custom CA for a TLS proxy in front of the node, otherwise the system roots are used
+	caFile := app.Settings.GetNodeCaFile ()
+	if len (caFile) > 0 {
+		caPem, err := os.ReadFile (caFile)
+		if err != nil { return nil, errors.New ("Failed to read CA file: " + err.Error ()) }
+
+		rootCAs := x509.NewCertPool ()
+		if !rootCAs.AppendCertsFromPEM (caPem) { return nil, errors.New ("No certificates found in CA file " + caFile + ".") }
+
+		transport.TLSClientConfig = &tls.Config { RootCAs: rootCAs }
+	}
+
+	return &http.Client { Transport: transport }, nil
+}
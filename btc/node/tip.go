@@ -0,0 +1,176 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btc-script-explorer/scantool/app"
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// the tip follower checks the chain tip in the background and passes every new block to the consumers that follow it
+// each block is requested once and its transactions are expanded once, no matter how many consumers there are
+// mempool transactions published over zmq are passed on while waiting for the next block
+
+const TIP_POLL_INTERVAL = 10 * time.Second
+const TIP_POLL_INTERVAL_WITH_ZMQ = 60 * time.Second
+
+// if more blocks than this were added since the last check, only the most recent ones are passed on
+const MAX_CATCH_UP_BLOCKS = 6
+
+// the handlers are called from the tip follower, one consumer at a time in the order they started following
+// either handler can be nil
+type TipHandlers struct {
+	NewBlock func (*TipBlock)
+	MempoolTx func (*TipMempoolTx)
+}
+
+// a new block whose transactions are only requested if a consumer asks for them
+type TipBlock struct {
+	nodeProxy *NodeProxy
+	block btc.Block
+	txs [] btc.Tx
+}
+
+func (tb *TipBlock) GetBlock () btc.Block {
+	return tb.block
+}
+
+// the transactions have their previous outputs, they are requested the first time a consumer asks for them and shared with the others after that
+// f is called as each transaction arrives, so the first consumer does not have to wait for the whole block
+// every call gets its own copies, so the copies can be changed without affecting the other consumers
+// if a transaction can not be requested, an error is returned after the ones before it, and the next consumer requests it again
+func (tb *TipBlock) ForEachTx (f func (btc.Tx)) error {
+
+	txIds := tb.block.GetTxIds ()
+	for t, txId := range txIds {
+		if t == len (tb.txs) {
+			tx := tb.nodeProxy.GetTx (TxRequest { TxId: txId, IncludeInputDetail: true })
+			if tx.IsNil () { return fmt.Errorf ("Failed to get transaction %s of block %s.", txId, tb.block.GetHash ()) }
+			tb.txs = append (tb.txs, tx)
+		}
+
+		f (tb.txs [t].Copy ())
+	}
+
+	return nil
+}
+
+// a mempool transaction whose previous outputs are only requested if a consumer asks for it
+type TipMempoolTx struct {
+	nodeProxy *NodeProxy
+	tx btc.Tx
+	previousOutputsSet bool
+}
+
+// every consumer gets its own copy, so the copies can be changed without affecting the others
+func (tt *TipMempoolTx) GetTx () btc.Tx {
+
+	if !tt.previousOutputsSet {
+		tt.nodeProxy.SetPreviousOutputs (&tt.tx)
+		tt.previousOutputsSet = true
+	}
+
+	return tt.tx.Copy ()
+}
+
+var tipHandlers [] TipHandlers
+var tipHandlersMutex sync.Mutex
+var startTipFollowerOnce sync.Once
+
+var currentTip btc.Block
+var currentTipMutex sync.Mutex
+
+// the tip follower is started by the first consumer
+func FollowTip (handlers TipHandlers) {
+
+	tipHandlersMutex.Lock ()
+	tipHandlers = append (tipHandlers, handlers)
+	tipHandlersMutex.Unlock ()
+
+	startTipFollowerOnce.Do (func () {
+		nodeProxy, err := GetNodeProxy ()
+		if err != nil { fmt.Println (err.Error ()); return }
+
+		// when the node publishes its blocks, the tip is checked as soon as one arrives and much less often otherwise
+		pollInterval := TIP_POLL_INTERVAL
+		if app.Settings.IsZmqOn () { pollInterval = TIP_POLL_INTERVAL_WITH_ZMQ }
+
+		go nodeProxy.followTip (SubscribeToNodeEvents (true), pollInterval)
+	})
+}
+
+// returns the most recent block the tip follower has seen
+func GetTip () btc.Block {
+	currentTipMutex.Lock ()
+	defer currentTipMutex.Unlock ()
+	return currentTip
+}
+
+func setTip (block btc.Block) {
+	currentTipMutex.Lock ()
+	currentTip = block
+	currentTipMutex.Unlock ()
+}
+
+func getTipHandlers () [] TipHandlers {
+	tipHandlersMutex.Lock ()
+	defer tipHandlersMutex.Unlock ()
+	return append ([] TipHandlers {}, tipHandlers...)
+}
+
+func (np *NodeProxy) followTip (nodeEvents <-chan NodeEvent, pollInterval time.Duration) {
+
+	lastHash := ""
+	for {
+		lastHash = np.checkTip (lastHash)
+		np.waitForBlock (nodeEvents, pollInterval)
+	}
+}
+
+// returns the hash of the most recent block that was passed on
+func (np *NodeProxy) checkTip (lastHash string) string {
+
+	tipHash := np.GetCurrentBlockHash ()
+	if len (tipHash) == 0 || tipHash == lastHash { return lastHash }
+
+	newBlocks := np.GetNewBlocks (tipHash, lastHash, MAX_CATCH_UP_BLOCKS)
+	if len (newBlocks) == 0 { return lastHash }
+
+	setTip (newBlocks [len (newBlocks) - 1])
+
+	// nothing is passed on for the block that was the tip when scantool started
+	if len (lastHash) > 0 {
+		for _, block := range newBlocks {
+			tipBlock := &TipBlock { nodeProxy: np, block: block }
+			for _, handlers := range getTipHandlers () {
+				if handlers.NewBlock != nil { handlers.NewBlock (tipBlock) }
+			}
+		}
+	}
+
+	return tipHash
+}
+
+// mempool transactions published by the node are passed on while waiting
+func (np *NodeProxy) waitForBlock (nodeEvents <-chan NodeEvent, pollInterval time.Duration) {
+
+	timeout := time.After (pollInterval)
+	for {
+		select {
+			case nodeEvent := <- nodeEvents:
+				if nodeEvent.GetEventType () == NODE_EVENT_BLOCK { return }
+
+				tx := nodeEvent.GetTx ()
+				if tx.IsNil () || tx.IsConfirmed () { continue }
+
+				tipTx := &TipMempoolTx { nodeProxy: np, tx: tx }
+				for _, handlers := range getTipHandlers () {
+					if handlers.MempoolTx != nil { handlers.MempoolTx (tipTx) }
+				}
+			case <- timeout:
+				return
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package node
+
+import (
+	"strings"
+	"testing"
+	"encoding/hex"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// the handlers are shared by every test in the package
+func setTestTipHandlers (handlers ...TipHandlers) {
+	tipHandlersMutex.Lock ()
+	tipHandlers = handlers
+	tipHandlersMutex.Unlock ()
+}
+
+func decodeTestTx (t *testing.T, txHex string) btc.Tx {
+	t.Helper ()
+
+	tx, err := btc.DecodeRawTx (decodeTestHex (t, txHex))
+	if err != nil { t.Fatal (err) }
+	return tx
+}
+
+// a chain of blocks that each have the genesis transaction, the node knows the genesis transaction
+func newTestChain (t *testing.T, hashes ...string) *testNode {
+
+	network := btc.GetNetwork ()
+	genesisTxId := network.GetGenesisTxId ()
+
+	node := &testNode {	blocks: make (map [string] map [string] interface {}),
+						txs: map [string] map [string] interface {} { genesisTxId: makeRawTxJson (decodeTestTx (t, network.GetGenesisTxHex ())) } }
+
+	for h, hash := range hashes {
+		block := map [string] interface {} { "hash": hash, "height": float64 (h + 1), "version": float64 (1), "time": float64 (0),
+												"tx": [] interface {} { map [string] interface {} { "txid": genesisTxId } } }
+		if h > 0 { block ["previousblockhash"] = hashes [h - 1] }
+		node.blocks [hash] = block
+	}
+
+	return node
+}
+
+func TestCheckTipPassesBlocksOnce (t *testing.T) {
+
+	a, b, c, d := strings.Repeat ("a", 64), strings.Repeat ("b", 64), strings.Repeat ("c", 64), strings.Repeat ("d", 64)
+	node := newTestChain (t, a, b, c, d)
+	np := newTestNodeProxy (node)
+
+	handled := [2] [] string {}
+	txCounts := [2] int {}
+	handler := func (h int) TipHandlers {
+		return TipHandlers { NewBlock: func (tipBlock *TipBlock) {
+			block := tipBlock.GetBlock ()
+			handled [h] = append (handled [h], block.GetHash ())
+			tipBlock.ForEachTx (func (tx btc.Tx) { txCounts [h]++ })
+		} }
+	}
+	setTestTipHandlers (handler (0), handler (1), TipHandlers {})
+	defer setTestTipHandlers ()
+
+	// the tip when scantool starts is not passed on
+	node.bestBlockHash = a
+	lastHash := np.checkTip ("")
+	tip := GetTip ()
+	if lastHash != a || tip.GetHash () != a || len (handled [0]) != 0 { t.Fatalf ("last hash %s, tip %s, %d blocks handled", lastHash, tip.GetHash (), len (handled [0])) }
+
+	node.bestBlockHash = b
+	lastHash = np.checkTip (lastHash)
+	if lastHash != b || len (handled [0]) != 1 || len (handled [1]) != 1 { t.Fatalf ("last hash %s, blocks handled %v", lastHash, handled) }
+
+	// the transactions are only requested for the first handler
+	if txCounts [0] != 1 || txCounts [1] != 1 || node.txRequests != 1 { t.Errorf ("tx counts %v, %d tx requests", txCounts, node.txRequests) }
+
+	// nothing changed
+	if np.checkTip (lastHash) != b || len (handled [0]) != 1 { t.Error ("a block was passed on again") }
+
+	// more than one block was added since the last check
+	node.bestBlockHash = d
+	lastHash = np.checkTip (lastHash)
+	if lastHash != d || strings.Join (handled [0], ",") != strings.Join ([] string { b, c, d }, ",") || len (handled [1]) != 3 { t.Errorf ("last hash %s, blocks handled %v", lastHash, handled) }
+	tip = GetTip ()
+	if tip.GetHash () != d { t.Errorf ("tip is %s", tip.GetHash ()) }
+}
+
+func TestTipMempoolTxCopies (t *testing.T) {
+
+	network := btc.GetNetwork ()
+	node := newTestChain (t)
+
+	// spends the first output of the genesis transaction
+	genesisTxId := network.GetGenesisTxId ()
+	outpoint := hex.EncodeToString (btc.ReverseBytes (decodeTestHex (t, genesisTxId))) + "00000000"
+	tx := decodeTestTx (t, "01000000" + "01" + outpoint + "00" + "ffffffff" + "01" + "0010a5d4e8000000" + "0151" + "00000000")
+
+	tipTx := &TipMempoolTx { nodeProxy: newTestNodeProxy (node), tx: tx }
+
+	first := tipTx.GetTx ()
+	second := tipTx.GetTx ()
+	if node.txRequests != 1 { t.Errorf ("%d tx requests", node.txRequests) }
+
+	firstInput := first.GetInput (0)
+	previousOutput := firstInput.GetPreviousOutput ()
+	if previousOutput.GetValue () != 5000000000 { t.Fatalf ("previous output value is %d", previousOutput.GetValue ()) }
+
+	// each consumer can change its copy
+	first.SetPreviousOutput (0, btc.Output {})
+	secondInput := second.GetInput (0)
+	secondPreviousOutput := secondInput.GetPreviousOutput ()
+	if secondPreviousOutput.GetValue () != 5000000000 { t.Error ("the copies share their inputs") }
+}
+
+// a transaction that could not be requested is requested again by the next consumer
+func TestTipBlockForEachTxRetries (t *testing.T) {
+
+	network := btc.GetNetwork ()
+	hash := strings.Repeat ("e", 64)
+	node := newTestChain (t, hash)
+
+	genesisTxId := network.GetGenesisTxId ()
+	outpoint := hex.EncodeToString (btc.ReverseBytes (decodeTestHex (t, genesisTxId))) + "00000000"
+	spendingTx := decodeTestTx (t, "01000000" + "01" + outpoint + "00" + "ffffffff" + "01" + "0010a5d4e8000000" + "0151" + "00000000")
+	spendingTxId := spendingTx.GetTxId ()
+
+	node.blocks [hash]["tx"] = [] interface {} { map [string] interface {} { "txid": genesisTxId }, map [string] interface {} { "txid": spendingTxId } }
+	np := newTestNodeProxy (node)
+	block := np.GetBlock (BlockRequest { BlockKey: hash })
+	tipBlock := &TipBlock { nodeProxy: np, block: block }
+
+	txIds := [] string {}
+	err := tipBlock.ForEachTx (func (tx btc.Tx) { txIds = append (txIds, tx.GetTxId ()) })
+	if err == nil || len (txIds) != 1 { t.Fatalf ("error %v, %d txs", err, len (txIds)) }
+
+	node.txs [spendingTxId] = makeRawTxJson (spendingTx)
+	txRequests := node.txRequests
+
+	txs := [] btc.Tx {}
+	err = tipBlock.ForEachTx (func (tx btc.Tx) { txs = append (txs, tx) })
+	if err != nil || len (txs) != 2 { t.Fatalf ("error %v, %d txs", err, len (txs)) }
+	if txs [1].GetTxId () != spendingTxId { t.Errorf ("second tx is %s", txs [1].GetTxId ()) }
+
+	// the genesis transaction was already loaded, and its previous output was requested the first time
+	if node.txRequests - txRequests > 2 { t.Errorf ("%d tx requests", node.txRequests - txRequests) }
+
+	// each consumer can change its copy
+	txs [1].SetPreviousOutput (0, btc.Output {})
+	tipBlock.ForEachTx (func (tx btc.Tx) {
+		if tx.GetTxId () != spendingTxId { return }
+		input := tx.GetInput (0)
+		previousOutput := input.GetPreviousOutput ()
+		if previousOutput.GetValue () != 5000000000 { t.Error ("the consumers share their transactions") }
+	})
+}
@@ -0,0 +1,125 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"time"
+	"strings"
+	"encoding/hex"
+	"encoding/binary"
+
+	"github.com/lightninglabs/gozmq"
+
+	"github.com/btc-script-explorer/scantool/app"
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// bitcoin core can publish new blocks and transactions over zmq (zmqpubrawblock, zmqpubrawtx and zmqpubhashblock in bitcoin.conf)
+// each topic can be published at its own address, so there is one connection for each address
+// the data is decoded, cached and passed on to the consumers that subscribed to node events
+
+const ZMQ_TOPIC_RAW_BLOCK = "rawblock"
+const ZMQ_TOPIC_RAW_TX = "rawtx"
+const ZMQ_TOPIC_HASH_BLOCK = "hashblock"
+
+const ZMQ_RECONNECT_INTERVAL = 5 * time.Second
+
+// connects to every address in the settings, and keeps reconnecting if the node goes away
+func StartZmq () {
+
+	topicsByAddr := make (map [string] [] string)
+	addTopic := func (addr string, topic string) {
+		if len (addr) > 0 { topicsByAddr [addr] = append (topicsByAddr [addr], topic) }
+	}
+	addTopic (app.Settings.GetZmqRawBlockAddr (), ZMQ_TOPIC_RAW_BLOCK)
+	addTopic (app.Settings.GetZmqRawTxAddr (), ZMQ_TOPIC_RAW_TX)
+	addTopic (app.Settings.GetZmqHashBlockAddr (), ZMQ_TOPIC_HASH_BLOCK)
+
+	if len (topicsByAddr) == 0 { return }
+
+	nodeProxy, err := GetNodeProxy ()
+	if err != nil { fmt.Println (err.Error ()); return }
+
+	for addr, topics := range topicsByAddr {
+		go nodeProxy.runZmqSubscriber (addr, topics)
+	}
+}
+
+func (np *NodeProxy) runZmqSubscriber (addr string, topics [] string) {
+
+	for {
+		conn, err := gozmq.Subscribe (addr, topics, ZMQ_RECONNECT_INTERVAL)
+		if err != nil {
+			fmt.Println (fmt.Sprintf ("ZMQ ERROR: %s: %s", addr, err.Error ()))
+			time.Sleep (ZMQ_RECONNECT_INTERVAL)
+			continue
+		}
+		fmt.Println (fmt.Sprintf ("Subscribed to %s at %s.", strings.Join (topics, ", "), addr))
+
+		// every topic has its own sequence number, so a gap means messages were dropped
+		sequences := make (map [string] uint32)
+
+		for {
+			message, err := conn.Receive (nil)
+			if err != nil {
+				// the connection is re-established by gozmq, which returns a timeout error while it does
+				if netErr, ok := err.(net.Error); ok && netErr.Timeout () { continue }
+				fmt.Println (fmt.Sprintf ("ZMQ ERROR: %s: %s", addr, err.Error ()))
+				break
+			}
+			if len (message) < 2 { continue }
+
+			topic := string (message [0])
+			if len (message) >= 3 {
+				if missed := getMissedZmqMessageCount (sequences, topic, message [2]); missed > 0 {
+					fmt.Println (fmt.Sprintf ("ZMQ: %d %s messages were missed.", missed, topic))
+				}
+			}
+
+			np.handleZmqMessage (topic, message [1])
+		}
+
+		conn.Close ()
+		time.Sleep (ZMQ_RECONNECT_INTERVAL)
+	}
+}
+
+// the sequence numbers start over when the node restarts, so only a sequence number that skips ahead is a gap
+func getMissedZmqMessageCount (sequences map [string] uint32, topic string, sequenceBytes [] byte) uint32 {
+
+	if len (sequenceBytes) != 4 { return 0 }
+
+	sequence := binary.LittleEndian.Uint32 (sequenceBytes)
+	previous, found := sequences [topic]
+	sequences [topic] = sequence
+
+	if !found || sequence <= previous + 1 { return 0 }
+	return sequence - previous - 1
+}
+
+func (np *NodeProxy) handleZmqMessage (topic string, body [] byte) {
+
+	switch topic {
+
+		case ZMQ_TOPIC_RAW_BLOCK:
+			rawBlock, err := btc.DecodeRawBlock (body)
+			if err != nil { fmt.Println (fmt.Sprintf ("ZMQ: invalid %s: %s", topic, err.Error ())); return }
+
+			block := np.cache.addRawBlock (rawBlock)
+			if !block.IsNil () { publishBlockEvent (block) }
+
+		// the hash is in the same byte order the node uses everywhere else
+		case ZMQ_TOPIC_HASH_BLOCK:
+			if len (body) != 32 { fmt.Println (fmt.Sprintf ("ZMQ: invalid %s", topic)); return }
+
+			block := np.GetBlock (BlockRequest { BlockKey: hex.EncodeToString (body) })
+			if !block.IsNil () { publishBlockEvent (block) }
+
+		case ZMQ_TOPIC_RAW_TX:
+			tx, err := btc.DecodeRawTx (body)
+			if err != nil { fmt.Println (fmt.Sprintf ("ZMQ: invalid %s: %s", topic, err.Error ())); return }
+
+			np.cache.addMempoolTx (tx)
+			publishTxEvent (tx, body)
+	}
+}
@@ -0,0 +1,341 @@
+package node
+
+import (
+	"io"
+	"net"
+	"sync"
+	"strings"
+	"time"
+	"errors"
+	"testing"
+	"encoding/hex"
+	"encoding/binary"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// the main network genesis block
+const testGenesisHeaderHex = "01000000" + "0000000000000000000000000000000000000000000000000000000000000000" +
+								"3ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a" + "29ab5f49" + "ffff001d" + "1dac2b7c"
+
+// a stand-in for the publisher in bitcoin core, it speaks just enough zmtp 3.0 for a subscriber
+type testZmqPublisher struct {
+	listener net.Listener
+	connections chan net.Conn
+	subscriptions chan string
+
+	openConnections [] net.Conn
+	openConnectionsMutex sync.Mutex
+}
+
+func newTestZmqPublisher (t *testing.T) *testZmqPublisher {
+
+	listener, err := net.Listen ("tcp", "127.0.0.1:0")
+	if err != nil { t.Fatal (err) }
+
+	p := &testZmqPublisher { listener: listener, connections: make (chan net.Conn, 10), subscriptions: make (chan string, 10) }
+
+	// the listener is closed first so that the subscriber can not reconnect
+	t.Cleanup (func () {
+		listener.Close ()
+
+		p.openConnectionsMutex.Lock ()
+		for _, conn := range p.openConnections { conn.Close () }
+		p.openConnectionsMutex.Unlock ()
+	})
+
+	go func () {
+		for {
+			conn, err := listener.Accept ()
+			if err != nil { return }
+
+			p.openConnectionsMutex.Lock ()
+			p.openConnections = append (p.openConnections, conn)
+			p.openConnectionsMutex.Unlock ()
+
+			if err := p.handshake (conn); err != nil { conn.Close (); continue }
+			go p.readSubscriptions (conn)
+			p.connections <- conn
+		}
+	} ()
+
+	return p
+}
+
+func (p *testZmqPublisher) getAddr () string {
+	return "tcp://" + p.listener.Addr ().String ()
+}
+
+func (p *testZmqPublisher) handshake (conn net.Conn) error {
+
+	greeting := make ([] byte, 64)
+	if _, err := io.ReadFull (conn, greeting); err != nil { return err }
+	if greeting [0] != 0xff || greeting [9] != 0x7f { return errors.New ("invalid greeting") }
+
+	reply := make ([] byte, 64)
+	reply [0] = 0xff
+	reply [9] = 0x7f
+	reply [10] = 3
+	copy (reply [12:], "NULL")
+	if _, err := conn.Write (reply); err != nil { return err }
+
+	// the subscriber's ready command
+	if _, _, err := readTestZmqFrame (conn); err != nil { return err }
+
+	ready := append ([] byte { 5 }, "READY"...)
+	ready = append (ready, 11)
+	ready = append (ready, "Socket-Type"...)
+	ready = append (ready, 0, 0, 0, 3)
+	ready = append (ready, "PUB"...)
+	return writeTestZmqFrame (conn, 4, ready)
+}
+
+// a subscription is a message with a 1 followed by the topic
+func (p *testZmqPublisher) readSubscriptions (conn net.Conn) {
+	for {
+		_, body, err := readTestZmqFrame (conn)
+		if err != nil { return }
+		if len (body) > 0 && body [0] == 1 { p.subscriptions <- string (body [1:]) }
+	}
+}
+
+func (p *testZmqPublisher) waitForConnection (t *testing.T) net.Conn {
+	t.Helper ()
+
+	select {
+		case conn := <-p.connections:
+			return conn
+		case <-time.After (5 * time.Second):
+			t.Fatal ("no connection")
+	}
+
+	return nil
+}
+
+// bitcoin core sends the topic, the body and a sequence number
+func publishTestZmqMessage (t *testing.T, conn net.Conn, topic string, body [] byte, sequence uint32) {
+	t.Helper ()
+
+	sequenceBytes := make ([] byte, 4)
+	binary.LittleEndian.PutUint32 (sequenceBytes, sequence)
+
+	if err := writeTestZmqFrame (conn, 1, [] byte (topic)); err != nil { t.Fatal (err) }
+	if err := writeTestZmqFrame (conn, 1, body); err != nil { t.Fatal (err) }
+	if err := writeTestZmqFrame (conn, 0, sequenceBytes); err != nil { t.Fatal (err) }
+}
+
+func writeTestZmqFrame (conn net.Conn, flag byte, body [] byte) error {
+
+	header := [] byte { flag, byte (len (body)) }
+	if len (body) > 255 {
+		header = make ([] byte, 9)
+		header [0] = flag | 2
+		binary.BigEndian.PutUint64 (header [1:], uint64 (len (body)))
+	}
+
+	_, err := conn.Write (append (header, body...))
+	return err
+}
+
+func readTestZmqFrame (conn net.Conn) (byte, [] byte, error) {
+
+	header := make ([] byte, 2)
+	if _, err := io.ReadFull (conn, header); err != nil { return 0, nil, err }
+
+	size := uint64 (header [1])
+	if header [0] & 2 != 0 {
+		sizeBytes := make ([] byte, 8)
+		sizeBytes [0] = header [1]
+		if _, err := io.ReadFull (conn, sizeBytes [1:]); err != nil { return 0, nil, err }
+		size = binary.BigEndian.Uint64 (sizeBytes)
+	}
+
+	body := make ([] byte, size)
+	_, err := io.ReadFull (conn, body)
+	return header [0], body, err
+}
+
+func decodeTestHex (t *testing.T, hexString string) [] byte {
+	t.Helper ()
+
+	bytes, err := hex.DecodeString (hexString)
+	if err != nil { t.Fatal (err) }
+	return bytes
+}
+
+func TestZmqRawTx (t *testing.T) {
+
+	resetNodeEvents ()
+	events := SubscribeToNodeEvents (true)
+
+	publisher := newTestZmqPublisher (t)
+	go newTestNodeProxy (&testNode {}).runZmqSubscriber (publisher.getAddr (), [] string { ZMQ_TOPIC_RAW_TX })
+	conn := publisher.waitForConnection (t)
+
+	network := btc.GetNetwork ()
+	publishTestZmqMessage (t, conn, ZMQ_TOPIC_RAW_TX, decodeTestHex (t, network.GetGenesisTxHex ()), 0)
+
+	event := waitForNodeEvent (t, events)
+	tx := event.GetTx ()
+	if event.GetEventType () != NODE_EVENT_TX || tx.GetTxId () != network.GetGenesisTxId () { t.Errorf ("%s event for tx %s", event.GetEventType (), tx.GetTxId ()) }
+
+	// invalid transactions are not passed on
+	newTestNodeProxy (&testNode {}).handleZmqMessage (ZMQ_TOPIC_RAW_TX, [] byte { 1, 2, 3 })
+	if len (events) != 0 { t.Errorf ("%d events for an invalid transaction", len (events)) }
+}
+
+// a published transaction is used from the cache until it is no longer in the mempool
+func TestZmqRawTxIsCached (t *testing.T) {
+
+	network := btc.GetNetwork ()
+	genesisTxId := network.GetGenesisTxId ()
+	outpoint := hex.EncodeToString (btc.ReverseBytes (decodeTestHex (t, genesisTxId))) + "01000000"
+	txBytes := decodeTestHex (t, "02000000" + "01" + outpoint + "00" + "ffffffff" + "01" + "e803000000000000" + "0151" + "00000000")
+	tx, _ := btc.DecodeRawTx (txBytes)
+	txId := tx.GetTxId ()
+
+	node := &testNode {	txs: map [string] map [string] interface {} {},
+						mempool: map [string] map [string] interface {} { txId: {	"time": float64 (1700000000), "fees": map [string] interface {} { "base": 0.00001 }, "vsize": float64 (60),
+																					"ancestorcount": float64 (1), "ancestorsize": float64 (60), "descendantcount": float64 (1), "descendantsize": float64 (60) } } }
+	np := newTestCachingNodeProxy (node)
+	txCacheMutex.Lock ()
+	delete (txMap, txId)
+	txCacheMutex.Unlock ()
+
+	np.handleZmqMessage (ZMQ_TOPIC_RAW_TX, txBytes)
+	waitForCachedTx (t, txId, false)
+
+	cachedTx := np.GetTx (TxRequest { TxId: txId })
+	mempoolEntry := cachedTx.GetMempoolEntry ()
+	if node.txRequests != 0 || cachedTx.IsConfirmed () || mempoolEntry.GetFee () != 1000 { t.Errorf ("%d tx requests, confirmed %t, fee %d", node.txRequests, cachedTx.IsConfirmed (), mempoolEntry.GetFee ()) }
+
+	// once it is in a block, the node has the confirmed transaction and it replaces the cached one
+	delete (node.mempool, txId)
+	confirmedTx := makeRawTxJson (tx)
+	confirmedTx ["blockhash"] = strings.Repeat ("b", 64)
+	confirmedTx ["blocktime"] = float64 (1700000600)
+	node.txs [txId] = confirmedTx
+
+	tx = np.GetTx (TxRequest { TxId: txId })
+	if node.txRequests != 1 || !tx.IsConfirmed () || tx.GetBlockHash () != strings.Repeat ("b", 64) { t.Fatalf ("%d tx requests, confirmed %t", node.txRequests, tx.IsConfirmed ()) }
+
+	waitForCachedTx (t, txId, true)
+	tx = np.GetTx (TxRequest { TxId: txId })
+	if node.txRequests != 1 || !tx.IsConfirmed () { t.Errorf ("%d tx requests, confirmed %t", node.txRequests, tx.IsConfirmed ()) }
+}
+
+func TestZmqRawBlock (t *testing.T) {
+
+	resetNodeEvents ()
+	events := SubscribeToNodeEvents (false)
+
+	network := btc.GetNetwork ()
+	genesisHash := network.GetGenesisBlockHash ()
+	node := &testNode { headers: map [string] map [string] interface {} {
+								genesisHash: { "height": float64 (0), "difficulty": float64 (1), "chainwork": "0000000000000000000000000000000000000000000000000000000100010001", "mediantime": float64 (network.GetGenesisBlockTime ()) } } }
+
+	publisher := newTestZmqPublisher (t)
+	go newTestNodeProxy (node).runZmqSubscriber (publisher.getAddr (), [] string { ZMQ_TOPIC_RAW_BLOCK })
+	conn := publisher.waitForConnection (t)
+
+	genesisBlock := decodeTestHex (t, testGenesisHeaderHex + "01" + network.GetGenesisTxHex ())
+	publishTestZmqMessage (t, conn, ZMQ_TOPIC_RAW_BLOCK, genesisBlock, 0)
+
+	event := waitForNodeEvent (t, events)
+	block := event.GetBlock ()
+	if event.GetEventType () != NODE_EVENT_BLOCK || block.GetHash () != genesisHash { t.Fatalf ("%s event for block %s", event.GetEventType (), block.GetHash ()) }
+	if block.GetHeight () != 0 || block.GetDifficulty () != 1 || block.GetTxCount () != 1 || block.GetTxIds () [0] != network.GetGenesisTxId () { t.Error ("the block does not match the genesis block") }
+
+	// a block the node does not know about is not passed on
+	resetNodeEvents ()
+	events = SubscribeToNodeEvents (false)
+	newTestNodeProxy (&testNode {}).handleZmqMessage (ZMQ_TOPIC_RAW_BLOCK, genesisBlock)
+	newTestNodeProxy (node).handleZmqMessage (ZMQ_TOPIC_RAW_BLOCK, genesisBlock [: 100])
+	if len (events) != 0 { t.Errorf ("%d events for invalid blocks", len (events)) }
+}
+
+func TestZmqHashBlock (t *testing.T) {
+
+	resetNodeEvents ()
+	events := SubscribeToNodeEvents (false)
+
+	network := btc.GetNetwork ()
+	genesisHash := network.GetGenesisBlockHash ()
+	node := &testNode { blocks: map [string] map [string] interface {} {
+								genesisHash: { "hash": genesisHash, "height": float64 (0), "version": float64 (1), "time": float64 (network.GetGenesisBlockTime ()),
+												"tx": [] interface {} { map [string] interface {} { "txid": network.GetGenesisTxId () } } } } }
+
+	publisher := newTestZmqPublisher (t)
+	go newTestNodeProxy (node).runZmqSubscriber (publisher.getAddr (), [] string { ZMQ_TOPIC_HASH_BLOCK })
+	conn := publisher.waitForConnection (t)
+
+	publishTestZmqMessage (t, conn, ZMQ_TOPIC_HASH_BLOCK, decodeTestHex (t, genesisHash), 0)
+
+	event := waitForNodeEvent (t, events)
+	block := event.GetBlock ()
+	if event.GetEventType () != NODE_EVENT_BLOCK || block.GetHash () != genesisHash { t.Errorf ("%s event for block %s", event.GetEventType (), block.GetHash ()) }
+
+	// the hash has to be a whole hash of a block the node knows about
+	resetNodeEvents ()
+	events = SubscribeToNodeEvents (false)
+	newTestNodeProxy (node).handleZmqMessage (ZMQ_TOPIC_HASH_BLOCK, decodeTestHex (t, genesisHash) [: 31])
+	newTestNodeProxy (&testNode {}).handleZmqMessage (ZMQ_TOPIC_HASH_BLOCK, decodeTestHex (t, genesisHash))
+	if len (events) != 0 { t.Errorf ("%d events for invalid hashes", len (events)) }
+}
+
+func TestZmqReconnect (t *testing.T) {
+
+	resetNodeEvents ()
+	events := SubscribeToNodeEvents (true)
+
+	publisher := newTestZmqPublisher (t)
+	topics := [] string { ZMQ_TOPIC_RAW_TX, ZMQ_TOPIC_HASH_BLOCK }
+	go newTestNodeProxy (&testNode {}).runZmqSubscriber (publisher.getAddr (), topics)
+
+	// the node goes away, and the subscriber has to subscribe again when it comes back
+	for c := 0; c < 2; c++ {
+		conn := publisher.waitForConnection (t)
+		for range topics {
+			select {
+				case <-publisher.subscriptions:
+				case <-time.After (5 * time.Second):
+					t.Fatalf ("connection %d: not subscribed", c)
+			}
+		}
+
+		network := btc.GetNetwork ()
+		publishTestZmqMessage (t, conn, ZMQ_TOPIC_RAW_TX, decodeTestHex (t, network.GetGenesisTxHex ()), 0)
+		waitForNodeEvent (t, events)
+
+		conn.Close ()
+		resetNodeEvents ()
+		events = SubscribeToNodeEvents (true)
+	}
+}
+
+func TestZmqSequenceGaps (t *testing.T) {
+
+	sequences := make (map [string] uint32)
+	check := func (topic string, sequence uint32, expectedMissed uint32) {
+		t.Helper ()
+
+		sequenceBytes := make ([] byte, 4)
+		binary.LittleEndian.PutUint32 (sequenceBytes, sequence)
+		if missed := getMissedZmqMessageCount (sequences, topic, sequenceBytes); missed != expectedMissed { t.Errorf ("%s %d: %d missed, expected %d", topic, sequence, missed, expectedMissed) }
+	}
+
+	check (ZMQ_TOPIC_RAW_TX, 5, 0)
+	check (ZMQ_TOPIC_RAW_TX, 6, 0)
+	check (ZMQ_TOPIC_RAW_TX, 10, 3)
+
+	// every topic has its own sequence
+	check (ZMQ_TOPIC_RAW_BLOCK, 0, 0)
+	check (ZMQ_TOPIC_RAW_BLOCK, 1, 0)
+	check (ZMQ_TOPIC_RAW_TX, 11, 0)
+
+	// the node restarted
+	check (ZMQ_TOPIC_RAW_TX, 0, 0)
+	check (ZMQ_TOPIC_RAW_TX, 2, 1)
+
+	if getMissedZmqMessageCount (sequences, ZMQ_TOPIC_RAW_TX, [] byte { 9 }) != 0 || sequences [ZMQ_TOPIC_RAW_TX] != 2 { t.Error ("an invalid sequence number was used") }
+}
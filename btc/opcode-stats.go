@@ -0,0 +1,100 @@
+package btc
+
+import (
+	"fmt"
+)
+
+// the kinds of scripts that opcodes can appear in
+const SCRIPT_TYPE_INPUT = "input_script"
+const SCRIPT_TYPE_REDEEM = "redeem_script"
+const SCRIPT_TYPE_WITNESS = "witness_script"
+const SCRIPT_TYPE_TAP = "tap_script"
+const SCRIPT_TYPE_OUTPUT = "output_script"
+
+var scriptTypes = [] string { SCRIPT_TYPE_INPUT, SCRIPT_TYPE_REDEEM, SCRIPT_TYPE_WITNESS, SCRIPT_TYPE_TAP, SCRIPT_TYPE_OUTPUT }
+
+func GetScriptTypes () [] string {
+	return scriptTypes
+}
+
+func IsScriptType (scriptType string) bool {
+	for _, t := range scriptTypes {
+		if t == scriptType { return true }
+	}
+
+	return false
+}
+
+// BIP 342 redefines these opcodes in tap scripts as OP_SUCCESSx, where x is the value of the opcode
+func IsOpSuccess (opcode byte) bool {
+	return opcode == 0x50 || opcode == 0x62 || (opcode >= 0x7e && opcode <= 0x81) || (opcode >= 0x83 && opcode <= 0x86) ||
+			(opcode >= 0x89 && opcode <= 0x8a) || (opcode >= 0x8d && opcode <= 0x8e) || (opcode >= 0x95 && opcode <= 0x99) || (opcode >= 0xbb && opcode <= 0xfe)
+}
+
+// the number of times each opcode appears, counted separately for each kind of script
+type OpcodeStats struct {
+	counts map [string] map [string] uint64
+}
+
+func NewOpcodeStats () OpcodeStats {
+	return OpcodeStats { counts: make (map [string] map [string] uint64) }
+}
+
+// script type -> opcode -> count
+func (os *OpcodeStats) GetCounts () map [string] map [string] uint64 {
+	return os.counts
+}
+
+func (os *OpcodeStats) GetCount (scriptType string, opcode string) uint64 {
+	return os.counts [scriptType] [opcode]
+}
+
+// inputs must have their previous outputs for the serialized scripts to be found
+func (os *OpcodeStats) AddTx (tx Tx) {
+
+	for _, input := range tx.GetInputs () {
+		if input.IsCoinbase () { continue }
+
+		os.AddScript (SCRIPT_TYPE_INPUT, input.GetInputScript ())
+		if input.HasRedeemScript () { os.AddScript (SCRIPT_TYPE_REDEEM, input.GetRedeemScript ()) }
+
+		segwit := input.GetSegwit ()
+		os.AddScript (SCRIPT_TYPE_WITNESS, segwit.GetWitnessScript ())
+		tapScript, _ := segwit.GetTapScript ()
+		os.AddScript (SCRIPT_TYPE_TAP, tapScript)
+	}
+
+	for _, output := range tx.GetOutputs () {
+		os.AddScript (SCRIPT_TYPE_OUTPUT, output.GetOutputScript ())
+	}
+}
+
+func (os *OpcodeStats) AddScript (scriptType string, script Script) {
+
+	if script.IsNil () { return }
+
+	for _, field := range script.GetFields () {
+		if !field.IsOpcode () { continue }
+
+		opcode := field.AsHex ()
+		if scriptType == SCRIPT_TYPE_TAP && IsOpSuccess (field.AsBytes () [0]) { opcode = fmt.Sprintf ("OP_SUCCESS%d", field.AsBytes () [0]) }
+		os.add (scriptType, opcode, 1)
+	}
+
+	// opcodes that are not defined at all end the script with a parse error, so they are found separately
+	if scriptType == SCRIPT_TYPE_TAP && script.HasParseError () {
+		if opcode, found := script.findUndefinedOpcode (); found && IsOpSuccess (opcode) { os.add (scriptType, fmt.Sprintf ("OP_SUCCESS%d", opcode), 1) }
+	}
+}
+
+// adds the counts from another range of blocks
+func (os *OpcodeStats) AddCounts (counts map [string] map [string] uint64) {
+	for scriptType, opcodes := range counts {
+		for opcode, count := range opcodes { os.add (scriptType, opcode, count) }
+	}
+}
+
+func (os *OpcodeStats) add (scriptType string, opcode string, count uint64) {
+	if os.counts [scriptType] == nil { os.counts [scriptType] = make (map [string] uint64) }
+	os.counts [scriptType] [opcode] += count
+}
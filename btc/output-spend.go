@@ -0,0 +1,54 @@
+package btc
+
+import (
+)
+
+const OUTPUT_UNSPENT = "unspent"
+const OUTPUT_SPENT = "spent"
+
+// describes whether an output has been spent and, when it can be found, the input that spent it
+// the spending tx id is empty when the output is spent in a block that has not been indexed
+// the block height is negative when the spending transaction is in the mempool
+type OutputSpend struct {
+	status string
+	txId string
+	inputIndex uint32
+	spendType string
+	blockHeight int64
+}
+
+func NewOutputSpend (status string, txId string, inputIndex uint32, spendType string, blockHeight int64) OutputSpend {
+	return OutputSpend { status: status, txId: txId, inputIndex: inputIndex, spendType: spendType, blockHeight: blockHeight }
+}
+
+func (os *OutputSpend) IsNil () bool {
+	return len (os.status) == 0
+}
+
+func (os *OutputSpend) GetStatus () string {
+	return os.status
+}
+
+func (os *OutputSpend) IsSpent () bool {
+	return os.status == OUTPUT_SPENT
+}
+
+func (os *OutputSpend) GetTxId () string {
+	return os.txId
+}
+
+func (os *OutputSpend) GetInputIndex () uint32 {
+	return os.inputIndex
+}
+
+func (os *OutputSpend) GetSpendType () string {
+	return os.spendType
+}
+
+func (os *OutputSpend) GetBlockHeight () int64 {
+	return os.blockHeight
+}
+
+func (os *OutputSpend) IsInMempool () bool {
+	return os.IsSpent () && len (os.txId) > 0 && os.blockHeight < 0
+}
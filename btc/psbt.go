@@ -0,0 +1,888 @@
+package btc
+
+import (
+	"fmt"
+	"bytes"
+	"sort"
+	"errors"
+	"strings"
+	"encoding/hex"
+	"encoding/base64"
+	"encoding/binary"
+)
+
+// BIP 174 (version 0) and BIP 370 (version 2) partially signed bitcoin transactions
+
+const PSBT_GLOBAL_UNSIGNED_TX = 0x00
+const PSBT_GLOBAL_XPUB = 0x01
+const PSBT_GLOBAL_TX_VERSION = 0x02
+const PSBT_GLOBAL_FALLBACK_LOCKTIME = 0x03
+const PSBT_GLOBAL_INPUT_COUNT = 0x04
+const PSBT_GLOBAL_OUTPUT_COUNT = 0x05
+const PSBT_GLOBAL_TX_MODIFIABLE = 0x06
+const PSBT_GLOBAL_VERSION = 0xfb
+
+const PSBT_IN_NON_WITNESS_UTXO = 0x00
+const PSBT_IN_WITNESS_UTXO = 0x01
+const PSBT_IN_PARTIAL_SIG = 0x02
+const PSBT_IN_SIGHASH_TYPE = 0x03
+const PSBT_IN_REDEEM_SCRIPT = 0x04
+const PSBT_IN_WITNESS_SCRIPT = 0x05
+const PSBT_IN_BIP32_DERIVATION = 0x06
+const PSBT_IN_FINAL_SCRIPTSIG = 0x07
+const PSBT_IN_FINAL_SCRIPTWITNESS = 0x08
+const PSBT_IN_PREVIOUS_TXID = 0x0e
+const PSBT_IN_OUTPUT_INDEX = 0x0f
+const PSBT_IN_SEQUENCE = 0x10
+const PSBT_IN_REQUIRED_TIME_LOCKTIME = 0x11
+const PSBT_IN_REQUIRED_HEIGHT_LOCKTIME = 0x12
+const PSBT_IN_TAP_KEY_SIG = 0x13
+const PSBT_IN_TAP_SCRIPT_SIG = 0x14
+const PSBT_IN_TAP_LEAF_SCRIPT = 0x15
+const PSBT_IN_TAP_BIP32_DERIVATION = 0x16
+const PSBT_IN_TAP_INTERNAL_KEY = 0x17
+const PSBT_IN_TAP_MERKLE_ROOT = 0x18
+
+const PSBT_OUT_REDEEM_SCRIPT = 0x00
+const PSBT_OUT_WITNESS_SCRIPT = 0x01
+const PSBT_OUT_BIP32_DERIVATION = 0x02
+const PSBT_OUT_AMOUNT = 0x03
+const PSBT_OUT_SCRIPT = 0x04
+const PSBT_OUT_TAP_INTERNAL_KEY = 0x05
+const PSBT_OUT_TAP_TREE = 0x06
+const PSBT_OUT_TAP_BIP32_DERIVATION = 0x07
+
+var psbtMagic = [] byte { 'p', 's', 'b', 't', 0xff }
+
+type Bip32Derivation struct {
+	publicKey [] byte
+	fingerprint [] byte
+	path [] uint32
+	leafHashes [] [] byte
+}
+
+func (d *Bip32Derivation) GetPublicKey () [] byte {
+	return d.publicKey
+}
+
+func (d *Bip32Derivation) GetFingerprint () [] byte {
+	return d.fingerprint
+}
+
+// returns the path in the usual m/84'/0'/0' format
+func (d *Bip32Derivation) GetPath () string {
+	path := "m"
+	for _, index := range d.path {
+		if index >= 0x80000000 {
+			path += fmt.Sprintf ("/%d'", index - 0x80000000)
+		} else {
+			path += fmt.Sprintf ("/%d", index)
+		}
+	}
+	return path
+}
+
+// only used for taproot derivations
+func (d *Bip32Derivation) GetLeafHashes () [] [] byte {
+	return d.leafHashes
+}
+
+type PsbtSignature struct {
+	publicKey [] byte
+	leafHash [] byte
+	signature [] byte
+}
+
+func (s *PsbtSignature) GetPublicKey () [] byte {
+	return s.publicKey
+}
+
+// only used for tap script signatures
+func (s *PsbtSignature) GetLeafHash () [] byte {
+	return s.leafHash
+}
+
+func (s *PsbtSignature) GetSignature () [] byte {
+	return s.signature
+}
+
+// a leaf script either being spent by an input (with a control block) or committed to by an output (with a depth)
+type TapLeafScript struct {
+	controlBlock [] byte
+	depth byte
+	leafVersion byte
+	script Script
+}
+
+func (l *TapLeafScript) GetControlBlock () [] byte {
+	return l.controlBlock
+}
+
+func (l *TapLeafScript) GetDepth () byte {
+	return l.depth
+}
+
+func (l *TapLeafScript) GetLeafVersion () byte {
+	return l.leafVersion
+}
+
+func (l *TapLeafScript) GetScript () Script {
+	return l.script
+}
+
+type PsbtInput struct {
+	input Input
+	finalized bool
+
+	partialSignatures [] PsbtSignature
+	sighashType uint32
+	hasSighashType bool
+	redeemScript Script
+	witnessScript Script
+	bip32Derivations [] Bip32Derivation
+
+	tapKeySignature [] byte
+	tapScriptSignatures [] PsbtSignature
+	tapLeafScripts [] TapLeafScript
+	tapBip32Derivations [] Bip32Derivation
+	tapInternalKey [] byte
+	tapMerkleRoot [] byte
+}
+
+func (pi *PsbtInput) GetInput () Input {
+	return pi.input
+}
+
+// if the input is not finalized, its input script and segwit fields were assembled from the psbt fields so that it could be classified
+func (pi *PsbtInput) IsFinalized () bool {
+	return pi.finalized
+}
+
+func (pi *PsbtInput) GetPartialSignatures () [] PsbtSignature {
+	return pi.partialSignatures
+}
+
+func (pi *PsbtInput) GetSighashType () (uint32, bool) {
+	return pi.sighashType, pi.hasSighashType
+}
+
+func (pi *PsbtInput) GetRedeemScript () Script {
+	return pi.redeemScript
+}
+
+func (pi *PsbtInput) GetWitnessScript () Script {
+	return pi.witnessScript
+}
+
+func (pi *PsbtInput) GetBip32Derivations () [] Bip32Derivation {
+	return pi.bip32Derivations
+}
+
+func (pi *PsbtInput) GetTapKeySignature () [] byte {
+	return pi.tapKeySignature
+}
+
+func (pi *PsbtInput) GetTapScriptSignatures () [] PsbtSignature {
+	return pi.tapScriptSignatures
+}
+
+func (pi *PsbtInput) GetTapLeafScripts () [] TapLeafScript {
+	return pi.tapLeafScripts
+}
+
+func (pi *PsbtInput) GetTapBip32Derivations () [] Bip32Derivation {
+	return pi.tapBip32Derivations
+}
+
+func (pi *PsbtInput) GetTapInternalKey () [] byte {
+	return pi.tapInternalKey
+}
+
+func (pi *PsbtInput) GetTapMerkleRoot () [] byte {
+	return pi.tapMerkleRoot
+}
+
+func GetSighashName (sighashType uint32) string {
+	switch sighashType {
+		case 0x00: return "SIGHASH_DEFAULT"
+		case 0x01: return "SIGHASH_ALL"
+		case 0x02: return "SIGHASH_NONE"
+		case 0x03: return "SIGHASH_SINGLE"
+		case 0x81: return "SIGHASH_ALL|ANYONECANPAY"
+		case 0x82: return "SIGHASH_NONE|ANYONECANPAY"
+		case 0x83: return "SIGHASH_SINGLE|ANYONECANPAY"
+	}
+	return fmt.Sprintf ("0x%02x", sighashType)
+}
+
+type PsbtOutput struct {
+	redeemScript Script
+	witnessScript Script
+	bip32Derivations [] Bip32Derivation
+	tapInternalKey [] byte
+	tapTree [] TapLeafScript
+	tapBip32Derivations [] Bip32Derivation
+}
+
+func (po *PsbtOutput) GetRedeemScript () Script {
+	return po.redeemScript
+}
+
+func (po *PsbtOutput) GetWitnessScript () Script {
+	return po.witnessScript
+}
+
+func (po *PsbtOutput) GetBip32Derivations () [] Bip32Derivation {
+	return po.bip32Derivations
+}
+
+func (po *PsbtOutput) GetTapInternalKey () [] byte {
+	return po.tapInternalKey
+}
+
+func (po *PsbtOutput) GetTapTree () [] TapLeafScript {
+	return po.tapTree
+}
+
+func (po *PsbtOutput) GetTapBip32Derivations () [] Bip32Derivation {
+	return po.tapBip32Derivations
+}
+
+type Psbt struct {
+	version uint32
+	tx Tx
+	xpubs [] Bip32Derivation
+	inputs [] PsbtInput
+	outputs [] PsbtOutput
+}
+
+func (p *Psbt) GetVersion () uint32 {
+	return p.version
+}
+
+// the inputs of the tx are the same as the inputs returned by GetInputs
+func (p *Psbt) GetTx () Tx {
+	return p.tx
+}
+
+func (p *Psbt) GetXpubs () [] Bip32Derivation {
+	return p.xpubs
+}
+
+func (p *Psbt) GetInputs () [] PsbtInput {
+	return p.inputs
+}
+
+func (p *Psbt) GetOutputs () [] PsbtOutput {
+	return p.outputs
+}
+
+// the fee can only be calculated if every previous output is known
+func (p *Psbt) GetFee () (uint64, bool) {
+
+	valueIn := uint64 (0)
+	for _, psbtInput := range p.inputs {
+		previousOutput := psbtInput.input.GetPreviousOutput ()
+		if len (previousOutput.GetOutputType ()) == 0 { return 0, false }
+		valueIn += previousOutput.GetValue ()
+	}
+
+	valueOut := uint64 (0)
+	for _, output := range p.tx.GetOutputs () {
+		valueOut += output.GetValue ()
+	}
+
+	if valueOut > valueIn { return 0, false }
+	return valueIn - valueOut, true
+}
+
+type psbtKeyValue struct {
+	keyType uint64
+	keyData [] byte
+	value [] byte
+}
+
+// a key can only appear once in a map, see BIP 174
+func readPsbtMap (r *byteReader) ([] psbtKeyValue, error) {
+
+	keyValues := make ([] psbtKeyValue, 0)
+	keys := make (map [string] bool)
+	for {
+		key := r.readVarBytes ()
+		if r.err != nil { return nil, r.err }
+
+		// a zero-length key is the separator at the end of the map
+		if len (key) == 0 { break }
+
+		keyType, byteCount := ReadVarInt (key)
+		if byteCount == 0 { return nil, errors.New ("Invalid psbt key.") }
+
+		if keys [string (key)] { return nil, errors.New (fmt.Sprintf ("Duplicate psbt key 0x%s.", hex.EncodeToString (key))) }
+		keys [string (key)] = true
+
+		value := r.readVarBytes ()
+		if r.err != nil { return nil, r.err }
+
+		keyValues = append (keyValues, psbtKeyValue { keyType: keyType, keyData: key [byteCount :], value: value })
+	}
+
+	return keyValues, nil
+}
+
+func readKeyPath (publicKey [] byte, value [] byte) (Bip32Derivation, error) {
+	if len (value) < 4 || len (value) % 4 != 0 { return Bip32Derivation {}, errors.New ("Invalid BIP 32 derivation.") }
+
+	derivation := Bip32Derivation { publicKey: publicKey, fingerprint: value [0 : 4], path: make ([] uint32, 0, (len (value) - 4) / 4) }
+	for pos := 4; pos < len (value); pos += 4 {
+		derivation.path = append (derivation.path, binary.LittleEndian.Uint32 (value [pos : pos + 4]))
+	}
+
+	return derivation, nil
+}
+
+func readTapKeyPath (publicKey [] byte, value [] byte) (Bip32Derivation, error) {
+	r := newByteReader (value)
+	leafHashCount := r.readVarInt ()
+	if leafHashCount > uint64 (len (value)) { return Bip32Derivation {}, errors.New ("Invalid taproot BIP 32 derivation.") }
+
+	leafHashes := make ([] [] byte, leafHashCount)
+	for h := uint64 (0); h < leafHashCount; h++ {
+		leafHashes [h] = r.read (32)
+	}
+	if r.err != nil { return Bip32Derivation {}, r.err }
+
+	derivation, err := readKeyPath (publicKey, value [r.pos :])
+	derivation.leafHashes = leafHashes
+	return derivation, err
+}
+
+func readUint32Value (value [] byte) (uint32, error) {
+	if len (value) != 4 { return 0, errors.New ("Invalid 32-bit psbt value.") }
+	return binary.LittleEndian.Uint32 (value), nil
+}
+
+func readVarIntValue (value [] byte) (uint64, error) {
+	count, byteCount := ReadVarInt (value)
+	if byteCount == 0 || byteCount != len (value) { return 0, errors.New ("Invalid compact size psbt value.") }
+	return count, nil
+}
+
+// the key data must have one of the lengths, most keys have no key data at all
+func checkPsbtKeyData (kv psbtKeyValue, keyName string, lengths ...int) error {
+	for _, length := range lengths {
+		if len (kv.keyData) == length { return nil }
+	}
+	return errors.New ("Invalid " + keyName + " key.")
+}
+
+// keys whose key data is a public key
+func checkPsbtPublicKey (kv psbtKeyValue, keyName string) error {
+	if !IsValidECPublicKey (kv.keyData) { return errors.New ("Invalid public key in " + keyName + " key.") }
+	return nil
+}
+
+func isValidTapSignature (signature [] byte) bool {
+	return len (signature) == 64 || len (signature) == 65
+}
+
+// a control block has the leaf version and internal key, followed by up to 128 hashes of the merkle path, see BIP 341
+func isValidControlBlock (controlBlock [] byte) bool {
+	return len (controlBlock) >= 33 && len (controlBlock) <= 33 + 128 * 32 && (len (controlBlock) - 33) % 32 == 0
+}
+
+// version 2 replaces the unsigned transaction with these fields, so they can not be in a version 0 psbt
+func isPsbtV2InputKey (keyType uint64) bool {
+	return keyType >= PSBT_IN_PREVIOUS_TXID && keyType <= PSBT_IN_REQUIRED_HEIGHT_LOCKTIME
+}
+
+func isPsbtV2OutputKey (keyType uint64) bool {
+	return keyType == PSBT_OUT_AMOUNT || keyType == PSBT_OUT_SCRIPT
+}
+
+// accepts base64, which is the standard psbt string format, or hex
+func DecodePsbtString (psbtStr string) (Psbt, error) {
+
+	psbtStr = strings.TrimSpace (psbtStr)
+
+	psbtBytes, err := base64.StdEncoding.DecodeString (psbtStr)
+	if err != nil {
+		psbtBytes, err = hex.DecodeString (psbtStr)
+		if err != nil { return Psbt {}, errors.New ("PSBT is neither a base64 string nor a hex string.") }
+	}
+
+	return DecodePsbt (psbtBytes)
+}
+
+func DecodePsbt (psbtBytes [] byte) (Psbt, error) {
+
+	if len (psbtBytes) < len (psbtMagic) || !bytes.Equal (psbtBytes [0 : len (psbtMagic)], psbtMagic) {
+		return Psbt {}, errors.New ("PSBT magic bytes not found.")
+	}
+
+	r := newByteReader (psbtBytes)
+	r.read (len (psbtMagic))
+
+	// global map
+	globals, err := readPsbtMap (&r)
+	if err != nil { return Psbt {}, err }
+
+	p := Psbt { xpubs: make ([] Bip32Derivation, 0) }
+
+	unsignedTx := [] byte (nil)
+	txVersion := uint32 (0)
+	fallbackLockTime := uint32 (0)
+	inputCount := uint64 (0)
+	outputCount := uint64 (0)
+
+	// the fields that version 2 requires
+	hasTxVersion := false
+	hasInputCount := false
+	hasOutputCount := false
+	hasV2Globals := false
+
+	for _, kv := range globals {
+		switch kv.keyType {
+			case PSBT_GLOBAL_UNSIGNED_TX:
+				err = checkPsbtKeyData (kv, "unsigned tx", 0)
+				unsignedTx = kv.value
+			case PSBT_GLOBAL_XPUB:
+				if err = checkPsbtKeyData (kv, "xpub", 78); err != nil { break }
+				var xpub Bip32Derivation
+				xpub, err = readKeyPath (kv.keyData, kv.value)
+				p.xpubs = append (p.xpubs, xpub)
+			case PSBT_GLOBAL_TX_VERSION:
+				if err = checkPsbtKeyData (kv, "tx version", 0); err != nil { break }
+				txVersion, err = readUint32Value (kv.value)
+				hasTxVersion = true
+			case PSBT_GLOBAL_FALLBACK_LOCKTIME:
+				if err = checkPsbtKeyData (kv, "fallback lock time", 0); err != nil { break }
+				fallbackLockTime, err = readUint32Value (kv.value)
+			case PSBT_GLOBAL_INPUT_COUNT:
+				if err = checkPsbtKeyData (kv, "input count", 0); err != nil { break }
+				inputCount, err = readVarIntValue (kv.value)
+				hasInputCount = true
+			case PSBT_GLOBAL_OUTPUT_COUNT:
+				if err = checkPsbtKeyData (kv, "output count", 0); err != nil { break }
+				outputCount, err = readVarIntValue (kv.value)
+				hasOutputCount = true
+			case PSBT_GLOBAL_TX_MODIFIABLE:
+				if err = checkPsbtKeyData (kv, "tx modifiable", 0); err != nil { break }
+				if len (kv.value) != 1 { err = errors.New ("Invalid tx modifiable flags.") }
+			case PSBT_GLOBAL_VERSION:
+				if err = checkPsbtKeyData (kv, "version", 0); err != nil { break }
+				p.version, err = readUint32Value (kv.value)
+		}
+		if err != nil { return Psbt {}, err }
+
+		if kv.keyType >= PSBT_GLOBAL_TX_VERSION && kv.keyType <= PSBT_GLOBAL_TX_MODIFIABLE { hasV2Globals = true }
+	}
+
+	if p.version != 0 && p.version != 2 { return Psbt {}, errors.New (fmt.Sprintf ("Unsupported PSBT version %d.", p.version)) }
+
+	if p.version == 0 {
+		if hasV2Globals { return Psbt {}, errors.New ("PSBT version 0 can not have version 2 global fields.") }
+		if unsignedTx == nil { return Psbt {}, errors.New ("PSBT does not contain an unsigned transaction.") }
+
+		p.tx, err = DecodeRawTx (unsignedTx)
+		if err != nil { return Psbt {}, errors.New ("Failed to decode unsigned transaction: " + err.Error ()) }
+
+		// the signatures go in the psbt input maps until the inputs are finalized
+		for _, input := range p.tx.GetInputs () {
+			inputScript := input.GetInputScript ()
+			if !inputScript.IsEmpty () || input.HasSegwitFields () { return Psbt {}, errors.New ("The unsigned transaction has an input script or witness.") }
+		}
+
+		inputCount = uint64 (p.tx.GetInputCount ())
+		outputCount = uint64 (p.tx.GetOutputCount ())
+	} else {
+		if unsignedTx != nil { return Psbt {}, errors.New ("PSBT version 2 can not have an unsigned transaction.") }
+		if !hasTxVersion || !hasInputCount || !hasOutputCount { return Psbt {}, errors.New ("PSBT version 2 requires the tx version, input count and output count.") }
+	}
+
+	if inputCount > uint64 (len (psbtBytes)) || outputCount > uint64 (len (psbtBytes)) { return Psbt {}, errors.New ("Invalid input or output count.") }
+
+	// input maps
+	inputMaps := make ([] [] psbtKeyValue, inputCount)
+	for i := uint64 (0); i < inputCount; i++ {
+		inputMaps [i], err = readPsbtMap (&r)
+		if err != nil { return Psbt {}, err }
+	}
+
+	// output maps
+	outputMaps := make ([] [] psbtKeyValue, outputCount)
+	for o := uint64 (0); o < outputCount; o++ {
+		outputMaps [o], err = readPsbtMap (&r)
+		if err != nil { return Psbt {}, err }
+	}
+
+	if !r.isFinished () { return Psbt {}, errors.New ("Unexpected data after the last output map.") }
+
+	// version 2 does not include the unsigned transaction, so we build it from the input and output maps
+	if p.version == 2 {
+		p.tx, err = buildPsbtV2Tx (txVersion, fallbackLockTime, inputMaps, outputMaps)
+		if err != nil { return Psbt {}, err }
+	}
+
+	p.inputs = make ([] PsbtInput, inputCount)
+	for i, inputMap := range inputMaps {
+		p.inputs [i], err = readPsbtInput (inputMap, p.tx.GetInput (uint16 (i)), p.version)
+		if err != nil { return Psbt {}, errors.New (fmt.Sprintf ("Input %d: %s", i, err.Error ())) }
+	}
+
+	p.outputs = make ([] PsbtOutput, outputCount)
+	for o, outputMap := range outputMaps {
+		p.outputs [o], err = readPsbtOutput (outputMap, p.version)
+		if err != nil { return Psbt {}, errors.New (fmt.Sprintf ("Output %d: %s", o, err.Error ())) }
+	}
+
+	// rebuild the transaction with the classified inputs
+	inputs := make ([] Input, inputCount)
+	bip141 := false
+	for i, psbtInput := range p.inputs {
+		inputs [i] = psbtInput.input
+		bip141 = bip141 || psbtInput.input.HasSegwitFields ()
+	}
+	p.tx = NewTx (p.tx.GetTxId (), p.tx.GetVersion (), inputs, p.tx.GetOutputs (), p.tx.GetLockTime (), false, bip141, "", 0)
+
+	return p, nil
+}
+
+func buildPsbtV2Tx (version uint32, fallbackLockTime uint32, inputMaps [] [] psbtKeyValue, outputMaps [] [] psbtKeyValue) (Tx, error) {
+
+	inputCount := len (inputMaps)
+	previousOutputTxIds := make ([] string, inputCount)
+	previousOutputIndexes := make ([] uint32, inputCount)
+	sequences := make ([] uint32, inputCount)
+
+	// lock time requirements, see BIP 370
+	maxTime := uint32 (0)
+	maxHeight := uint32 (0)
+	timeRequired := false
+	heightRequired := false
+	lockTimeRequired := false
+
+	var err error
+	for i, inputMap := range inputMaps {
+		sequences [i] = 0xffffffff
+		hasOutputIndex := false
+		hasTime := false
+		hasHeight := false
+		for _, kv := range inputMap {
+			if isPsbtV2InputKey (kv.keyType) && len (kv.keyData) > 0 { return Tx {}, errors.New (fmt.Sprintf ("Input %d has an invalid key 0x%02x.", i, kv.keyType)) }
+
+			switch kv.keyType {
+				case PSBT_IN_PREVIOUS_TXID:
+					if len (kv.value) != 32 { return Tx {}, errors.New ("Invalid previous tx id.") }
+					previousOutputTxIds [i] = hex.EncodeToString (ReverseBytes (kv.value))
+				case PSBT_IN_OUTPUT_INDEX:
+					previousOutputIndexes [i], err = readUint32Value (kv.value)
+					hasOutputIndex = true
+				case PSBT_IN_SEQUENCE: sequences [i], err = readUint32Value (kv.value)
+				case PSBT_IN_REQUIRED_TIME_LOCKTIME:
+					var lockTime uint32
+					lockTime, err = readUint32Value (kv.value)
+					if err == nil && lockTime < 500000000 { err = errors.New (fmt.Sprintf ("Input %d has a required time lock time below 500000000.", i)) }
+					if lockTime > maxTime { maxTime = lockTime }
+					hasTime = true
+				case PSBT_IN_REQUIRED_HEIGHT_LOCKTIME:
+					var lockTime uint32
+					lockTime, err = readUint32Value (kv.value)
+					if err == nil && (lockTime == 0 || lockTime >= 500000000) { err = errors.New (fmt.Sprintf ("Input %d has a required height lock time that is not a block height.", i)) }
+					if lockTime > maxHeight { maxHeight = lockTime }
+					hasHeight = true
+			}
+			if err != nil { return Tx {}, err }
+		}
+
+		if len (previousOutputTxIds [i]) == 0 { return Tx {}, errors.New (fmt.Sprintf ("Input %d has no previous tx id.", i)) }
+		if !hasOutputIndex { return Tx {}, errors.New (fmt.Sprintf ("Input %d has no previous output index.", i)) }
+
+		if hasTime || hasHeight { lockTimeRequired = true }
+		if hasTime && !hasHeight { timeRequired = true }
+		if hasHeight && !hasTime { heightRequired = true }
+	}
+
+	lockTime := fallbackLockTime
+	if lockTimeRequired {
+		if timeRequired && heightRequired { return Tx {}, errors.New ("Inputs require incompatible lock time types.") }
+		if timeRequired { lockTime = maxTime } else { lockTime = maxHeight }
+	}
+
+	outputCount := len (outputMaps)
+	outputValues := make ([] uint64, outputCount)
+	outputScripts := make ([] [] byte, outputCount)
+	for o, outputMap := range outputMaps {
+		hasAmount := false
+		for _, kv := range outputMap {
+			if isPsbtV2OutputKey (kv.keyType) && len (kv.keyData) > 0 { return Tx {}, errors.New (fmt.Sprintf ("Output %d has an invalid key 0x%02x.", o, kv.keyType)) }
+
+			switch kv.keyType {
+				case PSBT_OUT_AMOUNT:
+					if len (kv.value) != 8 { return Tx {}, errors.New ("Invalid output amount.") }
+					outputValues [o] = binary.LittleEndian.Uint64 (kv.value)
+					hasAmount = true
+				case PSBT_OUT_SCRIPT: outputScripts [o] = kv.value
+			}
+		}
+		if !hasAmount || outputScripts [o] == nil { return Tx {}, errors.New (fmt.Sprintf ("Output %d is missing its amount or script.", o)) }
+	}
+
+	strippedTx, err := serializeStrippedTx (version, previousOutputTxIds, previousOutputIndexes, sequences, outputValues, outputScripts, lockTime)
+	if err != nil { return Tx {}, err }
+
+	return DecodeRawTx (strippedTx)
+}
+
+func readPsbtInput (inputMap [] psbtKeyValue, unsignedInput Input, version uint32) (PsbtInput, error) {
+
+	pi := PsbtInput {	partialSignatures: make ([] PsbtSignature, 0),
+						bip32Derivations: make ([] Bip32Derivation, 0),
+						tapScriptSignatures: make ([] PsbtSignature, 0),
+						tapLeafScripts: make ([] TapLeafScript, 0),
+						tapBip32Derivations: make ([] Bip32Derivation, 0) }
+
+	previousOutput := Output {}
+	finalInputScript := [] byte (nil)
+	finalSegwitFields := [] [] byte (nil)
+
+	var err error
+	for _, kv := range inputMap {
+		if version == 0 && isPsbtV2InputKey (kv.keyType) { return pi, errors.New (fmt.Sprintf ("PSBT version 0 can not have the version 2 input key 0x%02x.", kv.keyType)) }
+
+		switch kv.keyType {
+
+			case PSBT_IN_NON_WITNESS_UTXO:
+				if err := checkPsbtKeyData (kv, "non-witness utxo", 0); err != nil { return pi, err }
+				previousTx, err := DecodeRawTx (kv.value)
+				if err != nil { return pi, errors.New ("Invalid non-witness utxo: " + err.Error ()) }
+				if previousTx.GetTxId () != unsignedInput.GetPreviousOutputTxId () { return pi, errors.New ("Non-witness utxo does not match the previous output tx id.") }
+				if unsignedInput.GetPreviousOutputIndex () >= previousTx.GetOutputCount () { return pi, errors.New ("Non-witness utxo does not contain the previous output.") }
+
+				// the witness utxo is preferred if there are both
+				if len (previousOutput.GetOutputType ()) == 0 {
+					previousOutput = previousTx.GetOutput (unsignedInput.GetPreviousOutputIndex ())
+				}
+
+			case PSBT_IN_WITNESS_UTXO:
+				if err := checkPsbtKeyData (kv, "witness utxo", 0); err != nil { return pi, err }
+				r := newByteReader (kv.value)
+				value := r.readUint64 ()
+				outputScript := NewScript (r.readVarBytes ())
+				if r.err != nil { return pi, errors.New ("Invalid witness utxo.") }
+				previousOutput = NewOutput (value, outputScript, GetAddress (outputScript))
+
+			case PSBT_IN_PARTIAL_SIG:
+				if err := checkPsbtPublicKey (kv, "partial signature"); err != nil { return pi, err }
+				pi.partialSignatures = append (pi.partialSignatures, PsbtSignature { publicKey: kv.keyData, signature: kv.value })
+
+			case PSBT_IN_SIGHASH_TYPE:
+				if err = checkPsbtKeyData (kv, "sighash type", 0); err != nil { break }
+				pi.sighashType, err = readUint32Value (kv.value)
+				pi.hasSighashType = true
+
+			case PSBT_IN_REDEEM_SCRIPT:
+				err = checkPsbtKeyData (kv, "redeem script", 0)
+				pi.redeemScript = NewScript (kv.value)
+			case PSBT_IN_WITNESS_SCRIPT:
+				err = checkPsbtKeyData (kv, "witness script", 0)
+				pi.witnessScript = NewScript (kv.value)
+
+			case PSBT_IN_BIP32_DERIVATION:
+				if err = checkPsbtPublicKey (kv, "BIP 32 derivation"); err != nil { break }
+				var derivation Bip32Derivation
+				derivation, err = readKeyPath (kv.keyData, kv.value)
+				pi.bip32Derivations = append (pi.bip32Derivations, derivation)
+
+			case PSBT_IN_FINAL_SCRIPTSIG:
+				err = checkPsbtKeyData (kv, "final input script", 0)
+				finalInputScript = kv.value
+
+			case PSBT_IN_FINAL_SCRIPTWITNESS:
+				if err := checkPsbtKeyData (kv, "final script witness", 0); err != nil { return pi, err }
+				r := newByteReader (kv.value)
+				fieldCount := r.readVarInt ()
+				if fieldCount > uint64 (len (kv.value)) { return pi, errors.New ("Invalid final script witness.") }
+				finalSegwitFields = make ([] [] byte, fieldCount)
+				for f := uint64 (0); f < fieldCount; f++ { finalSegwitFields [f] = r.readVarBytes () }
+				if r.err != nil { return pi, errors.New ("Invalid final script witness.") }
+
+			case PSBT_IN_TAP_KEY_SIG:
+				if err = checkPsbtKeyData (kv, "tap key signature", 0); err != nil { break }
+				if !isValidTapSignature (kv.value) { err = errors.New ("Invalid tap key signature.") }
+				pi.tapKeySignature = kv.value
+
+			case PSBT_IN_TAP_SCRIPT_SIG:
+				if len (kv.keyData) != 64 { return pi, errors.New ("Invalid tap script signature key.") }
+				if !isValidTapSignature (kv.value) { return pi, errors.New ("Invalid tap script signature.") }
+				pi.tapScriptSignatures = append (pi.tapScriptSignatures, PsbtSignature { publicKey: kv.keyData [0 : 32], leafHash: kv.keyData [32 :], signature: kv.value })
+
+			case PSBT_IN_TAP_LEAF_SCRIPT:
+				if !isValidControlBlock (kv.keyData) { return pi, errors.New ("Invalid control block in tap leaf script key.") }
+				if len (kv.value) < 1 { return pi, errors.New ("Invalid tap leaf script.") }
+				leafScript := NewScript (kv.value [0 : len (kv.value) - 1])
+				leafScript.setTapScriptFieldTypes ()
+				pi.tapLeafScripts = append (pi.tapLeafScripts, TapLeafScript { controlBlock: kv.keyData, leafVersion: kv.value [len (kv.value) - 1], script: leafScript })
+
+			case PSBT_IN_TAP_BIP32_DERIVATION:
+				if err = checkPsbtKeyData (kv, "taproot BIP 32 derivation", 32); err != nil { break }
+				var derivation Bip32Derivation
+				derivation, err = readTapKeyPath (kv.keyData, kv.value)
+				pi.tapBip32Derivations = append (pi.tapBip32Derivations, derivation)
+
+			case PSBT_IN_TAP_INTERNAL_KEY:
+				if err = checkPsbtKeyData (kv, "tap internal key", 0); err != nil { break }
+				if len (kv.value) != 32 { err = errors.New ("Invalid tap internal key.") }
+				pi.tapInternalKey = kv.value
+			case PSBT_IN_TAP_MERKLE_ROOT:
+				if err = checkPsbtKeyData (kv, "tap merkle root", 0); err != nil { break }
+				if len (kv.value) != 32 { err = errors.New ("Invalid tap merkle root.") }
+				pi.tapMerkleRoot = kv.value
+		}
+		if err != nil { return pi, err }
+	}
+
+	// classify the input
+	// if it has not been finalized, we assemble what the finalized input would look like from the fields we have
+	pi.finalized = finalInputScript != nil || finalSegwitFields != nil
+	inputScriptBytes := finalInputScript
+	segwitFields := finalSegwitFields
+	if !pi.finalized {
+		inputScriptBytes, segwitFields = pi.assembleUnfinalizedInput (previousOutput)
+	}
+
+	segwit := Segwit {}
+	if segwitFields != nil { segwit = NewSegwit (segwitFields) }
+	if inputScriptBytes == nil { inputScriptBytes = [] byte {} }
+
+	pi.input = NewInput (false, unsignedInput.GetPreviousOutputTxId (), unsignedInput.GetPreviousOutputIndex (), NewScript (inputScriptBytes), segwit, unsignedInput.GetSequence (), previousOutput)
+
+	return pi, nil
+}
+
+func (pi *PsbtInput) assembleUnfinalizedInput (previousOutput Output) ([] byte, [] [] byte) {
+
+	inputScript := [] byte (nil)
+	segwitFields := [] [] byte (nil)
+
+	outputType := previousOutput.GetOutputType ()
+	witnessProgramType := outputType
+	if outputType == OUTPUT_TYPE_P2SH && !pi.redeemScript.IsNil () {
+		inputScript = serializePushData (pi.redeemScript.AsBytes ())
+		if pi.redeemScript.IsP2wpkhOutput () { witnessProgramType = OUTPUT_TYPE_P2WPKH } else
+		if pi.redeemScript.IsP2wshOutput () { witnessProgramType = OUTPUT_TYPE_P2WSH }
+	}
+
+	switch witnessProgramType {
+		case OUTPUT_TYPE_P2WPKH:
+			segwitFields = make ([] [] byte, 0, 2)
+			if len (pi.partialSignatures) > 0 {
+				segwitFields = append (segwitFields, pi.partialSignatures [0].signature, pi.partialSignatures [0].publicKey)
+			}
+
+		case OUTPUT_TYPE_P2WSH:
+			segwitFields = pi.getWitnessScriptSignatures ()
+			if !pi.witnessScript.IsNil () { segwitFields = append (segwitFields, pi.witnessScript.AsBytes ()) }
+
+		case OUTPUT_TYPE_TAPROOT:
+			segwitFields = make ([] [] byte, 0)
+			if pi.tapKeySignature != nil {
+				segwitFields = append (segwitFields, pi.tapKeySignature)
+			} else if len (pi.tapLeafScripts) > 0 {
+				leaf := pi.tapLeafScripts [0]
+				for _, scriptSignature := range pi.tapScriptSignatures {
+					segwitFields = append (segwitFields, scriptSignature.signature)
+				}
+				segwitFields = append (segwitFields, leaf.script.AsBytes (), leaf.controlBlock)
+			}
+	}
+
+	return inputScript, segwitFields
+}
+
+// the signatures are put in the order of their public keys in the witness script, which is the order CHECKMULTISIG requires
+// CHECKMULTISIG also removes one more item from the stack than it uses, so an empty item goes in front of the signatures
+func (pi *PsbtInput) getWitnessScriptSignatures () [] [] byte {
+
+	fields := pi.witnessScript.GetFields ()
+	getPosition := func (publicKey [] byte) int {
+		for f, field := range fields {
+			if !field.IsOpcode () && bytes.Equal (field.AsBytes (), publicKey) { return f }
+		}
+		return len (fields)
+	}
+
+	partialSignatures := append ([] PsbtSignature {}, pi.partialSignatures...)
+	sort.SliceStable (partialSignatures, func (a, b int) bool { return getPosition (partialSignatures [a].publicKey) < getPosition (partialSignatures [b].publicKey) })
+
+	signatures := make ([] [] byte, 0, len (partialSignatures) + 2)
+	for _, field := range fields {
+		if field.IsOpcode () && (field.AsHex () == "OP_CHECKMULTISIG" || field.AsHex () == "OP_CHECKMULTISIGVERIFY") {
+			signatures = append (signatures, [] byte {})
+			break
+		}
+	}
+
+	for _, partialSignature := range partialSignatures {
+		signatures = append (signatures, partialSignature.signature)
+	}
+
+	return signatures
+}
+
+func readPsbtOutput (outputMap [] psbtKeyValue, version uint32) (PsbtOutput, error) {
+
+	po := PsbtOutput {	bip32Derivations: make ([] Bip32Derivation, 0),
+						tapTree: make ([] TapLeafScript, 0),
+						tapBip32Derivations: make ([] Bip32Derivation, 0) }
+
+	var err error
+	for _, kv := range outputMap {
+		if version == 0 && isPsbtV2OutputKey (kv.keyType) { return po, errors.New (fmt.Sprintf ("PSBT version 0 can not have the version 2 output key 0x%02x.", kv.keyType)) }
+
+		switch kv.keyType {
+			case PSBT_OUT_REDEEM_SCRIPT:
+				err = checkPsbtKeyData (kv, "output redeem script", 0)
+				po.redeemScript = NewScript (kv.value)
+			case PSBT_OUT_WITNESS_SCRIPT:
+				err = checkPsbtKeyData (kv, "output witness script", 0)
+				po.witnessScript = NewScript (kv.value)
+
+			case PSBT_OUT_BIP32_DERIVATION:
+				if err = checkPsbtPublicKey (kv, "output BIP 32 derivation"); err != nil { break }
+				var derivation Bip32Derivation
+				derivation, err = readKeyPath (kv.keyData, kv.value)
+				po.bip32Derivations = append (po.bip32Derivations, derivation)
+
+			case PSBT_OUT_TAP_INTERNAL_KEY:
+				if err = checkPsbtKeyData (kv, "output tap internal key", 0); err != nil { break }
+				if len (kv.value) != 32 { err = errors.New ("Invalid output tap internal key.") }
+				po.tapInternalKey = kv.value
+
+			case PSBT_OUT_TAP_TREE:
+				if err = checkPsbtKeyData (kv, "tap tree", 0); err != nil { break }
+				if len (kv.value) == 0 { err = errors.New ("Invalid tap tree."); break }
+				r := newByteReader (kv.value)
+				for !r.isFinished () && r.err == nil {
+					depth := r.read (1)
+					leafVersion := r.read (1)
+					scriptBytes := r.readVarBytes ()
+					if r.err != nil { break }
+					if depth [0] > 128 { r.err = errors.New ("Invalid tap tree depth."); break }
+
+					leafScript := NewScript (scriptBytes)
+					leafScript.setTapScriptFieldTypes ()
+					po.tapTree = append (po.tapTree, TapLeafScript { depth: depth [0], leafVersion: leafVersion [0], script: leafScript })
+				}
+				if r.err != nil { err = errors.New ("Invalid tap tree.") }
+
+			case PSBT_OUT_TAP_BIP32_DERIVATION:
+				if err = checkPsbtKeyData (kv, "output taproot BIP 32 derivation", 32); err != nil { break }
+				var derivation Bip32Derivation
+				derivation, err = readTapKeyPath (kv.keyData, kv.value)
+				po.tapBip32Derivations = append (po.tapBip32Derivations, derivation)
+		}
+		if err != nil { return po, err }
+	}
+
+	return po, nil
+}
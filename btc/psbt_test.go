@@ -0,0 +1,275 @@
+package btc
+
+import (
+	"strings"
+	"testing"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/base64"
+	"encoding/binary"
+)
+
+// the first valid test vector in BIP 174, a psbt with one P2PKH input that has a non-witness utxo, and empty outputs
+const testBip174Psbt = "cHNidP8BAHUCAAAAASaBcTce3/KF6Tet7qSze3gADAVmy7OtZGQXE8pCFxv2AAAAAAD+////AtPf9QUAAAAAGXapFNDFmQPFusKGh2DpD9UhpGZap2UgiKwA4fUFAAAAABepFDVF5uM7gyxHBQ8k0+65PJwDlIvHh7MuEwAAAQD9pQEBAAAAAAECiaPHHqtNIOA3G7ukzGmPopXJRjr6Ljl/hTPMti+VZ+UBAAAAFxYAFL4Y0VKpsBIDna89p95PUzSe7LmF/////4b4qkOnHf8USIk6UwpyN+9rRgi7st0tAXHmOuxqSJC0AQAAABcWABT+Pp7xp0XpdNkCxDVZQ6vLNL1TU/////8CAMLrCwAAAAAZdqkUhc/xCX/Z4Ai7NK9wnGIZeziXikiIrHL++E4sAAAAF6kUM5cluiHv1irHU6m80GfWx6ajnQWHAkcwRAIgJxK+IuAnDzlPVoMR3HyppolwuAJf3TskAinwf4pfOiQCIAGLONfc0xTnNMkna9b7QPZzMlvEuqFEyADS8vAtsnZcASED0uFWdJQbrUqZY3LLh+GFbTZSYG2YVi/jnF6efkE/IQUCSDBFAiEA0SuFLYXc2WHS9fSrZgZU327tzHlMDDPOXMMJ/7X85Y0CIGczio4OFyXBl/saiK9Z9R5E5CVbIBZ8hoQDHAXR8lkqASECI7cr7vCWXRC+B3jv7NYfysb3mk6haTkzgHNEZPhPKrMAAAAAAAAA"
+
+// an unsigned tx with one input and one output, for the psbts built by the tests
+const testUnsignedTxHex = "02000000" + "01" + "1111111111111111111111111111111111111111111111111111111111111111" + "00000000" + "00" + "fdffffff" +
+							"01" + "e803000000000000" + "160014" + "2222222222222222222222222222222222222222" + "00000000"
+
+const testPublicKeyHex = "02" + testGeneratorPointXHex
+
+// a key type, key data and value, the key data and value are hex
+type testPsbtKeyValue struct {
+	keyType byte
+	keyData string
+	value string
+}
+
+// serializes the global map followed by the input and output maps
+func serializeTestPsbt (t *testing.T, maps ...[] testPsbtKeyValue) [] byte {
+	t.Helper ()
+
+	psbtBytes := append ([] byte {}, psbtMagic...)
+	for _, m := range maps {
+		for _, kv := range m {
+			key := append ([] byte { kv.keyType }, decodeTestHex (t, kv.keyData)...)
+			psbtBytes = append (psbtBytes, serializeVarBytes (key)...)
+			psbtBytes = append (psbtBytes, serializeVarBytes (decodeTestHex (t, kv.value))...)
+		}
+		psbtBytes = append (psbtBytes, 0x00)
+	}
+	return psbtBytes
+}
+
+func serializeVarBytes (data [] byte) [] byte {
+	return append ([] byte { byte (len (data)) }, data...)
+}
+
+func uint32TestHex (value uint32) string {
+	valueBytes := make ([] byte, 4)
+	binary.LittleEndian.PutUint32 (valueBytes, value)
+	return hex.EncodeToString (valueBytes)
+}
+
+// the maps of a version 2 psbt with one input and one output, the fields are added to the maps given
+func newTestPsbtV2Maps (globals [] testPsbtKeyValue, input [] testPsbtKeyValue, output [] testPsbtKeyValue) [] [] testPsbtKeyValue {
+	globals = append ([] testPsbtKeyValue {	{ PSBT_GLOBAL_VERSION, "", uint32TestHex (2) },
+											{ PSBT_GLOBAL_TX_VERSION, "", uint32TestHex (2) },
+											{ PSBT_GLOBAL_INPUT_COUNT, "", "01" },
+											{ PSBT_GLOBAL_OUTPUT_COUNT, "", "01" } }, globals...)
+	input = append ([] testPsbtKeyValue {	{ PSBT_IN_PREVIOUS_TXID, "", strings.Repeat ("11", 32) },
+											{ PSBT_IN_OUTPUT_INDEX, "", uint32TestHex (1) } }, input...)
+	output = append ([] testPsbtKeyValue {	{ PSBT_OUT_AMOUNT, "", "e803000000000000" },
+											{ PSBT_OUT_SCRIPT, "", "0014" + strings.Repeat ("22", 20) } }, output...)
+	return [] [] testPsbtKeyValue { globals, input, output }
+}
+
+// removes the fields with the key type from a map
+func withoutTestKey (m [] testPsbtKeyValue, keyType byte) [] testPsbtKeyValue {
+	without := make ([] testPsbtKeyValue, 0, len (m))
+	for _, kv := range m {
+		if kv.keyType != keyType { without = append (without, kv) }
+	}
+	return without
+}
+
+func TestDecodePsbtBip174 (t *testing.T) {
+
+	p, err := DecodePsbtString (testBip174Psbt)
+	if err != nil { t.Fatal (err) }
+
+	tx := p.GetTx ()
+	if p.GetVersion () != 0 || tx.GetInputCount () != 1 || tx.GetOutputCount () != 2 || len (p.GetInputs ()) != 1 || len (p.GetOutputs ()) != 2 { t.Fatalf ("version %d, %d inputs, %d outputs", p.GetVersion (), tx.GetInputCount (), tx.GetOutputCount ()) }
+
+	// the previous output comes from the non-witness utxo
+	input := tx.GetInput (0)
+	previousOutput := input.GetPreviousOutput ()
+	if input.GetPreviousOutputTxId () != "f61b1742ca13176464adb3cb66050c00787bb3a4eead37e985f2df1e37718126" || previousOutput.GetValue () != 200000000 || previousOutput.GetOutputType () != OUTPUT_TYPE_P2PKH { t.Errorf ("previous output %s:%d, %d sats", input.GetPreviousOutputTxId (), input.GetPreviousOutputIndex (), previousOutput.GetValue ()) }
+
+	psbtInput := p.GetInputs () [0]
+	if psbtInput.IsFinalized () { t.Error ("the input is finalized") }
+
+	if fee, known := p.GetFee (); !known || fee != 301 { t.Errorf ("fee %d, known %t", fee, known) }
+
+	// the same psbt as hex
+	psbtBytes, _ := base64.StdEncoding.DecodeString (testBip174Psbt)
+	if _, err := DecodePsbtString (hex.EncodeToString (psbtBytes)); err != nil { t.Errorf ("hex: %s", err.Error ()) }
+}
+
+// the invalid cases in BIP 174 and BIP 370, each built from a psbt that is valid without the change
+func TestDecodePsbtInvalid (t *testing.T) {
+
+	bip174Bytes, _ := base64.StdEncoding.DecodeString (testBip174Psbt)
+
+	v0 := func (globals [] testPsbtKeyValue, input [] testPsbtKeyValue, output [] testPsbtKeyValue) [] byte {
+		return serializeTestPsbt (t, append ([] testPsbtKeyValue { { PSBT_GLOBAL_UNSIGNED_TX, "", testUnsignedTxHex } }, globals...), input, output)
+	}
+	v2 := func (globals [] testPsbtKeyValue, input [] testPsbtKeyValue, output [] testPsbtKeyValue) [] byte {
+		return serializeTestPsbt (t, newTestPsbtV2Maps (globals, input, output)...)
+	}
+
+	// the psbts the cases are built from are valid
+	if _, err := DecodePsbt (v0 (nil, nil, nil)); err != nil { t.Fatalf ("version 0: %s", err.Error ()) }
+	if _, err := DecodePsbt (v2 (nil, nil, nil)); err != nil { t.Fatalf ("version 2: %s", err.Error ()) }
+
+	none := [] testPsbtKeyValue {}
+	v2Maps := newTestPsbtV2Maps (nil, nil, nil)
+	signedTxHex := strings.Replace (testUnsignedTxHex, "00" + "fdffffff", "01" + "51" + "fdffffff", 1)
+	signature := strings.Repeat ("11", 64)
+	controlBlock := "c0" + testGeneratorPointXHex
+
+	for name, psbtBytes := range map [string] [] byte {
+		// BIP 174
+		"network transaction": decodeTestHex (t, testUnsignedTxHex),
+		"missing outputs": bip174Bytes [: len (bip174Bytes) - 2],
+		"unsigned tx with an input script": serializeTestPsbt (t, [] testPsbtKeyValue { { PSBT_GLOBAL_UNSIGNED_TX, "", signedTxHex } }, none, none),
+		"no unsigned tx": serializeTestPsbt (t, none, none, none),
+		"duplicate keys in an input": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_SIGHASH_TYPE, "", "01000000" }, { PSBT_IN_SIGHASH_TYPE, "", "01000000" } }, nil),
+		"unsigned tx key with key data": v0 ([] testPsbtKeyValue { { PSBT_GLOBAL_UNSIGNED_TX, "00", testUnsignedTxHex } }, nil, nil),
+		"witness utxo key with key data": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_WITNESS_UTXO, "00", "e803000000000000" + "160014" + strings.Repeat ("22", 20) } }, nil),
+		"partial signature with an invalid public key": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_PARTIAL_SIG, testGeneratorPointXHex, "3006020101020101" + "01" } }, nil),
+		"redeem script key with key data": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_REDEEM_SCRIPT, "00", "51" } }, nil),
+		"witness script key with key data": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_WITNESS_SCRIPT, "00", "51" } }, nil),
+		"bip 32 derivation with an invalid public key": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_BIP32_DERIVATION, "04" + testGeneratorPointXHex, "01020304" } }, nil),
+		"non-witness utxo key with key data": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_NON_WITNESS_UTXO, "00", testUnsignedTxHex } }, nil),
+		"final input script key with key data": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_FINAL_SCRIPTSIG, "00", "51" } }, nil),
+		"final script witness key with key data": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_FINAL_SCRIPTWITNESS, "00", "0101" + "51" } }, nil),
+		"output bip 32 derivation with an invalid public key": v0 (nil, nil, [] testPsbtKeyValue { { PSBT_OUT_BIP32_DERIVATION, "02" + testGeneratorPointXHex [2:], "01020304" } }),
+		"sighash type key with key data": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_SIGHASH_TYPE, "00", "01000000" } }, nil),
+		"output redeem script key with key data": v0 (nil, nil, [] testPsbtKeyValue { { PSBT_OUT_REDEEM_SCRIPT, "00", "51" } }),
+		"output witness script key with key data": v0 (nil, nil, [] testPsbtKeyValue { { PSBT_OUT_WITNESS_SCRIPT, "00", "51" } }),
+		"tap key signature key with key data": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_TAP_KEY_SIG, "00", signature } }, nil),
+		"tap key signature too long": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_TAP_KEY_SIG, "", signature + "0101" } }, nil),
+		"tap script signature with a short key": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_TAP_SCRIPT_SIG, testGeneratorPointXHex, signature } }, nil),
+		"tap script signature too short": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_TAP_SCRIPT_SIG, testGeneratorPointXHex + strings.Repeat ("33", 32), signature [2:] } }, nil),
+		"tap leaf script with an invalid control block": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_TAP_LEAF_SCRIPT, controlBlock + "00", "51" + "c0" } }, nil),
+		"tap bip 32 derivation with a compressed public key": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_TAP_BIP32_DERIVATION, testPublicKeyHex, "00" + "01020304" } }, nil),
+		"tap internal key too short": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_TAP_INTERNAL_KEY, "", testGeneratorPointXHex [2:] } }, nil),
+		"tap merkle root key with key data": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_TAP_MERKLE_ROOT, "00", strings.Repeat ("33", 32) } }, nil),
+		"output tap internal key too long": v0 (nil, nil, [] testPsbtKeyValue { { PSBT_OUT_TAP_INTERNAL_KEY, "", "02" + testGeneratorPointXHex } }),
+		"empty output tap tree": v0 (nil, nil, [] testPsbtKeyValue { { PSBT_OUT_TAP_TREE, "", "" } }),
+		"output tap tree deeper than 128": v0 (nil, nil, [] testPsbtKeyValue { { PSBT_OUT_TAP_TREE, "", "81" + "c0" + "0151" } }),
+		"output tap bip 32 derivation with a compressed public key": v0 (nil, nil, [] testPsbtKeyValue { { PSBT_OUT_TAP_BIP32_DERIVATION, testPublicKeyHex, "00" + "01020304" } }),
+		"data after the last output map": append (v0 (nil, nil, nil), 0x00),
+
+		// BIP 370
+		"version 0 with a tx version": v0 ([] testPsbtKeyValue { { PSBT_GLOBAL_TX_VERSION, "", uint32TestHex (2) } }, nil, nil),
+		"version 0 with a fallback lock time": v0 ([] testPsbtKeyValue { { PSBT_GLOBAL_FALLBACK_LOCKTIME, "", uint32TestHex (0) } }, nil, nil),
+		"version 0 with an input count": v0 ([] testPsbtKeyValue { { PSBT_GLOBAL_INPUT_COUNT, "", "01" } }, nil, nil),
+		"version 0 with an output count": v0 ([] testPsbtKeyValue { { PSBT_GLOBAL_OUTPUT_COUNT, "", "01" } }, nil, nil),
+		"version 0 with tx modifiable flags": v0 ([] testPsbtKeyValue { { PSBT_GLOBAL_TX_MODIFIABLE, "", "00" } }, nil, nil),
+		"version 0 with a previous tx id": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_PREVIOUS_TXID, "", strings.Repeat ("11", 32) } }, nil),
+		"version 0 with an output index": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_OUTPUT_INDEX, "", uint32TestHex (0) } }, nil),
+		"version 0 with a sequence": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_SEQUENCE, "", uint32TestHex (0xffffffff) } }, nil),
+		"version 0 with a required time lock time": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_REQUIRED_TIME_LOCKTIME, "", uint32TestHex (500000000) } }, nil),
+		"version 0 with a required height lock time": v0 (nil, [] testPsbtKeyValue { { PSBT_IN_REQUIRED_HEIGHT_LOCKTIME, "", uint32TestHex (800000) } }, nil),
+		"version 0 with an output amount": v0 (nil, nil, [] testPsbtKeyValue { { PSBT_OUT_AMOUNT, "", "e803000000000000" } }),
+		"version 0 with an output script": v0 (nil, nil, [] testPsbtKeyValue { { PSBT_OUT_SCRIPT, "", "51" } }),
+		"version 2 without an input count": serializeTestPsbt (t, withoutTestKey (v2Maps [0], PSBT_GLOBAL_INPUT_COUNT), v2Maps [1], v2Maps [2]),
+		"version 2 without an output count": serializeTestPsbt (t, withoutTestKey (v2Maps [0], PSBT_GLOBAL_OUTPUT_COUNT), v2Maps [1], v2Maps [2]),
+		"version 2 without a tx version": serializeTestPsbt (t, withoutTestKey (v2Maps [0], PSBT_GLOBAL_TX_VERSION), v2Maps [1], v2Maps [2]),
+		"version 2 without a previous tx id": serializeTestPsbt (t, v2Maps [0], withoutTestKey (v2Maps [1], PSBT_IN_PREVIOUS_TXID), v2Maps [2]),
+		"version 2 without an output index": serializeTestPsbt (t, v2Maps [0], withoutTestKey (v2Maps [1], PSBT_IN_OUTPUT_INDEX), v2Maps [2]),
+		"version 2 without an output amount": serializeTestPsbt (t, v2Maps [0], v2Maps [1], withoutTestKey (v2Maps [2], PSBT_OUT_AMOUNT)),
+		"version 2 without an output script": serializeTestPsbt (t, v2Maps [0], v2Maps [1], withoutTestKey (v2Maps [2], PSBT_OUT_SCRIPT)),
+		"version 2 with a required time lock time below 500000000": v2 (nil, [] testPsbtKeyValue { { PSBT_IN_REQUIRED_TIME_LOCKTIME, "", uint32TestHex (499999999) } }, nil),
+		"version 2 with a required height lock time of 500000000": v2 (nil, [] testPsbtKeyValue { { PSBT_IN_REQUIRED_HEIGHT_LOCKTIME, "", uint32TestHex (500000000) } }, nil),
+		"version 2 with an unsigned tx": v2 ([] testPsbtKeyValue { { PSBT_GLOBAL_UNSIGNED_TX, "", testUnsignedTxHex } }, nil, nil),
+		"version 2 with an input count that is not a compact size": serializeTestPsbt (t, append (withoutTestKey (v2Maps [0], PSBT_GLOBAL_INPUT_COUNT), testPsbtKeyValue { PSBT_GLOBAL_INPUT_COUNT, "", "0100" }), v2Maps [1], v2Maps [2]),
+		"version 2 previous tx id key with key data": v2 (nil, [] testPsbtKeyValue { { PSBT_IN_PREVIOUS_TXID, "00", strings.Repeat ("11", 32) } }, nil),
+		"unsupported version": serializeTestPsbt (t, [] testPsbtKeyValue { { PSBT_GLOBAL_UNSIGNED_TX, "", testUnsignedTxHex }, { PSBT_GLOBAL_VERSION, "", uint32TestHex (1) } }, none, none),
+	} {
+		if _, err := DecodePsbt (psbtBytes); err == nil { t.Errorf ("%s: no error", name) }
+	}
+}
+
+// the lock time of a version 2 psbt comes from the inputs that require one, or the fallback lock time if none do
+func TestDecodePsbtV2LockTime (t *testing.T) {
+
+	fallback := [] testPsbtKeyValue { { PSBT_GLOBAL_FALLBACK_LOCKTIME, "", uint32TestHex (700000) } }
+	secondInput := func (kvs ...testPsbtKeyValue) [] testPsbtKeyValue {
+		return append ([] testPsbtKeyValue { { PSBT_IN_PREVIOUS_TXID, "", strings.Repeat ("33", 32) }, { PSBT_IN_OUTPUT_INDEX, "", uint32TestHex (0) } }, kvs...)
+	}
+	twoInputs := func (first [] testPsbtKeyValue, second [] testPsbtKeyValue) [] byte {
+		maps := newTestPsbtV2Maps (fallback, first, nil)
+		maps [0] = append (withoutTestKey (maps [0], PSBT_GLOBAL_INPUT_COUNT), testPsbtKeyValue { PSBT_GLOBAL_INPUT_COUNT, "", "02" })
+		return serializeTestPsbt (t, maps [0], maps [1], secondInput (second...), maps [2])
+	}
+
+	time := func (lockTime uint32) testPsbtKeyValue { return testPsbtKeyValue { PSBT_IN_REQUIRED_TIME_LOCKTIME, "", uint32TestHex (lockTime) } }
+	height := func (lockTime uint32) testPsbtKeyValue { return testPsbtKeyValue { PSBT_IN_REQUIRED_HEIGHT_LOCKTIME, "", uint32TestHex (lockTime) } }
+
+	for name, test := range map [string] struct {
+		psbtBytes [] byte
+		lockTime uint32
+	} {	"fallback": { serializeTestPsbt (t, newTestPsbtV2Maps (fallback, nil, nil)...), 700000 },
+		"no fallback": { serializeTestPsbt (t, newTestPsbtV2Maps (nil, nil, nil)...), 0 },
+		"height": { serializeTestPsbt (t, newTestPsbtV2Maps (fallback, [] testPsbtKeyValue { height (800000) }, nil)...), 800000 },
+		"time": { serializeTestPsbt (t, newTestPsbtV2Maps (fallback, [] testPsbtKeyValue { time (1700000000) }, nil)...), 1700000000 },
+		"both types in one input": { serializeTestPsbt (t, newTestPsbtV2Maps (fallback, [] testPsbtKeyValue { time (1700000000), height (800000) }, nil)...), 800000 },
+		"largest height": { twoInputs ([] testPsbtKeyValue { height (800000) }, [] testPsbtKeyValue { height (800001), time (1700000000) }), 800001 },
+		"time and an input with both": { twoInputs ([] testPsbtKeyValue { time (1700000000) }, [] testPsbtKeyValue { height (800001), time (1700000001) }), 1700000001 } } {
+		p, err := DecodePsbt (test.psbtBytes)
+		if err != nil { t.Errorf ("%s: %s", name, err.Error ()); continue }
+		tx := p.GetTx ()
+		if tx.GetLockTime () != test.lockTime { t.Errorf ("%s: lock time %d, expected %d", name, tx.GetLockTime (), test.lockTime) }
+	}
+
+	// one input requires a height and the other a time
+	if _, err := DecodePsbt (twoInputs ([] testPsbtKeyValue { height (800000) }, [] testPsbtKeyValue { time (1700000000) })); err == nil { t.Error ("incompatible lock times: no error") }
+
+	// the tx is built from the fields
+	p, err := DecodePsbt (twoInputs ([] testPsbtKeyValue { { PSBT_IN_SEQUENCE, "", uint32TestHex (0xfffffffd) } }, nil))
+	if err != nil { t.Fatal (err) }
+	tx := p.GetTx ()
+	first, second := tx.GetInput (0), tx.GetInput (1)
+	output := tx.GetOutput (0)
+	if tx.GetVersion () != 2 || first.GetPreviousOutputTxId () != strings.Repeat ("11", 32) || first.GetPreviousOutputIndex () != 1 || first.GetSequence () != 0xfffffffd || second.GetSequence () != 0xffffffff || output.GetValue () != 1000 { t.Errorf ("tx %s", tx.GetTxId ()) }
+}
+
+// an unfinalized 2-of-3 P2WSH input, signed by the third and first keys in that order
+func TestDecodePsbtMultisigWitness (t *testing.T) {
+
+	publicKeys := [] string { "02" + strings.Repeat ("a1", 32), "03" + strings.Repeat ("a2", 32), "02" + strings.Repeat ("a3", 32) }
+	witnessScript := "52" + "21" + publicKeys [0] + "21" + publicKeys [1] + "21" + publicKeys [2] + "53" + "ae"
+	witnessScriptHash := sha256.Sum256 (decodeTestHex (t, witnessScript))
+	witnessUtxo := "e803000000000000" + "22" + "0020" + hex.EncodeToString (witnessScriptHash [:])
+
+	signature := func (r string) string { return "30440220" + strings.Repeat (r, 32) + "0220" + strings.Repeat ("22", 32) + "01" }
+
+	psbtBytes := serializeTestPsbt (t,	[] testPsbtKeyValue { { PSBT_GLOBAL_UNSIGNED_TX, "", testUnsignedTxHex } },
+										[] testPsbtKeyValue {	{ PSBT_IN_WITNESS_UTXO, "", witnessUtxo },
+																{ PSBT_IN_PARTIAL_SIG, publicKeys [2], signature ("33") },
+																{ PSBT_IN_PARTIAL_SIG, publicKeys [0], signature ("11") },
+																{ PSBT_IN_WITNESS_SCRIPT, "", witnessScript } },
+										[] testPsbtKeyValue {})
+
+	p, err := DecodePsbt (psbtBytes)
+	if err != nil { t.Fatal (err) }
+
+	psbtInput := p.GetInputs () [0]
+	input := psbtInput.GetInput ()
+	segwit := input.GetSegwit ()
+
+	fields := make ([] string, 0)
+	for _, field := range segwit.GetFields () { fields = append (fields, field.AsHex ()) }
+	expected := [] string { "", signature ("11"), signature ("33"), witnessScript }
+	if strings.Join (fields, ",") != strings.Join (expected, ",") { t.Errorf ("segwit fields %v, expected %v", fields, expected) }
+	if input.GetSpendType () != OUTPUT_TYPE_P2WSH { t.Errorf ("spend type %s", input.GetSpendType ()) }
+
+	// a single key script has no empty item
+	singleKeyScript := "21" + publicKeys [0] + "ac"
+	singleKeyHash := sha256.Sum256 (decodeTestHex (t, singleKeyScript))
+	psbtBytes = serializeTestPsbt (t,	[] testPsbtKeyValue { { PSBT_GLOBAL_UNSIGNED_TX, "", testUnsignedTxHex } },
+										[] testPsbtKeyValue {	{ PSBT_IN_WITNESS_UTXO, "", "e803000000000000" + "22" + "0020" + hex.EncodeToString (singleKeyHash [:]) },
+																{ PSBT_IN_PARTIAL_SIG, publicKeys [0], signature ("11") },
+																{ PSBT_IN_WITNESS_SCRIPT, "", singleKeyScript } },
+										[] testPsbtKeyValue {})
+
+	p, err = DecodePsbt (psbtBytes)
+	if err != nil { t.Fatal (err) }
+	psbtInput = p.GetInputs () [0]
+	input = psbtInput.GetInput ()
+	segwit = input.GetSegwit ()
+	if segwit.GetFieldCount () != 2 { t.Errorf ("%d segwit fields", segwit.GetFieldCount ()) }
+}
@@ -0,0 +1,90 @@
+package btc
+
+import (
+	"fmt"
+	"errors"
+	"encoding/hex"
+)
+
+const BLOCK_HEADER_SIZE = 80
+
+// the fields of a serialized block
+// the height and the fields that depend on the rest of the chain are not part of the block, so they have to come from the node
+type RawBlock struct {
+	hash string
+	previousHash string
+	version int32
+	timestamp int64
+	merkleRoot string
+	bits string
+	nonce uint32
+
+	size uint32
+	strippedSize uint32
+	weight uint32
+
+	txs [] Tx
+}
+
+// decodes a serialized block without the help of a node
+// like DecodeRawTx, the inputs will not have previous outputs
+func DecodeRawBlock (rawBytes [] byte) (RawBlock, error) {
+
+	if len (rawBytes) < BLOCK_HEADER_SIZE { return RawBlock {}, errors.New ("Block is shorter than a block header.") }
+
+	r := newByteReader (rawBytes)
+
+	header := r.read (BLOCK_HEADER_SIZE)
+	hr := newByteReader (header)
+	version := int32 (hr.readUint32 ())
+	previousHash := hex.EncodeToString (ReverseBytes (hr.read (32)))
+	merkleRoot := hex.EncodeToString (ReverseBytes (hr.read (32)))
+	timestamp := int64 (hr.readUint32 ())
+	bits := fmt.Sprintf ("%08x", hr.readUint32 ())
+	nonce := hr.readUint32 ()
+
+	txCount := r.readVarInt ()
+	if r.err != nil { return RawBlock {}, r.err }
+	if txCount == 0 || txCount > uint64 (len (rawBytes)) { return RawBlock {}, errors.New ("Invalid transaction count.") }
+
+	hash := hex.EncodeToString (ReverseBytes (doubleSha256 (header)))
+
+	strippedSize := r.pos
+	txs := make ([] Tx, txCount)
+	for t := uint64 (0); t < txCount; t++ {
+		tx, strippedTxSize, err := readTx (&r)
+		if err != nil { return RawBlock {}, fmt.Errorf ("Transaction %d: %s", t, err.Error ()) }
+
+		tx.blockHash = hash
+		tx.blockTime = timestamp
+		txs [t] = tx
+		strippedSize += strippedTxSize
+	}
+	if !r.isFinished () { return RawBlock {}, errors.New ("Unexpected data after the end of the block.") }
+
+	size := len (rawBytes)
+	return RawBlock {	hash: hash, previousHash: previousHash, version: version, timestamp: timestamp, merkleRoot: merkleRoot, bits: bits, nonce: nonce,
+						size: uint32 (size), strippedSize: uint32 (strippedSize), weight: uint32 (strippedSize * 3 + size),
+						txs: txs }, nil
+}
+
+func (rb *RawBlock) GetHash () string {
+	return rb.hash
+}
+
+func (rb *RawBlock) GetPreviousHash () string {
+	return rb.previousHash
+}
+
+func (rb *RawBlock) GetTxs () [] Tx {
+	return rb.txs
+}
+
+// creates the block with the fields that come from the node
+func (rb *RawBlock) ToBlock (height uint32, nextHash string, difficulty float64, chainwork string, medianTime int64) Block {
+
+	txIds := make ([] string, len (rb.txs))
+	for t, tx := range rb.txs { txIds [t] = tx.GetTxId () }
+
+	return NewBlock (rb.hash, rb.previousHash, nextHash, height, rb.version, rb.timestamp, txIds, rb.merkleRoot, rb.bits, rb.nonce, difficulty, chainwork, medianTime, rb.size, rb.strippedSize, rb.weight)
+}
@@ -0,0 +1,74 @@
+package btc
+
+import (
+	"testing"
+	"encoding/hex"
+)
+
+// the header of the main network genesis block, the only transaction is the genesis transaction
+const testGenesisHeaderHex = "01000000" + "0000000000000000000000000000000000000000000000000000000000000000" +
+								"3ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a" + "29ab5f49" + "ffff001d" + "1dac2b7c"
+
+func decodeTestBlock (t *testing.T, blockHex string) RawBlock {
+	rawBytes, err := hex.DecodeString (blockHex)
+	if err != nil { t.Fatal (err) }
+
+	rawBlock, err := DecodeRawBlock (rawBytes)
+	if err != nil { t.Fatal (err) }
+
+	return rawBlock
+}
+
+func TestDecodeRawBlockGenesis (t *testing.T) {
+
+	network := GetNetwork ()
+	rawBlock := decodeTestBlock (t, testGenesisHeaderHex + "01" + network.GetGenesisTxHex ())
+
+	if rawBlock.GetHash () != network.GetGenesisBlockHash () { t.Errorf ("hash is %s", rawBlock.GetHash ()) }
+	if rawBlock.GetPreviousHash () != "0000000000000000000000000000000000000000000000000000000000000000" { t.Errorf ("previous hash is %s", rawBlock.GetPreviousHash ()) }
+	if rawBlock.timestamp != network.GetGenesisBlockTime () { t.Errorf ("timestamp is %d", rawBlock.timestamp) }
+	if rawBlock.bits != "1d00ffff" { t.Errorf ("bits are %s", rawBlock.bits) }
+	if rawBlock.nonce != 2083236893 { t.Errorf ("nonce is %d", rawBlock.nonce) }
+	if rawBlock.size != 285 || rawBlock.strippedSize != 285 || rawBlock.weight != 1140 { t.Errorf ("size %d, stripped size %d, weight %d", rawBlock.size, rawBlock.strippedSize, rawBlock.weight) }
+
+	txs := rawBlock.GetTxs ()
+	if len (txs) != 1 { t.Fatalf ("%d transactions", len (txs)) }
+	if txs [0].GetTxId () != network.GetGenesisTxId () { t.Errorf ("tx id is %s", txs [0].GetTxId ()) }
+	if !txs [0].IsCoinbase () { t.Error ("the genesis transaction is not a coinbase") }
+	if txs [0].GetBlockHash () != rawBlock.GetHash () || txs [0].GetBlockTime () != rawBlock.timestamp { t.Error ("the transaction does not have the block") }
+
+	block := rawBlock.ToBlock (0, "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048", 1, "0000000000000000000000000000000000000000000000000000000100010001", rawBlock.timestamp)
+	if block.GetHeight () != 0 || block.GetTxCount () != 1 || block.GetMerkleRoot () != "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b" { t.Error ("the block does not match the raw block") }
+	if !block.IsMerkleRootValid () { t.Error ("the merkle root is not valid") }
+}
+
+func TestDecodeRawBlockSegwit (t *testing.T) {
+
+	network := GetNetwork ()
+	rawBlock := decodeTestBlock (t, testGenesisHeaderHex + "02" + network.GetGenesisTxHex () + testSegwitTxHex)
+
+	// the marker, the flag and the witness are not part of the stripped size
+	witnessSize := uint32 (2 + 1 + 1 + 71 + 1 + 33)
+	if rawBlock.strippedSize != rawBlock.size - witnessSize { t.Errorf ("size %d, stripped size %d", rawBlock.size, rawBlock.strippedSize) }
+	if rawBlock.weight != rawBlock.strippedSize * 3 + rawBlock.size { t.Errorf ("weight is %d", rawBlock.weight) }
+
+	txs := rawBlock.GetTxs ()
+	if len (txs) != 2 { t.Fatalf ("%d transactions", len (txs)) }
+	segwitTx := decodeTestTx (t, testSegwitTxHex)
+	if txs [1].GetTxId () != segwitTx.GetTxId () { t.Errorf ("tx id is %s", txs [1].GetTxId ()) }
+	if !txs [1].SupportsBip141 () { t.Error ("the segwit transaction is not bip141") }
+}
+
+func TestDecodeRawBlockErrors (t *testing.T) {
+
+	network := GetNetwork ()
+	genesisBlockHex := testGenesisHeaderHex + "01" + network.GetGenesisTxHex ()
+
+	for name, blockHex := range map [string] string {	"short header": testGenesisHeaderHex [: 100],
+														"no transactions": testGenesisHeaderHex + "00",
+														"truncated transaction": genesisBlockHex [: len (genesisBlockHex) - 10],
+														"trailing data": genesisBlockHex + "00" } {
+		rawBytes, _ := hex.DecodeString (blockHex)
+		if _, err := DecodeRawBlock (rawBytes); err == nil { t.Errorf ("%s: no error", name) }
+	}
+}
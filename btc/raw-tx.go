@@ -0,0 +1,231 @@
+package btc
+
+import (
+	"fmt"
+	"errors"
+	"encoding/hex"
+	"encoding/binary"
+	"crypto/sha256"
+)
+
+// reads serialized bitcoin data sequentially
+// once a read fails, every read after it fails too, so errors only need to be checked at the end
+type byteReader struct {
+	data [] byte
+	pos int
+	err error
+}
+
+func newByteReader (data [] byte) byteReader {
+	return byteReader { data: data }
+}
+
+func (r *byteReader) read (byteCount int) [] byte {
+	if r.err != nil { return nil }
+	if byteCount < 0 || r.pos + byteCount > len (r.data) {
+		r.err = errors.New ("Unexpected end of data.")
+		return nil
+	}
+
+	bytes := r.data [r.pos : r.pos + byteCount]
+	r.pos += byteCount
+	return bytes
+}
+
+func (r *byteReader) readVarInt () uint64 {
+	if r.err != nil { return 0 }
+
+	value, byteCount := ReadVarInt (r.data [r.pos :])
+	if byteCount == 0 {
+		r.err = errors.New ("Unexpected end of data.")
+		return 0
+	}
+
+	r.pos += byteCount
+	return value
+}
+
+func (r *byteReader) readVarBytes () [] byte {
+	byteCount := r.readVarInt ()
+	if byteCount > uint64 (len (r.data)) {
+		r.err = errors.New ("Field length exceeds the data length.")
+		return nil
+	}
+	return r.read (int (byteCount))
+}
+
+func (r *byteReader) readUint32 () uint32 {
+	bytes := r.read (4)
+	if bytes == nil { return 0 }
+	return binary.LittleEndian.Uint32 (bytes)
+}
+
+func (r *byteReader) readUint64 () uint64 {
+	bytes := r.read (8)
+	if bytes == nil { return 0 }
+	return binary.LittleEndian.Uint64 (bytes)
+}
+
+func (r *byteReader) isFinished () bool {
+	return r.pos >= len (r.data)
+}
+
+func doubleSha256 (data [] byte) [] byte {
+	first := sha256.Sum256 (data)
+	second := sha256.Sum256 (first [:])
+	return second [:]
+}
+
+// returns the id of a serialized transaction, which must not include the segwit data
+func getTxIdFromBytes (strippedTxBytes [] byte) string {
+	return hex.EncodeToString (ReverseBytes (doubleSha256 (strippedTxBytes)))
+}
+
+// decodes a serialized transaction without the help of a node
+// the inputs will not have previous outputs, so their spend types will not be known until SetPreviousOutput is called
+func DecodeRawTx (rawBytes [] byte) (Tx, error) {
+
+	r := newByteReader (rawBytes)
+
+	tx, _, err := readTx (&r)
+	if err != nil { return Tx {}, err }
+	if !r.isFinished () { return Tx {}, errors.New ("Unexpected data after the end of the transaction.") }
+
+	return tx, nil
+}
+
+// reads one transaction and returns it with the size of the transaction without its segwit data
+func readTx (r *byteReader) (Tx, int, error) {
+
+	rawBytes := r.data
+	txBegin := r.pos
+
+	// the transaction id is the hash of everything except the segwit marker, flag and fields
+	strippedTx := make ([] byte, 0, 256)
+
+	versionBytes := r.read (4)
+	strippedTx = append (strippedTx, versionBytes...)
+
+	isBip141 := r.err == nil && len (rawBytes) > txBegin + 6 && rawBytes [txBegin + 4] == 0x00 && rawBytes [txBegin + 5] == 0x01
+	if isBip141 { r.read (2) }
+
+	// inputs
+	inputsBegin := r.pos
+	inputCount := r.readVarInt ()
+	if inputCount == 0 && r.err == nil { return Tx {}, 0, errors.New ("Transaction has no inputs.") }
+	if inputCount > uint64 (len (rawBytes)) { return Tx {}, 0, errors.New ("Invalid input count.") }
+
+	type rawInput struct {
+		previousOutputTxId [] byte
+		previousOutputIndex uint32
+		inputScript [] byte
+		sequence uint32
+	}
+	rawInputs := make ([] rawInput, inputCount)
+	for i := uint64 (0); i < inputCount; i++ {
+		rawInputs [i].previousOutputTxId = r.read (32)
+		rawInputs [i].previousOutputIndex = r.readUint32 ()
+		rawInputs [i].inputScript = r.readVarBytes ()
+		rawInputs [i].sequence = r.readUint32 ()
+	}
+
+	// outputs
+	outputCount := r.readVarInt ()
+	if outputCount > uint64 (len (rawBytes)) { return Tx {}, 0, errors.New ("Invalid output count.") }
+
+	outputs := make ([] Output, outputCount)
+	for o := uint64 (0); o < outputCount; o++ {
+		value := r.readUint64 ()
+		outputScript := NewScript (r.readVarBytes ())
+		if r.err != nil { break }
+		outputs [o] = NewOutput (value, outputScript, GetAddress (outputScript))
+	}
+	if r.err != nil { return Tx {}, 0, r.err }
+	strippedTx = append (strippedTx, rawBytes [inputsBegin : r.pos]...)
+
+	// segwit fields
+	segwitFields := make ([] [] [] byte, inputCount)
+	if isBip141 {
+		for i := uint64 (0); i < inputCount; i++ {
+			fieldCount := r.readVarInt ()
+			if fieldCount > uint64 (len (rawBytes)) { return Tx {}, 0, errors.New ("Invalid segwit field count.") }
+
+			segwitFields [i] = make ([] [] byte, fieldCount)
+			for f := uint64 (0); f < fieldCount; f++ {
+				segwitFields [i][f] = r.readVarBytes ()
+			}
+		}
+	}
+
+	lockTimeBegin := r.pos
+	lockTime := r.readUint32 ()
+	if r.err != nil { return Tx {}, 0, r.err }
+	strippedTx = append (strippedTx, rawBytes [lockTimeBegin : r.pos]...)
+
+	// now we can create the inputs
+	inputs := make ([] Input, inputCount)
+	for i, raw := range rawInputs {
+
+		isCoinbase := inputCount == 1 && raw.previousOutputIndex == 0xffffffff
+		for _, b := range raw.previousOutputTxId {
+			if b != 0 { isCoinbase = false; break }
+		}
+
+		previousOutputTxId := ""
+		previousOutputIndex := uint16 (0)
+		if !isCoinbase {
+			// output indexes are 16 bits everywhere else, so a larger index can not be looked up
+			if raw.previousOutputIndex > 0xffff { return Tx {}, 0, errors.New (fmt.Sprintf ("Input %d spends output %d, which is above the largest supported output index.", i, raw.previousOutputIndex)) }
+
+			previousOutputTxId = hex.EncodeToString (ReverseBytes (raw.previousOutputTxId))
+			previousOutputIndex = uint16 (raw.previousOutputIndex)
+		}
+
+		// inputs with no segwit fields have no segwit, even when the transaction is serialized with segwit data
+		segwit := Segwit {}
+		if len (segwitFields [i]) > 0 { segwit = NewSegwit (segwitFields [i]) }
+
+		inputs [i] = NewInput (isCoinbase, previousOutputTxId, previousOutputIndex, NewScript (raw.inputScript), segwit, raw.sequence, Output {})
+	}
+
+	version := binary.LittleEndian.Uint32 (versionBytes)
+	return NewTx (getTxIdFromBytes (strippedTx), version, inputs, outputs, lockTime, inputs [0].IsCoinbase (), isBip141, "", 0), len (strippedTx), nil
+}
+
+// serializes the parts of a transaction that are used to calculate the transaction id
+// previous output tx ids are expected in the usual (reversed) hex format
+func serializeStrippedTx (version uint32, previousOutputTxIds [] string, previousOutputIndexes [] uint32, sequences [] uint32, outputValues [] uint64, outputScripts [] [] byte, lockTime uint32) ([] byte, error) {
+
+	serialized := make ([] byte, 0, 256)
+	serialized = binary.LittleEndian.AppendUint32 (serialized, version)
+
+	serialized = appendVarInt (serialized, uint64 (len (previousOutputTxIds)))
+	for i, txId := range previousOutputTxIds {
+		txIdBytes, err := hex.DecodeString (txId)
+		if err != nil || len (txIdBytes) != 32 { return nil, errors.New ("Invalid previous output tx id " + txId) }
+
+		serialized = append (serialized, ReverseBytes (txIdBytes)...)
+		serialized = binary.LittleEndian.AppendUint32 (serialized, previousOutputIndexes [i])
+		serialized = append (serialized, 0x00)
+		serialized = binary.LittleEndian.AppendUint32 (serialized, sequences [i])
+	}
+
+	serialized = appendVarInt (serialized, uint64 (len (outputValues)))
+	for o, value := range outputValues {
+		serialized = binary.LittleEndian.AppendUint64 (serialized, value)
+		serialized = appendVarInt (serialized, uint64 (len (outputScripts [o])))
+		serialized = append (serialized, outputScripts [o]...)
+	}
+
+	serialized = binary.LittleEndian.AppendUint32 (serialized, lockTime)
+	return serialized, nil
+}
+
+func appendVarInt (data [] byte, value uint64) [] byte {
+	switch {
+		case value <= 0xfc: return append (data, byte (value))
+		case value <= 0xffff: return binary.LittleEndian.AppendUint16 (append (data, 0xfd), uint16 (value))
+		case value <= 0xffffffff: return binary.LittleEndian.AppendUint32 (append (data, 0xfe), uint32 (value))
+	}
+	return binary.LittleEndian.AppendUint64 (append (data, 0xff), value)
+}
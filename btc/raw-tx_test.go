@@ -0,0 +1,93 @@
+package btc
+
+import (
+	"testing"
+	"encoding/hex"
+)
+
+// a p2pkh spend of output 1 with one p2pkh output
+const testLegacyTxHex = "01000000" + "01" + "1111111111111111111111111111111111111111111111111111111111111111" + "01000000" +
+						"6a" + "47" + "3044022011111111111111111111111111111111111111111111111111111111111111110220222222222222222222222222222222222222222222222222222222222222222201" +
+						"21" + "023333333333333333333333333333333333333333333333333333333333333333" + "feffffff" +
+						"01" + "e803000000000000" + "19" + "76a914" + "2222222222222222222222222222222222222222" + "88ac" + "00000000"
+
+// the segwit transaction without its marker, flag and witness
+const testStrippedSegwitTxHex = "02000000" + "01" + "1111111111111111111111111111111111111111111111111111111111111111" + "00000000" + "00" + "ffffffff" +
+								"01" + "e803000000000000" + "160014" + "2222222222222222222222222222222222222222" + "00000000"
+
+func TestDecodeRawTxSegwit (t *testing.T) {
+
+	tx := decodeTestTx (t, testSegwitTxHex)
+	if !tx.SupportsBip141 () || tx.IsCoinbase () || tx.GetVersion () != 2 || tx.GetInputCount () != 1 || tx.GetOutputCount () != 1 { t.Fatal ("the transaction has the wrong structure") }
+
+	// the witness is not part of the id
+	strippedTx := decodeTestTx (t, testStrippedSegwitTxHex)
+	if tx.GetTxId () != strippedTx.GetTxId () { t.Errorf ("tx id is %s, expected %s", tx.GetTxId (), strippedTx.GetTxId ()) }
+	if strippedTx.SupportsBip141 () { t.Error ("the stripped transaction is bip141") }
+
+	input := tx.GetInput (0)
+	segwit := input.GetSegwit ()
+	if segwit.GetFieldCount () != 2 { t.Errorf ("%d segwit fields", segwit.GetFieldCount ()) }
+	if input.GetPreviousOutputIndex () != 0 || input.GetSequence () != 0xffffffff { t.Errorf ("previous output index %d, sequence %x", input.GetPreviousOutputIndex (), input.GetSequence ()) }
+
+	output := tx.GetOutput (0)
+	if output.GetValue () != 1000 || output.GetOutputType () != OUTPUT_TYPE_P2WPKH { t.Errorf ("output value %d, type %s", output.GetValue (), output.GetOutputType ()) }
+}
+
+func TestDecodeRawTxLegacy (t *testing.T) {
+
+	tx := decodeTestTx (t, testLegacyTxHex)
+	if tx.SupportsBip141 () || tx.IsCoinbase () || tx.GetVersion () != 1 || tx.GetInputCount () != 1 || tx.GetOutputCount () != 1 { t.Fatal ("the transaction has the wrong structure") }
+
+	rawBytes, _ := hex.DecodeString (testLegacyTxHex)
+	if expected := hex.EncodeToString (ReverseBytes (doubleSha256 (rawBytes))); tx.GetTxId () != expected { t.Errorf ("tx id is %s, expected %s", tx.GetTxId (), expected) }
+
+	input := tx.GetInput (0)
+	segwit := input.GetSegwit ()
+	inputScript := input.GetInputScript ()
+	if input.GetPreviousOutputTxId () != "1111111111111111111111111111111111111111111111111111111111111111" || input.GetPreviousOutputIndex () != 1 { t.Errorf ("previous output %s:%d", input.GetPreviousOutputTxId (), input.GetPreviousOutputIndex ()) }
+	if input.GetSequence () != 0xfffffffe || !segwit.IsNil () || len (inputScript.fields) != 2 { t.Error ("the input has the wrong fields") }
+
+	output := tx.GetOutput (0)
+	if output.GetValue () != 1000 || output.GetOutputType () != OUTPUT_TYPE_P2PKH { t.Errorf ("output value %d, type %s", output.GetValue (), output.GetOutputType ()) }
+}
+
+func TestDecodeRawTxCoinbase (t *testing.T) {
+
+	network := GetNetwork ()
+	tx := decodeTestTx (t, network.GetGenesisTxHex ())
+
+	if tx.GetTxId () != network.GetGenesisTxId () { t.Errorf ("tx id is %s", tx.GetTxId ()) }
+	if !tx.IsCoinbase () { t.Fatal ("the genesis transaction is not a coinbase") }
+
+	// the coinbase input does not refer to a previous output
+	input := tx.GetInput (0)
+	if !input.IsCoinbase () || input.GetPreviousOutputTxId () != "" || input.GetPreviousOutputIndex () != 0 { t.Errorf ("coinbase input spends %s:%d", input.GetPreviousOutputTxId (), input.GetPreviousOutputIndex ()) }
+
+	output := tx.GetOutput (0)
+	if output.GetValue () != 5000000000 || output.GetOutputType () != OUTPUT_TYPE_P2PK { t.Errorf ("output value %d, type %s", output.GetValue (), output.GetOutputType ()) }
+}
+
+func TestDecodeRawTxErrors (t *testing.T) {
+
+	for name, txHex := range map [string] string {	"empty": "",
+													"version only": "01000000",
+													"no inputs": "01000000" + "00" + "00" + "00000000",
+													"truncated input": testLegacyTxHex [: 80],
+													"truncated output": testLegacyTxHex [: len (testLegacyTxHex) - 20],
+													"no lock time": testLegacyTxHex [: len (testLegacyTxHex) - 8],
+													"truncated witness": testSegwitTxHex [: len (testSegwitTxHex) - 40],
+													"input count above the size": "01000000" + "fdff00",
+													"trailing data": testLegacyTxHex + "00",
+													"trailing segwit data": testSegwitTxHex + "0000",
+													"output index above 0xffff": testLegacyTxHex [: 74] + "00000100" + testLegacyTxHex [82:] } {
+		rawBytes, err := hex.DecodeString (txHex)
+		if err != nil { t.Fatal (err) }
+		if _, err := DecodeRawTx (rawBytes); err == nil { t.Errorf ("%s: no error", name) }
+	}
+
+	// the largest supported output index
+	largestIndexTx := decodeTestTx (t, testLegacyTxHex [: 74] + "ffff0000" + testLegacyTxHex [82:])
+	input := largestIndexTx.GetInput (0)
+	if input.GetPreviousOutputIndex () != 0xffff { t.Errorf ("previous output index %d", input.GetPreviousOutputIndex ()) }
+}
@@ -0,0 +1,179 @@
+package btc
+
+import (
+	"fmt"
+	"sync"
+	"bytes"
+	"strings"
+	"strconv"
+	"encoding/hex"
+)
+
+// a script pattern is a list of script fields separated by spaces
+//
+//	OP_CHECKSIG    an opcode, the OP_ prefix is optional, CLTV and CSV can also be used
+//	<32>           a data push of 32 bytes, the same format used by script templates
+//	<>             a data push of any size
+//	0x0014         a data push of exactly these bytes
+//	?              any one field
+//	*              any number of fields, including none
+//
+// a pattern matches a script if its fields appear consecutively anywhere in the script
+// ^ at the beginning or $ at the end anchors the pattern to the beginning or end of the script
+
+const patternTokenOpcode = 0
+const patternTokenPush = 1
+const patternTokenData = 2
+const patternTokenAnyField = 3
+const patternTokenAnyFields = 4
+
+type patternToken struct {
+	tokenType int
+	opcode string
+	pushSize int
+	data [] byte
+}
+
+type ScriptPattern struct {
+	text string
+	tokens [] patternToken
+	anchorStart bool
+	anchorEnd bool
+}
+
+var opcodeAliases = map [string] string {	"CLTV": "OP_CHECKLOCKTIMEVERIFY",
+											"CSV": "OP_CHECKSEQUENCEVERIFY",
+											"OP_FALSE": "OP_0",
+											"OP_TRUE": "OP_1",
+											"OP_NOP2": "OP_CHECKLOCKTIMEVERIFY",
+											"OP_NOP3": "OP_CHECKSEQUENCEVERIFY" }
+
+var opcodeNames map [string] bool
+var initOpcodeNamesOnce sync.Once
+
+func initOpcodeNames () {
+	opcodeNames = make (map [string] bool)
+	for b := 0; b <= 0xff; b++ {
+		if isValidOpcode (byte (b)) { opcodeNames [getOpcodeName (byte (b))] = true }
+	}
+}
+
+func ParseScriptPattern (pattern string) (ScriptPattern, error) {
+
+	initOpcodeNamesOnce.Do (initOpcodeNames)
+
+	sp := ScriptPattern { text: strings.TrimSpace (pattern) }
+
+	words := strings.Fields (sp.text)
+	if len (words) > 0 && words [0] == "^" { sp.anchorStart = true; words = words [1:] }
+	if len (words) > 0 && words [len (words) - 1] == "$" { sp.anchorEnd = true; words = words [: len (words) - 1] }
+	if len (words) == 0 { return ScriptPattern {}, fmt.Errorf ("pattern is empty") }
+
+	for _, word := range words {
+
+		token := patternToken {}
+		switch {
+			case word == "?":
+				token.tokenType = patternTokenAnyField
+
+			case word == "*":
+				// consecutive wildcards match the same fields as one
+				if len (sp.tokens) > 0 && sp.tokens [len (sp.tokens) - 1].tokenType == patternTokenAnyFields { continue }
+				token.tokenType = patternTokenAnyFields
+
+			case strings.HasPrefix (word, "<") && strings.HasSuffix (word, ">"):
+				token.tokenType = patternTokenPush
+				token.pushSize = -1
+				sizeStr := word [1 : len (word) - 1]
+				if len (sizeStr) > 0 {
+					size, err := strconv.ParseUint (sizeStr, 10, 32)
+					if err != nil { return ScriptPattern {}, fmt.Errorf ("%s is not a valid push size", word) }
+					token.pushSize = int (size)
+				}
+
+			case strings.HasPrefix (word, "0x"):
+				data, err := hex.DecodeString (word [2:])
+				if err != nil { return ScriptPattern {}, fmt.Errorf ("%s is not valid hex", word) }
+				token.tokenType = patternTokenData
+				token.data = data
+
+			default:
+				opcode := strings.ToUpper (word)
+				if _, isAlias := opcodeAliases [opcode]; !isAlias && !strings.HasPrefix (opcode, "OP_") { opcode = "OP_" + opcode }
+				if alias, isAlias := opcodeAliases [opcode]; isAlias { opcode = alias }
+				if !opcodeNames [opcode] { return ScriptPattern {}, fmt.Errorf ("%s is not a known opcode", word) }
+				token.tokenType = patternTokenOpcode
+				token.opcode = opcode
+		}
+
+		sp.tokens = append (sp.tokens, token)
+	}
+
+	return sp, nil
+}
+
+func (sp *ScriptPattern) IsNil () bool {
+	return len (sp.tokens) == 0
+}
+
+func (sp *ScriptPattern) String () string {
+	return sp.text
+}
+
+// returns the index of the first field of the first match, or -1 if the pattern does not match the script
+func (sp *ScriptPattern) Match (script Script) int {
+
+	if sp.IsNil () || script.IsNil () { return -1 }
+
+	fields := script.GetFields ()
+
+	lastStart := len (fields)
+	if sp.anchorStart { lastStart = 0 }
+
+	// whether the tokens from t on match the fields from f on does not depend on where the match started,
+	// so each field and token position only has to be tried once, no matter how many wildcards there are
+	failed := make ([] bool, (len (fields) + 1) * (len (sp.tokens) + 1))
+
+	for start := 0; start <= lastStart; start++ {
+		if sp.matchAt (fields, start, 0, failed) { return start }
+	}
+
+	return -1
+}
+
+func (sp *ScriptPattern) matchAt (fields [] ScriptField, f int, t int, failed [] bool) bool {
+
+	if t == len (sp.tokens) { return !sp.anchorEnd || f == len (fields) }
+
+	position := f * (len (sp.tokens) + 1) + t
+	if failed [position] { return false }
+
+	matched := false
+	token := sp.tokens [t]
+	if token.tokenType == patternTokenAnyFields {
+		for next := f; next <= len (fields) && !matched; next++ {
+			matched = sp.matchAt (fields, next, t + 1, failed)
+		}
+	} else {
+		matched = f < len (fields) && token.matches (fields [f]) && sp.matchAt (fields, f + 1, t + 1, failed)
+	}
+
+	if !matched { failed [position] = true }
+	return matched
+}
+
+func (pt *patternToken) matches (field ScriptField) bool {
+
+	switch pt.tokenType {
+		case patternTokenOpcode:
+			return field.IsOpcode () && field.AsHex () == pt.opcode
+		case patternTokenPush:
+			return !field.IsOpcode () && (pt.pushSize < 0 || len (field.AsBytes ()) == pt.pushSize)
+		case patternTokenData:
+			return !field.IsOpcode () && bytes.Equal (field.AsBytes (), pt.data)
+		case patternTokenAnyField:
+			return true
+	}
+
+	return false
+}
@@ -0,0 +1,109 @@
+package btc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseScriptPattern (t *testing.T) {
+
+	for name, test := range map [string] struct {
+		pattern string
+		tokenTypes [] int
+		anchorStart bool
+		anchorEnd bool
+	} {	"opcode": { "OP_CHECKSIG", [] int { patternTokenOpcode }, false, false },
+		"anchors": { "^ OP_0 <20> $", [] int { patternTokenOpcode, patternTokenPush }, true, true },
+		"wildcards": { "? * <>", [] int { patternTokenAnyField, patternTokenAnyFields, patternTokenPush }, false, false },
+		"consecutive wildcards": { "OP_IF * * * OP_ENDIF", [] int { patternTokenOpcode, patternTokenAnyFields, patternTokenOpcode }, false, false },
+		"data": { "0x0014 OP_DROP", [] int { patternTokenData, patternTokenOpcode }, false, false } } {
+		sp, err := ParseScriptPattern (test.pattern)
+		if err != nil { t.Errorf ("%s: %s", name, err.Error ()); continue }
+
+		tokenTypes := make ([] int, len (sp.tokens))
+		for tk, token := range sp.tokens { tokenTypes [tk] = token.tokenType }
+		if len (tokenTypes) != len (test.tokenTypes) { t.Errorf ("%s: token types %v, expected %v", name, tokenTypes, test.tokenTypes); continue }
+		for tk := range tokenTypes {
+			if tokenTypes [tk] != test.tokenTypes [tk] { t.Errorf ("%s: token types %v, expected %v", name, tokenTypes, test.tokenTypes); break }
+		}
+		if sp.anchorStart != test.anchorStart || sp.anchorEnd != test.anchorEnd { t.Errorf ("%s: anchors %t %t", name, sp.anchorStart, sp.anchorEnd) }
+	}
+
+	// every way of naming an opcode ends up as its name in the script
+	for alias, opcode := range map [string] string {	"checksig": "OP_CHECKSIG",
+														"op_checksig": "OP_CHECKSIG",
+														"CLTV": "OP_CHECKLOCKTIMEVERIFY",
+														"csv": "OP_CHECKSEQUENCEVERIFY",
+														"OP_NOP2": "OP_CHECKLOCKTIMEVERIFY",
+														"OP_FALSE": "OP_0",
+														"true": "OP_1" } {
+		sp, err := ParseScriptPattern (alias)
+		if err != nil { t.Errorf ("%s: %s", alias, err.Error ()); continue }
+		if sp.tokens [0].opcode != opcode { t.Errorf ("%s: opcode %s, expected %s", alias, sp.tokens [0].opcode, opcode) }
+	}
+
+	// the push size and data are kept
+	sp, _ := ParseScriptPattern ("<32> 0xabcd")
+	if sp.tokens [0].pushSize != 32 || string (sp.tokens [1].data) != "\xab\xcd" { t.Errorf ("push size %d, data %x", sp.tokens [0].pushSize, sp.tokens [1].data) }
+
+	for name, pattern := range map [string] string {	"empty": "",
+														"only anchors": "^ $",
+														"unknown opcode": "OP_CHECKSIGG",
+														"push size": "<x>",
+														"negative push size": "<-1>",
+														"hex": "0xabc" } {
+		if _, err := ParseScriptPattern (pattern); err == nil { t.Errorf ("%s: no error", name) }
+	}
+}
+
+func TestScriptPatternMatch (t *testing.T) {
+
+	p2wpkh := "0014" + strings.Repeat ("ab", 20)
+	p2pkh := "76a914" + strings.Repeat ("ab", 20) + "88ac"
+	timelock := "04" + "00e1f505" + "b175" + "21" + "02" + testGeneratorPointXHex + "ac"
+	nullData := "6a" + "04" + "6f726421"
+
+	for name, test := range map [string] struct {
+		pattern string
+		scriptHex string
+		start int
+	} {	"opcode": { "OP_CHECKSIG", p2pkh, 4 },
+		"without the prefix": { "HASH160 <20> EQUALVERIFY", p2pkh, 1 },
+		"anywhere in the script": { "OP_EQUALVERIFY OP_CHECKSIG", p2pkh, 3 },
+		"not in order": { "OP_CHECKSIG OP_EQUALVERIFY", p2pkh, -1 },
+		"anchored start": { "^ OP_0 <20> $", p2wpkh, 0 },
+		"anchored start not at the start": { "^ <20>", p2wpkh, -1 },
+		"anchored end": { "OP_EQUALVERIFY $", p2pkh, -1 },
+		"anchored end at the end": { "<20> $", p2wpkh, 1 },
+		"push size": { "<20>", p2pkh, 2 },
+		"wrong push size": { "OP_0 <32>", p2wpkh, -1 },
+		"any push": { "OP_RETURN <>", nullData, 0 },
+		"any push is not an opcode": { "<> OP_DUP", p2pkh, -1 },
+		"data": { "OP_RETURN 0x6f726421 $", nullData, 0 },
+		"different data": { "OP_RETURN 0x6f726422", nullData, -1 },
+		"alias": { "<4> CLTV OP_DROP", timelock, 0 },
+		"nop alias": { "OP_NOP2", timelock, 1 },
+		"any field": { "^ OP_DUP ? <20>", p2pkh, 0 },
+		"any fields": { "^ OP_DUP * OP_CHECKSIG $", p2pkh, 0 },
+		"no fields for a wildcard": { "OP_DROP * <33>", timelock, 2 },
+		"wildcard at the end": { "OP_HASH160 *", p2pkh, 1 },
+		"wildcard without a match": { "OP_DUP * OP_CHECKMULTISIG", p2pkh, -1 } } {
+		sp, err := ParseScriptPattern (test.pattern)
+		if err != nil { t.Errorf ("%s: %s", name, err.Error ()); continue }
+
+		if start := sp.Match (NewScript (decodeTestHex (t, test.scriptHex))); start != test.start { t.Errorf ("%s: match at %d, expected %d", name, start, test.start) }
+	}
+
+	sp, _ := ParseScriptPattern ("OP_CHECKSIG")
+	if sp.Match (NewScript ([] byte {})) != -1 { t.Error ("the empty script matches") }
+}
+
+// each wildcard tries every remaining field, which is exponential unless the positions that failed are remembered
+func TestScriptPatternMatchWildcards (t *testing.T) {
+
+	sp, err := ParseScriptPattern (strings.Repeat ("* OP_1 ", 20) + "OP_CHECKSIG")
+	if err != nil { t.Fatal (err) }
+
+	script := NewScript ([] byte (strings.Repeat ("\x51", 500)))
+	if start := sp.Match (script); start != -1 { t.Errorf ("match at %d", start) }
+}
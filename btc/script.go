@@ -2,6 +2,9 @@ package btc
 
 import (
 	"fmt"
+	"strings"
+	"strconv"
+	"crypto/sha256"
 	"encoding/hex"
 )
 
@@ -164,13 +167,88 @@ func NewScript (rawBytes [] byte) Script {
 		if field.IsOpcode () {
 			fields [f].dataType = field.AsHex ()
 		} else {
-			fields [f].dataType = GetStackItemType (field.AsBytes (), false)
+			fields [f].dataType = getScriptFieldType (fields, f, false)
 		}
 	}
 
 	return Script { rawBytes: rawBytes, fields: fields, parseError: parseError, appearsValid: appearsValid }
 }
 
+// determines the data type of a field, taking the fields around it into account
+func getScriptFieldType (fields [] ScriptField, f int, schnorr bool) string {
+
+	fieldBytes := fields [f].AsBytes ()
+
+	previousOpcode := ""
+	if f > 0 && fields [f - 1].IsOpcode () { previousOpcode = fields [f - 1].AsHex () }
+
+	// a hash that the result of a hashing opcode is compared to
+	hashType := getHashOpcodeType (previousOpcode, len (fieldBytes))
+	if len (hashType) > 0 {
+		if hashType == "HASH160" && f > 1 && fields [f - 2].IsOpcode () && fields [f - 2].AsHex () == "OP_DUP" { return "Public Key Hash" }
+		return "Hash (" + hashType + ")"
+	}
+
+	// a nonce that the script puts in front of the s value to build a signature
+	if isSignatureNonce (fields, f) {
+		if IsGeneratorPointX (fieldBytes) { return "BIP-340 Nonce (G.x)" }
+		return "BIP-340 Nonce"
+	}
+
+	return GetStackItemType (fieldBytes, schnorr)
+}
+
+// a signature is built as <R.x> OP_SWAP OP_CAT from an s value already on the stack, or as <R.x> <s> OP_CAT, and it is checked later in the script
+func isSignatureNonce (fields [] ScriptField, f int) bool {
+
+	if !IsValidBip340Nonce (fields [f].AsBytes ()) || f + 2 >= len (fields) { return false }
+
+	next := fields [f + 1]
+	concatenated := fields [f + 2].IsOpcode () && fields [f + 2].AsHex () == "OP_CAT"
+	if next.IsOpcode () {
+		concatenated = concatenated && next.AsHex () == "OP_SWAP"
+	} else {
+		concatenated = concatenated && len (next.AsBytes ()) == 32
+	}
+	if !concatenated { return false }
+
+	for _, field := range fields [f + 3:] {
+		if !field.IsOpcode () { continue }
+		switch field.AsHex () {
+			case "OP_CHECKSIG", "OP_CHECKSIGVERIFY", "OP_CHECKSIGADD": return true
+		}
+	}
+
+	return false
+}
+
+// tap scripts use schnorr signatures and public keys
+func (s *Script) setTapScriptFieldTypes () {
+	isOrdinal := s.IsOrdinal ()
+	for f, field := range s.fields {
+		if !field.IsOpcode () {
+			itemType := getScriptFieldType (s.fields, f, true)
+			if isOrdinal && itemType == "Schnorr Signature" {
+				itemType = getScriptFieldType (s.fields, f, false)
+			}
+			s.fields [f].dataType = itemType
+		}
+	}
+}
+
+// returns the script bytes that push the data onto the stack
+func serializePushData (data [] byte) [] byte {
+	dataLen := len (data)
+	serialized := make ([] byte, 0, dataLen + 5)
+	switch {
+		case dataLen < 0x4c: serialized = append (serialized, byte (dataLen))
+		case dataLen <= 0xff: serialized = append (serialized, 0x4c, byte (dataLen))
+		case dataLen <= 0xffff: serialized = append (serialized, 0x4d, byte (dataLen), byte (dataLen >> 8))
+		default: serialized = append (serialized, 0x4e, byte (dataLen), byte (dataLen >> 8), byte (dataLen >> 16), byte (dataLen >> 24))
+	}
+	return append (serialized, data...)
+}
+
 // used only for testing
 /*
 func (s *Script) PrintToScreen () {
@@ -211,6 +289,13 @@ func (s *Script) GetFieldCount () uint16 {
 	return uint16 (len (s.fields))
 }
 
+// the field types of the copy can be changed without changing the original, the bytes are shared because they are never changed
+func (s *Script) copy () Script {
+	c := *s
+	if s.fields != nil { c.fields = append ([] ScriptField {}, s.fields...) }
+	return c
+}
+
 func (s *Script) GetFields () [] ScriptField {
 	return s.fields
 }
@@ -396,6 +481,90 @@ func (s *Script) IsOrdinal () bool {
 	return true
 }
 
+// returns the content type and the content of an ordinal tap script
+// the content is split across multiple fields when it is larger than 520 bytes
+func (s *Script) GetOrdinalContent () (string, [] byte) {
+
+	if !s.IsOrdinal () { return "", nil }
+
+	ordBegin := 2
+	if s.fields [3].AsHex () == "OP_DROP" { ordBegin = 4 }
+
+	contentType := string (s.fields [ordBegin + 4].AsBytes ())
+
+	content := make ([] byte, 0)
+	for f := ordBegin + 6; f < len (s.fields) - 1; f++ {
+		if s.fields [f].IsOpcode () { continue }
+		content = append (content, s.fields [f].AsBytes ()...)
+	}
+
+	return contentType, content
+}
+
+// the script with every data push replaced by its size, so scripts with the same structure have the same template
+func (s *Script) GetTemplate () string {
+
+	parts := make ([] string, len (s.fields))
+	for f, field := range s.fields {
+		if field.IsOpcode () {
+			parts [f] = field.AsHex ()
+		} else {
+			parts [f] = "<" + strconv.Itoa (len (field.AsBytes ())) + ">"
+		}
+	}
+
+	return strings.Join (parts, " ")
+}
+
+// a short hash of the template, or an empty string for an empty script
+func (s *Script) GetFingerprint () string {
+	if len (s.fields) == 0 { return "" }
+	hash := sha256.Sum256 ([] byte (s.GetTemplate ()))
+	return hex.EncodeToString (hash [: 8])
+}
+
+// every opcode that appears in the script, in the order they first appear
+func (s *Script) GetOpcodes () [] string {
+
+	found := make (map [string] bool)
+	opcodes := make ([] string, 0)
+	for _, field := range s.fields {
+		if !field.IsOpcode () { continue }
+
+		opcode := field.AsHex ()
+		if found [opcode] { continue }
+
+		found [opcode] = true
+		opcodes = append (opcodes, opcode)
+	}
+
+	return opcodes
+}
+
+// returns the first byte in an opcode position that is not a defined opcode
+// data pushes are skipped the same way they are when the script is parsed
+func (s *Script) findUndefinedOpcode () (byte, bool) {
+
+	pos := 0
+	for pos < len (s.rawBytes) {
+		b := s.rawBytes [pos]
+		switch {
+			case isValidOpcode (b):
+				pos++
+			case b < 0x4c:
+				pos += 1 + int (b)
+			case b <= 0x4e:
+				sizeLen := 1 << (b - 0x4c)
+				if pos + 1 + sizeLen > len (s.rawBytes) { return 0, false }
+				pos += 1 + sizeLen + int (ReadNumeric (s.rawBytes [pos + 1 : pos + 1 + sizeLen]))
+			default:
+				return b, true
+		}
+	}
+
+	return 0, false
+}
+
 func isValidOpcode (b byte) bool {
 	return (b == 0x00 || b >= 0x4f) && getOpcodeName (b) != "OP_INVALIDOPCODE"
 }
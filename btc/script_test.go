@@ -0,0 +1,53 @@
+package btc
+
+import (
+	"strings"
+	"testing"
+)
+
+// returns the types of the fields in a script, using the tap script types if tapScript is set
+func getTestFieldTypes (t *testing.T, scriptHex string, tapScript bool) [] string {
+	t.Helper ()
+
+	script := NewScript (decodeTestHex (t, scriptHex))
+	if tapScript { script.setTapScriptFieldTypes () }
+
+	types := make ([] string, 0, len (script.fields))
+	for _, field := range script.fields { types = append (types, field.AsType ()) }
+	return types
+}
+
+func TestScriptFieldTypes (t *testing.T) {
+
+	hash20 := "14" + strings.Repeat ("ab", 20)
+	hash32 := "20" + strings.Repeat ("cd", 32)
+	gx := "20" + testGeneratorPointXHex
+
+	// x = 1 is on the curve and x = 5 is not
+	x1 := "20" + strings.Repeat ("00", 31) + "01"
+	x5 := "20" + strings.Repeat ("00", 31) + "05"
+
+	for name, test := range map [string] struct {
+		scriptHex string
+		tapScript bool
+		types [] string
+	} {	"p2pkh": { "76a9" + hash20 + "88ac", false, [] string { "OP_DUP", "OP_HASH160", "Public Key Hash", "OP_EQUALVERIFY", "OP_CHECKSIG" } },
+		"p2sh": { "a9" + hash20 + "87", false, [] string { "OP_HASH160", "Hash (HASH160)", "OP_EQUAL" } },
+		"sha256 preimage": { "a8" + hash32 + "87", false, [] string { "OP_SHA256", "Hash (SHA256)", "OP_EQUAL" } },
+		"hash256 preimage": { "aa" + hash32 + "87", false, [] string { "OP_HASH256", "Hash (HASH256)", "OP_EQUAL" } },
+		"ripemd160 preimage": { "a6" + hash20 + "87", false, [] string { "OP_RIPEMD160", "Hash (RIPEMD160)", "OP_EQUAL" } },
+		"wrong hash length": { "a8" + hash20 + "87", false, [] string { "OP_SHA256", "Data (20 Bytes)", "OP_EQUAL" } },
+		"hash without a hashing opcode": { hash32 + "87", false, [] string { "Data (32 Bytes)", "OP_EQUAL" } },
+
+		// the nonce of 1 is put in front of s, and the generator point is also the public key
+		"generator point nonce": { gx + "7c7e" + gx + "ac", true, [] string { "BIP-340 Nonce (G.x)", "OP_SWAP", "OP_CAT", "Public Key", "OP_CHECKSIG" } },
+		"nonce with s pushed": { x1 + hash32 + "7e" + gx + "ac", true, [] string { "BIP-340 Nonce", "Public Key", "OP_CAT", "Public Key", "OP_CHECKSIG" } },
+		"nonce in a legacy script": { x1 + "7c7e" + "21" + "02" + testGeneratorPointXHex + "ac", false, [] string { "BIP-340 Nonce", "OP_SWAP", "OP_CAT", "Public Key", "OP_CHECKSIG" } },
+		"not on the curve": { x5 + "7c7e" + gx + "ac", true, [] string { "Public Key", "OP_SWAP", "OP_CAT", "Public Key", "OP_CHECKSIG" } },
+		"appended after s": { gx + "7e" + gx + "ac", true, [] string { "Public Key", "OP_CAT", "Public Key", "OP_CHECKSIG" } },
+		"no signature check": { gx + "7c7e" + "87", true, [] string { "Public Key", "OP_SWAP", "OP_CAT", "OP_EQUAL" } },
+		"generator point as a public key": { gx + "ac", true, [] string { "Public Key", "OP_CHECKSIG" } } } {
+		types := getTestFieldTypes (t, test.scriptHex, test.tapScript)
+		if strings.Join (types, ",") != strings.Join (test.types, ",") { t.Errorf ("%s: types %v, expected %v", name, types, test.types) }
+	}
+}
@@ -74,6 +74,14 @@ func (s *Segwit) IsNil () bool {
 	return s.fields == nil
 }
 
+func (s *Segwit) copy () Segwit {
+	c := *s
+	if s.fields != nil { c.fields = append ([] SegwitField {}, s.fields...) }
+	c.witnessScript = s.witnessScript.copy ()
+	c.tapScript = s.tapScript.copy ()
+	return c
+}
+
 func (s *Segwit) IsEmpty () bool {
 	return s.IsNil () || len (s.fields) == 0
 }
@@ -161,7 +169,7 @@ func (s *Segwit) SetWitnessScript (ws Script) {
 		if field.IsOpcode () {
 			s.witnessScript.SetFieldType (f, field.AsHex ())
 		} else {
-			s.witnessScript.SetFieldType (f, GetStackItemType (field.AsBytes (), false))
+			s.witnessScript.SetFieldType (f, getScriptFieldType (witnessScriptFields, f, false))
 		}
 	}
 }
@@ -222,16 +230,7 @@ func (s *Segwit) SetTapScript (ts Script, i uint32) {
 	s.fields [cbIndex].SetType (fmt.Sprintf ("Control Block (Version %X, Parity %d, %d %s)", s.GetTapLeafVersion (), parity, cbLeafCount, leafCountLabel))
 
 	// set the field types for the Tap Script
-	tapScriptFields := s.tapScript.GetFields ()
-	for f, field := range tapScriptFields {
-		if !field.IsOpcode () {
-			itemType := GetStackItemType (field.AsBytes (), true)
-			if s.tapScript.IsOrdinal () && itemType == "Schnorr Signature" {
-				itemType = GetStackItemType (field.AsBytes (), false)
-			}
-			s.tapScript.SetFieldType (f, itemType)
-		}
-	}
+	s.tapScript.setTapScriptFieldTypes ()
 }
 
 func (s *Segwit) HasAnnex () bool {
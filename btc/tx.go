@@ -15,6 +15,9 @@ type Tx struct {
 
 	blockHash string
 	blockTime int64
+
+	// only set for transactions in the mempool
+	mempoolEntry MempoolEntry
 }
 
 func NewTx (id string, version uint32, inputs [] Input, outputs [] Output, lockTime uint32, coinbase bool, bip141 bool, blockHash string, blockTime int64) Tx {
@@ -37,6 +40,18 @@ func (tx *Tx) GetBlockHash () string {
 	return tx.blockHash
 }
 
+func (tx *Tx) IsConfirmed () bool {
+	return len (tx.blockHash) > 0
+}
+
+func (tx *Tx) GetMempoolEntry () MempoolEntry {
+	return tx.mempoolEntry
+}
+
+func (tx *Tx) SetMempoolEntry (mempoolEntry MempoolEntry) {
+	tx.mempoolEntry = mempoolEntry
+}
+
 func (tx *Tx) GetBlockTime () int64 {
 	return tx.blockTime
 }
@@ -61,6 +76,16 @@ func (tx *Tx) GetInputs () [] Input {
 	return tx.inputs
 }
 
+// transactions share their inputs with every copy of the Tx value, including the ones in the cache
+// the inputs of a deep copy can be changed by SetPreviousOutput without changing anyone else's transaction
+func (tx *Tx) Copy () Tx {
+	c := *tx
+	c.inputs = make ([] Input, len (tx.inputs))
+	for i, _ := range tx.inputs { c.inputs [i] = tx.inputs [i].copy () }
+	c.outputs = append ([] Output {}, tx.outputs...)
+	return c
+}
+
 func (tx *Tx) SetPreviousOutput (inputIndex uint16, previousOutput Output) {
 	if inputIndex < tx.GetInputCount () {
 		tx.inputs [inputIndex].SetPreviousOutput (previousOutput)
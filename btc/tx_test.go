@@ -0,0 +1,52 @@
+package btc
+
+import (
+	"strings"
+	"testing"
+	"encoding/hex"
+)
+
+// a p2wpkh spend with one p2wpkh output
+const testSegwitTxHex = "02000000000101" + "1111111111111111111111111111111111111111111111111111111111111111" + "00000000" + "00" + "ffffffff" +
+						"01" + "e803000000000000" + "160014" + "2222222222222222222222222222222222222222" +
+						"02" + "47" + "3044022011111111111111111111111111111111111111111111111111111111111111110220222222222222222222222222222222222222222222222222222222222222222201" +
+						"21" + "023333333333333333333333333333333333333333333333333333333333333333" + "00000000"
+
+func decodeTestTx (t *testing.T, txHex string) Tx {
+	rawBytes, err := hex.DecodeString (txHex)
+	if err != nil { t.Fatal (err) }
+
+	tx, err := DecodeRawTx (rawBytes)
+	if err != nil { t.Fatal (err) }
+
+	return tx
+}
+
+func TestCopyDoesNotShareInputs (t *testing.T) {
+
+	original := decodeTestTx (t, testSegwitTxHex)
+	shared := original
+	c := original.Copy ()
+
+	previousOutputScript, _ := hex.DecodeString ("0014" + strings.Repeat ("44", 20))
+	script := NewScript (previousOutputScript)
+	c.SetPreviousOutput (0, NewOutput (2000, script, GetAddress (script)))
+
+	copiedInput := c.GetInput (0)
+	if copiedInput.GetSpendType () != OUTPUT_TYPE_P2WPKH { t.Errorf ("spend type of the copy is %s", copiedInput.GetSpendType ()) }
+
+	copiedSegwit := copiedInput.GetSegwit ()
+	copiedInputScript := copiedInput.GetInputScript ()
+
+	// neither the original nor another value of the same transaction sees the previous output
+	for _, tx := range [] Tx { original, shared } {
+		input := tx.GetInput (0)
+		if input.GetSpendType () == OUTPUT_TYPE_P2WPKH { t.Error ("the original has the spend type of the copy") }
+
+		segwit := input.GetSegwit ()
+		if &segwit.fields [0] == &copiedSegwit.fields [0] { t.Error ("the copy shares its segwit fields with the original") }
+
+		inputScript := input.GetInputScript ()
+		if len (inputScript.fields) > 0 && &inputScript.fields [0] == &copiedInputScript.fields [0] { t.Error ("the copy shares its input script fields with the original") }
+	}
+}
@@ -1,8 +1,13 @@
 package btc
 
 import (
-//	"fmt"
+	"fmt"
+	"bytes"
+	"math/big"
 	"strconv"
+	"unicode"
+	"unicode/utf8"
+	"encoding/json"
 )
 
 func ReadNumeric (rawBytes [] byte) uint64 {
@@ -16,8 +21,11 @@ func ReadNumeric (rawBytes [] byte) uint64 {
 	return val
 }
 
+// returns a byte count of 0 if there are not enough bytes to read the value
 func ReadVarInt (rawBytes [] byte) (uint64, int) {
 
+	if len (rawBytes) == 0 { return 0, 0 }
+
 	byteCount := 1
 	firstByte := ReadNumeric (rawBytes [0:1])
 	if firstByte <= 0xfc {
@@ -30,7 +38,9 @@ func ReadVarInt (rawBytes [] byte) (uint64, int) {
 		case 0xff: byteCount += 8; break
 	}
 	
-	return ReadNumeric (rawBytes [1 : firstByte]), byteCount
+	if len (rawBytes) < byteCount { return 0, 0 }
+
+	return ReadNumeric (rawBytes [1 : byteCount]), byteCount
 }
 
 func ReverseBytes (rawBytes [] byte) [] byte {
@@ -58,6 +68,18 @@ func IsValidECPublicKey (field [] byte) bool {
 }
 
 func IsValidECSignature (field [] byte) bool {
+	return isValidDerSignature (field) && isStandardSighash (field [len (field) - 1])
+}
+
+// a DER-encoded signature followed by a sighash byte that is not one of the standard sighash types
+func IsNonStandardSighashECSignature (field [] byte) bool {
+	if !isValidDerSignature (field) { return false }
+
+	// without a standard sighash byte, we require the length to match exactly
+	return len (field) == int (field [1]) + 3 && !isStandardSighash (field [len (field) - 1])
+}
+
+func isValidDerSignature (field [] byte) bool {
 
 	fieldLen := len (field)
 	if fieldLen < 4 { return false }
@@ -77,11 +99,11 @@ func IsValidECSignature (field [] byte) bool {
 
 	// s
 	sLen := int (field [rLen + 5])
-	if rLen + sLen + 4 != signatureLen { return false }
+	return rLen + sLen + 4 == signatureLen
+}
 
-	// sighash byte
-	lastByte := field [fieldLen - 1]
-	return lastByte == 0x01 || lastByte == 0x02 || lastByte == 0x03 || lastByte == 0x81 || lastByte == 0x82 || lastByte == 0x83
+func isStandardSighash (sighash byte) bool {
+	return sighash == 0x01 || sighash == 0x02 || sighash == 0x03 || sighash == 0x81 || sighash == 0x82 || sighash == 0x83
 }
 
 func IsValidSchnorrPublicKey (field [] byte) bool {
@@ -96,8 +118,90 @@ func IsValidSchnorrSignature (field [] byte) bool {
 	if fieldLen == 64 { return true }
 	if fieldLen != 65 { return false }
 
-	lastByte := field [fieldLen - 1]
-	return lastByte == 0x01 || lastByte == 0x02 || lastByte == 0x03 || lastByte == 0x81 || lastByte == 0x82 || lastByte == 0x83
+	return isStandardSighash (field [fieldLen - 1])
+}
+
+// the x coordinate of the secp256k1 generator point
+// scripts that use OP_CAT to inspect transactions sign with a nonce of 1, whose R.x is the generator point, so that the signature can be computed in the script
+var generatorPointX = [] byte {	0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac, 0x55, 0xa0, 0x62, 0x95, 0xce, 0x87, 0x0b, 0x07,
+								0x02, 0x9b, 0xfc, 0xdb, 0x2d, 0xce, 0x28, 0xd9, 0x59, 0xf2, 0x81, 0x5b, 0x16, 0xf8, 0x17, 0x98 }
+
+func IsGeneratorPointX (field [] byte) bool {
+	return bytes.Equal (field, generatorPointX)
+}
+
+// the field size of secp256k1
+var secp256k1P, _ = new (big.Int).SetString ("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+
+// a BIP-340 nonce is the R.x half of a schnorr signature, so it must be the x coordinate of a point on the curve (lift_x in BIP-340)
+// the field itself can not show it is a nonce, see getScriptFieldType for the script context that does
+func IsValidBip340Nonce (field [] byte) bool {
+
+	if len (field) != 32 { return false }
+
+	x := new (big.Int).SetBytes (field)
+	if x.Cmp (secp256k1P) >= 0 { return false }
+
+	// y^2 = x^3 + 7 must have a square root
+	ySquared := new (big.Int).Exp (x, big.NewInt (3), secp256k1P)
+	ySquared.Add (ySquared, big.NewInt (7))
+	ySquared.Mod (ySquared, secp256k1P)
+	return new (big.Int).ModSqrt (ySquared, secp256k1P) != nil
+}
+
+func IsText (field [] byte) bool {
+
+	// very short fields are too likely to be printable by accident
+	if len (field) < 3 || !utf8.Valid (field) { return false }
+
+	for _, r := range string (field) {
+		if !unicode.IsPrint (r) && r != '\n' && r != '\r' && r != '\t' { return false }
+	}
+
+	return true
+}
+
+func IsJson (field [] byte) bool {
+	trimmed := bytes.TrimSpace (field)
+	if len (trimmed) < 2 { return false }
+	if trimmed [0] != '{' && trimmed [0] != '[' { return false }
+	return json.Valid (trimmed)
+}
+
+type fileSignature struct {
+	offset int
+	magic [] byte
+	fileType string
+}
+
+var fileSignatures = [] fileSignature {
+	fileSignature { offset: 0, magic: [] byte { 0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a }, fileType: "PNG Image" },
+	fileSignature { offset: 0, magic: [] byte { 0xff, 0xd8, 0xff }, fileType: "JPEG Image" },
+	fileSignature { offset: 0, magic: [] byte ("GIF87a"), fileType: "GIF Image" },
+	fileSignature { offset: 0, magic: [] byte ("GIF89a"), fileType: "GIF Image" },
+	fileSignature { offset: 8, magic: [] byte ("WEBP"), fileType: "WebP Image" },
+	fileSignature { offset: 0, magic: [] byte ("%PDF-"), fileType: "PDF Document" },
+	fileSignature { offset: 0, magic: [] byte { 0x1f, 0x8b, 0x08 }, fileType: "Gzip Data" } }
+
+// returns an empty string if the field does not begin with a known file signature
+func GetFileType (field [] byte) string {
+
+	for _, signature := range fileSignatures {
+		end := signature.offset + len (signature.magic)
+		if len (field) < end || !bytes.Equal (field [signature.offset : end], signature.magic) { continue }
+
+		// webp files are riff files
+		if signature.fileType == "WebP Image" && !bytes.Equal (field [0 : 4], [] byte ("RIFF")) { continue }
+
+		return signature.fileType
+	}
+
+	// zlib has only a two-byte header, so we require the header checksum and some data following it
+	if len (field) > 8 && field [0] == 0x78 && (field [1] == 0x01 || field [1] == 0x5e || field [1] == 0x9c || field [1] == 0xda) && ((uint16 (field [0]) << 8) | uint16 (field [1])) % 31 == 0 {
+		return "Zlib Data"
+	}
+
+	return ""
 }
 
 func GetStackItemType (field [] byte, schnorr bool) string {
@@ -107,13 +211,34 @@ func GetStackItemType (field [] byte, schnorr bool) string {
 //		if IsValidUncompressedPublicKey (field) { return "Uncompressed Public Key" }
 //		if IsValidCompressedPublicKey (field) { return "Compressed Public Key" }
 		if IsValidECPublicKey (field) { return "Public Key" }
+		if IsNonStandardSighashECSignature (field) { return fmt.Sprintf ("Signature (Non-Standard Sighash 0x%02x)", field [len (field) - 1]) }
 	} else {
-		if IsValidSchnorrSignature (field) { return "Schnorr Signature" }
-		if IsValidSchnorrPublicKey (field) { return "Public Key" }
+		if IsValidSchnorrSignature (field) && !IsText (field) { return "Schnorr Signature" }
+		if IsValidSchnorrPublicKey (field) && !IsText (field) { return "Public Key" }
 	}
 
 	fieldLen := len (field)
 	s := ""; if fieldLen != 1 { s = "s" }
-	return "Data (" + strconv.Itoa (fieldLen) + " Byte" + s + ")"
+	sizeLabel := " (" + strconv.Itoa (fieldLen) + " Byte" + s + ")"
+
+	fileType := GetFileType (field)
+	if len (fileType) > 0 { return fileType + sizeLabel }
+	if IsJson (field) { return "JSON" + sizeLabel }
+	if IsText (field) { return "Text" + sizeLabel }
+
+	// a field is only labeled as a hash when the script shows it is one, see getScriptFieldType
+	return "Data" + sizeLabel
 }
 
+// returns the hash type produced by a hashing opcode, or an empty string if it is not a hashing opcode
+func getHashOpcodeType (opcodeName string, hashLen int) string {
+	switch opcodeName {
+		case "OP_RIPEMD160": if hashLen == 20 { return "RIPEMD160" }
+		case "OP_SHA1": if hashLen == 20 { return "SHA1" }
+		case "OP_HASH160": if hashLen == 20 { return "HASH160" }
+		case "OP_SHA256": if hashLen == 32 { return "SHA256" }
+		case "OP_HASH256": if hashLen == 32 { return "HASH256" }
+	}
+
+	return ""
+}
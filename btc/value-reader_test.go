@@ -0,0 +1,113 @@
+package btc
+
+import (
+	"strings"
+	"testing"
+	"encoding/hex"
+)
+
+const testGeneratorPointXHex = "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+
+func decodeTestHex (t *testing.T, hexStr string) [] byte {
+	t.Helper ()
+
+	field, err := hex.DecodeString (hexStr)
+	if err != nil { t.Fatal (err) }
+	return field
+}
+
+func TestGetFileType (t *testing.T) {
+
+	for name, test := range map [string] struct {
+		field [] byte
+		fileType string
+	} {	"png": { append ([] byte { 0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a }, 0, 0, 0, 0x0d), "PNG Image" },
+		"jpeg": { [] byte { 0xff, 0xd8, 0xff, 0xe0, 0, 0x10 }, "JPEG Image" },
+		"gif87a": { [] byte ("GIF87a..."), "GIF Image" },
+		"gif89a": { [] byte ("GIF89a..."), "GIF Image" },
+		"webp": { [] byte ("RIFF\x24\x00\x00\x00WEBPVP8 "), "WebP Image" },
+		"webp without riff": { [] byte ("RIFX\x24\x00\x00\x00WEBPVP8 "), "" },
+		"pdf": { [] byte ("%PDF-1.7\n"), "PDF Document" },
+		"gzip": { [] byte { 0x1f, 0x8b, 0x08, 0, 0, 0, 0, 0, 0, 0x03 }, "Gzip Data" },
+		"zlib": { [] byte { 0x78, 0x9c, 0xcb, 0x48, 0xcd, 0xc9, 0xc9, 0x07, 0x00 }, "Zlib Data" },
+		"zlib without data": { [] byte { 0x78, 0x9c, 0x03, 0x00 }, "" },
+		"zlib with a bad header checksum": { [] byte { 0x78, 0x9d, 0xcb, 0x48, 0xcd, 0xc9, 0xc9, 0x07, 0x00 }, "" },
+		"shorter than the magic": { [] byte { 0x89, 'P', 'N' }, "" },
+		"empty": { [] byte {}, "" },
+		"text": { [] byte ("hello world"), "" } } {
+		if fileType := GetFileType (test.field); fileType != test.fileType { t.Errorf ("%s: file type %q, expected %q", name, fileType, test.fileType) }
+	}
+}
+
+func TestIsText (t *testing.T) {
+
+	for name, test := range map [string] struct {
+		field [] byte
+		isText bool
+	} {	"ascii": { [] byte ("hello world"), true },
+		"utf-8": { [] byte ("héllo wörld ₿"), true },
+		"line breaks and tabs": { [] byte ("a\tb\r\nc"), true },
+		"too short": { [] byte ("ab"), false },
+		"invalid utf-8": { [] byte { 'a', 'b', 0xff, 'c' }, false },
+		"control character": { [] byte ("ab\x00cd"), false },
+		"escape": { [] byte ("ab\x1bcd"), false } } {
+		if IsText (test.field) != test.isText { t.Errorf ("%s: expected %t", name, test.isText) }
+	}
+}
+
+func TestIsJson (t *testing.T) {
+
+	for name, test := range map [string] struct {
+		field string
+		isJson bool
+	} {	"object": { `{"p":"brc-20","op":"mint","amt":"1000"}`, true },
+		"array": { `[1, 2, 3]`, true },
+		"surrounding space": { " \n{}\n ", true },
+		"string": { `"text"`, false },
+		"number": { "123", false },
+		"unterminated": { `{"a":1`, false },
+		"trailing data": { `{"a":1}x`, false },
+		"empty": { "", false } } {
+		if IsJson ([] byte (test.field)) != test.isJson { t.Errorf ("%s: expected %t", name, test.isJson) }
+	}
+}
+
+func TestIsValidBip340Nonce (t *testing.T) {
+
+	// x = 5 is not on the curve, and the field size itself is out of range
+	for name, test := range map [string] struct {
+		fieldHex string
+		isNonce bool
+	} {	"generator point": { testGeneratorPointXHex, true },
+		"x = 1": { strings.Repeat ("00", 31) + "01", true },
+		"not on the curve": { strings.Repeat ("00", 31) + "05", false },
+		"field size": { "fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", false },
+		"compressed public key": { "02" + testGeneratorPointXHex, false },
+		"short": { testGeneratorPointXHex [2:], false } } {
+		if IsValidBip340Nonce (decodeTestHex (t, test.fieldHex)) != test.isNonce { t.Errorf ("%s: expected %t", name, test.isNonce) }
+	}
+}
+
+func TestGetStackItemType (t *testing.T) {
+
+	derSignature := "30440220" + strings.Repeat ("11", 32) + "0220" + strings.Repeat ("22", 32)
+
+	for name, test := range map [string] struct {
+		fieldHex string
+		schnorr bool
+		itemType string
+	} {	"signature": { derSignature + "01", false, "Signature" },
+		"non-standard sighash": { derSignature + "04", false, "Signature (Non-Standard Sighash 0x04)" },
+		"public key": { "02" + testGeneratorPointXHex, false, "Public Key" },
+		"schnorr signature": { strings.Repeat ("11", 64), true, "Schnorr Signature" },
+		"schnorr signature with sighash": { strings.Repeat ("11", 64) + "83", true, "Schnorr Signature" },
+		"schnorr public key": { testGeneratorPointXHex, true, "Public Key" },
+		"32 bytes outside of a tap script": { testGeneratorPointXHex, false, "Data (32 Bytes)" },
+		"png": { "89504e470d0a1a0a0000000d", false, "PNG Image (12 Bytes)" },
+		"json": { hex.EncodeToString ([] byte (`{"a":[1,2]}`)), false, "JSON (11 Bytes)" },
+		"text": { hex.EncodeToString ([] byte ("ord")), false, "Text (3 Bytes)" },
+		"text in a tap script": { hex.EncodeToString ([] byte (strings.Repeat ("a", 32))), true, "Text (32 Bytes)" },
+		"one byte": { "ff", false, "Data (1 Byte)" } } {
+		if itemType := GetStackItemType (decodeTestHex (t, test.fieldHex), test.schnorr); itemType != test.itemType { t.Errorf ("%s: type %q, expected %q", name, itemType, test.itemType) }
+	}
+}
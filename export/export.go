@@ -0,0 +1,279 @@
+package export
+
+import (
+	"fmt"
+	"time"
+	"sort"
+	"sync"
+	"strconv"
+	"path/filepath"
+	"encoding/hex"
+	"crypto/rand"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+const EXPORT_STATUS_RUNNING = "running"
+const EXPORT_STATUS_STOPPED = "stopped"
+const EXPORT_STATUS_COMPLETE = "complete"
+const EXPORT_STATUS_FAILED = "failed"
+
+const FORMAT_CSV = "csv"
+const FORMAT_NDJSON = "ndjson"
+const FORMAT_PARQUET = "parquet"
+
+var formats = [] string { FORMAT_CSV, FORMAT_NDJSON, FORMAT_PARQUET }
+
+// an export writes the rows of each of its tables for a range of blocks to files in the exports directory
+// unlike jobs, exports are not resumed after a restart, but a new export can be started from the next height
+type Export struct {
+	id string
+	startHeight uint32
+	endHeight uint32
+	nextHeight uint32
+	format string
+	tables [] string
+	rowCounts map [string] uint64
+	status string
+	errorMessage string
+	created int64
+	finished int64
+	stopRequested bool
+	mutex sync.Mutex
+}
+
+var exportMap = make (map [string] *Export)
+var exportMapMutex sync.Mutex
+
+var path string
+
+func SetExportsPath (exportsPath string) {
+	path = exportsPath
+}
+
+func GetPath () string {
+	return path
+}
+
+func GetFormats () [] string {
+	return formats
+}
+
+// if no tables are requested, every table is exported
+func StartExport (startHeight uint32, endHeight uint32, format string, tables [] string) (*Export, error) {
+
+	if len (path) == 0 { return nil, fmt.Errorf ("no exports directory") }
+	if startHeight > endHeight { return nil, fmt.Errorf ("start_height is greater than end_height") }
+
+	validFormat := false
+	for _, f := range formats { if f == format { validFormat = true } }
+	if !validFormat { return nil, fmt.Errorf ("%s is not a valid format", format) }
+
+	nodeProxy, err := node.GetNodeProxy ()
+	if err != nil { return nil, err }
+
+	currentHeight := nodeProxy.GetCurrentBlockHeight ()
+	if currentHeight < 0 || endHeight > uint32 (currentHeight) { return nil, fmt.Errorf ("end_height is above the current block height") }
+
+	// the tables are always kept in the same order
+	requested := make (map [string] bool)
+	for _, table := range tables {
+		if tableColumns [table] == nil { return nil, fmt.Errorf ("%s is not a valid table", table) }
+		requested [table] = true
+	}
+
+	exportTables := make ([] string, 0, len (tableNames))
+	for _, table := range tableNames {
+		if len (requested) == 0 || requested [table] { exportTables = append (exportTables, table) }
+	}
+
+	idBytes := make ([] byte, 8)
+	if _, err := rand.Read (idBytes); err != nil { return nil, err }
+
+	export := &Export {	id: hex.EncodeToString (idBytes),
+						startHeight: startHeight,
+						endHeight: endHeight,
+						nextHeight: startHeight,
+						format: format,
+						tables: exportTables,
+						rowCounts: make (map [string] uint64),
+						status: EXPORT_STATUS_RUNNING,
+						created: time.Now ().Unix () }
+
+	// the files are created before the export starts so that file errors are returned to the caller
+	writers := make (map [string] tableWriter)
+	for _, table := range exportTables {
+		writers [table], err = newTableWriter (export.getFileName (table), format, tableColumns [table])
+		if err != nil {
+			for _, writer := range writers { writer.Close () }
+			return nil, err
+		}
+	}
+
+	exportMapMutex.Lock ()
+	exportMap [export.id] = export
+	exportMapMutex.Unlock ()
+
+	go export.run (writers)
+
+	return export, nil
+}
+
+func GetExport (id string) *Export {
+	exportMapMutex.Lock ()
+	defer exportMapMutex.Unlock ()
+	return exportMap [id]
+}
+
+// returns every export, oldest first
+func GetExports () [] *Export {
+
+	exportMapMutex.Lock ()
+	exports := make ([] *Export, 0, len (exportMap))
+	for _, export := range exportMap {
+		exports = append (exports, export)
+	}
+	exportMapMutex.Unlock ()
+
+	sort.SliceStable (exports, func (i, j int) bool {
+		if exports [i].created == exports [j].created { return exports [i].id < exports [j].id }
+		return exports [i].created < exports [j].created
+	})
+
+	return exports
+}
+
+// the export stops after the block it is currently writing, and the files contain every block before it
+func (e *Export) Stop () {
+	e.mutex.Lock ()
+	defer e.mutex.Unlock ()
+	if e.status == EXPORT_STATUS_RUNNING { e.stopRequested = true }
+}
+
+func (e *Export) run (writers map [string] tableWriter) {
+
+	status, errorMessage := e.writeBlocks (writers)
+
+	// the files must be closed for parquet files to be readable
+	for _, table := range e.tables {
+		if err := writers [table].Close (); err != nil && len (errorMessage) == 0 { status = EXPORT_STATUS_FAILED; errorMessage = err.Error () }
+	}
+
+	if len (errorMessage) > 0 { fmt.Println (fmt.Sprintf ("Export %s failed: %s", e.id, errorMessage)) }
+
+	e.mutex.Lock ()
+	e.status = status
+	e.errorMessage = errorMessage
+	e.stopRequested = false
+	e.finished = time.Now ().Unix ()
+	e.mutex.Unlock ()
+}
+
+// returns the final status and an error message
+func (e *Export) writeBlocks (writers map [string] tableWriter) (string, string) {
+
+	nodeProxy, err := node.GetNodeProxy ()
+	if err != nil { return EXPORT_STATUS_FAILED, err.Error () }
+
+	includeInputDetail := writers [TABLE_TXS] != nil || writers [TABLE_INPUTS] != nil || writers [TABLE_SCRIPT_FIELDS] != nil
+
+	for height := e.startHeight; height <= e.endHeight; height++ {
+
+		e.mutex.Lock ()
+		stopRequested := e.stopRequested
+		e.mutex.Unlock ()
+
+		if stopRequested { return EXPORT_STATUS_STOPPED, "" }
+
+		block := nodeProxy.GetBlock (node.BlockRequest { BlockKey: strconv.FormatUint (uint64 (height), 10) })
+		if block.IsNil () { return EXPORT_STATUS_FAILED, fmt.Sprintf ("block %d not found", height) }
+
+		rows := make (map [string] [] [] interface {})
+		rows [TABLE_BLOCKS] = [] [] interface {} { getBlockRow (block) }
+
+		if includeInputDetail || writers [TABLE_OUTPUTS] != nil {
+			for t, txId := range block.GetTxIds () {
+				tx := nodeProxy.GetTx (node.TxRequest { TxId: txId, IncludeInputDetail: includeInputDetail })
+				if tx.IsNil () { return EXPORT_STATUS_FAILED, fmt.Sprintf ("tx %s in block %d not found", txId, height) }
+
+				addTxRows (rows, tx, height, t)
+			}
+		}
+
+		for _, table := range e.tables {
+			for _, row := range rows [table] {
+				if err := writers [table].WriteRow (row); err != nil { return EXPORT_STATUS_FAILED, err.Error () }
+			}
+		}
+
+		e.mutex.Lock ()
+		for _, table := range e.tables { e.rowCounts [table] += uint64 (len (rows [table])) }
+		e.nextHeight = height + 1
+		e.mutex.Unlock ()
+	}
+
+	return EXPORT_STATUS_COMPLETE, ""
+}
+
+func addTxRows (rows map [string] [] [] interface {}, tx btc.Tx, height uint32, txIndex int) {
+
+	txId := tx.GetTxId ()
+	rows [TABLE_TXS] = append (rows [TABLE_TXS], getTxRow (tx, height, txIndex))
+
+	for i, input := range tx.GetInputs () {
+		rows [TABLE_INPUTS] = append (rows [TABLE_INPUTS], getInputRow (input, i, txId, height))
+	}
+
+	for o, output := range tx.GetOutputs () {
+		rows [TABLE_OUTPUTS] = append (rows [TABLE_OUTPUTS], getOutputRow (output, o, txId, height))
+	}
+
+	rows [TABLE_SCRIPT_FIELDS] = append (rows [TABLE_SCRIPT_FIELDS], getScriptFieldRows (tx, height)...)
+}
+
+func (e *Export) getFileName (table string) string {
+	return filepath.Join (path, e.id + "-" + table + "." + e.format)
+}
+
+func (e *Export) GetId () string {
+	return e.id
+}
+
+func (e *Export) GetStatus () string {
+	e.mutex.Lock ()
+	defer e.mutex.Unlock ()
+	return e.status
+}
+
+// returns the progress and the files being written, with the number of rows written to each one
+func (e *Export) GetReport () map [string] interface {} {
+
+	e.mutex.Lock ()
+	defer e.mutex.Unlock ()
+
+	blockCount := e.endHeight - e.startHeight + 1
+	blocksExported := e.nextHeight - e.startHeight
+
+	files := make ([] map [string] interface {}, len (e.tables))
+	for t, table := range e.tables {
+		files [t] = map [string] interface {} { "table": table, "file": e.getFileName (table), "row_count": e.rowCounts [table] }
+	}
+
+	report := make (map [string] interface {})
+	report ["id"] = e.id
+	report ["status"] = e.status
+	report ["start_height"] = e.startHeight
+	report ["end_height"] = e.endHeight
+	report ["next_height"] = e.nextHeight
+	report ["block_count"] = blockCount
+	report ["blocks_exported"] = blocksExported
+	report ["percent_complete"] = float64 (blocksExported * 100) / float64 (blockCount)
+	report ["created"] = e.created
+	report ["finished"] = e.finished
+	report ["format"] = e.format
+	report ["files"] = files
+	if len (e.errorMessage) > 0 { report ["error"] = e.errorMessage }
+
+	return report
+}
@@ -0,0 +1,340 @@
+package export
+
+import (
+	"io"
+	"bytes"
+	"encoding/binary"
+)
+
+// a minimal parquet writer
+// every column is required and plain encoded in uncompressed data pages
+// this is enough for DuckDB, pandas (pyarrow) and Spark to read the files without any conversion
+
+const parquetMagic = "PAR1"
+const parquetRowGroupSize = 65536
+
+// page sizes are 32 bits in the page headers, so a column is split into pages long before its size could overflow
+// a single value is never split, so a page can be larger if one value is
+const parquetMaxPageSize = 1 << 20
+
+// parquet physical types
+const parquetTypeBoolean = 0
+const parquetTypeInt64 = 2
+const parquetTypeByteArray = 6
+
+// other parquet enum values that are used
+const parquetRepetitionRequired = 0
+const parquetConvertedTypeUtf8 = 0
+const parquetEncodingPlain = 0
+const parquetEncodingRle = 3
+const parquetPageTypeData = 0
+const parquetCodecUncompressed = 0
+
+// thrift compact protocol types
+const thriftTypeI32 = 5
+const thriftTypeI64 = 6
+const thriftTypeBinary = 8
+const thriftTypeList = 9
+const thriftTypeStruct = 12
+
+type parquetPage struct {
+	data [] byte
+	valueCount int32
+}
+
+type parquetColumnChunk struct {
+	offset int64
+	size int64
+}
+
+type parquetRowGroup struct {
+	columns [] parquetColumnChunk
+	size int64
+	rowCount int64
+}
+
+type parquetWriter struct {
+	file io.WriteCloser
+	offset int64
+	columns [] column
+
+	// the page being filled and the pages that are full for each column
+	pages [] bytes.Buffer
+	pageValueCounts [] int32
+	fullPages [] [] parquetPage
+	bools [] [] bool
+	rowCount int64
+	totalRowCount int64
+	rowGroups [] parquetRowGroup
+}
+
+func newParquetWriter (file io.WriteCloser, columns [] column) (*parquetWriter, error) {
+
+	pw := &parquetWriter { file: file, columns: columns, pages: make ([] bytes.Buffer, len (columns)), pageValueCounts: make ([] int32, len (columns)),
+							fullPages: make ([] [] parquetPage, len (columns)), bools: make ([] [] bool, len (columns)) }
+	if err := pw.write ([] byte (parquetMagic)); err != nil { return nil, err }
+
+	return pw, nil
+}
+
+func (pw *parquetWriter) WriteRow (row [] interface {}) error {
+
+	for c, value := range row {
+		page := &pw.pages [c]
+		switch pw.columns [c].columnType {
+			case COLUMN_STRING:
+				s := value.(string)
+				if page.Len () > 0 && page.Len () + 4 + len (s) > parquetMaxPageSize { pw.finishPage (c) }
+				binary.Write (page, binary.LittleEndian, uint32 (len (s)))
+				page.WriteString (s)
+				pw.pageValueCounts [c]++
+			case COLUMN_INT64:
+				if page.Len () + 8 > parquetMaxPageSize { pw.finishPage (c) }
+				binary.Write (page, binary.LittleEndian, value.(int64))
+				pw.pageValueCounts [c]++
+			case COLUMN_BOOL:
+				pw.bools [c] = append (pw.bools [c], value.(bool))
+		}
+	}
+
+	pw.rowCount++
+	if pw.rowCount >= parquetRowGroupSize { return pw.flushRowGroup () }
+
+	return nil
+}
+
+func (pw *parquetWriter) Close () error {
+
+	if err := pw.flushRowGroup (); err != nil { pw.file.Close (); return err }
+
+	footer := pw.getFileMetaData ()
+	footerSize := make ([] byte, 4)
+	binary.LittleEndian.PutUint32 (footerSize, uint32 (len (footer)))
+
+	for _, data := range [] [] byte { footer, footerSize, [] byte (parquetMagic) } {
+		if err := pw.write (data); err != nil { pw.file.Close (); return err }
+	}
+
+	return pw.file.Close ()
+}
+
+func (pw *parquetWriter) write (data [] byte) error {
+	n, err := pw.file.Write (data)
+	pw.offset += int64 (n)
+	return err
+}
+
+func (pw *parquetWriter) finishPage (c int) {
+	if pw.pageValueCounts [c] == 0 { return }
+
+	page := parquetPage { data: append ([] byte {}, pw.pages [c].Bytes ()...), valueCount: pw.pageValueCounts [c] }
+	pw.fullPages [c] = append (pw.fullPages [c], page)
+
+	pw.pages [c].Reset ()
+	pw.pageValueCounts [c] = 0
+}
+
+// writes the pages of each column
+func (pw *parquetWriter) flushRowGroup () error {
+
+	if pw.rowCount == 0 { return nil }
+
+	rowGroup := parquetRowGroup { rowCount: pw.rowCount }
+	for c := range pw.columns {
+
+		// booleans are bit packed, least significant bit first
+		// a row group of them is far below the page size, so they are always one page
+		if pw.columns [c].columnType == COLUMN_BOOL {
+			packed := make ([] byte, (len (pw.bools [c]) + 7) / 8)
+			for b, value := range pw.bools [c] {
+				if value { packed [b / 8] |= 1 << uint (b % 8) }
+			}
+			pw.pages [c].Write (packed)
+			pw.pageValueCounts [c] = int32 (len (pw.bools [c]))
+			pw.bools [c] = pw.bools [c] [: 0]
+		}
+		pw.finishPage (c)
+
+		chunk := parquetColumnChunk { offset: pw.offset }
+		for _, page := range pw.fullPages [c] {
+			pageHeader := getPageHeader (page.valueCount, int32 (len (page.data)))
+			if err := pw.write (pageHeader); err != nil { return err }
+			if err := pw.write (page.data); err != nil { return err }
+			chunk.size += int64 (len (pageHeader) + len (page.data))
+		}
+
+		rowGroup.columns = append (rowGroup.columns, chunk)
+		rowGroup.size += chunk.size
+		pw.fullPages [c] = nil
+	}
+
+	pw.rowGroups = append (pw.rowGroups, rowGroup)
+	pw.totalRowCount += pw.rowCount
+	pw.rowCount = 0
+
+	return nil
+}
+
+func getPageHeader (valueCount int32, pageSize int32) [] byte {
+
+	var tw thriftWriter
+	tw.beginStruct ()
+	tw.writeI32Field (1, parquetPageTypeData)
+	tw.writeI32Field (2, pageSize)
+	tw.writeI32Field (3, pageSize)
+
+	// data page header
+	tw.writeFieldHeader (5, thriftTypeStruct)
+	tw.beginStruct ()
+	tw.writeI32Field (1, valueCount)
+	tw.writeI32Field (2, parquetEncodingPlain)
+	tw.writeI32Field (3, parquetEncodingRle)
+	tw.writeI32Field (4, parquetEncodingRle)
+	tw.endStruct ()
+
+	tw.endStruct ()
+	return tw.buff.Bytes ()
+}
+
+func (pw *parquetWriter) getFileMetaData () [] byte {
+
+	var tw thriftWriter
+	tw.beginStruct ()
+	tw.writeI32Field (1, 1)
+
+	// schema, the root element is followed by one element for each column
+	tw.writeListHeader (2, thriftTypeStruct, len (pw.columns) + 1)
+	tw.beginStruct ()
+	tw.writeBinaryField (4, [] byte ("schema"))
+	tw.writeI32Field (5, int32 (len (pw.columns)))
+	tw.endStruct ()
+	for _, c := range pw.columns {
+		tw.beginStruct ()
+		tw.writeI32Field (1, c.getParquetType ())
+		tw.writeI32Field (3, parquetRepetitionRequired)
+		tw.writeBinaryField (4, [] byte (c.name))
+		if c.columnType == COLUMN_STRING { tw.writeI32Field (6, parquetConvertedTypeUtf8) }
+		tw.endStruct ()
+	}
+
+	tw.writeI64Field (3, pw.totalRowCount)
+
+	// row groups
+	tw.writeListHeader (4, thriftTypeStruct, len (pw.rowGroups))
+	for _, rowGroup := range pw.rowGroups {
+		tw.beginStruct ()
+
+		tw.writeListHeader (1, thriftTypeStruct, len (rowGroup.columns))
+		for c, chunk := range rowGroup.columns {
+
+			// column chunk
+			tw.beginStruct ()
+			tw.writeI64Field (2, chunk.offset)
+
+			// column metadata
+			tw.writeFieldHeader (3, thriftTypeStruct)
+			tw.beginStruct ()
+			tw.writeI32Field (1, pw.columns [c].getParquetType ())
+			tw.writeListHeader (2, thriftTypeI32, 2)
+			tw.writeVarint (zigzag (parquetEncodingPlain))
+			tw.writeVarint (zigzag (parquetEncodingRle))
+			tw.writeListHeader (3, thriftTypeBinary, 1)
+			tw.writeBinary ([] byte (pw.columns [c].name))
+			tw.writeI32Field (4, parquetCodecUncompressed)
+			tw.writeI64Field (5, rowGroup.rowCount)
+			tw.writeI64Field (6, chunk.size)
+			tw.writeI64Field (7, chunk.size)
+			tw.writeI64Field (9, chunk.offset)
+			tw.endStruct ()
+
+			tw.endStruct ()
+		}
+
+		tw.writeI64Field (2, rowGroup.size)
+		tw.writeI64Field (3, rowGroup.rowCount)
+		tw.endStruct ()
+	}
+
+	tw.writeBinaryField (6, [] byte ("scantool"))
+	tw.endStruct ()
+
+	return tw.buff.Bytes ()
+}
+
+func (c *column) getParquetType () int32 {
+	switch c.columnType {
+		case COLUMN_INT64: return parquetTypeInt64
+		case COLUMN_BOOL: return parquetTypeBoolean
+	}
+
+	return parquetTypeByteArray
+}
+
+// parquet metadata is serialized with the thrift compact protocol
+// field ids are written as deltas from the previous field in the same struct, so the last id of every open struct is kept
+type thriftWriter struct {
+	buff bytes.Buffer
+	lastFieldIds [] int16
+}
+
+func (tw *thriftWriter) beginStruct () {
+	tw.lastFieldIds = append (tw.lastFieldIds, 0)
+}
+
+func (tw *thriftWriter) endStruct () {
+	tw.buff.WriteByte (0)
+	tw.lastFieldIds = tw.lastFieldIds [: len (tw.lastFieldIds) - 1]
+}
+
+func (tw *thriftWriter) writeFieldHeader (id int16, fieldType byte) {
+
+	last := len (tw.lastFieldIds) - 1
+	delta := id - tw.lastFieldIds [last]
+	if delta > 0 && delta <= 15 {
+		tw.buff.WriteByte (byte (delta) << 4 | fieldType)
+	} else {
+		tw.buff.WriteByte (fieldType)
+		tw.writeVarint (zigzag (int64 (id)))
+	}
+
+	tw.lastFieldIds [last] = id
+}
+
+func (tw *thriftWriter) writeI32Field (id int16, value int32) {
+	tw.writeFieldHeader (id, thriftTypeI32)
+	tw.writeVarint (zigzag (int64 (value)))
+}
+
+func (tw *thriftWriter) writeI64Field (id int16, value int64) {
+	tw.writeFieldHeader (id, thriftTypeI64)
+	tw.writeVarint (zigzag (value))
+}
+
+func (tw *thriftWriter) writeBinaryField (id int16, value [] byte) {
+	tw.writeFieldHeader (id, thriftTypeBinary)
+	tw.writeBinary (value)
+}
+
+func (tw *thriftWriter) writeBinary (value [] byte) {
+	tw.writeVarint (uint64 (len (value)))
+	tw.buff.Write (value)
+}
+
+func (tw *thriftWriter) writeListHeader (id int16, elementType byte, size int) {
+	tw.writeFieldHeader (id, thriftTypeList)
+	if size < 15 {
+		tw.buff.WriteByte (byte (size) << 4 | elementType)
+	} else {
+		tw.buff.WriteByte (0xf0 | elementType)
+		tw.writeVarint (uint64 (size))
+	}
+}
+
+func (tw *thriftWriter) writeVarint (value uint64) {
+	varint := make ([] byte, binary.MaxVarintLen64)
+	tw.buff.Write (varint [: binary.PutUvarint (varint, value)])
+}
+
+func zigzag (value int64) uint64 {
+	return uint64 ((value << 1) ^ (value >> 63))
+}
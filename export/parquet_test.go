@@ -0,0 +1,266 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"encoding/binary"
+)
+
+// the file is decoded with the parquet format spec, reading the thrift structs by their field ids
+// https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift
+
+type testParquetFile struct {
+	bytes.Buffer
+}
+
+func (f *testParquetFile) Close () error {
+	return nil
+}
+
+// reads thrift compact protocol structs into maps of field id -> value
+type testThriftReader struct {
+	data [] byte
+	pos int
+	t *testing.T
+}
+
+func (tr *testThriftReader) readByte () byte {
+	if tr.pos >= len (tr.data) { tr.t.Fatalf ("thrift data ends at %d", tr.pos) }
+	b := tr.data [tr.pos]
+	tr.pos++
+	return b
+}
+
+func (tr *testThriftReader) readVarint () uint64 {
+	value, n := binary.Uvarint (tr.data [tr.pos:])
+	if n <= 0 { tr.t.Fatalf ("invalid varint at %d", tr.pos) }
+	tr.pos += n
+	return value
+}
+
+func (tr *testThriftReader) readZigzag () int64 {
+	value := tr.readVarint ()
+	return int64 (value >> 1) ^ -int64 (value & 1)
+}
+
+func (tr *testThriftReader) readStruct () map [int16] interface {} {
+
+	fields := make (map [int16] interface {})
+	lastId := int16 (0)
+	for {
+		header := tr.readByte ()
+		if header == 0 { return fields }
+
+		id := lastId + int16 (header >> 4)
+		if header >> 4 == 0 { id = int16 (tr.readZigzag ()) }
+		fields [id] = tr.readValue (header & 0x0f)
+		lastId = id
+	}
+}
+
+func (tr *testThriftReader) readValue (valueType byte) interface {} {
+
+	switch valueType {
+		case 1, 2: return valueType == 1
+		case thriftTypeI32, thriftTypeI64: return tr.readZigzag ()
+		case thriftTypeBinary:
+			size := int (tr.readVarint ())
+			value := tr.data [tr.pos : tr.pos + size]
+			tr.pos += size
+			return string (value)
+		case thriftTypeList:
+			header := tr.readByte ()
+			size := int (header >> 4)
+			if size == 15 { size = int (tr.readVarint ()) }
+			list := make ([] interface {}, size)
+			for l := range list { list [l] = tr.readValue (header & 0x0f) }
+			return list
+		case thriftTypeStruct:
+			return tr.readStruct ()
+	}
+
+	tr.t.Fatalf ("unexpected thrift type %d", valueType)
+	return nil
+}
+
+func writeTestParquet (t *testing.T, columns [] column, rows [] [] interface {}) [] byte {
+	t.Helper ()
+
+	file := &testParquetFile {}
+	pw, err := newParquetWriter (file, columns)
+	if err != nil { t.Fatal (err) }
+
+	for _, row := range rows {
+		if err := pw.WriteRow (row); err != nil { t.Fatal (err) }
+	}
+	if err := pw.Close (); err != nil { t.Fatal (err) }
+
+	return file.Bytes ()
+}
+
+func readTestFooter (t *testing.T, data [] byte) map [int16] interface {} {
+	t.Helper ()
+
+	if len (data) < 12 || string (data [: 4]) != parquetMagic || string (data [len (data) - 4:]) != parquetMagic { t.Fatal ("the file does not begin and end with the magic") }
+
+	footerSize := int (binary.LittleEndian.Uint32 (data [len (data) - 8:]))
+	footerBegin := len (data) - 8 - footerSize
+	if footerBegin < 4 { t.Fatalf ("footer size %d", footerSize) }
+
+	tr := testThriftReader { data: data [footerBegin : len (data) - 8], t: t }
+	footer := tr.readStruct ()
+	if tr.pos != footerSize { t.Errorf ("the footer is %d bytes but %d were read", footerSize, tr.pos) }
+
+	return footer
+}
+
+// returns the values of a column chunk and the sizes of its pages
+func readTestColumnChunk (t *testing.T, data [] byte, chunk map [int16] interface {}) ([] interface {}, [] int) {
+	t.Helper ()
+
+	metaData := chunk [3].(map [int16] interface {})
+	offset := int (chunk [2].(int64))
+	if metaData [9].(int64) != int64 (offset) { t.Errorf ("data page offset %d, file offset %d", metaData [9], offset) }
+	if metaData [6] != metaData [7] { t.Errorf ("compressed size %d, uncompressed size %d", metaData [7], metaData [6]) }
+
+	values := [] interface {} {}
+	pageSizes := [] int {}
+	chunkEnd := offset + int (metaData [7].(int64))
+	for offset < chunkEnd {
+		tr := testThriftReader { data: data [: chunkEnd], pos: offset, t: t }
+		pageHeader := tr.readStruct ()
+		if pageHeader [1] != int64 (parquetPageTypeData) || pageHeader [2] != pageHeader [3] { t.Fatalf ("page header %v", pageHeader) }
+
+		dataPageHeader := pageHeader [5].(map [int16] interface {})
+		if dataPageHeader [2] != int64 (parquetEncodingPlain) { t.Fatalf ("encoding %v", dataPageHeader [2]) }
+
+		pageSize := int (pageHeader [2].(int64))
+		page := data [tr.pos : tr.pos + pageSize]
+		valueCount := int (dataPageHeader [1].(int64))
+		for v := 0; v < valueCount; v++ {
+			switch metaData [1].(int64) {
+				case parquetTypeInt64:
+					values = append (values, int64 (binary.LittleEndian.Uint64 (page)))
+					page = page [8:]
+				case parquetTypeByteArray:
+					size := int (binary.LittleEndian.Uint32 (page))
+					values = append (values, string (page [4 : 4 + size]))
+					page = page [4 + size:]
+				case parquetTypeBoolean:
+					values = append (values, page [v / 8] & (1 << uint (v % 8)) != 0)
+			}
+		}
+		if metaData [1].(int64) != parquetTypeBoolean && len (page) != 0 { t.Errorf ("%d bytes left in the page", len (page)) }
+
+		pageSizes = append (pageSizes, pageSize)
+		offset = tr.pos + pageSize
+	}
+	if offset != chunkEnd { t.Errorf ("the pages end at %d, the column chunk at %d", offset, chunkEnd) }
+	if int64 (len (values)) != metaData [5].(int64) { t.Errorf ("%d values, expected %d", len (values), metaData [5]) }
+
+	return values, pageSizes
+}
+
+func TestParquetWriter (t *testing.T) {
+
+	columns := [] column { { "height", COLUMN_INT64 }, { "hash", COLUMN_STRING }, { "coinbase", COLUMN_BOOL } }
+	rows := [] [] interface {} {	{ int64 (0), "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f", true },
+									{ int64 (1), "", false },
+									{ int64 (-1), "ünïcode", true } }
+	data := writeTestParquet (t, columns, rows)
+	footer := readTestFooter (t, data)
+
+	if footer [1] != int64 (1) || footer [3] != int64 (len (rows)) || footer [6] != "scantool" { t.Errorf ("version %v, rows %v, created by %v", footer [1], footer [3], footer [6]) }
+
+	// the root of the schema is followed by the columns
+	schema := footer [2].([] interface {})
+	if len (schema) != len (columns) + 1 { t.Fatalf ("%d schema elements", len (schema)) }
+	root := schema [0].(map [int16] interface {})
+	if root [4] != "schema" || root [5] != int64 (len (columns)) { t.Errorf ("schema root %v", root) }
+
+	expectedTypes := [] int64 { parquetTypeInt64, parquetTypeByteArray, parquetTypeBoolean }
+	for c, col := range columns {
+		element := schema [c + 1].(map [int16] interface {})
+		if element [1] != expectedTypes [c] || element [3] != int64 (parquetRepetitionRequired) || element [4] != col.name { t.Errorf ("schema element %v", element) }
+		if _, hasConvertedType := element [6]; hasConvertedType != (col.columnType == COLUMN_STRING) { t.Errorf ("%s converted type %v", col.name, element [6]) }
+	}
+
+	rowGroups := footer [4].([] interface {})
+	if len (rowGroups) != 1 { t.Fatalf ("%d row groups", len (rowGroups)) }
+	rowGroup := rowGroups [0].(map [int16] interface {})
+	if rowGroup [3] != int64 (len (rows)) { t.Errorf ("row group rows %v", rowGroup [3]) }
+
+	chunks := rowGroup [1].([] interface {})
+	if len (chunks) != len (columns) { t.Fatalf ("%d column chunks", len (chunks)) }
+
+	chunkSizes := int64 (0)
+	for c := range columns {
+		chunk := chunks [c].(map [int16] interface {})
+		metaData := chunk [3].(map [int16] interface {})
+		if metaData [1] != expectedTypes [c] || metaData [4] != int64 (parquetCodecUncompressed) { t.Errorf ("column metadata %v", metaData) }
+		if path := metaData [3].([] interface {}); len (path) != 1 || path [0] != columns [c].name { t.Errorf ("path %v", path) }
+		chunkSizes += metaData [7].(int64)
+
+		values, _ := readTestColumnChunk (t, data, chunk)
+		for r, row := range rows {
+			if values [r] != row [c] { t.Errorf ("%s row %d is %v, expected %v", columns [c].name, r, values [r], row [c]) }
+		}
+	}
+	if rowGroup [2] != chunkSizes { t.Errorf ("row group size %v, column chunks %d", rowGroup [2], chunkSizes) }
+}
+
+func TestParquetWriterRowGroups (t *testing.T) {
+
+	columns := [] column { { "index", COLUMN_INT64 }, { "odd", COLUMN_BOOL } }
+	rows := make ([] [] interface {}, parquetRowGroupSize + 10)
+	for r := range rows { rows [r] = [] interface {} { int64 (r), r % 2 == 1 } }
+
+	data := writeTestParquet (t, columns, rows)
+	footer := readTestFooter (t, data)
+	if footer [3] != int64 (len (rows)) { t.Errorf ("%v rows", footer [3]) }
+
+	rowGroups := footer [4].([] interface {})
+	if len (rowGroups) != 2 { t.Fatalf ("%d row groups", len (rowGroups)) }
+
+	r := 0
+	for g, expectedRows := range [] int { parquetRowGroupSize, 10 } {
+		rowGroup := rowGroups [g].(map [int16] interface {})
+		if rowGroup [3] != int64 (expectedRows) { t.Errorf ("row group %d has %v rows", g, rowGroup [3]) }
+
+		chunks := rowGroup [1].([] interface {})
+		indexes, _ := readTestColumnChunk (t, data, chunks [0].(map [int16] interface {}))
+		odds, _ := readTestColumnChunk (t, data, chunks [1].(map [int16] interface {}))
+		for v := range indexes {
+			if indexes [v] != int64 (r) || odds [v] != (r % 2 == 1) { t.Fatalf ("row %d is %v %v", r, indexes [v], odds [v]) }
+			r++
+		}
+	}
+}
+
+// a row group of large scripts is split into pages instead of overflowing the 32 bit page size
+func TestParquetWriterPageSize (t *testing.T) {
+
+	columns := [] column { { "script", COLUMN_STRING }, { "size", COLUMN_INT64 } }
+	rows := [] [] interface {} {}
+	for r := 0; r < 5; r++ { rows = append (rows, [] interface {} { strings.Repeat (string (rune ('a' + r)), 400000), int64 (400000) }) }
+
+	// a single value larger than a page is still written whole
+	rows = append (rows, [] interface {} { strings.Repeat ("z", parquetMaxPageSize + 1), int64 (parquetMaxPageSize + 1) })
+
+	data := writeTestParquet (t, columns, rows)
+	footer := readTestFooter (t, data)
+	chunks := footer [4].([] interface {}) [0].(map [int16] interface {}) [1].([] interface {})
+
+	scripts, pageSizes := readTestColumnChunk (t, data, chunks [0].(map [int16] interface {}))
+	if len (pageSizes) != 4 { t.Errorf ("page sizes %v", pageSizes) }
+	for p, pageSize := range pageSizes [: len (pageSizes) - 1] {
+		if pageSize > parquetMaxPageSize { t.Errorf ("page %d is %d bytes", p, pageSize) }
+	}
+	for r, row := range rows {
+		if scripts [r] != row [0] { t.Errorf ("script %d has the wrong value", r) }
+	}
+
+	sizes, pageSizes := readTestColumnChunk (t, data, chunks [1].(map [int16] interface {}))
+	if len (pageSizes) != 1 || len (sizes) != len (rows) { t.Errorf ("page sizes %v, %d values", pageSizes, len (sizes)) }
+}
@@ -0,0 +1,225 @@
+package export
+
+import (
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// the tables that can be exported, each one is written to its own file
+const TABLE_BLOCKS = "blocks"
+const TABLE_TXS = "txs"
+const TABLE_INPUTS = "inputs"
+const TABLE_OUTPUTS = "outputs"
+const TABLE_SCRIPT_FIELDS = "script_fields"
+
+var tableNames = [] string { TABLE_BLOCKS, TABLE_TXS, TABLE_INPUTS, TABLE_OUTPUTS, TABLE_SCRIPT_FIELDS }
+
+var tableColumns = map [string] [] column {
+
+	TABLE_BLOCKS: [] column {	{ "height", COLUMN_INT64 },
+								{ "hash", COLUMN_STRING },
+								{ "previous_hash", COLUMN_STRING },
+								{ "version", COLUMN_INT64 },
+								{ "timestamp", COLUMN_INT64 },
+								{ "median_time", COLUMN_INT64 },
+								{ "merkle_root", COLUMN_STRING },
+								{ "bits", COLUMN_STRING },
+								{ "nonce", COLUMN_INT64 },
+								{ "size", COLUMN_INT64 },
+								{ "stripped_size", COLUMN_INT64 },
+								{ "weight", COLUMN_INT64 },
+								{ "tx_count", COLUMN_INT64 } },
+
+	TABLE_TXS: [] column {	{ "height", COLUMN_INT64 },
+							{ "tx_index", COLUMN_INT64 },
+							{ "tx_id", COLUMN_STRING },
+							{ "version", COLUMN_INT64 },
+							{ "lock_time", COLUMN_INT64 },
+							{ "coinbase", COLUMN_BOOL },
+							{ "bip141", COLUMN_BOOL },
+							{ "input_count", COLUMN_INT64 },
+							{ "output_count", COLUMN_INT64 },
+							{ "input_value", COLUMN_INT64 },
+							{ "output_value", COLUMN_INT64 },
+							{ "fee", COLUMN_INT64 } },
+
+	TABLE_INPUTS: [] column {	{ "height", COLUMN_INT64 },
+								{ "tx_id", COLUMN_STRING },
+								{ "input_index", COLUMN_INT64 },
+								{ "coinbase", COLUMN_BOOL },
+								{ "previous_output_tx_id", COLUMN_STRING },
+								{ "previous_output_index", COLUMN_INT64 },
+								{ "previous_output_script", COLUMN_STRING },
+								{ "sequence", COLUMN_INT64 },
+								{ "spend_type", COLUMN_STRING },
+								{ "value", COLUMN_INT64 },
+								{ "input_script", COLUMN_STRING },
+								{ "redeem_script", COLUMN_STRING },
+								{ "witness_script", COLUMN_STRING },
+								{ "tap_script", COLUMN_STRING },
+								{ "serialized_script", COLUMN_STRING },
+								{ "fingerprint", COLUMN_STRING },
+								{ "witness_field_count", COLUMN_INT64 } },
+
+	TABLE_OUTPUTS: [] column {	{ "height", COLUMN_INT64 },
+								{ "tx_id", COLUMN_STRING },
+								{ "output_index", COLUMN_INT64 },
+								{ "output_type", COLUMN_STRING },
+								{ "value", COLUMN_INT64 },
+								{ "output_script", COLUMN_STRING },
+								{ "address", COLUMN_STRING },
+								{ "fingerprint", COLUMN_STRING } },
+
+	TABLE_SCRIPT_FIELDS: [] column {	{ "height", COLUMN_INT64 },
+										{ "tx_id", COLUMN_STRING },
+										{ "input", COLUMN_BOOL },
+										{ "index", COLUMN_INT64 },
+										{ "script_type", COLUMN_STRING },
+										{ "position", COLUMN_INT64 },
+										{ "opcode", COLUMN_BOOL },
+										{ "field", COLUMN_STRING },
+										{ "field_type", COLUMN_STRING },
+										{ "size", COLUMN_INT64 } } }
+
+func GetTableNames () [] string {
+	return tableNames
+}
+
+func getBlockRow (block btc.Block) [] interface {} {
+	return [] interface {} {	int64 (block.GetHeight ()),
+								block.GetHash (),
+								block.GetPreviousHash (),
+								int64 (block.GetVersion ()),
+								block.GetTimestamp (),
+								block.GetMedianTime (),
+								block.GetMerkleRoot (),
+								block.GetBits (),
+								int64 (block.GetNonce ()),
+								int64 (block.GetSize ()),
+								int64 (block.GetStrippedSize ()),
+								int64 (block.GetWeight ()),
+								int64 (block.GetTxCount ()) }
+}
+
+// the inputs must have their previous outputs
+func getTxRow (tx btc.Tx, height uint32, txIndex int) [] interface {} {
+
+	inputValue := uint64 (0)
+	for _, input := range tx.GetInputs () {
+		if input.IsCoinbase () { continue }
+		previousOutput := input.GetPreviousOutput ()
+		inputValue += previousOutput.GetValue ()
+	}
+
+	outputValue := uint64 (0)
+	for _, output := range tx.GetOutputs () { outputValue += output.GetValue () }
+
+	fee := int64 (0)
+	if !tx.IsCoinbase () { fee = int64 (inputValue) - int64 (outputValue) }
+
+	return [] interface {} {	int64 (height),
+								int64 (txIndex),
+								tx.GetTxId (),
+								int64 (tx.GetVersion ()),
+								int64 (tx.GetLockTime ()),
+								tx.IsCoinbase (),
+								tx.SupportsBip141 (),
+								int64 (tx.GetInputCount ()),
+								int64 (tx.GetOutputCount ()),
+								int64 (inputValue),
+								int64 (outputValue),
+								fee }
+}
+
+// the serialized script is the one that was executed, if there is one, and the fingerprint is taken from it
+func getInputRow (input btc.Input, inputIndex int, txId string, height uint32) [] interface {} {
+
+	previousOutput := input.GetPreviousOutput ()
+	previousOutputScript := previousOutput.GetOutputScript ()
+
+	segwit := input.GetSegwit ()
+	tapScript, _ := segwit.GetTapScript ()
+	witnessScript := segwit.GetWitnessScript ()
+	redeemScript := input.GetRedeemScript ()
+
+	serializedScript := btc.Script {}
+	if !tapScript.IsNil () {
+		serializedScript = tapScript
+	} else if !witnessScript.IsNil () {
+		serializedScript = witnessScript
+	} else if input.HasRedeemScript () {
+		serializedScript = redeemScript
+	}
+
+	inputScript := input.GetInputScript ()
+	return [] interface {} {	int64 (height),
+								txId,
+								int64 (inputIndex),
+								input.IsCoinbase (),
+								input.GetPreviousOutputTxId (),
+								int64 (input.GetPreviousOutputIndex ()),
+								previousOutputScript.AsHex (),
+								int64 (input.GetSequence ()),
+								input.GetSpendType (),
+								int64 (previousOutput.GetValue ()),
+								inputScript.AsHex (),
+								redeemScript.AsHex (),
+								witnessScript.AsHex (),
+								tapScript.AsHex (),
+								serializedScript.AsHex (),
+								serializedScript.GetFingerprint (),
+								int64 (segwit.GetFieldCount ()) }
+}
+
+func getOutputRow (output btc.Output, outputIndex int, txId string, height uint32) [] interface {} {
+
+	outputScript := output.GetOutputScript ()
+	return [] interface {} {	int64 (height),
+								txId,
+								int64 (outputIndex),
+								output.GetOutputType (),
+								int64 (output.GetValue ()),
+								outputScript.AsHex (),
+								output.GetAddress (),
+								outputScript.GetFingerprint () }
+}
+
+// one row for every field of every script in the tx
+// the coinbase input script is not included because it is not a real script
+func getScriptFieldRows (tx btc.Tx, height uint32) [] [] interface {} {
+
+	rows := make ([] [] interface {}, 0)
+	txId := tx.GetTxId ()
+
+	addScript := func (script btc.Script, scriptType string, input bool, index int) {
+		for f, field := range script.GetFields () {
+			rows = append (rows, [] interface {} {	int64 (height),
+													txId,
+													input,
+													int64 (index),
+													scriptType,
+													int64 (f),
+													field.IsOpcode (),
+													field.AsHex (),
+													field.AsType (),
+													int64 (len (field.AsBytes ())) })
+		}
+	}
+
+	for i, input := range tx.GetInputs () {
+		if input.IsCoinbase () { continue }
+
+		addScript (input.GetInputScript (), btc.SCRIPT_TYPE_INPUT, true, i)
+		if input.HasRedeemScript () { addScript (input.GetRedeemScript (), btc.SCRIPT_TYPE_REDEEM, true, i) }
+
+		segwit := input.GetSegwit ()
+		addScript (segwit.GetWitnessScript (), btc.SCRIPT_TYPE_WITNESS, true, i)
+		tapScript, _ := segwit.GetTapScript ()
+		addScript (tapScript, btc.SCRIPT_TYPE_TAP, true, i)
+	}
+
+	for o, output := range tx.GetOutputs () {
+		addScript (output.GetOutputScript (), btc.SCRIPT_TYPE_OUTPUT, false, o)
+	}
+
+	return rows
+}
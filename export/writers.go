@@ -0,0 +1,116 @@
+package export
+
+import (
+	"os"
+	"fmt"
+	"bufio"
+	"strconv"
+	"encoding/csv"
+	"encoding/json"
+)
+
+const COLUMN_STRING = 0
+const COLUMN_INT64 = 1
+const COLUMN_BOOL = 2
+
+type column struct {
+	name string
+	columnType int
+}
+
+// rows must have one value for each column, a string, int64 or bool depending on the column type
+type tableWriter interface {
+	WriteRow (row [] interface {}) error
+	Close () error
+}
+
+func newTableWriter (fileName string, format string, columns [] column) (tableWriter, error) {
+
+	file, err := os.Create (fileName)
+	if err != nil { return nil, err }
+
+	switch format {
+		case FORMAT_CSV: return newCsvWriter (file, columns)
+		case FORMAT_NDJSON: return &ndjsonWriter { file: file, buffered: bufio.NewWriter (file), columns: columns }, nil
+		case FORMAT_PARQUET: return newParquetWriter (&bufferedFile { file: file, buffered: bufio.NewWriter (file) }, columns)
+	}
+
+	file.Close ()
+	os.Remove (fileName)
+	return nil, fmt.Errorf ("%s is not a valid format", format)
+}
+
+// the first line contains the column names
+type csvWriter struct {
+	file *os.File
+	writer *csv.Writer
+	record [] string
+}
+
+func newCsvWriter (file *os.File, columns [] column) (*csvWriter, error) {
+
+	cw := &csvWriter { file: file, writer: csv.NewWriter (file), record: make ([] string, len (columns)) }
+
+	header := make ([] string, len (columns))
+	for c, col := range columns { header [c] = col.name }
+	if err := cw.writer.Write (header); err != nil { file.Close (); return nil, err }
+
+	return cw, nil
+}
+
+func (cw *csvWriter) WriteRow (row [] interface {}) error {
+
+	for c, value := range row {
+		switch v := value.(type) {
+			case string: cw.record [c] = v
+			case int64: cw.record [c] = strconv.FormatInt (v, 10)
+			case bool: cw.record [c] = strconv.FormatBool (v)
+		}
+	}
+
+	return cw.writer.Write (cw.record)
+}
+
+func (cw *csvWriter) Close () error {
+	cw.writer.Flush ()
+	if err := cw.writer.Error (); err != nil { cw.file.Close (); return err }
+	return cw.file.Close ()
+}
+
+// one json object per line, with the column names as the keys
+type ndjsonWriter struct {
+	file *os.File
+	buffered *bufio.Writer
+	columns [] column
+}
+
+func (nw *ndjsonWriter) WriteRow (row [] interface {}) error {
+
+	object := make (map [string] interface {}, len (row))
+	for c, value := range row { object [nw.columns [c].name] = value }
+
+	rowBytes, err := json.Marshal (object)
+	if err != nil { return err }
+
+	if _, err := nw.buffered.Write (rowBytes); err != nil { return err }
+	return nw.buffered.WriteByte ('\n')
+}
+
+func (nw *ndjsonWriter) Close () error {
+	if err := nw.buffered.Flush (); err != nil { nw.file.Close (); return err }
+	return nw.file.Close ()
+}
+
+type bufferedFile struct {
+	file *os.File
+	buffered *bufio.Writer
+}
+
+func (bf *bufferedFile) Write (data [] byte) (int, error) {
+	return bf.buffered.Write (data)
+}
+
+func (bf *bufferedFile) Close () error {
+	if err := bf.buffered.Flush (); err != nil { bf.file.Close (); return err }
+	return bf.file.Close ()
+}
@@ -2,9 +2,20 @@ module github.com/btc-script-explorer/scantool
 
 go 1.20
 
-require github.com/go-echarts/go-echarts/v2 v2.2.6
+require (
+	github.com/go-echarts/go-echarts/v2 v2.2.6
+	github.com/graph-gophers/graphql-go v1.5.0
+	github.com/lightninglabs/gozmq v0.0.0-20191113021534-d20a764486bf
+	github.com/shopspring/decimal v1.3.1
+	go.etcd.io/bbolt v1.3.8
+	google.golang.org/grpc v1.59.0
+	google.golang.org/protobuf v1.31.0
+)
 
 require (
-	github.com/shopspring/decimal v1.3.1 // indirect
-	github.com/stretchr/testify v1.7.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20231002182017-d307bd883b97 // indirect
 )
@@ -0,0 +1,105 @@
+package graphql
+
+import (
+	"fmt"
+	"context"
+	"errors"
+	"encoding/json"
+	"net/http"
+
+	gqlgo "github.com/graph-gophers/graphql-go"
+
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+// queries can be sent to /graphql as a json POST request with query, operationName and variables
+// or as a GET request with the same parameters in the query string, in which case variables is a json object
+
+// deeply nested queries can request a very large number of transactions, such as every previous block of the chain
+// the depth does not limit how many transactions each level can request, so the lookup limits that, see MAX_QUERY_TXS
+const MAX_QUERY_DEPTH = 12
+
+var schema = gqlgo.MustParseSchema (schemaString, &queryResolver {}, gqlgo.MaxDepth (MAX_QUERY_DEPTH))
+
+type graphqlRequest struct {
+	Query string `json:"query"`
+	OperationName string `json:"operationName"`
+	Variables map [string] interface {} `json:"variables"`
+}
+
+type lookupKey struct {
+}
+
+func getLookup (ctx context.Context) *txLookup {
+	return ctx.Value (lookupKey {}).(*txLookup)
+}
+
+func GraphqlHandler (response http.ResponseWriter, request *http.Request) {
+
+	if request.Method != "GET" && request.Method != "POST" {
+		response.Header ().Set ("Allow", "GET, POST")
+		writeGraphqlError (response, http.StatusMethodNotAllowed, "graphql queries must be sent as a GET or POST request.")
+		return
+	}
+
+	query, err := getGraphqlRequest (request)
+	if err != nil {
+		fmt.Println (err.Error ())
+		writeGraphqlError (response, http.StatusBadRequest, err.Error ())
+		return
+	}
+
+	nodeProxy, err := node.GetNodeProxy ()
+	if err != nil {
+		fmt.Println (err.Error ())
+		writeGraphqlError (response, http.StatusServiceUnavailable, "node is not available")
+		return
+	}
+
+	// the lookup belongs to this query, so transactions are only shared between the fields of one query
+	ctx := context.WithValue (request.Context (), lookupKey {}, newTxLookup (nodeProxy))
+
+	graphqlResponse := schema.Exec (ctx, query.Query, query.OperationName, query.Variables)
+	responseBytes, err := json.Marshal (graphqlResponse)
+	if err != nil {
+		fmt.Println (err.Error ())
+		writeGraphqlError (response, http.StatusInternalServerError, err.Error ())
+		return
+	}
+
+	response.Header ().Set ("Content-Type", "application/json")
+	response.Write (responseBytes)
+}
+
+// returns the query from the body of a POST request or the query string of a GET request
+func getGraphqlRequest (request *http.Request) (graphqlRequest, error) {
+
+	query := graphqlRequest {}
+
+	if request.Method == "POST" {
+		err := json.NewDecoder (request.Body).Decode (&query)
+		if err != nil { return query, err }
+	} else {
+		queryParams := request.URL.Query ()
+		query.Query = queryParams.Get ("query")
+		query.OperationName = queryParams.Get ("operationName")
+		if variables := queryParams.Get ("variables"); len (variables) > 0 {
+			err := json.Unmarshal ([] byte (variables), &query.Variables)
+			if err != nil { return query, fmt.Errorf ("variables is not a valid json object: %s", err.Error ()) }
+		}
+	}
+
+	if len (query.Query) == 0 { return query, errors.New ("query is required") }
+
+	return query, nil
+}
+
+// errors that occur before the query is run are returned in the same format as query errors
+func writeGraphqlError (response http.ResponseWriter, statusCode int, message string) {
+
+	responseBytes, _ := json.Marshal (map [string] interface {} { "errors": [] map [string] string { { "message": message } } })
+
+	response.Header ().Set ("Content-Type", "application/json")
+	response.WriteHeader (statusCode)
+	response.Write (responseBytes)
+}
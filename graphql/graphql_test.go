@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"encoding/json"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node/nodetest"
+)
+
+type testGraphqlResponse struct {
+	Data map [string] interface {} `json:"data"`
+	Errors [] struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func runTestQuery (t *testing.T, query string) testGraphqlResponse {
+	t.Helper ()
+
+	nodetest.Start ()
+
+	requestBytes, _ := json.Marshal (graphqlRequest { Query: query })
+	recorder := httptest.NewRecorder ()
+	GraphqlHandler (recorder, httptest.NewRequest ("POST", "/graphql", strings.NewReader (string (requestBytes))))
+
+	var response testGraphqlResponse
+	if err := json.Unmarshal (recorder.Body.Bytes (), &response); err != nil { t.Fatalf ("%s: %s", err.Error (), recorder.Body.String ()) }
+	return response
+}
+
+// returns the value at a path of field names and list indexes in the response data
+func getTestValue (t *testing.T, data interface {}, path ...interface {}) interface {} {
+	t.Helper ()
+
+	for _, p := range path {
+		switch key := p.(type) {
+			case string:
+				object, isObject := data.(map [string] interface {})
+				if !isObject { t.Fatalf ("%v is not an object at %v", data, p) }
+				data = object [key]
+			case int:
+				list, isList := data.([] interface {})
+				if !isList || key >= len (list) { t.Fatalf ("%v has no item %d", data, key) }
+				data = list [key]
+		}
+	}
+
+	return data
+}
+
+func TestGraphqlBlock (t *testing.T) {
+
+	chain, _ := nodetest.Start ()
+	blockHash, fundingTxId, spendingTxId := addTestBlock (t, chain)
+
+	response := runTestQuery (t, `{ block (hash: "` + blockHash + `") { hash height txCount txIds txs (offset: 1) { id status coinbase inputs { index spendType previousOutput { txId index value } } outputs { value outputType } } previousBlock { hash } nextBlock { hash } } }`)
+	if len (response.Errors) > 0 { t.Fatal (response.Errors [0].Message) }
+
+	block := response.Data ["block"]
+	if getTestValue (t, block, "hash") != blockHash || getTestValue (t, block, "height") != float64 (chain.GetHeight ()) || getTestValue (t, block, "txCount") != float64 (3) { t.Errorf ("block %v", block) }
+	if getTestValue (t, block, "previousBlock", "hash") != chain.GetBlockHash (chain.GetHeight () - 1) || getTestValue (t, block, "nextBlock") != nil { t.Errorf ("block %v", block) }
+
+	// the coinbase is skipped
+	txs := getTestValue (t, block, "txs").([] interface {})
+	if len (txs) != 2 || getTestValue (t, txs, 0, "id") != fundingTxId || getTestValue (t, txs, 1, "id") != spendingTxId { t.Fatalf ("txs %v", txs) }
+	if getTestValue (t, txs, 1, "status") != "confirmed" || getTestValue (t, txs, 1, "coinbase") != false { t.Errorf ("tx %v", txs [1]) }
+
+	previousOutput := getTestValue (t, txs, 1, "inputs", 1, "previousOutput")
+	if getTestValue (t, previousOutput, "txId") != fundingTxId || getTestValue (t, previousOutput, "index") != float64 (1) || getTestValue (t, previousOutput, "value") != float64 (1000000000) { t.Errorf ("previous output %v", previousOutput) }
+	if getTestValue (t, txs, 0, "outputs", 0, "outputType") != btc.OUTPUT_TYPE_P2WPKH || getTestValue (t, txs, 0, "outputs", 0, "value") != float64 (3000000000) { t.Errorf ("outputs %v", getTestValue (t, txs, 0, "outputs")) }
+
+	// the tip is returned without a hash or height, and blocks that are not found are null
+	response = runTestQuery (t, `{ tip: block { hash } unknown: block (hash: "` + strings.Repeat ("0", 64) + `") { hash } }`)
+	if len (response.Errors) > 0 || getTestValue (t, response.Data, "tip", "hash") != blockHash || response.Data ["unknown"] != nil { t.Errorf ("response %v", response) }
+}
+
+func TestGraphqlTxs (t *testing.T) {
+
+	chain, _ := nodetest.Start ()
+	blockHash, fundingTxId, spendingTxId := addTestBlock (t, chain)
+	unknownTxId := strings.Repeat ("ab", 32)
+
+	response := runTestQuery (t, `{ tx (id: "` + spendingTxId + `") { inputCount input (index: 0) { previousOutputTxId } output (index: 0) { value spentBy { spent } } block { hash } }
+									txs (ids: [ "` + fundingTxId + `", "` + unknownTxId + `" ]) { id outputs { spentBy { spent txId inputIndex } } }
+									output (txId: "` + fundingTxId + `", outputIndex: 1) { value outputScript { hex opcodes } } }`)
+	if len (response.Errors) > 0 { t.Fatal (response.Errors [0].Message) }
+
+	tx := response.Data ["tx"]
+	if getTestValue (t, tx, "inputCount") != float64 (2) || getTestValue (t, tx, "input", "previousOutputTxId") != fundingTxId || getTestValue (t, tx, "block", "hash") != blockHash { t.Errorf ("tx %v", tx) }
+	if getTestValue (t, tx, "output", "value") != float64 (3900000000) || getTestValue (t, tx, "output", "spentBy", "spent") != false { t.Errorf ("output %v", getTestValue (t, tx, "output")) }
+
+	// transactions that are not found are null
+	txs := response.Data ["txs"].([] interface {})
+	if len (txs) != 2 || getTestValue (t, txs, 0, "id") != fundingTxId || txs [1] != nil { t.Fatalf ("txs %v", txs) }
+	spentBy := getTestValue (t, txs, 0, "outputs", 1, "spentBy")
+	if getTestValue (t, spentBy, "spent") != true || getTestValue (t, spentBy, "txId") != spendingTxId || getTestValue (t, spentBy, "inputIndex") != float64 (1) { t.Errorf ("spent by %v", spentBy) }
+
+	output := response.Data ["output"]
+	if getTestValue (t, output, "value") != float64 (1000000000) || getTestValue (t, output, "outputScript", "hex") != "51" { t.Errorf ("output %v", output) }
+
+	for name, query := range map [string] string {	"invalid tx id": `{ tx (id: "abc") { id } }`,
+													"invalid tx ids": `{ txs (ids: [ "abc" ]) { id } }`,
+													"negative output index": `{ output (txId: "` + fundingTxId + `", outputIndex: -1) { value } }`,
+													"negative height": `{ block (height: -1) { hash } }`,
+													"limit": `{ block { txs (limit: 1001) { id } } }`,
+													"depth": `{ block { previousBlock { previousBlock { previousBlock { previousBlock { previousBlock { previousBlock { previousBlock { previousBlock { previousBlock { previousBlock { previousBlock { previousBlock { hash } } } } } } } } } } } } } }` } {
+		if response := runTestQuery (t, query); len (response.Errors) == 0 { t.Errorf ("%s: no error", name) }
+	}
+}
+
+// a query that would request too many transactions returns an error for the fields that would request them
+func TestGraphqlTxLimit (t *testing.T) {
+
+	chain, nodeProxy := nodetest.Start ()
+	_, fundingTxId, spendingTxId := addTestBlock (t, chain)
+
+	lookup := newTxLookup (nodeProxy)
+	lookup.getTxs ([] string { fundingTxId, spendingTxId })
+	lookup.requestCount = MAX_QUERY_TXS
+
+	// the transactions that were already requested can still be used, but the coinbase the funding transaction spends can not be requested
+	query := `{ tx (id: "` + fundingTxId + `") { id inputs { spendType } } spending: tx (id: "` + spendingTxId + `") { id } }`
+	response := schema.Exec (context.WithValue (context.Background (), lookupKey {}, lookup), query, "", nil)
+	if len (response.Errors) == 0 || !strings.Contains (response.Errors [0].Message, "transactions") { t.Fatalf ("errors %v", response.Errors) }
+
+	var data map [string] interface {}
+	if err := json.Unmarshal (response.Data, &data); err != nil { t.Fatal (err) }
+	if getTestValue (t, data, "spending", "id") != spendingTxId { t.Errorf ("data %v", data) }
+}
+
+func TestGraphqlRequests (t *testing.T) {
+
+	network := btc.GetNetwork ()
+	nodetest.Start ()
+
+	query := url.Values { "query": { `query ($id: String!) { tx (id: $id) { id } }` }, "variables": { `{ "id": "` + network.GetGenesisTxId () + `" }` } }
+	recorder := httptest.NewRecorder ()
+	GraphqlHandler (recorder, httptest.NewRequest ("GET", "/graphql?" + query.Encode (), nil))
+	if recorder.Code != http.StatusOK || !strings.Contains (recorder.Body.String (), network.GetGenesisTxId ()) { t.Errorf ("GET: status %d, %s", recorder.Code, recorder.Body.String ()) }
+
+	for name, test := range map [string] struct {
+		request *http.Request
+		statusCode int
+	} {	"no query": { httptest.NewRequest ("GET", "/graphql", nil), http.StatusBadRequest },
+		"invalid variables": { httptest.NewRequest ("GET", "/graphql?query=%7B%7D&variables=x", nil), http.StatusBadRequest },
+		"invalid json": { httptest.NewRequest ("POST", "/graphql", strings.NewReader ("{")), http.StatusBadRequest },
+		"method": { httptest.NewRequest ("PUT", "/graphql", nil), http.StatusMethodNotAllowed } } {
+		recorder := httptest.NewRecorder ()
+		GraphqlHandler (recorder, test.request)
+		if recorder.Code != test.statusCode || !strings.Contains (recorder.Body.String (), `"errors"`) { t.Errorf ("%s: status %d, %s", name, recorder.Code, recorder.Body.String ()) }
+	}
+}
@@ -0,0 +1,133 @@
+package graphql
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+// each query has its own lookup, so every transaction is requested from the node once per query
+// transactions that are needed together, such as the previous outputs of every input in a block, are requested at the same time
+
+const MAX_CONCURRENT_LOOKUPS = 8
+
+// the depth limit does not limit how many transactions the fields at each level can request,
+// such as the previous outputs of every transaction in a block, in every previous block
+const MAX_QUERY_TXS = 10000
+
+type txLookup struct {
+	nodeProxy *node.NodeProxy
+	txs map [string] btc.Tx
+	requestCount int
+	mutex sync.Mutex
+}
+
+func newTxLookup (nodeProxy *node.NodeProxy) *txLookup {
+	return &txLookup { nodeProxy: nodeProxy, txs: make (map [string] btc.Tx) }
+}
+
+// transactions that are not found are remembered too
+func (tl *txLookup) getTx (txId string) (btc.Tx, error) {
+	txs, err := tl.getTxs ([] string { txId })
+	if err != nil { return btc.Tx {}, err }
+	return txs [0], nil
+}
+
+// returns the transactions in the order they were requested
+// the ones that have not been requested yet are requested at the same time, unless that would be more than the query is allowed
+func (tl *txLookup) getTxs (txIds [] string) ([] btc.Tx, error) {
+
+	missing := make (map [string] bool)
+	tl.mutex.Lock ()
+	for _, txId := range txIds {
+		if _, found := tl.txs [txId]; !found { missing [txId] = true }
+	}
+	if tl.requestCount + len (missing) > MAX_QUERY_TXS {
+		tl.mutex.Unlock ()
+		return nil, fmt.Errorf ("the query requests more than %d transactions", MAX_QUERY_TXS)
+	}
+	tl.requestCount += len (missing)
+	tl.mutex.Unlock ()
+
+	var wg sync.WaitGroup
+	slots := make (chan bool, MAX_CONCURRENT_LOOKUPS)
+	for txId, _ := range missing {
+		wg.Add (1)
+		slots <- true
+		go func (txId string) {
+			defer wg.Done ()
+			tx := tl.nodeProxy.GetTx (node.TxRequest { TxId: txId })
+
+			tl.mutex.Lock ()
+			tl.txs [txId] = tx
+			tl.mutex.Unlock ()
+			<- slots
+		} (txId)
+	}
+	wg.Wait ()
+
+	txs := make ([] btc.Tx, len (txIds))
+	tl.mutex.Lock ()
+	for t, txId := range txIds { txs [t] = tl.txs [txId] }
+	tl.mutex.Unlock ()
+
+	return txs, nil
+}
+
+func (tl *txLookup) getOutput (txId string, outputIndex uint16) (btc.Output, error) {
+
+	tx, err := tl.getTx (txId)
+	if err != nil || tx.IsNil () || outputIndex >= tx.GetOutputCount () { return btc.Output {}, err }
+
+	return tx.GetOutput (outputIndex), nil
+}
+
+// transactions that were returned together share one request for the previous outputs of all of their inputs
+// the previous outputs are only requested if a field that needs them is
+// the transactions are shared with the lookup and the cache, so the previous outputs are set on copies of them
+type txGroup struct {
+	lookup *txLookup
+	txs [] btc.Tx
+	txsWithPreviousOutputs [] btc.Tx
+	previousOutputsErr error
+	previousOutputsOnce sync.Once
+}
+
+func newTxGroup (lookup *txLookup, txs [] btc.Tx) *txGroup {
+	return &txGroup { lookup: lookup, txs: txs }
+}
+
+func (tg *txGroup) getTxWithPreviousOutputs (txIndex int) (*btc.Tx, error) {
+	tg.loadPreviousOutputs ()
+	if tg.previousOutputsErr != nil { return nil, tg.previousOutputsErr }
+	return &tg.txsWithPreviousOutputs [txIndex], nil
+}
+
+func (tg *txGroup) loadPreviousOutputs () {
+
+	tg.previousOutputsOnce.Do (func () {
+
+		previousTxIds := make ([] string, 0)
+		for _, tx := range tg.txs {
+			if tx.IsNil () || tx.IsCoinbase () { continue }
+			for _, input := range tx.GetInputs () { previousTxIds = append (previousTxIds, input.GetPreviousOutputTxId ()) }
+		}
+
+		if _, err := tg.lookup.getTxs (previousTxIds); err != nil { tg.previousOutputsErr = err; return }
+
+		txs := make ([] btc.Tx, len (tg.txs))
+		for t, tx := range tg.txs {
+			txs [t] = tx
+			if tx.IsNil () || tx.IsCoinbase () { continue }
+
+			txs [t] = tx.Copy ()
+			for i, input := range tx.GetInputs () {
+				previousOutput, _ := tg.lookup.getOutput (input.GetPreviousOutputTxId (), input.GetPreviousOutputIndex ())
+				txs [t].SetPreviousOutput (uint16 (i), previousOutput)
+			}
+		}
+		tg.txsWithPreviousOutputs = txs
+	})
+}
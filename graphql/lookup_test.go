@@ -0,0 +1,102 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btc-script-explorer/scantool/btc/node/nodetest"
+)
+
+const testP2wpkhScriptHex = "0014" + "4444444444444444444444444444444444444444"
+
+// adds a block with a transaction that spends the coinbase of the tip and one that spends both of its outputs
+func addTestBlock (t *testing.T, chain *nodetest.Chain) (string, string, string) {
+	t.Helper ()
+
+	coinbaseTxId := chain.GetTxIds (chain.GetBlockHash (chain.GetHeight ())) [0]
+	fundingTxHex := nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: coinbaseTxId, Index: 0 } }, [] nodetest.TxOutput { { Value: 3000000000, ScriptHex: testP2wpkhScriptHex }, { Value: 1000000000, ScriptHex: "51" } })
+	fundingTxId := nodetest.GetTxId (fundingTxHex)
+	spendingTxHex := nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: fundingTxId, Index: 0 }, { TxId: fundingTxId, Index: 1 } }, [] nodetest.TxOutput { { Value: 3900000000, ScriptHex: testP2wpkhScriptHex } })
+
+	return chain.AddBlock (fundingTxHex, spendingTxHex), fundingTxId, nodetest.GetTxId (spendingTxHex)
+}
+
+func TestTxLookup (t *testing.T) {
+
+	chain, nodeProxy := nodetest.Start ()
+	_, fundingTxId, spendingTxId := addTestBlock (t, chain)
+	unknownTxId := strings.Repeat ("ab", 32)
+
+	// the transactions are returned in order, and each one is requested once
+	lookup := newTxLookup (nodeProxy)
+	txs, err := lookup.getTxs ([] string { spendingTxId, fundingTxId, spendingTxId, unknownTxId })
+	if err != nil { t.Fatal (err) }
+	if len (txs) != 4 || txs [0].GetTxId () != spendingTxId || txs [1].GetTxId () != fundingTxId || txs [2].GetTxId () != spendingTxId || !txs [3].IsNil () { t.Fatalf ("txs %v", txs) }
+	if lookup.requestCount != 3 { t.Errorf ("%d requests", lookup.requestCount) }
+
+	// transactions that were not found are not requested again
+	tx, err := lookup.getTx (unknownTxId)
+	if err != nil || !tx.IsNil () || lookup.requestCount != 3 { t.Errorf ("error %v, %d requests", err, lookup.requestCount) }
+
+	output, err := lookup.getOutput (fundingTxId, 1)
+	if err != nil || output.GetValue () != 1000000000 { t.Errorf ("error %v, value %d", err, output.GetValue ()) }
+	output, err = lookup.getOutput (fundingTxId, 2)
+	if err != nil || len (output.GetOutputType ()) > 0 { t.Errorf ("error %v, output type %s", err, output.GetOutputType ()) }
+}
+
+func TestTxLookupLimit (t *testing.T) {
+
+	chain, nodeProxy := nodetest.Start ()
+	_, fundingTxId, spendingTxId := addTestBlock (t, chain)
+
+	lookup := newTxLookup (nodeProxy)
+	lookup.requestCount = MAX_QUERY_TXS - 1
+
+	if _, err := lookup.getTxs ([] string { fundingTxId, spendingTxId }); err == nil { t.Error ("more than the limit was requested") }
+	if lookup.requestCount != MAX_QUERY_TXS - 1 { t.Errorf ("%d requests", lookup.requestCount) }
+
+	// transactions that were already requested do not count again
+	if _, err := lookup.getTxs ([] string { fundingTxId, fundingTxId }); err != nil { t.Fatal (err) }
+	if _, err := lookup.getTxs ([] string { fundingTxId }); err != nil { t.Error (err) }
+	if _, err := lookup.getTx (spendingTxId); err == nil { t.Error ("more than the limit was requested") }
+}
+
+// the previous outputs of the whole group are requested together, and only once
+func TestTxGroupPreviousOutputs (t *testing.T) {
+
+	chain, nodeProxy := nodetest.Start ()
+	_, fundingTxId, spendingTxId := addTestBlock (t, chain)
+
+	lookup := newTxLookup (nodeProxy)
+	txs, err := lookup.getTxs ([] string { fundingTxId, spendingTxId })
+	if err != nil { t.Fatal (err) }
+
+	group := newTxGroup (lookup, txs)
+	spendingTx, err := group.getTxWithPreviousOutputs (1)
+	if err != nil { t.Fatal (err) }
+
+	// the funding transaction was already in the lookup, so only the coinbase it spends was requested
+	if lookup.requestCount != 3 { t.Errorf ("%d requests", lookup.requestCount) }
+	for i, expectedValue := range [] uint64 { 3000000000, 1000000000 } {
+		input := spendingTx.GetInput (uint16 (i))
+		previousOutput := input.GetPreviousOutput ()
+		if previousOutput.GetValue () != expectedValue { t.Errorf ("input %d previous output value %d", i, previousOutput.GetValue ()) }
+	}
+
+	fundingTx, err := group.getTxWithPreviousOutputs (0)
+	if err != nil { t.Fatal (err) }
+	input := fundingTx.GetInput (0)
+	previousOutput := input.GetPreviousOutput ()
+	if previousOutput.GetValue () != 5000000000 || lookup.requestCount != 3 { t.Errorf ("previous output value %d, %d requests", previousOutput.GetValue (), lookup.requestCount) }
+
+	// the transactions in the group are not changed
+	input = group.txs [1].GetInput (0)
+	previousOutput = input.GetPreviousOutput ()
+	if len (previousOutput.GetOutputType ()) > 0 { t.Error ("the previous output was set on the shared transaction") }
+
+	// the group fails if the previous outputs would be more than the limit
+	lookup = newTxLookup (nodeProxy)
+	txs, _ = lookup.getTxs ([] string { spendingTxId })
+	lookup.requestCount = MAX_QUERY_TXS
+	if _, err := newTxGroup (lookup, txs).getTxWithPreviousOutputs (0); err == nil { t.Error ("more than the limit was requested") }
+}
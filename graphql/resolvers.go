@@ -0,0 +1,386 @@
+package graphql
+
+import (
+	"fmt"
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+	"github.com/btc-script-explorer/scantool/index"
+)
+
+// the resolvers wrap the btc types, and everything they need from the node is requested through the query's lookup
+
+// no more than this many transactions can be requested from a block at a time
+const MAX_BLOCK_TXS = 1000
+
+type queryResolver struct {
+}
+
+type blockArgs struct {
+	Hash *string
+	Height *int32
+}
+
+func (qr *queryResolver) Block (ctx context.Context, args blockArgs) (*blockResolver, error) {
+
+	blockRequest := node.BlockRequest {}
+	if args.Hash != nil {
+		if len (*args.Hash) != 64 { return nil, errors.New ("hash is not a valid block hash") }
+		blockRequest.BlockKey = *args.Hash
+	} else if args.Height != nil {
+		if *args.Height < 0 { return nil, errors.New ("height must not be negative") }
+		blockRequest.BlockKey = strconv.Itoa (int (*args.Height))
+	}
+
+	return newBlockResolver (getLookup (ctx), blockRequest), nil
+}
+
+type txArgs struct {
+	Id string
+}
+
+func (qr *queryResolver) Tx (ctx context.Context, args txArgs) (*txResolver, error) {
+
+	if len (args.Id) != 64 { return nil, errors.New ("id is not a valid transaction id") }
+
+	lookup := getLookup (ctx)
+	tx, err := lookup.getTx (args.Id)
+	if err != nil { return nil, err }
+
+	return newTxGroup (lookup, [] btc.Tx { tx }).getTxResolver (0), nil
+}
+
+type txsArgs struct {
+	Ids [] string
+}
+
+// transactions that are not found are null
+func (qr *queryResolver) Txs (ctx context.Context, args txsArgs) ([] *txResolver, error) {
+
+	if len (args.Ids) > MAX_BLOCK_TXS { return nil, fmt.Errorf ("no more than %d ids can be requested at a time", MAX_BLOCK_TXS) }
+	for _, txId := range args.Ids {
+		if len (txId) != 64 { return nil, fmt.Errorf ("%s is not a valid transaction id", txId) }
+	}
+
+	lookup := getLookup (ctx)
+	txs, err := lookup.getTxs (args.Ids)
+	if err != nil { return nil, err }
+
+	return newTxGroup (lookup, txs).getTxResolvers (), nil
+}
+
+type outputArgs struct {
+	TxId string
+	OutputIndex int32
+}
+
+func (qr *queryResolver) Output (ctx context.Context, args outputArgs) (*outputResolver, error) {
+
+	if len (args.TxId) != 64 { return nil, errors.New ("txId is not a valid transaction id") }
+	if args.OutputIndex < 0 { return nil, errors.New ("outputIndex must not be negative") }
+
+	return newOutputResolver (getLookup (ctx), args.TxId, uint16 (args.OutputIndex))
+}
+
+// block
+
+type blockResolver struct {
+	lookup *txLookup
+	block btc.Block
+}
+
+// returns nil if the block is not found
+func newBlockResolver (lookup *txLookup, blockRequest node.BlockRequest) *blockResolver {
+
+	block := lookup.nodeProxy.GetBlock (blockRequest)
+	if block.IsNil () { return nil }
+
+	return &blockResolver { lookup: lookup, block: block }
+}
+
+func (br *blockResolver) Hash () string { return br.block.GetHash () }
+func (br *blockResolver) PreviousHash () string { return br.block.GetPreviousHash () }
+func (br *blockResolver) NextHash () string { return br.block.GetNextHash () }
+func (br *blockResolver) Height () int32 { return int32 (br.block.GetHeight ()) }
+func (br *blockResolver) Version () int32 { return br.block.GetVersion () }
+func (br *blockResolver) Timestamp () float64 { return float64 (br.block.GetTimestamp ()) }
+func (br *blockResolver) MedianTime () float64 { return float64 (br.block.GetMedianTime ()) }
+func (br *blockResolver) MerkleRoot () string { return br.block.GetMerkleRoot () }
+func (br *blockResolver) Bits () string { return br.block.GetBits () }
+func (br *blockResolver) Nonce () float64 { return float64 (br.block.GetNonce ()) }
+func (br *blockResolver) Difficulty () float64 { return br.block.GetDifficulty () }
+func (br *blockResolver) Chainwork () string { return br.block.GetChainwork () }
+func (br *blockResolver) Size () int32 { return int32 (br.block.GetSize ()) }
+func (br *blockResolver) StrippedSize () int32 { return int32 (br.block.GetStrippedSize ()) }
+func (br *blockResolver) Weight () int32 { return int32 (br.block.GetWeight ()) }
+func (br *blockResolver) TxCount () int32 { return int32 (br.block.GetTxCount ()) }
+func (br *blockResolver) TxIds () [] string { return br.block.GetTxIds () }
+
+type blockTxsArgs struct {
+	Offset int32
+	Limit int32
+}
+
+// the transactions in the range are requested at the same time, and so are the previous outputs of all of their inputs
+func (br *blockResolver) Txs (args blockTxsArgs) ([] *txResolver, error) {
+
+	if args.Offset < 0 { return nil, errors.New ("offset must not be negative") }
+	if args.Limit < 0 || args.Limit > MAX_BLOCK_TXS { return nil, fmt.Errorf ("limit must be between 0 and %d", MAX_BLOCK_TXS) }
+
+	txIds := br.block.GetTxIds ()
+	start := int (args.Offset)
+	if start > len (txIds) { start = len (txIds) }
+	end := start + int (args.Limit)
+	if end > len (txIds) { end = len (txIds) }
+
+	txs, err := br.lookup.getTxs (txIds [start : end])
+	if err != nil { return nil, err }
+
+	return newTxGroup (br.lookup, txs).getTxResolvers (), nil
+}
+
+func (br *blockResolver) PreviousBlock () *blockResolver {
+	if br.block.GetHeight () == 0 { return nil }
+	return newBlockResolver (br.lookup, node.BlockRequest { BlockKey: br.block.GetPreviousHash () })
+}
+
+func (br *blockResolver) NextBlock () *blockResolver {
+	if len (br.block.GetNextHash ()) == 0 { return nil }
+	return newBlockResolver (br.lookup, node.BlockRequest { BlockKey: br.block.GetNextHash () })
+}
+
+// transaction
+
+type txResolver struct {
+	group *txGroup
+	txIndex int
+}
+
+// transactions that are not found are nil
+func (tg *txGroup) getTxResolver (txIndex int) *txResolver {
+	if tg.txs [txIndex].IsNil () { return nil }
+	return &txResolver { group: tg, txIndex: txIndex }
+}
+
+func (tg *txGroup) getTxResolvers () [] *txResolver {
+	txResolvers := make ([] *txResolver, len (tg.txs))
+	for t, _ := range tg.txs { txResolvers [t] = tg.getTxResolver (t) }
+	return txResolvers
+}
+
+func (tr *txResolver) tx () *btc.Tx {
+	return &tr.group.txs [tr.txIndex]
+}
+
+func (tr *txResolver) Id () string { return tr.tx ().GetTxId () }
+func (tr *txResolver) Version () float64 { return float64 (tr.tx ().GetVersion ()) }
+func (tr *txResolver) LockTime () float64 { return float64 (tr.tx ().GetLockTime ()) }
+func (tr *txResolver) Coinbase () bool { return tr.tx ().IsCoinbase () }
+func (tr *txResolver) Bip141 () bool { return tr.tx ().SupportsBip141 () }
+func (tr *txResolver) BlockHash () string { return tr.tx ().GetBlockHash () }
+func (tr *txResolver) BlockTime () float64 { return float64 (tr.tx ().GetBlockTime ()) }
+func (tr *txResolver) InputCount () int32 { return int32 (tr.tx ().GetInputCount ()) }
+func (tr *txResolver) OutputCount () int32 { return int32 (tr.tx ().GetOutputCount ()) }
+
+func (tr *txResolver) Status () string {
+	if tr.tx ().IsConfirmed () { return "confirmed" }
+	return "unconfirmed"
+}
+
+func (tr *txResolver) Block () *blockResolver {
+	if !tr.tx ().IsConfirmed () { return nil }
+	return newBlockResolver (tr.group.lookup, node.BlockRequest { BlockKey: tr.tx ().GetBlockHash () })
+}
+
+func (tr *txResolver) Inputs () [] *inputResolver {
+	inputs := make ([] *inputResolver, tr.tx ().GetInputCount ())
+	for i, _ := range inputs { inputs [i] = &inputResolver { tx: tr, inputIndex: uint16 (i) } }
+	return inputs
+}
+
+func (tr *txResolver) Outputs () [] *outputResolver {
+	outputs := make ([] *outputResolver, tr.tx ().GetOutputCount ())
+	for o, output := range tr.tx ().GetOutputs () { outputs [o] = &outputResolver { lookup: tr.group.lookup, txId: tr.Id (), outputIndex: uint16 (o), output: output } }
+	return outputs
+}
+
+type indexArgs struct {
+	Index int32
+}
+
+func (tr *txResolver) Input (args indexArgs) *inputResolver {
+	if args.Index < 0 || args.Index >= int32 (tr.tx ().GetInputCount ()) { return nil }
+	return &inputResolver { tx: tr, inputIndex: uint16 (args.Index) }
+}
+
+func (tr *txResolver) Output (args indexArgs) *outputResolver {
+	if args.Index < 0 || args.Index >= int32 (tr.tx ().GetOutputCount ()) { return nil }
+	return &outputResolver { lookup: tr.group.lookup, txId: tr.Id (), outputIndex: uint16 (args.Index), output: tr.tx ().GetOutput (uint16 (args.Index)) }
+}
+
+// input
+
+// the spend type, the scripts and the segwit field types depend on the previous output
+// so the previous outputs of the whole group are loaded before any of them are resolved
+type inputResolver struct {
+	tx *txResolver
+	inputIndex uint16
+}
+
+func (ir *inputResolver) input () btc.Input {
+	return ir.tx.tx ().GetInput (ir.inputIndex)
+}
+
+func (ir *inputResolver) inputWithPreviousOutput () (btc.Input, error) {
+	tx, err := ir.tx.group.getTxWithPreviousOutputs (ir.tx.txIndex)
+	if err != nil { return btc.Input {}, err }
+	return tx.GetInput (ir.inputIndex), nil
+}
+
+func (ir *inputResolver) Index () int32 { return int32 (ir.inputIndex) }
+func (ir *inputResolver) Coinbase () bool { input := ir.input (); return input.IsCoinbase () }
+func (ir *inputResolver) PreviousOutputTxId () string { input := ir.input (); return input.GetPreviousOutputTxId () }
+func (ir *inputResolver) PreviousOutputIndex () int32 { input := ir.input (); return int32 (input.GetPreviousOutputIndex ()) }
+func (ir *inputResolver) Sequence () float64 { input := ir.input (); return float64 (input.GetSequence ()) }
+func (ir *inputResolver) SpendType () (string, error) {
+	input, err := ir.inputWithPreviousOutput ()
+	if err != nil { return "", err }
+	return input.GetSpendType (), nil
+}
+
+func (ir *inputResolver) PreviousOutput () (*outputResolver, error) {
+	input, err := ir.inputWithPreviousOutput ()
+	if err != nil || input.IsCoinbase () { return nil, err }
+
+	previousOutput := input.GetPreviousOutput ()
+	if len (previousOutput.GetOutputType ()) == 0 { return nil, nil }
+
+	return &outputResolver { lookup: ir.tx.group.lookup, txId: input.GetPreviousOutputTxId (), outputIndex: input.GetPreviousOutputIndex (), output: previousOutput }, nil
+}
+
+func (ir *inputResolver) InputScript () (*scriptResolver, error) {
+	input, err := ir.inputWithPreviousOutput ()
+	if err != nil { return nil, err }
+	return &scriptResolver { script: input.GetInputScript () }, nil
+}
+
+func (ir *inputResolver) RedeemScript () (*scriptResolver, error) {
+	input, err := ir.inputWithPreviousOutput ()
+	if err != nil || !input.HasRedeemScript () { return nil, err }
+	return &scriptResolver { script: input.GetRedeemScript () }, nil
+}
+
+func (ir *inputResolver) Segwit () (*segwitResolver, error) {
+	input, err := ir.inputWithPreviousOutput ()
+	if err != nil || !input.HasSegwitFields () { return nil, err }
+	return &segwitResolver { segwit: input.GetSegwit () }, nil
+}
+
+// output
+
+type outputResolver struct {
+	lookup *txLookup
+	txId string
+	outputIndex uint16
+	output btc.Output
+}
+
+// returns nil if the output is not found
+func newOutputResolver (lookup *txLookup, txId string, outputIndex uint16) (*outputResolver, error) {
+
+	output, err := lookup.getOutput (txId, outputIndex)
+	if err != nil || len (output.GetOutputType ()) == 0 { return nil, err }
+
+	return &outputResolver { lookup: lookup, txId: txId, outputIndex: outputIndex, output: output }, nil
+}
+
+func (or *outputResolver) TxId () string { return or.txId }
+func (or *outputResolver) Index () int32 { return int32 (or.outputIndex) }
+func (or *outputResolver) Value () float64 { return float64 (or.output.GetValue ()) }
+func (or *outputResolver) OutputType () string { return or.output.GetOutputType () }
+func (or *outputResolver) Address () string { return or.output.GetAddress () }
+func (or *outputResolver) OutputScript () *scriptResolver { return &scriptResolver { script: or.output.GetOutputScript () } }
+
+// the index is used if it is on, otherwise the node is asked
+func (or *outputResolver) SpentBy () *outputSpendResolver {
+	outputSpend := index.GetOutputSpend (node.OutputRequest { TxId: or.txId, OutputIndex: or.outputIndex })
+	if outputSpend.IsNil () { return nil }
+	return &outputSpendResolver { outputSpend: outputSpend }
+}
+
+type outputSpendResolver struct {
+	outputSpend btc.OutputSpend
+}
+
+func (osr *outputSpendResolver) Spent () bool { return osr.outputSpend.IsSpent () }
+func (osr *outputSpendResolver) TxId () string { return osr.outputSpend.GetTxId () }
+func (osr *outputSpendResolver) InputIndex () int32 { return int32 (osr.outputSpend.GetInputIndex ()) }
+func (osr *outputSpendResolver) SpendType () string { return osr.outputSpend.GetSpendType () }
+func (osr *outputSpendResolver) InMempool () bool { return osr.outputSpend.IsInMempool () }
+func (osr *outputSpendResolver) BlockHeight () float64 { return float64 (osr.outputSpend.GetBlockHeight ()) }
+
+// script
+
+type scriptResolver struct {
+	script btc.Script
+}
+
+func (sr *scriptResolver) Hex () string { return sr.script.AsHex () }
+func (sr *scriptResolver) FieldCount () int32 { return int32 (sr.script.GetFieldCount ()) }
+func (sr *scriptResolver) ParseError () bool { return sr.script.HasParseError () }
+func (sr *scriptResolver) IsOrdinal () bool { return sr.script.IsOrdinal () }
+func (sr *scriptResolver) IsMultisig () bool { return sr.script.IsMultiSigOutput () }
+func (sr *scriptResolver) Template () string { return sr.script.GetTemplate () }
+func (sr *scriptResolver) Fingerprint () string { return sr.script.GetFingerprint () }
+func (sr *scriptResolver) Opcodes () [] string { return sr.script.GetOpcodes () }
+
+func (sr *scriptResolver) Fields () [] *scriptFieldResolver {
+	fields := make ([] *scriptFieldResolver, sr.script.GetFieldCount ())
+	for f, field := range sr.script.GetFields () { fields [f] = &scriptFieldResolver { field: field } }
+	return fields
+}
+
+type scriptFieldResolver struct {
+	field btc.ScriptField
+}
+
+func (sfr *scriptFieldResolver) Hex () string { return sfr.field.AsHex () }
+func (sfr *scriptFieldResolver) Type () string { return sfr.field.AsType () }
+func (sfr *scriptFieldResolver) IsOpcode () bool { return sfr.field.IsOpcode () }
+
+// segwit
+
+type segwitResolver struct {
+	segwit btc.Segwit
+}
+
+func (sr *segwitResolver) FieldCount () int32 { return int32 (sr.segwit.GetFieldCount ()) }
+func (sr *segwitResolver) HasAnnex () bool { return sr.segwit.HasAnnex () }
+
+func (sr *segwitResolver) Fields () [] *segwitFieldResolver {
+	fields := make ([] *segwitFieldResolver, sr.segwit.GetFieldCount ())
+	for f, field := range sr.segwit.GetFields () { fields [f] = &segwitFieldResolver { field: field } }
+	return fields
+}
+
+func (sr *segwitResolver) WitnessScript () *scriptResolver {
+	witnessScript := sr.segwit.GetWitnessScript ()
+	if witnessScript.IsNil () { return nil }
+	return &scriptResolver { script: witnessScript }
+}
+
+func (sr *segwitResolver) TapScript () *scriptResolver {
+	tapScript, _ := sr.segwit.GetTapScript ()
+	if tapScript.IsNil () { return nil }
+	return &scriptResolver { script: tapScript }
+}
+
+type segwitFieldResolver struct {
+	field btc.SegwitField
+}
+
+func (sfr *segwitFieldResolver) Hex () string { return sfr.field.AsHex () }
+func (sfr *segwitFieldResolver) Type () string { return sfr.field.AsType () }
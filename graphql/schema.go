@@ -0,0 +1,121 @@
+package graphql
+
+// the schema mirrors the btc types, with the same names the rest api uses
+// values are in satoshis and are returned as floats because graphql integers are only 32 bits
+const schemaString = `
+schema {
+	query: Query
+}
+
+type Query {
+	# without a hash or height, the most recent block is returned
+	block (hash: String, height: Int): Block
+	tx (id: String!): Tx
+	txs (ids: [String!]!): [Tx]!
+	output (txId: String!, outputIndex: Int!): Output
+}
+
+type Block {
+	hash: String!
+	previousHash: String!
+	nextHash: String!
+	height: Int!
+	version: Int!
+	timestamp: Float!
+	medianTime: Float!
+	merkleRoot: String!
+	bits: String!
+	nonce: Float!
+	difficulty: Float!
+	chainwork: String!
+	size: Int!
+	strippedSize: Int!
+	weight: Int!
+	txCount: Int!
+	txIds: [String!]!
+	txs (offset: Int = 0, limit: Int = 25): [Tx]!
+	previousBlock: Block
+	nextBlock: Block
+}
+
+type Tx {
+	id: String!
+	version: Float!
+	lockTime: Float!
+	coinbase: Boolean!
+	bip141: Boolean!
+	status: String!
+	blockHash: String!
+	blockTime: Float!
+	block: Block
+	inputCount: Int!
+	outputCount: Int!
+	inputs: [Input!]!
+	outputs: [Output!]!
+	input (index: Int!): Input
+	output (index: Int!): Output
+}
+
+type Input {
+	index: Int!
+	coinbase: Boolean!
+	previousOutputTxId: String!
+	previousOutputIndex: Int!
+	previousOutput: Output
+	sequence: Float!
+	inputScript: Script!
+	redeemScript: Script
+	segwit: Segwit
+	spendType: String!
+}
+
+type Output {
+	txId: String!
+	index: Int!
+	value: Float!
+	outputType: String!
+	address: String!
+	outputScript: Script!
+	spentBy: OutputSpend
+}
+
+type OutputSpend {
+	spent: Boolean!
+	txId: String!
+	inputIndex: Int!
+	spendType: String!
+	inMempool: Boolean!
+	blockHeight: Float!
+}
+
+type Script {
+	hex: String!
+	fields: [ScriptField!]!
+	fieldCount: Int!
+	parseError: Boolean!
+	isOrdinal: Boolean!
+	isMultisig: Boolean!
+	template: String!
+	fingerprint: String!
+	opcodes: [String!]!
+}
+
+type ScriptField {
+	hex: String!
+	type: String!
+	isOpcode: Boolean!
+}
+
+type Segwit {
+	fields: [SegwitField!]!
+	fieldCount: Int!
+	witnessScript: Script
+	tapScript: Script
+	hasAnnex: Boolean!
+}
+
+type SegwitField {
+	hex: String!
+	type: String!
+}
+`
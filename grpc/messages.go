@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/grpc/pb"
+)
+
+// the btc types converted to protobuf messages, with the same fields the rest api returns
+
+func blockToMessage (block btc.Block) *pb.Block {
+	return &pb.Block {	Hash: block.GetHash (),
+						PreviousHash: block.GetPreviousHash (),
+						NextHash: block.GetNextHash (),
+						Height: block.GetHeight (),
+						Version: block.GetVersion (),
+						Timestamp: block.GetTimestamp (),
+						MedianTime: block.GetMedianTime (),
+						MerkleRoot: block.GetMerkleRoot (),
+						MerkleRootValid: block.IsMerkleRootValid (),
+						Bits: block.GetBits (),
+						Nonce: block.GetNonce (),
+						Difficulty: block.GetDifficulty (),
+						Chainwork: block.GetChainwork (),
+						Size: block.GetSize (),
+						StrippedSize: block.GetStrippedSize (),
+						Weight: block.GetWeight (),
+						TxCount: block.GetTxCount (),
+						TxIds: block.GetTxIds () }
+}
+
+func txToMessage (tx btc.Tx) *pb.Tx {
+
+	inputs := make ([] *pb.Input, tx.GetInputCount ())
+	for i, input := range tx.GetInputs () { inputs [i] = inputToMessage (input) }
+
+	outputs := make ([] *pb.Output, tx.GetOutputCount ())
+	for o, output := range tx.GetOutputs () { outputs [o] = outputToMessage (output) }
+
+	return &pb.Tx {	Id: tx.GetTxId (),
+					Version: tx.GetVersion (),
+					Inputs: inputs,
+					Outputs: outputs,
+					Locktime: tx.GetLockTime (),
+					Coinbase: tx.IsCoinbase (),
+					Bip141: tx.SupportsBip141 (),
+					BlockHash: tx.GetBlockHash (),
+					BlockTime: tx.GetBlockTime () }
+}
+
+func inputToMessage (input btc.Input) *pb.Input {
+
+	message := &pb.Input {	Coinbase: input.IsCoinbase (),
+							InputScript: scriptToMessage (input.GetInputScript ()),
+							Sequence: input.GetSequence () }
+
+	if !input.IsCoinbase () {
+		message.PreviousOutputTxId = input.GetPreviousOutputTxId ()
+		message.PreviousOutputIndex = uint32 (input.GetPreviousOutputIndex ())
+	}
+
+	segwit := input.GetSegwit ()
+	if !segwit.IsNil () { message.Segwit = segwitToMessage (segwit) }
+
+	// the previous output is only there if the input detail was requested
+	previousOutput := input.GetPreviousOutput ()
+	if len (previousOutput.GetOutputType ()) > 0 {
+		message.PreviousOutput = outputToMessage (previousOutput)
+		if input.HasRedeemScript () { message.RedeemScript = scriptToMessage (input.GetRedeemScript ()) }
+		message.SpendType = input.GetSpendType ()
+	}
+
+	return message
+}
+
+func outputToMessage (output btc.Output) *pb.Output {
+	return &pb.Output {	Value: output.GetValue (),
+						OutputScript: scriptToMessage (output.GetOutputScript ()),
+						OutputType: output.GetOutputType (),
+						Address: output.GetAddress () }
+}
+
+func scriptToMessage (script btc.Script) *pb.Script {
+
+	fields := make ([] *pb.ScriptField, script.GetFieldCount ())
+	for f, field := range script.GetFields () {
+		fields [f] = &pb.ScriptField { IsOpcode: field.IsOpcode (), Type: field.AsType () }
+		if field.IsOpcode () {
+			fields [f].Opcode = field.AsHex ()
+		} else {
+			fields [f].Data = field.AsBytes ()
+		}
+	}
+
+	return &pb.Script {	Raw: script.AsBytes (),
+						Fields: fields,
+						IsOrdinal: script.IsOrdinal (),
+						IsMultisig: script.IsMultiSigOutput (),
+						ParseError: script.HasParseError (),
+						Template: script.GetTemplate (),
+						Fingerprint: script.GetFingerprint () }
+}
+
+func segwitToMessage (segwit btc.Segwit) *pb.Segwit {
+
+	message := &pb.Segwit {}
+
+	witnessScript := segwit.GetWitnessScript ()
+	if !witnessScript.IsNil () { message.WitnessScript = scriptToMessage (witnessScript) }
+
+	cbIndex := btc.INVALID_CB_INDEX
+	tapScript, _ := segwit.GetTapScript ()
+	if !tapScript.IsNil () {
+		message.TapScript = scriptToMessage (tapScript)
+		cbIndex = segwit.GetControlBlockIndex ()
+	}
+
+	message.Fields = make ([] *pb.SegwitField, segwit.GetFieldCount ())
+	for f, field := range segwit.GetFields () {
+		message.Fields [f] = &pb.SegwitField { Data: field.AsBytes (), Type: field.AsType () }
+
+		// the same tap leaf hashes the rest api returns
+		if cbIndex != btc.INVALID_CB_INDEX && uint32 (f) == cbIndex {
+			message.Fields [f].Type = "Control Block"
+			parity, _ := segwit.GetTapTweakParity ()
+			controlBlock := &pb.ControlBlock { LeafVersion: uint32 (segwit.GetTapLeafVersion ()), Parity: uint32 (parity) }
+
+			data := field.AsBytes ()
+			for start := 1; start + 32 <= len (data); start += 32 {
+				controlBlock.TapLeafHashes = append (controlBlock.TapLeafHashes, data [start : start + 32])
+			}
+			message.Fields [f].ControlBlock = controlBlock
+		}
+	}
+
+	return message
+}
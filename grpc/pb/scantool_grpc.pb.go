@@ -0,0 +1,329 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: grpc/pb/scantool.proto
+
+// the same data model the rest api returns as json, with typed fields
+// hashes and transaction ids are hex strings in the byte order used everywhere else, scripts and data pushes are raw bytes
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Scantool_GetBlock_FullMethodName              = "/scantool.v1.Scantool/GetBlock"
+	Scantool_GetTx_FullMethodName                 = "/scantool.v1.Scantool/GetTx"
+	Scantool_GetInput_FullMethodName              = "/scantool.v1.Scantool/GetInput"
+	Scantool_GetOutput_FullMethodName             = "/scantool.v1.Scantool/GetOutput"
+	Scantool_GetCurrentBlockHeight_FullMethodName = "/scantool.v1.Scantool/GetCurrentBlockHeight"
+	Scantool_GetBlocks_FullMethodName             = "/scantool.v1.Scantool/GetBlocks"
+)
+
+// ScantoolClient is the client API for Scantool service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ScantoolClient interface {
+	// without a hash or height, the most recent block is returned
+	GetBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Block, error)
+	GetTx(ctx context.Context, in *TxRequest, opts ...grpc.CallOption) (*Tx, error)
+	GetInput(ctx context.Context, in *InputRequest, opts ...grpc.CallOption) (*Input, error)
+	GetOutput(ctx context.Context, in *OutputRequest, opts ...grpc.CallOption) (*Output, error)
+	GetCurrentBlockHeight(ctx context.Context, in *CurrentBlockHeightRequest, opts ...grpc.CallOption) (*CurrentBlockHeight, error)
+	// every block in the range, oldest first, each one followed by its transactions if they were requested
+	GetBlocks(ctx context.Context, in *BlockRangeRequest, opts ...grpc.CallOption) (Scantool_GetBlocksClient, error)
+}
+
+type scantoolClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewScantoolClient(cc grpc.ClientConnInterface) ScantoolClient {
+	return &scantoolClient{cc}
+}
+
+func (c *scantoolClient) GetBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, Scantool_GetBlock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scantoolClient) GetTx(ctx context.Context, in *TxRequest, opts ...grpc.CallOption) (*Tx, error) {
+	out := new(Tx)
+	err := c.cc.Invoke(ctx, Scantool_GetTx_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scantoolClient) GetInput(ctx context.Context, in *InputRequest, opts ...grpc.CallOption) (*Input, error) {
+	out := new(Input)
+	err := c.cc.Invoke(ctx, Scantool_GetInput_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scantoolClient) GetOutput(ctx context.Context, in *OutputRequest, opts ...grpc.CallOption) (*Output, error) {
+	out := new(Output)
+	err := c.cc.Invoke(ctx, Scantool_GetOutput_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scantoolClient) GetCurrentBlockHeight(ctx context.Context, in *CurrentBlockHeightRequest, opts ...grpc.CallOption) (*CurrentBlockHeight, error) {
+	out := new(CurrentBlockHeight)
+	err := c.cc.Invoke(ctx, Scantool_GetCurrentBlockHeight_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scantoolClient) GetBlocks(ctx context.Context, in *BlockRangeRequest, opts ...grpc.CallOption) (Scantool_GetBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Scantool_ServiceDesc.Streams[0], Scantool_GetBlocks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &scantoolGetBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Scantool_GetBlocksClient interface {
+	Recv() (*BlockRangeItem, error)
+	grpc.ClientStream
+}
+
+type scantoolGetBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *scantoolGetBlocksClient) Recv() (*BlockRangeItem, error) {
+	m := new(BlockRangeItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ScantoolServer is the server API for Scantool service.
+// All implementations must embed UnimplementedScantoolServer
+// for forward compatibility
+type ScantoolServer interface {
+	// without a hash or height, the most recent block is returned
+	GetBlock(context.Context, *BlockRequest) (*Block, error)
+	GetTx(context.Context, *TxRequest) (*Tx, error)
+	GetInput(context.Context, *InputRequest) (*Input, error)
+	GetOutput(context.Context, *OutputRequest) (*Output, error)
+	GetCurrentBlockHeight(context.Context, *CurrentBlockHeightRequest) (*CurrentBlockHeight, error)
+	// every block in the range, oldest first, each one followed by its transactions if they were requested
+	GetBlocks(*BlockRangeRequest, Scantool_GetBlocksServer) error
+	mustEmbedUnimplementedScantoolServer()
+}
+
+// UnimplementedScantoolServer must be embedded to have forward compatible implementations.
+type UnimplementedScantoolServer struct {
+}
+
+func (UnimplementedScantoolServer) GetBlock(context.Context, *BlockRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedScantoolServer) GetTx(context.Context, *TxRequest) (*Tx, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTx not implemented")
+}
+func (UnimplementedScantoolServer) GetInput(context.Context, *InputRequest) (*Input, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInput not implemented")
+}
+func (UnimplementedScantoolServer) GetOutput(context.Context, *OutputRequest) (*Output, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOutput not implemented")
+}
+func (UnimplementedScantoolServer) GetCurrentBlockHeight(context.Context, *CurrentBlockHeightRequest) (*CurrentBlockHeight, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCurrentBlockHeight not implemented")
+}
+func (UnimplementedScantoolServer) GetBlocks(*BlockRangeRequest, Scantool_GetBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetBlocks not implemented")
+}
+func (UnimplementedScantoolServer) mustEmbedUnimplementedScantoolServer() {}
+
+// UnsafeScantoolServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ScantoolServer will
+// result in compilation errors.
+type UnsafeScantoolServer interface {
+	mustEmbedUnimplementedScantoolServer()
+}
+
+func RegisterScantoolServer(s grpc.ServiceRegistrar, srv ScantoolServer) {
+	s.RegisterService(&Scantool_ServiceDesc, srv)
+}
+
+func _Scantool_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScantoolServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scantool_GetBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScantoolServer).GetBlock(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scantool_GetTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScantoolServer).GetTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scantool_GetTx_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScantoolServer).GetTx(ctx, req.(*TxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scantool_GetInput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InputRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScantoolServer).GetInput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scantool_GetInput_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScantoolServer).GetInput(ctx, req.(*InputRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scantool_GetOutput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutputRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScantoolServer).GetOutput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scantool_GetOutput_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScantoolServer).GetOutput(ctx, req.(*OutputRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scantool_GetCurrentBlockHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CurrentBlockHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScantoolServer).GetCurrentBlockHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scantool_GetCurrentBlockHeight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScantoolServer).GetCurrentBlockHeight(ctx, req.(*CurrentBlockHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scantool_GetBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BlockRangeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ScantoolServer).GetBlocks(m, &scantoolGetBlocksServer{stream})
+}
+
+type Scantool_GetBlocksServer interface {
+	Send(*BlockRangeItem) error
+	grpc.ServerStream
+}
+
+type scantoolGetBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *scantoolGetBlocksServer) Send(m *BlockRangeItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Scantool_ServiceDesc is the grpc.ServiceDesc for Scantool service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Scantool_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scantool.v1.Scantool",
+	HandlerType: (*ScantoolServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBlock",
+			Handler:    _Scantool_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetTx",
+			Handler:    _Scantool_GetTx_Handler,
+		},
+		{
+			MethodName: "GetInput",
+			Handler:    _Scantool_GetInput_Handler,
+		},
+		{
+			MethodName: "GetOutput",
+			Handler:    _Scantool_GetOutput_Handler,
+		},
+		{
+			MethodName: "GetCurrentBlockHeight",
+			Handler:    _Scantool_GetCurrentBlockHeight_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetBlocks",
+			Handler:       _Scantool_GetBlocks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grpc/pb/scantool.proto",
+}
@@ -0,0 +1,142 @@
+package grpc
+
+import (
+	"fmt"
+	"net"
+	"context"
+	"strconv"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/btc-script-explorer/scantool/app"
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+	"github.com/btc-script-explorer/scantool/grpc/pb"
+)
+
+// the grpc service runs on its own port next to the http server, see pb/scantool.proto for the messages
+// the generated code in pb is created with protoc-gen-go and protoc-gen-go-grpc:
+//     protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative grpc/pb/scantool.proto
+
+type scantoolServer struct {
+	pb.UnimplementedScantoolServer
+	nodeProxy *node.NodeProxy
+}
+
+// returns once the server is listening, or with an error if it can not listen on the grpc port
+func Start () error {
+
+	nodeProxy, err := node.GetNodeProxy ()
+	if err != nil { return err }
+
+	listener, err := net.Listen ("tcp", app.Settings.GetGrpcAddr ())
+	if err != nil { return err }
+
+	server := grpcgo.NewServer ()
+	pb.RegisterScantoolServer (server, &scantoolServer { nodeProxy: nodeProxy })
+
+	// clients such as grpcurl can list the services without the proto file
+	reflection.Register (server)
+
+	go func () {
+		if err := server.Serve (listener); err != nil { fmt.Println (err.Error ()) }
+	} ()
+
+	return nil
+}
+
+func (s *scantoolServer) GetBlock (ctx context.Context, request *pb.BlockRequest) (*pb.Block, error) {
+
+	blockKey := ""
+	switch key := request.Key.(type) {
+		case *pb.BlockRequest_Hash:
+			if len (key.Hash) != 64 { return nil, status.Error (codes.InvalidArgument, "hash is not a valid block hash") }
+			blockKey = key.Hash
+		case *pb.BlockRequest_Height:
+			blockKey = strconv.FormatUint (uint64 (key.Height), 10)
+		default:
+			return nil, status.Error (codes.InvalidArgument, "hash or height is required")
+	}
+
+	block := s.nodeProxy.GetBlock (node.BlockRequest { BlockKey: blockKey })
+	if block.IsNil () { return nil, status.Error (codes.NotFound, "block not found") }
+
+	return blockToMessage (block), nil
+}
+
+func (s *scantoolServer) GetTx (ctx context.Context, request *pb.TxRequest) (*pb.Tx, error) {
+
+	tx, err := s.getTx (request.Id, request.IncludeInputDetail)
+	if err != nil { return nil, err }
+
+	return txToMessage (tx), nil
+}
+
+func (s *scantoolServer) GetInput (ctx context.Context, request *pb.InputRequest) (*pb.Input, error) {
+
+	tx, err := s.getTx (request.TxId, true)
+	if err != nil { return nil, err }
+
+	if request.InputIndex >= uint32 (tx.GetInputCount ()) { return nil, status.Error (codes.NotFound, "input not found") }
+
+	return inputToMessage (tx.GetInput (uint16 (request.InputIndex))), nil
+}
+
+func (s *scantoolServer) GetOutput (ctx context.Context, request *pb.OutputRequest) (*pb.Output, error) {
+
+	tx, err := s.getTx (request.TxId, false)
+	if err != nil { return nil, err }
+
+	if request.OutputIndex >= uint32 (tx.GetOutputCount ()) { return nil, status.Error (codes.NotFound, "output not found") }
+
+	return outputToMessage (tx.GetOutput (uint16 (request.OutputIndex))), nil
+}
+
+func (s *scantoolServer) GetCurrentBlockHeight (ctx context.Context, request *pb.CurrentBlockHeightRequest) (*pb.CurrentBlockHeight, error) {
+
+	height := s.nodeProxy.GetCurrentBlockHeight ()
+	if height < 0 { return nil, status.Error (codes.Unavailable, "node is not available") }
+
+	return &pb.CurrentBlockHeight { CurrentBlockHeight: uint32 (height) }, nil
+}
+
+// the range is not limited, the client can cancel the stream at any time
+func (s *scantoolServer) GetBlocks (request *pb.BlockRangeRequest, stream pb.Scantool_GetBlocksServer) error {
+
+	if request.StartHeight > request.EndHeight { return status.Error (codes.InvalidArgument, "start_height is greater than end_height") }
+
+	currentHeight := s.nodeProxy.GetCurrentBlockHeight ()
+	if currentHeight < 0 || request.EndHeight > uint32 (currentHeight) { return status.Error (codes.OutOfRange, "end_height is above the current block height") }
+
+	for height := request.StartHeight; height <= request.EndHeight; height++ {
+		if err := stream.Context ().Err (); err != nil { return status.FromContextError (err).Err () }
+
+		block := s.nodeProxy.GetBlock (node.BlockRequest { BlockKey: strconv.FormatUint (uint64 (height), 10) })
+		if block.IsNil () { return status.Errorf (codes.NotFound, "block %d not found", height) }
+
+		if err := stream.Send (&pb.BlockRangeItem { Item: &pb.BlockRangeItem_Block { Block: blockToMessage (block) } }); err != nil { return err }
+		if !request.IncludeTxs { continue }
+
+		for _, txId := range block.GetTxIds () {
+			tx, err := s.getTx (txId, request.IncludeInputDetail)
+			if err != nil { return err }
+
+			if err := stream.Send (&pb.BlockRangeItem { Item: &pb.BlockRangeItem_Tx { Tx: txToMessage (tx) } }); err != nil { return err }
+		}
+	}
+
+	return nil
+}
+
+func (s *scantoolServer) getTx (txId string, includeInputDetail bool) (btc.Tx, error) {
+
+	if len (txId) != 64 { return btc.Tx {}, status.Error (codes.InvalidArgument, "id is not a valid transaction id") }
+
+	tx := s.nodeProxy.GetTx (node.TxRequest { TxId: txId, IncludeInputDetail: includeInputDetail })
+	if tx.IsNil () { return btc.Tx {}, status.Error (codes.NotFound, "tx not found") }
+
+	return tx, nil
+}
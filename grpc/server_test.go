@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	"io"
+	"os"
+	"fmt"
+	"net"
+	"sync"
+	"context"
+	"strings"
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"encoding/hex"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/btc-script-explorer/scantool/app"
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+	"github.com/btc-script-explorer/scantool/grpc/pb"
+)
+
+const testGenesisHeaderHex = "01000000" + "0000000000000000000000000000000000000000000000000000000000000000" +
+								"3ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a" + "29ab5f49" + "ffff001d" + "1dac2b7c"
+
+// the node proxy is created once per process, so every test shares one Esplora stand-in whose chain is only the genesis block
+var testNodeProxy *node.NodeProxy
+var testNodeProxyOnce sync.Once
+
+func getTestNodeProxy (t *testing.T) *node.NodeProxy {
+	t.Helper ()
+
+	testNodeProxyOnce.Do (func () {
+		genesisHash := "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+		genesisTxId := "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+		// the genesis transaction is known without asking the server
+		responses := map [string] string {	"/blocks/tip/hash": genesisHash,
+											"/block-height/0": genesisHash,
+											"/block/" + genesisHash: `{ "id": "` + genesisHash + `", "height": 0, "version": 1, "timestamp": 1231006505, "mediantime": 1231006505, "tx_count": 1,
+																		"size": 285, "weight": 1140, "merkle_root": "` + genesisTxId + `", "nonce": 2083236893, "bits": 486604799, "difficulty": 1 }`,
+											"/block/" + genesisHash + "/status": `{ "in_best_chain": true, "height": 0 }`,
+											"/block/" + genesisHash + "/txids": `[ "` + genesisTxId + `" ]` }
+
+		server := httptest.NewServer (http.HandlerFunc (func (response http.ResponseWriter, request *http.Request) {
+			if request.URL.Path == "/block/" + genesisHash + "/raw" {
+				network := btc.GetNetwork ()
+				rawBlock, _ := hex.DecodeString (testGenesisHeaderHex + "01" + network.GetGenesisTxHex ())
+				response.Write (rawBlock)
+				return
+			}
+
+			body, found := responses [request.URL.Path]
+			if !found { http.Error (response, "not found", http.StatusNotFound); return }
+			fmt.Fprint (response, body)
+		}))
+
+		os.Args = [] string { "scantool", "--node-type=esplora", "--esplora-url=" + server.URL }
+		app.ParseSettings ("test")
+
+		testNodeProxy, _ = node.GetNodeProxy ()
+	})
+
+	return testNodeProxy
+}
+
+// a client connected to the server through memory
+func newTestClient (t *testing.T) pb.ScantoolClient {
+	t.Helper ()
+
+	listener := bufconn.Listen (1 << 20)
+	server := grpcgo.NewServer ()
+	pb.RegisterScantoolServer (server, &scantoolServer { nodeProxy: getTestNodeProxy (t) })
+	go server.Serve (listener)
+	t.Cleanup (server.Stop)
+
+	dialer := func (ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext (ctx) }
+	conn, err := grpcgo.DialContext (context.Background (), "bufnet", grpcgo.WithContextDialer (dialer), grpcgo.WithTransportCredentials (insecure.NewCredentials ()))
+	if err != nil { t.Fatal (err) }
+	t.Cleanup (func () { conn.Close () })
+
+	return pb.NewScantoolClient (conn)
+}
+
+func checkTestStatusCode (t *testing.T, name string, err error, expected codes.Code) {
+	t.Helper ()
+	if status.Code (err) != expected { t.Errorf ("%s: status %s, expected %s", name, status.Code (err), expected) }
+}
+
+func TestGetTx (t *testing.T) {
+
+	client := newTestClient (t)
+	ctx := context.Background ()
+	network := btc.GetNetwork ()
+
+	tx, err := client.GetTx (ctx, &pb.TxRequest { Id: network.GetGenesisTxId (), IncludeInputDetail: true })
+	if err != nil { t.Fatal (err) }
+	if tx.Id != network.GetGenesisTxId () || !tx.Coinbase || tx.BlockHash != network.GetGenesisBlockHash () || len (tx.Inputs) != 1 || len (tx.Outputs) != 1 { t.Errorf ("tx %s", tx.String ()) }
+
+	_, err = client.GetTx (ctx, &pb.TxRequest { Id: "00" })
+	checkTestStatusCode (t, "short id", err, codes.InvalidArgument)
+
+	_, err = client.GetTx (ctx, &pb.TxRequest { Id: strings.Repeat ("ab", 32) })
+	checkTestStatusCode (t, "unknown tx", err, codes.NotFound)
+}
+
+func TestGetBlock (t *testing.T) {
+
+	client := newTestClient (t)
+	ctx := context.Background ()
+	network := btc.GetNetwork ()
+	genesisHash := network.GetGenesisBlockHash ()
+
+	for name, request := range map [string] *pb.BlockRequest {	"by height": { Key: &pb.BlockRequest_Height { Height: 0 } },
+																"by hash": { Key: &pb.BlockRequest_Hash { Hash: genesisHash } } } {
+		block, err := client.GetBlock (ctx, request)
+		if err != nil { t.Errorf ("%s: %s", name, err.Error ()); continue }
+		if block.Hash != genesisHash || block.Height != 0 || block.TxCount != 1 || len (block.TxIds) != 1 || block.TxIds [0] != network.GetGenesisTxId () || !block.MerkleRootValid { t.Errorf ("%s: block %s", name, block.String ()) }
+	}
+
+	_, err := client.GetBlock (ctx, &pb.BlockRequest {})
+	checkTestStatusCode (t, "no key", err, codes.InvalidArgument)
+
+	_, err = client.GetBlock (ctx, &pb.BlockRequest { Key: &pb.BlockRequest_Hash { Hash: "00" } })
+	checkTestStatusCode (t, "short hash", err, codes.InvalidArgument)
+
+	_, err = client.GetBlock (ctx, &pb.BlockRequest { Key: &pb.BlockRequest_Hash { Hash: strings.Repeat ("ab", 32) } })
+	checkTestStatusCode (t, "unknown hash", err, codes.NotFound)
+}
+
+func TestGetBlocks (t *testing.T) {
+
+	client := newTestClient (t)
+	ctx := context.Background ()
+	network := btc.GetNetwork ()
+
+	// the block comes before its transactions
+	stream, err := client.GetBlocks (ctx, &pb.BlockRangeRequest { StartHeight: 0, EndHeight: 0, IncludeTxs: true })
+	if err != nil { t.Fatal (err) }
+
+	items := [] *pb.BlockRangeItem {}
+	for {
+		item, err := stream.Recv ()
+		if err == io.EOF { break }
+		if err != nil { t.Fatal (err) }
+		items = append (items, item)
+	}
+
+	if len (items) != 2 { t.Fatalf ("%d items", len (items)) }
+	if block := items [0].GetBlock (); block == nil || block.Hash != network.GetGenesisBlockHash () { t.Errorf ("first item %s", items [0].String ()) }
+	if tx := items [1].GetTx (); tx == nil || tx.Id != network.GetGenesisTxId () { t.Errorf ("second item %s", items [1].String ()) }
+
+	// the errors arrive with the first receive
+	for name, test := range map [string] struct {
+		request *pb.BlockRangeRequest
+		code codes.Code
+	} {	"above the tip": { &pb.BlockRangeRequest { StartHeight: 0, EndHeight: 1 }, codes.OutOfRange },
+		"start after end": { &pb.BlockRangeRequest { StartHeight: 1, EndHeight: 0 }, codes.InvalidArgument } } {
+		stream, err := client.GetBlocks (ctx, test.request)
+		if err != nil { t.Fatal (err) }
+
+		_, err = stream.Recv ()
+		checkTestStatusCode (t, name, err, test.code)
+	}
+}
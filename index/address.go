@@ -0,0 +1,135 @@
+package index
+
+import (
+	"fmt"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// every output and every input that spends it is also stored under the hash of the output script
+// this makes it possible to look up the history of an address, or of a script that has no address format
+
+var bucketScripts = [] byte ("scripts")
+
+const ADDRESS_EVENT_FUNDING = "funding"
+const ADDRESS_EVENT_SPENDING = "spending"
+
+// for funding events, the index is the output index
+// for spending events, it is the input index
+type AddressEvent struct {
+	Height uint32 `json:"height"`
+	TxId string `json:"tx_id"`
+	Type string `json:"type"`
+	Index uint32 `json:"index"`
+	Value uint64 `json:"value"`
+	SpendType string `json:"spend_type,omitempty"`
+	Balance int64 `json:"balance"`
+}
+
+type AddressHistory struct {
+	ScriptHash string
+	FundingCount uint32
+	SpendingCount uint32
+	Received uint64
+	Spent uint64
+	Balance int64
+	SpendTypes map [string] uint32
+	Events [] AddressEvent
+}
+
+// the sha256 hash of the output script
+func GetScriptHash (outputScript btc.Script) string {
+	hash := sha256.Sum256 (outputScript.AsBytes ())
+	return hex.EncodeToString (hash [:])
+}
+
+// script keys sort by script hash, then in blockchain order
+// the event type comes before the index, so funding events come before spending events in the same transaction
+func makeScriptKey (scriptHash string, height uint32, txIndex uint32, index uint32, eventType string) ([] byte, error) {
+
+	hashBytes, err := hex.DecodeString (scriptHash)
+	if err != nil || len (hashBytes) != sha256.Size { return nil, fmt.Errorf ("invalid script hash %s", scriptHash) }
+
+	eventByte := byte (0)
+	if eventType == ADDRESS_EVENT_SPENDING { eventByte = 1 }
+
+	positionKey := makeKey (height, txIndex, index)
+	key := append (hashBytes, positionKey [: 8]...)
+	key = append (key, eventByte)
+	return append (key, positionKey [8:]...), nil
+}
+
+func putAddressEvent (scripts *bolt.Bucket, scriptHash string, txIndex uint32, event AddressEvent) error {
+
+	key, err := makeScriptKey (scriptHash, event.Height, txIndex, event.Index, event.Type)
+	if err != nil { return err }
+
+	eventBytes, err := json.Marshal (event)
+	if err != nil { return err }
+
+	return scripts.Put (key, eventBytes)
+}
+
+func addInputAddressEvent (scripts *bolt.Bucket, record InputRecord, txIndex uint32) error {
+	event := AddressEvent { Height: record.Height, TxId: record.TxId, Type: ADDRESS_EVENT_SPENDING, Index: record.InputIndex, Value: record.Value, SpendType: record.SpendType }
+	return putAddressEvent (scripts, record.ScriptHash, txIndex, event)
+}
+
+func addOutputAddressEvent (scripts *bolt.Bucket, record OutputRecord, txIndex uint32) error {
+	event := AddressEvent { Height: record.Height, TxId: record.TxId, Type: ADDRESS_EVENT_FUNDING, Index: record.OutputIndex, Value: record.Value }
+	return putAddressEvent (scripts, record.ScriptHash, txIndex, event)
+}
+
+// returns the totals for every indexed event and the most recent events, newest first, up to the limit
+// outputs created before the index start height are not included, so the balance can be lower than the actual balance
+func GetAddressHistory (outputScript btc.Script, limit int) (AddressHistory, error) {
+
+	if idx == nil { return AddressHistory {}, fmt.Errorf ("index is not enabled") }
+
+	if limit <= 0 { limit = DEFAULT_QUERY_LIMIT }
+	if limit > MAX_QUERY_LIMIT { limit = MAX_QUERY_LIMIT }
+
+	history := AddressHistory { ScriptHash: GetScriptHash (outputScript), SpendTypes: make (map [string] uint32) }
+	prefix, _ := hex.DecodeString (history.ScriptHash)
+
+	events := make ([] AddressEvent, 0)
+	err := idx.db.View (func (tx *bolt.Tx) error {
+
+		cursor := tx.Bucket (bucketScripts).Cursor ()
+		for k, v := cursor.Seek (prefix); k != nil && bytes.HasPrefix (k, prefix); k, v = cursor.Next () {
+
+			var event AddressEvent
+			if err := json.Unmarshal (v, &event); err != nil { return err }
+
+			if event.Type == ADDRESS_EVENT_FUNDING {
+				history.FundingCount++
+				history.Received += event.Value
+				history.Balance += int64 (event.Value)
+			} else {
+				history.SpendingCount++
+				history.Spent += event.Value
+				history.Balance -= int64 (event.Value)
+				history.SpendTypes [event.SpendType]++
+			}
+
+			event.Balance = history.Balance
+			events = append (events, event)
+		}
+
+		return nil
+	})
+	if err != nil { return history, err }
+
+	// newest first
+	for e := len (events) - 1; e >= 0 && len (history.Events) < limit; e-- {
+		history.Events = append (history.Events, events [e])
+	}
+
+	return history, nil
+}
@@ -0,0 +1,270 @@
+package index
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"strconv"
+	"encoding/binary"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+// the index is an embedded key-value file that is populated by scanning blocks in the background
+// it is optional, and every other request is still answered live from the node
+
+var bucketMeta = [] byte ("meta")
+var bucketBlocks = [] byte ("blocks")
+var bucketInputs = [] byte ("inputs")
+var bucketOutputs = [] byte ("outputs")
+
+var keyNextHeight = [] byte ("next_height")
+var keyStartHeight = [] byte ("start_height")
+
+// how long to wait before checking for a new block once the index has caught up
+const POLL_INTERVAL = 60 * time.Second
+
+type blockIndex struct {
+	db *bolt.DB
+	fileName string
+	lastError string
+	mutex sync.Mutex
+}
+
+var idx *blockIndex = nil
+
+func IsOpen () bool {
+	return idx != nil
+}
+
+// opens or creates the index file
+// the start height is only used when the file is created
+func Open (fileName string, startHeight uint32) error {
+
+	db, err := bolt.Open (fileName, 0644, &bolt.Options { Timeout: time.Second })
+	if err != nil { return err }
+
+	err = db.Update (func (tx *bolt.Tx) error {
+		for _, bucketName := range [] [] byte { bucketMeta, bucketBlocks, bucketInputs, bucketOutputs, bucketScripts, bucketSpends } {
+			if _, err := tx.CreateBucketIfNotExists (bucketName); err != nil { return err }
+		}
+
+		meta := tx.Bucket (bucketMeta)
+		if meta.Get (keyNextHeight) == nil {
+			if err := meta.Put (keyStartHeight, uint32ToBytes (startHeight)); err != nil { return err }
+			if err := meta.Put (keyNextHeight, uint32ToBytes (startHeight)); err != nil { return err }
+		}
+
+		return nil
+	})
+	if err != nil { db.Close (); return err }
+
+	idx = &blockIndex { db: db, fileName: fileName }
+	return nil
+}
+
+// scans blocks in the background until the index reaches the current block, then follows new blocks
+func Start () {
+	if idx == nil { return }
+	go idx.run ()
+}
+
+func (bi *blockIndex) run () {
+
+	nodeProxy, err := node.GetNodeProxy ()
+	if err != nil { bi.setError (err.Error ()); return }
+
+	// new blocks announced by the node are indexed right away instead of at the next check
+	blockEvents := node.SubscribeToNodeEvents (false)
+
+	for {
+		caughtUp, err := bi.indexNextBlock (nodeProxy)
+		if err != nil { bi.setError (err.Error ()); time.Sleep (POLL_INTERVAL); continue }
+		bi.setError ("")
+
+		if caughtUp {
+			select {
+				case <- blockEvents:
+				case <- time.After (POLL_INTERVAL):
+			}
+		}
+	}
+}
+
+// adds the next block to the index, or removes the last one if it is no longer in the chain
+// returns true if there is no next block yet
+func (bi *blockIndex) indexNextBlock (nodeProxy *node.NodeProxy) (bool, error) {
+
+	nextHeight := bi.getMetaHeight (keyNextHeight)
+	currentHeight := nodeProxy.GetCurrentBlockHeight ()
+	if currentHeight < 0 || nextHeight > uint32 (currentHeight) { return true, nil }
+
+	block := nodeProxy.GetBlock (node.BlockRequest { BlockKey: strconv.FormatUint (uint64 (nextHeight), 10) })
+	if block.IsNil () { return false, fmt.Errorf ("block %d not found", nextHeight) }
+
+	// if the previous block is no longer in the chain, remove it and index the replacement
+	if nextHeight > bi.getMetaHeight (keyStartHeight) && bi.getBlockHash (nextHeight - 1) != block.GetPreviousHash () {
+		fmt.Println (fmt.Sprintf ("Block %d has been replaced. Removing it from the index.", nextHeight - 1))
+		return false, bi.removeBlock (nextHeight - 1)
+	}
+
+	txs := make ([] btc.Tx, 0, block.GetTxCount ())
+	for _, txId := range block.GetTxIds () {
+		tx := nodeProxy.GetTx (node.TxRequest { TxId: txId, IncludeInputDetail: true })
+		if tx.IsNil () { return false, fmt.Errorf ("failed to get every tx in block %d", nextHeight) }
+		txs = append (txs, tx)
+	}
+
+	return false, bi.addBlock (block, txs)
+}
+
+// the records and the new height are written in a single transaction, so the index is always consistent
+func (bi *blockIndex) addBlock (block btc.Block, txs [] btc.Tx) error {
+
+	height := block.GetHeight ()
+	return bi.db.Update (func (tx *bolt.Tx) error {
+
+		inputs := tx.Bucket (bucketInputs)
+		outputs := tx.Bucket (bucketOutputs)
+		scripts := tx.Bucket (bucketScripts)
+		spends := tx.Bucket (bucketSpends)
+
+		for t, blockTx := range txs {
+			txId := blockTx.GetTxId ()
+
+			for i, input := range blockTx.GetInputs () {
+				if input.IsCoinbase () { continue }
+
+				record := makeInputRecord (input, uint32 (i), txId, height)
+				recordBytes, err := json.Marshal (record)
+				if err != nil { return err }
+				if err := inputs.Put (makeKey (height, uint32 (t), uint32 (i)), recordBytes); err != nil { return err }
+				if err := addInputAddressEvent (scripts, record, uint32 (t)); err != nil { return err }
+				if err := addSpend (spends, record); err != nil { return err }
+			}
+
+			for o, output := range blockTx.GetOutputs () {
+				record := makeOutputRecord (output, uint32 (o), txId, height)
+				recordBytes, err := json.Marshal (record)
+				if err != nil { return err }
+				if err := outputs.Put (makeKey (height, uint32 (t), uint32 (o)), recordBytes); err != nil { return err }
+				if err := addOutputAddressEvent (scripts, record, uint32 (t)); err != nil { return err }
+			}
+		}
+
+		if err := tx.Bucket (bucketBlocks).Put (uint32ToBytes (height), [] byte (block.GetHash ())); err != nil { return err }
+		return tx.Bucket (bucketMeta).Put (keyNextHeight, uint32ToBytes (height + 1))
+	})
+}
+
+func (bi *blockIndex) removeBlock (height uint32) error {
+
+	return bi.db.Update (func (tx *bolt.Tx) error {
+
+		prefix := uint32ToBytes (height)
+		inputs := tx.Bucket (bucketInputs)
+		outputs := tx.Bucket (bucketOutputs)
+		scripts := tx.Bucket (bucketScripts)
+		spends := tx.Bucket (bucketSpends)
+
+		// the keys are collected first because deleting while iterating can skip records
+		// the address events and spends are found through the input and output records
+		inputKeys := make ([] [] byte, 0)
+		outputKeys := make ([] [] byte, 0)
+		scriptKeys := make ([] [] byte, 0)
+		spendKeys := make ([] [] byte, 0)
+
+		cursor := inputs.Cursor ()
+		for k, v := cursor.Seek (prefix); k != nil && getKeyHeight (k) == height; k, v = cursor.Next () {
+			var record InputRecord
+			if err := json.Unmarshal (v, &record); err != nil { return err }
+
+			scriptKey, err := makeScriptKey (record.ScriptHash, height, getKeyTxIndex (k), record.InputIndex, ADDRESS_EVENT_SPENDING)
+			if err != nil { return err }
+			spendKey, err := makeOutpointKey (record.PreviousTxId, record.PreviousOutputIndex)
+			if err != nil { return err }
+
+			inputKeys = append (inputKeys, append ([] byte {}, k...))
+			scriptKeys = append (scriptKeys, scriptKey)
+			spendKeys = append (spendKeys, spendKey)
+		}
+
+		cursor = outputs.Cursor ()
+		for k, v := cursor.Seek (prefix); k != nil && getKeyHeight (k) == height; k, v = cursor.Next () {
+			var record OutputRecord
+			if err := json.Unmarshal (v, &record); err != nil { return err }
+
+			scriptKey, err := makeScriptKey (record.ScriptHash, height, getKeyTxIndex (k), record.OutputIndex, ADDRESS_EVENT_FUNDING)
+			if err != nil { return err }
+			outputKeys = append (outputKeys, append ([] byte {}, k...))
+			scriptKeys = append (scriptKeys, scriptKey)
+		}
+
+		for _, k := range inputKeys { if err := inputs.Delete (k); err != nil { return err } }
+		for _, k := range outputKeys { if err := outputs.Delete (k); err != nil { return err } }
+		for _, k := range scriptKeys { if err := scripts.Delete (k); err != nil { return err } }
+		for _, k := range spendKeys { if err := spends.Delete (k); err != nil { return err } }
+
+		if err := tx.Bucket (bucketBlocks).Delete (prefix); err != nil { return err }
+		return tx.Bucket (bucketMeta).Put (keyNextHeight, prefix)
+	})
+}
+
+func (bi *blockIndex) getMetaHeight (key [] byte) uint32 {
+	height := uint32 (0)
+	bi.db.View (func (tx *bolt.Tx) error {
+		value := tx.Bucket (bucketMeta).Get (key)
+		if value != nil { height = binary.BigEndian.Uint32 (value) }
+		return nil
+	})
+
+	return height
+}
+
+func (bi *blockIndex) getBlockHash (height uint32) string {
+	hash := ""
+	bi.db.View (func (tx *bolt.Tx) error {
+		hash = string (tx.Bucket (bucketBlocks).Get (uint32ToBytes (height)))
+		return nil
+	})
+
+	return hash
+}
+
+func (bi *blockIndex) setError (errorMessage string) {
+	bi.mutex.Lock ()
+	defer bi.mutex.Unlock ()
+	if len (errorMessage) > 0 && errorMessage != bi.lastError { fmt.Println ("INDEX ERROR: " + errorMessage) }
+	bi.lastError = errorMessage
+}
+
+func uint32ToBytes (value uint32) [] byte {
+	valueBytes := make ([] byte, 4)
+	binary.BigEndian.PutUint32 (valueBytes, value)
+	return valueBytes
+}
+
+type Status struct {
+	File string `json:"file"`
+	StartHeight uint32 `json:"start_height"`
+	NextHeight uint32 `json:"next_height"`
+	Error string `json:"error,omitempty"`
+}
+
+func GetStatus () Status {
+
+	if idx == nil { return Status {} }
+
+	idx.mutex.Lock ()
+	lastError := idx.lastError
+	idx.mutex.Unlock ()
+
+	return Status {	File: idx.fileName,
+					StartHeight: idx.getMetaHeight (keyStartHeight),
+					NextHeight: idx.getMetaHeight (keyNextHeight),
+					Error: lastError }
+}
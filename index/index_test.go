@@ -0,0 +1,100 @@
+package index
+
+import (
+	"testing"
+	"path/filepath"
+	"encoding/hex"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+	"github.com/btc-script-explorer/scantool/btc/node/nodetest"
+)
+
+const testScriptAHex = "0014" + "4444444444444444444444444444444444444444"
+const testScriptBHex = "0014" + "5555555555555555555555555555555555555555"
+
+func openTestIndex (t *testing.T, startHeight uint32) {
+	t.Helper ()
+
+	if err := Open (filepath.Join (t.TempDir (), "index.db"), startHeight); err != nil { t.Fatal (err) }
+	t.Cleanup (func () {
+		idx.db.Close ()
+		idx = nil
+	})
+}
+
+// indexes blocks until the index reaches the tip
+func indexTestBlocks (t *testing.T, nodeProxy *node.NodeProxy) {
+	t.Helper ()
+
+	for b := 0; b < 10; b++ {
+		caughtUp, err := idx.indexNextBlock (nodeProxy)
+		if err != nil { t.Fatal (err) }
+		if caughtUp { return }
+	}
+	t.Fatal ("the index did not catch up")
+}
+
+func getTestHistory (t *testing.T, scriptHex string) AddressHistory {
+	t.Helper ()
+
+	scriptBytes, err := hex.DecodeString (scriptHex)
+	if err != nil { t.Fatal (err) }
+
+	history, err := GetAddressHistory (btc.NewScript (scriptBytes), 0)
+	if err != nil { t.Fatal (err) }
+	return history
+}
+
+func TestIndexReorg (t *testing.T) {
+
+	chain, nodeProxy := nodetest.Start ()
+	startHeight := chain.GetHeight () + 1
+	openTestIndex (t, startHeight)
+
+	// pays script a, which is spent in the next block in the same transaction that pays it again
+	coinbaseTxId := chain.GetTxIds (chain.GetBlockHash (chain.GetHeight ())) [0]
+	fundingTxHex := nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: coinbaseTxId, Index: 0 } }, [] nodetest.TxOutput { { Value: 3000000000, ScriptHex: testScriptAHex } })
+	fundingTxId := nodetest.GetTxId (fundingTxHex)
+	chain.AddBlock (fundingTxHex)
+
+	spendingTxHex := nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: fundingTxId, Index: 0 } }, [] nodetest.TxOutput { { Value: 1900000000, ScriptHex: testScriptBHex }, { Value: 1000000000, ScriptHex: testScriptAHex } })
+	spendingTxId := nodetest.GetTxId (spendingTxHex)
+	chain.AddBlock (spendingTxHex)
+
+	indexTestBlocks (t, nodeProxy)
+	if status := GetStatus (); status.NextHeight != startHeight + 2 { t.Fatalf ("next height %d", status.NextHeight) }
+
+	// newest first, with the funding event before the spending event in the same transaction
+	history := getTestHistory (t, testScriptAHex)
+	if history.FundingCount != 2 || history.SpendingCount != 1 || history.Received != 4000000000 || history.Spent != 3000000000 || history.Balance != 1000000000 { t.Errorf ("history %+v", history) }
+	if len (history.Events) != 3 || len (history.Events [0].SpendType) == 0 { t.Fatalf ("events %+v", history.Events) }
+	for e, expected := range [] AddressEvent {	{ Height: startHeight + 1, TxId: spendingTxId, Type: ADDRESS_EVENT_SPENDING, Index: 0, Value: 3000000000, SpendType: history.Events [0].SpendType, Balance: 1000000000 },
+												{ Height: startHeight + 1, TxId: spendingTxId, Type: ADDRESS_EVENT_FUNDING, Index: 1, Value: 1000000000, Balance: 4000000000 },
+												{ Height: startHeight, TxId: fundingTxId, Type: ADDRESS_EVENT_FUNDING, Index: 0, Value: 3000000000, Balance: 3000000000 } } {
+		if history.Events [e] != expected { t.Errorf ("event %d is %+v, expected %+v", e, history.Events [e], expected) }
+	}
+
+	outputSpend := GetOutputSpend (node.OutputRequest { TxId: fundingTxId, OutputIndex: 0 })
+	if outputSpend.GetTxId () != spendingTxId || outputSpend.GetBlockHeight () != int64 (startHeight + 1) { t.Errorf ("spent by %s at %d", outputSpend.GetTxId (), outputSpend.GetBlockHeight ()) }
+
+	// the second block is replaced by one that spends the same output to script b only, and the chain gets longer
+	chain.RemoveTip ()
+	replacementTxHex := nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: fundingTxId, Index: 0 } }, [] nodetest.TxOutput { { Value: 2500000000, ScriptHex: testScriptBHex } })
+	replacementTxId := nodetest.GetTxId (replacementTxHex)
+	chain.AddBlock (replacementTxHex)
+	chain.AddBlock ()
+
+	indexTestBlocks (t, nodeProxy)
+	if status := GetStatus (); status.NextHeight != startHeight + 3 { t.Fatalf ("next height %d", status.NextHeight) }
+
+	history = getTestHistory (t, testScriptAHex)
+	if history.FundingCount != 1 || history.SpendingCount != 1 || history.Balance != 0 || len (history.Events) != 2 { t.Fatalf ("history %+v", history) }
+	if history.Events [0].TxId != replacementTxId || history.Events [1].TxId != fundingTxId { t.Errorf ("events %+v", history.Events) }
+
+	history = getTestHistory (t, testScriptBHex)
+	if history.FundingCount != 1 || history.Received != 2500000000 || history.Events [0].TxId != replacementTxId { t.Errorf ("history %+v", history) }
+
+	outputSpend = GetOutputSpend (node.OutputRequest { TxId: fundingTxId, OutputIndex: 0 })
+	if outputSpend.GetTxId () != replacementTxId || outputSpend.GetBlockHeight () != int64 (startHeight + 1) { t.Errorf ("spent by %s at %d", outputSpend.GetTxId (), outputSpend.GetBlockHeight ()) }
+}
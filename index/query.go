@@ -0,0 +1,116 @@
+package index
+
+import (
+	"fmt"
+	"encoding/hex"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const DEFAULT_QUERY_LIMIT = 100
+const MAX_QUERY_LIMIT = 1000
+
+// empty fields are not used as filters
+// ContinueFrom is returned by the previous query when there were more results than the limit
+type InputQuery struct {
+	StartHeight uint32
+	EndHeight uint32
+	SpendType string
+	Opcodes [] string
+	Fingerprint string
+	InscriptionsOnly bool
+	ContentType string
+	Limit int
+	ContinueFrom string
+}
+
+type OutputQuery struct {
+	StartHeight uint32
+	EndHeight uint32
+	OutputType string
+	Opcodes [] string
+	Fingerprint string
+	Limit int
+	ContinueFrom string
+}
+
+func QueryInputs (query InputQuery) ([] InputRecord, string, error) {
+
+	results := make ([] InputRecord, 0)
+	continueFrom, err := scanRange (bucketInputs, query.StartHeight, query.EndHeight, query.Limit, query.ContinueFrom, func (value [] byte) (bool, error) {
+
+		var record InputRecord
+		if err := json.Unmarshal (value, &record); err != nil { return false, err }
+
+		if len (query.SpendType) > 0 && record.SpendType != query.SpendType { return false, nil }
+		if len (query.Fingerprint) > 0 && record.Fingerprint != query.Fingerprint { return false, nil }
+		if query.InscriptionsOnly && !record.Inscription { return false, nil }
+		if len (query.ContentType) > 0 && record.ContentType != query.ContentType { return false, nil }
+		if !hasOpcodes (record.Opcodes, query.Opcodes) { return false, nil }
+
+		results = append (results, record)
+		return true, nil
+	})
+
+	return results, continueFrom, err
+}
+
+func QueryOutputs (query OutputQuery) ([] OutputRecord, string, error) {
+
+	results := make ([] OutputRecord, 0)
+	continueFrom, err := scanRange (bucketOutputs, query.StartHeight, query.EndHeight, query.Limit, query.ContinueFrom, func (value [] byte) (bool, error) {
+
+		var record OutputRecord
+		if err := json.Unmarshal (value, &record); err != nil { return false, err }
+
+		if len (query.OutputType) > 0 && record.OutputType != query.OutputType { return false, nil }
+		if len (query.Fingerprint) > 0 && record.Fingerprint != query.Fingerprint { return false, nil }
+		if !hasOpcodes (record.Opcodes, query.Opcodes) { return false, nil }
+
+		results = append (results, record)
+		return true, nil
+	})
+
+	return results, continueFrom, err
+}
+
+// calls match for every record in the height range until the limit is reached
+// returns the key to continue from as hex, or an empty string if there are no more records in the range
+func scanRange (bucketName [] byte, startHeight uint32, endHeight uint32, limit int, continueFrom string, match func ([] byte) (bool, error)) (string, error) {
+
+	if idx == nil { return "", fmt.Errorf ("index is not enabled") }
+	if startHeight > endHeight { return "", fmt.Errorf ("start_height is greater than end_height") }
+
+	if limit <= 0 { limit = DEFAULT_QUERY_LIMIT }
+	if limit > MAX_QUERY_LIMIT { limit = MAX_QUERY_LIMIT }
+
+	seekKey := makeKey (startHeight, 0, 0)
+	if len (continueFrom) > 0 {
+		continueKey, err := hex.DecodeString (continueFrom)
+		if err != nil || len (continueKey) != len (seekKey) { return "", fmt.Errorf ("continue_from is not valid") }
+		seekKey = continueKey
+	}
+
+	nextKey := ""
+	err := idx.db.View (func (tx *bolt.Tx) error {
+
+		matchCount := 0
+		cursor := tx.Bucket (bucketName).Cursor ()
+		for k, v := cursor.Seek (seekKey); k != nil && getKeyHeight (k) <= endHeight; k, v = cursor.Next () {
+
+			if matchCount == limit {
+				nextKey = hex.EncodeToString (k)
+				break
+			}
+
+			matched, err := match (v)
+			if err != nil { return err }
+			if matched { matchCount++ }
+		}
+
+		return nil
+	})
+
+	return nextKey, err
+}
@@ -0,0 +1,135 @@
+package index
+
+import (
+	"encoding/binary"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// one record is stored for every non-coinbase input
+// the script hash is the hash of the previous output script
+type InputRecord struct {
+	Height uint32 `json:"height"`
+	TxId string `json:"tx_id"`
+	InputIndex uint32 `json:"input_index"`
+	SpendType string `json:"spend_type"`
+	Value uint64 `json:"value"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Opcodes [] string `json:"opcodes"`
+	Inscription bool `json:"inscription"`
+	ContentType string `json:"content_type,omitempty"`
+	ScriptHash string `json:"script_hash"`
+	PreviousTxId string `json:"previous_output_tx_id"`
+	PreviousOutputIndex uint32 `json:"previous_output_index"`
+}
+
+// one record is stored for every output
+type OutputRecord struct {
+	Height uint32 `json:"height"`
+	TxId string `json:"tx_id"`
+	OutputIndex uint32 `json:"output_index"`
+	OutputType string `json:"output_type"`
+	Value uint64 `json:"value"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Opcodes [] string `json:"opcodes"`
+	ScriptHash string `json:"script_hash"`
+}
+
+// keys sort by block height, then by the position of the tx in the block, then by the input or output index
+func makeKey (height uint32, txIndex uint32, index uint32) [] byte {
+	key := make ([] byte, 12)
+	binary.BigEndian.PutUint32 (key [0 : 4], height)
+	binary.BigEndian.PutUint32 (key [4 : 8], txIndex)
+	binary.BigEndian.PutUint32 (key [8 : 12], index)
+	return key
+}
+
+func getKeyHeight (key [] byte) uint32 {
+	return binary.BigEndian.Uint32 (key [0 : 4])
+}
+
+func getKeyTxIndex (key [] byte) uint32 {
+	return binary.BigEndian.Uint32 (key [4 : 8])
+}
+
+// the input must have its previous output
+func makeInputRecord (input btc.Input, inputIndex uint32, txId string, height uint32) InputRecord {
+
+	previousOutput := input.GetPreviousOutput ()
+	record := InputRecord {	Height: height,
+							TxId: txId,
+							InputIndex: inputIndex,
+							SpendType: input.GetSpendType (),
+							Value: previousOutput.GetValue (),
+							ScriptHash: GetScriptHash (previousOutput.GetOutputScript ()),
+							PreviousTxId: input.GetPreviousOutputTxId (),
+							PreviousOutputIndex: uint32 (input.GetPreviousOutputIndex ()) }
+
+	// the fingerprint is taken from the serialized script that was executed, if there is one
+	segwit := input.GetSegwit ()
+	tapScript, _ := segwit.GetTapScript ()
+	witnessScript := segwit.GetWitnessScript ()
+	redeemScript := input.GetRedeemScript ()
+	if !tapScript.IsNil () {
+		record.Fingerprint = tapScript.GetFingerprint ()
+	} else if !witnessScript.IsNil () {
+		record.Fingerprint = witnessScript.GetFingerprint ()
+	} else if input.HasRedeemScript () {
+		record.Fingerprint = redeemScript.GetFingerprint ()
+	}
+
+	// opcodes from every script in the input
+	inputScript := input.GetInputScript ()
+	scripts := [] btc.Script { inputScript, redeemScript, witnessScript, tapScript }
+	record.Opcodes = mergeOpcodes (scripts)
+
+	if tapScript.IsOrdinal () {
+		record.Inscription = true
+		record.ContentType, _ = tapScript.GetOrdinalContent ()
+	}
+
+	return record
+}
+
+func makeOutputRecord (output btc.Output, outputIndex uint32, txId string, height uint32) OutputRecord {
+
+	outputScript := output.GetOutputScript ()
+	return OutputRecord {	Height: height,
+							TxId: txId,
+							OutputIndex: outputIndex,
+							OutputType: output.GetOutputType (),
+							Value: output.GetValue (),
+							Fingerprint: outputScript.GetFingerprint (),
+							Opcodes: outputScript.GetOpcodes (),
+							ScriptHash: GetScriptHash (outputScript) }
+}
+
+func mergeOpcodes (scripts [] btc.Script) [] string {
+
+	found := make (map [string] bool)
+	opcodes := make ([] string, 0)
+	for _, script := range scripts {
+		if script.IsNil () { continue }
+		for _, opcode := range script.GetOpcodes () {
+			if found [opcode] { continue }
+			found [opcode] = true
+			opcodes = append (opcodes, opcode)
+		}
+	}
+
+	return opcodes
+}
+
+// returns true if every required opcode is in the list
+func hasOpcodes (opcodes [] string, required [] string) bool {
+
+	for _, r := range required {
+		found := false
+		for _, opcode := range opcodes {
+			if opcode == r { found = true; break }
+		}
+		if !found { return false }
+	}
+
+	return true
+}
@@ -0,0 +1,71 @@
+package index
+
+import (
+	"fmt"
+	"encoding/hex"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+// every input is also stored under the output it spends, so outputs can be followed forward to the input that spent them
+
+var bucketSpends = [] byte ("spends")
+
+type spendRecord struct {
+	Height uint32 `json:"height"`
+	TxId string `json:"tx_id"`
+	InputIndex uint32 `json:"input_index"`
+	SpendType string `json:"spend_type"`
+}
+
+// outpoint keys are the previous tx id followed by the output index
+func makeOutpointKey (txId string, outputIndex uint32) ([] byte, error) {
+
+	txIdBytes, err := hex.DecodeString (txId)
+	if err != nil || len (txIdBytes) != 32 { return nil, fmt.Errorf ("invalid tx id %s", txId) }
+
+	return append (txIdBytes, uint32ToBytes (outputIndex)...), nil
+}
+
+func addSpend (spends *bolt.Bucket, record InputRecord) error {
+
+	key, err := makeOutpointKey (record.PreviousTxId, record.PreviousOutputIndex)
+	if err != nil { return err }
+
+	spendBytes, err := json.Marshal (spendRecord { Height: record.Height, TxId: record.TxId, InputIndex: record.InputIndex, SpendType: record.SpendType })
+	if err != nil { return err }
+
+	return spends.Put (key, spendBytes)
+}
+
+// the index is checked first, and if the spending input has not been indexed, the node is asked
+// the node can only find spending transactions in the mempool
+func GetOutputSpend (outputRequest node.OutputRequest) btc.OutputSpend {
+
+	if idx != nil {
+		var record spendRecord
+		found := false
+		idx.db.View (func (tx *bolt.Tx) error {
+			key, err := makeOutpointKey (outputRequest.TxId, uint32 (outputRequest.OutputIndex))
+			if err != nil { return err }
+
+			recordBytes := tx.Bucket (bucketSpends).Get (key)
+			if recordBytes == nil { return nil }
+
+			if err := json.Unmarshal (recordBytes, &record); err != nil { fmt.Println (err.Error ()); return err }
+			found = true
+			return nil
+		})
+
+		if found { return btc.NewOutputSpend (btc.OUTPUT_SPENT, record.TxId, record.InputIndex, record.SpendType, int64 (record.Height)) }
+	}
+
+	nodeProxy, err := node.GetNodeProxy ()
+	if err != nil { fmt.Println (err.Error ()); return btc.OutputSpend {} }
+
+	return nodeProxy.GetOutputSpend (outputRequest)
+}
@@ -0,0 +1,207 @@
+package jobs
+
+import (
+	"fmt"
+	"encoding/json"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// an analyzer gathers data from every transaction in the job's block range
+// its state is saved with each checkpoint so that it can continue where it left off
+type Analyzer interface {
+	GetName () string
+	AnalyzeTx (tx btc.Tx, blockHeight uint32)
+	GetResults () map [string] interface {}
+	saveState () ([] byte, error)
+	loadState (state [] byte) error
+}
+
+func GetAnalyzerNames () [] string {
+	return [] string { "spend_types", "opcodes", "inscriptions" }
+}
+
+func newAnalyzer (name string) (Analyzer, error) {
+	switch name {
+		case "spend_types": return &spendTypeAnalyzer { state: spendTypeState { SpendTypes: make (map [string] typeCount), OutputTypes: make (map [string] typeCount) } }, nil
+		case "opcodes": return &opcodeAnalyzer { state: btc.NewOpcodeStats () }, nil
+		case "inscriptions": return &inscriptionAnalyzer { state: inscriptionState { ContentTypes: make (map [string] typeCount), Protocols: make (map [string] uint64), Operations: make (map [string] uint64) } }, nil
+	}
+
+	return nil, fmt.Errorf ("%s is not a valid analyzer", name)
+}
+
+type typeCount struct {
+	Count uint64 `json:"count"`
+	Value uint64 `json:"value"`
+}
+
+func addTypeCount (typeMap map [string] typeCount, typeName string, value uint64) {
+	if len (typeName) == 0 { return }
+
+	tc := typeMap [typeName]
+	tc.Count++
+	tc.Value += value
+	typeMap [typeName] = tc
+}
+
+// spend types and output types
+
+type spendTypeState struct {
+	SpendTypes map [string] typeCount `json:"spend_types"`
+	OutputTypes map [string] typeCount `json:"output_types"`
+}
+
+type spendTypeAnalyzer struct {
+	state spendTypeState
+}
+
+func (a *spendTypeAnalyzer) GetName () string {
+	return "spend_types"
+}
+
+func (a *spendTypeAnalyzer) AnalyzeTx (tx btc.Tx, blockHeight uint32) {
+
+	for _, input := range tx.GetInputs () {
+		if input.IsCoinbase () { continue }
+		previousOutput := input.GetPreviousOutput ()
+		addTypeCount (a.state.SpendTypes, input.GetSpendType (), previousOutput.GetValue ())
+	}
+
+	for _, output := range tx.GetOutputs () {
+		addTypeCount (a.state.OutputTypes, output.GetOutputType (), output.GetValue ())
+	}
+}
+
+func (a *spendTypeAnalyzer) GetResults () map [string] interface {} {
+	return map [string] interface {} { "spend_types": a.state.SpendTypes, "output_types": a.state.OutputTypes }
+}
+
+func (a *spendTypeAnalyzer) saveState () ([] byte, error) {
+	return json.Marshal (a.state)
+}
+
+func (a *spendTypeAnalyzer) loadState (state [] byte) error {
+	return json.Unmarshal (state, &a.state)
+}
+
+// opcode frequencies, counted separately for each kind of script
+
+type opcodeAnalyzer struct {
+	state btc.OpcodeStats
+}
+
+func (a *opcodeAnalyzer) GetName () string {
+	return "opcodes"
+}
+
+func (a *opcodeAnalyzer) AnalyzeTx (tx btc.Tx, blockHeight uint32) {
+	a.state.AddTx (tx)
+}
+
+func (a *opcodeAnalyzer) GetResults () map [string] interface {} {
+	results := make (map [string] interface {})
+	for scriptType, opcodes := range a.state.GetCounts () {
+		results [scriptType] = opcodes
+	}
+
+	return results
+}
+
+func (a *opcodeAnalyzer) saveState () ([] byte, error) {
+	return json.Marshal (a.state.GetCounts ())
+}
+
+func (a *opcodeAnalyzer) loadState (state [] byte) error {
+	var counts map [string] map [string] uint64
+	if err := json.Unmarshal (state, &counts); err != nil { return err }
+
+	a.state = btc.NewOpcodeStats ()
+	a.state.AddCounts (counts)
+	return nil
+}
+
+// ordinal inscriptions, including the protocol and operation of json inscriptions such as brc-20
+
+type inscriptionState struct {
+	InscriptionCount uint64 `json:"inscription_count"`
+	ContentBytes uint64 `json:"content_bytes"`
+	BlocksWithInscriptions uint64 `json:"blocks_with_inscriptions"`
+	LastBlockWithInscriptions uint32 `json:"last_block_with_inscriptions"`
+	ContentTypes map [string] typeCount `json:"content_types"`
+	Protocols map [string] uint64 `json:"protocols"`
+	Operations map [string] uint64 `json:"operations"`
+}
+
+type inscriptionAnalyzer struct {
+	state inscriptionState
+}
+
+func (a *inscriptionAnalyzer) GetName () string {
+	return "inscriptions"
+}
+
+func (a *inscriptionAnalyzer) AnalyzeTx (tx btc.Tx, blockHeight uint32) {
+
+	for _, input := range tx.GetInputs () {
+		if input.GetSpendType () != btc.SPEND_TYPE_P2TR_Script { continue }
+
+		segwit := input.GetSegwit ()
+		tapScript, _ := segwit.GetTapScript ()
+		if !tapScript.IsOrdinal () { continue }
+
+		contentType, content := tapScript.GetOrdinalContent ()
+
+		a.state.InscriptionCount++
+		a.state.ContentBytes += uint64 (len (content))
+		if a.state.LastBlockWithInscriptions != blockHeight {
+			a.state.BlocksWithInscriptions++
+			a.state.LastBlockWithInscriptions = blockHeight
+		}
+
+		// the content value is the number of bytes
+		addTypeCount (a.state.ContentTypes, contentType, uint64 (len (content)))
+
+		// standard inscriptions are json objects with a protocol and an operation
+		var standardFields map [string] interface {}
+		if json.Unmarshal (content, &standardFields) != nil { continue }
+
+		if protocol, ok := standardFields ["p"].(string); ok { a.state.Protocols [protocol]++ }
+		if operation, ok := standardFields ["op"].(string); ok { a.state.Operations [operation]++ }
+	}
+}
+
+func (a *inscriptionAnalyzer) GetResults () map [string] interface {} {
+
+	results := make (map [string] interface {})
+	results ["inscription_count"] = a.state.InscriptionCount
+	results ["content_bytes"] = a.state.ContentBytes
+	results ["blocks_with_inscriptions"] = a.state.BlocksWithInscriptions
+	results ["content_types"] = a.state.ContentTypes
+	results ["protocols"] = a.state.Protocols
+	results ["operations"] = a.state.Operations
+
+	return results
+}
+
+func (a *inscriptionAnalyzer) saveState () ([] byte, error) {
+	return json.Marshal (a.state)
+}
+
+func (a *inscriptionAnalyzer) loadState (state [] byte) error {
+	return json.Unmarshal (state, &a.state)
+}
+
+// removes duplicate analyzer names, keeping the order they were requested in
+func removeDuplicateNames (names [] string) [] string {
+
+	unique := make (map [string] bool)
+	uniqueNames := make ([] string, 0)
+	for _, name := range names {
+		if unique [name] { continue }
+		unique [name] = true
+		uniqueNames = append (uniqueNames, name)
+	}
+
+	return uniqueNames
+}
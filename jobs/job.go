@@ -0,0 +1,376 @@
+package jobs
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"sort"
+	"sync"
+	"strings"
+	"strconv"
+	"path/filepath"
+	"encoding/hex"
+	"encoding/json"
+	"crypto/rand"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+const JOB_STATUS_RUNNING = "running"
+const JOB_STATUS_STOPPED = "stopped"
+const JOB_STATUS_COMPLETE = "complete"
+const JOB_STATUS_FAILED = "failed"
+
+// a job scans a range of blocks in the background and runs each of its analyzers on every transaction
+type Job struct {
+	id string
+	startHeight uint32
+	endHeight uint32
+	nextHeight uint32
+	analyzers [] Analyzer
+	status string
+	errorMessage string
+	created int64
+	finished int64
+	stopRequested bool
+	mutex sync.Mutex
+}
+
+// the checkpoint file contains everything needed to resume the job after a restart
+type jobCheckpoint struct {
+	Id string `json:"id"`
+	StartHeight uint32 `json:"start_height"`
+	EndHeight uint32 `json:"end_height"`
+	NextHeight uint32 `json:"next_height"`
+	Status string `json:"status"`
+	Error string `json:"error"`
+	Created int64 `json:"created"`
+	Finished int64 `json:"finished"`
+	AnalyzerNames [] string `json:"analyzer_names"`
+	AnalyzerStates map [string] json.RawMessage `json:"analyzer_states"`
+}
+
+var jobMap = make (map [string] *Job)
+var jobMapMutex sync.Mutex
+
+var path string
+
+func SetJobsPath (jobsPath string) {
+	path = jobsPath
+}
+
+func GetPath () string {
+	return path
+}
+
+func StartJob (startHeight uint32, endHeight uint32, analyzerNames [] string) (*Job, error) {
+
+	if startHeight > endHeight { return nil, fmt.Errorf ("start_height is greater than end_height") }
+
+	nodeProxy, err := node.GetNodeProxy ()
+	if err != nil { return nil, err }
+
+	currentHeight := nodeProxy.GetCurrentBlockHeight ()
+	if currentHeight < 0 || endHeight > uint32 (currentHeight) { return nil, fmt.Errorf ("end_height is above the current block height") }
+
+	analyzerNames = removeDuplicateNames (analyzerNames)
+	if len (analyzerNames) == 0 { return nil, fmt.Errorf ("at least one analyzer is required") }
+
+	analyzers := make ([] Analyzer, len (analyzerNames))
+	for a, name := range analyzerNames {
+		analyzers [a], err = newAnalyzer (name)
+		if err != nil { return nil, err }
+	}
+
+	idBytes := make ([] byte, 8)
+	if _, err := rand.Read (idBytes); err != nil { return nil, err }
+
+	job := &Job {	id: hex.EncodeToString (idBytes),
+					startHeight: startHeight,
+					endHeight: endHeight,
+					nextHeight: startHeight,
+					analyzers: analyzers,
+					status: JOB_STATUS_RUNNING,
+					created: time.Now ().Unix () }
+
+	jobMapMutex.Lock ()
+	jobMap [job.id] = job
+	jobMapMutex.Unlock ()
+
+	job.saveCheckpoint ()
+	go job.run ()
+
+	return job, nil
+}
+
+// loads every checkpoint from the jobs directory and restarts the jobs that were running
+func ResumeJobs () {
+
+	if len (path) == 0 { return }
+
+	fileNames, err := filepath.Glob (filepath.Join (path, "*.json"))
+	if err != nil { fmt.Println (err.Error ()); return }
+
+	for _, fileName := range fileNames {
+		if strings.HasSuffix (fileName, "-report.json") { continue }
+
+		job, err := loadCheckpoint (fileName)
+		if err != nil { fmt.Println (fmt.Sprintf ("Failed to load job checkpoint %s: %s", fileName, err.Error ())); continue }
+
+		jobMapMutex.Lock ()
+		jobMap [job.id] = job
+		jobMapMutex.Unlock ()
+
+		if job.status == JOB_STATUS_RUNNING {
+			fmt.Println (fmt.Sprintf ("Resuming job %s at block %d.", job.id, job.nextHeight))
+			go job.run ()
+		}
+	}
+}
+
+func GetJob (id string) *Job {
+	jobMapMutex.Lock ()
+	defer jobMapMutex.Unlock ()
+	return jobMap [id]
+}
+
+// returns every job, oldest first
+func GetJobs () [] *Job {
+
+	jobMapMutex.Lock ()
+	jobs := make ([] *Job, 0, len (jobMap))
+	for _, job := range jobMap {
+		jobs = append (jobs, job)
+	}
+	jobMapMutex.Unlock ()
+
+	sort.SliceStable (jobs, func (i, j int) bool {
+		if jobs [i].created == jobs [j].created { return jobs [i].id < jobs [j].id }
+		return jobs [i].created < jobs [j].created
+	})
+
+	return jobs
+}
+
+// the job stops after the block it is currently scanning
+func (j *Job) Stop () {
+	j.mutex.Lock ()
+	defer j.mutex.Unlock ()
+	if j.status == JOB_STATUS_RUNNING { j.stopRequested = true }
+}
+
+// restarts a stopped or failed job from its last checkpoint
+func (j *Job) Resume () error {
+
+	j.mutex.Lock ()
+	if j.status != JOB_STATUS_STOPPED && j.status != JOB_STATUS_FAILED {
+		j.mutex.Unlock ()
+		return fmt.Errorf ("job %s is %s", j.id, j.status)
+	}
+
+	j.status = JOB_STATUS_RUNNING
+	j.errorMessage = ""
+	j.stopRequested = false
+	j.mutex.Unlock ()
+
+	j.saveCheckpoint ()
+	go j.run ()
+
+	return nil
+}
+
+func (j *Job) run () {
+
+	nodeProxy, err := node.GetNodeProxy ()
+	if err != nil { j.fail (err.Error ()); return }
+
+	for {
+		j.mutex.Lock ()
+		height := j.nextHeight
+		stopRequested := j.stopRequested
+		j.mutex.Unlock ()
+
+		if height > j.endHeight { break }
+
+		if stopRequested {
+			j.mutex.Lock ()
+			j.status = JOB_STATUS_STOPPED
+			j.stopRequested = false
+			j.mutex.Unlock ()
+			j.saveCheckpoint ()
+			return
+		}
+
+		// get every transaction in the block before analyzing any of them so that a block is never partially analyzed
+		block := nodeProxy.GetBlock (node.BlockRequest { BlockKey: strconv.FormatUint (uint64 (height), 10) })
+		if block.IsNil () { j.fail (fmt.Sprintf ("block %d not found", height)); return }
+
+		txs := make ([] btc.Tx, 0, block.GetTxCount ())
+		for _, txId := range block.GetTxIds () {
+			tx := nodeProxy.GetTx (node.TxRequest { TxId: txId, IncludeInputDetail: true })
+			if tx.IsNil () { j.fail (fmt.Sprintf ("tx %s in block %d not found", txId, height)); return }
+			txs = append (txs, tx)
+		}
+
+		j.mutex.Lock ()
+		for _, tx := range txs {
+			for _, analyzer := range j.analyzers {
+				analyzer.AnalyzeTx (tx, height)
+			}
+		}
+		j.nextHeight = height + 1
+		j.mutex.Unlock ()
+
+		j.saveCheckpoint ()
+	}
+
+	j.mutex.Lock ()
+	j.status = JOB_STATUS_COMPLETE
+	j.finished = time.Now ().Unix ()
+	j.mutex.Unlock ()
+
+	j.saveCheckpoint ()
+	j.saveReport ()
+}
+
+func (j *Job) fail (errorMessage string) {
+
+	fmt.Println (fmt.Sprintf ("Job %s failed: %s", j.id, errorMessage))
+
+	j.mutex.Lock ()
+	j.status = JOB_STATUS_FAILED
+	j.errorMessage = errorMessage
+	j.mutex.Unlock ()
+
+	j.saveCheckpoint ()
+}
+
+func (j *Job) saveCheckpoint () {
+
+	if len (path) == 0 { return }
+
+	j.mutex.Lock ()
+	checkpoint := jobCheckpoint {	Id: j.id,
+									StartHeight: j.startHeight,
+									EndHeight: j.endHeight,
+									NextHeight: j.nextHeight,
+									Status: j.status,
+									Error: j.errorMessage,
+									Created: j.created,
+									Finished: j.finished,
+									AnalyzerNames: j.getAnalyzerNames (),
+									AnalyzerStates: make (map [string] json.RawMessage) }
+
+	for _, analyzer := range j.analyzers {
+		state, err := analyzer.saveState ()
+		if err != nil { fmt.Println (err.Error ()); continue }
+		checkpoint.AnalyzerStates [analyzer.GetName ()] = state
+	}
+	j.mutex.Unlock ()
+
+	checkpointBytes, err := json.Marshal (checkpoint)
+	if err != nil { fmt.Println (err.Error ()); return }
+
+	writeFile (filepath.Join (path, j.id + ".json"), checkpointBytes)
+}
+
+func (j *Job) saveReport () {
+
+	if len (path) == 0 { return }
+
+	reportBytes, err := json.MarshalIndent (j.GetReport (), "", "\t")
+	if err != nil { fmt.Println (err.Error ()); return }
+
+	writeFile (filepath.Join (path, j.id + "-report.json"), reportBytes)
+}
+
+// the file is written to a temporary file first so that a restart during the write can not leave a partial checkpoint
+func writeFile (fileName string, data [] byte) {
+
+	tempFileName := fileName + ".tmp"
+	if err := os.WriteFile (tempFileName, data, 0644); err != nil { fmt.Println (err.Error ()); return }
+	if err := os.Rename (tempFileName, fileName); err != nil { fmt.Println (err.Error ()) }
+}
+
+func loadCheckpoint (fileName string) (*Job, error) {
+
+	checkpointBytes, err := os.ReadFile (fileName)
+	if err != nil { return nil, err }
+
+	var checkpoint jobCheckpoint
+	if err := json.Unmarshal (checkpointBytes, &checkpoint); err != nil { return nil, err }
+
+	job := &Job {	id: checkpoint.Id,
+					startHeight: checkpoint.StartHeight,
+					endHeight: checkpoint.EndHeight,
+					nextHeight: checkpoint.NextHeight,
+					status: checkpoint.Status,
+					errorMessage: checkpoint.Error,
+					created: checkpoint.Created,
+					finished: checkpoint.Finished }
+
+	for _, name := range checkpoint.AnalyzerNames {
+		analyzer, err := newAnalyzer (name)
+		if err != nil { return nil, err }
+
+		if err := analyzer.loadState (checkpoint.AnalyzerStates [name]); err != nil { return nil, err }
+		job.analyzers = append (job.analyzers, analyzer)
+	}
+
+	return job, nil
+}
+
+// must be called with the mutex locked
+func (j *Job) getAnalyzerNames () [] string {
+	names := make ([] string, len (j.analyzers))
+	for a, analyzer := range j.analyzers {
+		names [a] = analyzer.GetName ()
+	}
+
+	return names
+}
+
+func (j *Job) GetId () string {
+	return j.id
+}
+
+func (j *Job) GetStatus () string {
+	j.mutex.Lock ()
+	defer j.mutex.Unlock ()
+	return j.status
+}
+
+// returns the progress and the results so far, which are the final results once the job is complete
+func (j *Job) GetReport () map [string] interface {} {
+
+	j.mutex.Lock ()
+	defer j.mutex.Unlock ()
+
+	blockCount := j.endHeight - j.startHeight + 1
+	blocksScanned := j.nextHeight - j.startHeight
+
+	report := make (map [string] interface {})
+	report ["id"] = j.id
+	report ["status"] = j.status
+	report ["start_height"] = j.startHeight
+	report ["end_height"] = j.endHeight
+	report ["block_count"] = blockCount
+	report ["blocks_scanned"] = blocksScanned
+	report ["percent_complete"] = float64 (blocksScanned * 100) / float64 (blockCount)
+	report ["created"] = j.created
+	report ["finished"] = j.finished
+	report ["analyzers"] = j.getAnalyzerNames ()
+	if len (j.errorMessage) > 0 { report ["error"] = j.errorMessage }
+
+	// the results are serialized while the mutex is locked because the job continues to update them
+	results := make (map [string] json.RawMessage)
+	for _, analyzer := range j.analyzers {
+		resultsBytes, err := json.Marshal (analyzer.GetResults ())
+		if err != nil { fmt.Println (err.Error ()); continue }
+		results [analyzer.GetName ()] = resultsBytes
+	}
+	report ["results"] = results
+
+	return report
+}
@@ -0,0 +1,132 @@
+package jobs
+
+import (
+	"os"
+	"time"
+	"strings"
+	"testing"
+	"path/filepath"
+	"encoding/json"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node/nodetest"
+)
+
+const testP2wpkhScriptHex = "0014" + "4444444444444444444444444444444444444444"
+
+// counts the transactions of every block it sees, and stops its job once after the stop height
+type testStopAnalyzer struct {
+	job *Job
+	stopHeight uint32
+	txCounts map [uint32] int
+}
+
+func (a *testStopAnalyzer) GetName () string { return "test_stop" }
+
+// the job's mutex is locked while the transactions are analyzed
+func (a *testStopAnalyzer) AnalyzeTx (tx btc.Tx, blockHeight uint32) {
+	a.txCounts [blockHeight]++
+	if blockHeight == a.stopHeight {
+		a.job.stopRequested = true
+		a.stopHeight = 0
+	}
+}
+
+func (a *testStopAnalyzer) GetResults () map [string] interface {} { return nil }
+func (a *testStopAnalyzer) saveState () ([] byte, error) { return json.Marshal (a.txCounts) }
+func (a *testStopAnalyzer) loadState (state [] byte) error { return json.Unmarshal (state, &a.txCounts) }
+
+// every block after the first has a transaction that spends the coinbase of the block before it
+func addTestBlocks (t *testing.T, chain *nodetest.Chain, blockCount int) (uint32, uint32) {
+	t.Helper ()
+
+	startHeight := chain.GetHeight () + 1
+	for b := 0; b < blockCount; b++ {
+		coinbaseTxId := chain.GetTxIds (chain.GetBlockHash (chain.GetHeight ())) [0]
+		chain.AddBlock (nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: coinbaseTxId, Index: 0 } }, [] nodetest.TxOutput { { Value: 4000000000, ScriptHex: testP2wpkhScriptHex } }))
+	}
+
+	return startHeight, chain.GetHeight ()
+}
+
+func newTestJob (id string, startHeight uint32, endHeight uint32, analyzers ...Analyzer) *Job {
+	job := &Job { id: id, startHeight: startHeight, endHeight: endHeight, nextHeight: startHeight, analyzers: analyzers, status: JOB_STATUS_RUNNING }
+
+	jobMapMutex.Lock ()
+	jobMap [id] = job
+	jobMapMutex.Unlock ()
+
+	return job
+}
+
+func newTestAnalyzer (t *testing.T, name string) Analyzer {
+	t.Helper ()
+
+	analyzer, err := newAnalyzer (name)
+	if err != nil { t.Fatal (err) }
+	return analyzer
+}
+
+func getTestResults (t *testing.T, job *Job) string {
+	t.Helper ()
+
+	report := job.GetReport ()
+	resultsBytes, err := json.Marshal (report ["results"].(map [string] json.RawMessage) ["spend_types"])
+	if err != nil { t.Fatal (err) }
+	return string (resultsBytes)
+}
+
+// a job that is stopped and resumed ends up with the same results as one that ran without stopping
+func TestJobStopAndResume (t *testing.T) {
+
+	chain, _ := nodetest.Start ()
+	SetJobsPath (t.TempDir ())
+	defer SetJobsPath ("")
+
+	startHeight, endHeight := addTestBlocks (t, chain, 4)
+
+	expected := newTestJob ("expected", startHeight, endHeight, newTestAnalyzer (t, "spend_types"))
+	expected.run ()
+	if expected.GetStatus () != JOB_STATUS_COMPLETE { t.Fatalf ("job is %s", expected.GetStatus ()) }
+	expectedResults := getTestResults (t, expected)
+	if !strings.Contains (expectedResults, `"P2WPKH":{"count":4,`) { t.Fatalf ("results %s", expectedResults) }
+
+	stopAnalyzer := &testStopAnalyzer { stopHeight: startHeight + 1, txCounts: make (map [uint32] int) }
+	job := newTestJob ("stopped", startHeight, endHeight, newTestAnalyzer (t, "spend_types"), stopAnalyzer)
+	stopAnalyzer.job = job
+
+	// the block it was scanning when it was asked to stop is finished
+	job.run ()
+	if job.GetStatus () != JOB_STATUS_STOPPED || job.nextHeight != startHeight + 2 { t.Fatalf ("job is %s at %d", job.GetStatus (), job.nextHeight) }
+
+	checkpointBytes, err := os.ReadFile (filepath.Join (GetPath (), "stopped.json"))
+	if err != nil { t.Fatal (err) }
+
+	if err := job.Resume (); err != nil { t.Fatal (err) }
+	for tries := 0; tries < 100 && job.GetStatus () == JOB_STATUS_RUNNING; tries++ { time.Sleep (10 * time.Millisecond) }
+	if job.GetStatus () != JOB_STATUS_COMPLETE { t.Fatalf ("job is %s", job.GetStatus ()) }
+
+	if results := getTestResults (t, job); results != expectedResults { t.Errorf ("results %s, expected %s", results, expectedResults) }
+	for height := startHeight; height <= endHeight; height++ {
+		if stopAnalyzer.txCounts [height] != 2 { t.Errorf ("block %d was analyzed with %d transactions", height, stopAnalyzer.txCounts [height]) }
+	}
+
+	// a job that is loaded from the checkpoint after a restart continues at the next block
+	var checkpoint jobCheckpoint
+	if err := json.Unmarshal (checkpointBytes, &checkpoint); err != nil { t.Fatal (err) }
+	checkpoint.Id = "restarted"
+	checkpoint.AnalyzerNames = [] string { "spend_types" }
+	checkpointBytes, _ = json.Marshal (checkpoint)
+
+	checkpointFileName := filepath.Join (GetPath (), "restarted.json")
+	if err := os.WriteFile (checkpointFileName, checkpointBytes, 0644); err != nil { t.Fatal (err) }
+
+	restarted, err := loadCheckpoint (checkpointFileName)
+	if err != nil { t.Fatal (err) }
+	if restarted.status != JOB_STATUS_STOPPED || restarted.nextHeight != startHeight + 2 { t.Fatalf ("job is %s at %d", restarted.status, restarted.nextHeight) }
+
+	restarted.status = JOB_STATUS_RUNNING
+	restarted.run ()
+	if restarted.GetStatus () != JOB_STATUS_COMPLETE { t.Fatalf ("job is %s", restarted.GetStatus ()) }
+	if results := getTestResults (t, restarted); results != expectedResults { t.Errorf ("results %s, expected %s", results, expectedResults) }
+}
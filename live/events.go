@@ -0,0 +1,85 @@
+package live
+
+import (
+	"fmt"
+	"time"
+	"strconv"
+	"encoding/json"
+	"net/http"
+)
+
+// clients subscribe with server-sent events at /live/events
+// the most recent block is sent as a tip event when the client connects, and then every new block is pushed as it is found
+// transactions are only sent with ?txs=true, because a large block has thousands of them
+
+// proxies close connections that have been quiet for too long
+const KEEPALIVE_INTERVAL = 30 * time.Second
+
+const EVENT_TIP = "tip"
+
+func EventsHandler (response http.ResponseWriter, request *http.Request) {
+
+	if request.Method != "GET" {
+		response.Header ().Set ("Allow", "GET")
+		http.Error (response, "live events must be requested with GET.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, canFlush := response.(http.Flusher)
+	if !canFlush {
+		http.Error (response, "streaming is not supported by this connection.", http.StatusInternalServerError)
+		return
+	}
+
+	// an option without a value is true, the same as the rest api
+	includeTxs := false
+	if request.URL.Query ().Has ("txs") {
+		txs := request.URL.Query ().Get ("txs")
+		option, err := strconv.ParseBool (txs)
+		if len (txs) > 0 && err != nil {
+			http.Error (response, "option txs is not a bool", http.StatusBadRequest)
+			return
+		}
+		includeTxs = len (txs) == 0 || option
+	}
+
+	response.Header ().Set ("Content-Type", "text/event-stream")
+	response.Header ().Set ("Cache-Control", "no-cache")
+	response.Header ().Set ("Connection", "keep-alive")
+
+	s := subscribe (includeTxs)
+	defer unsubscribe (s)
+
+	if tip := getCurrentTip (); len (tip.Hash) > 0 {
+		writeEvent (response, event { name: EVENT_TIP, data: tip })
+	}
+	flusher.Flush ()
+
+	keepalive := time.NewTicker (KEEPALIVE_INTERVAL)
+	defer keepalive.Stop ()
+
+	for {
+		select {
+			case e, open := <- s.events:
+				// the subscriber was dropped because it fell behind
+				if !open { return }
+				if !writeEvent (response, e) { return }
+			case <- keepalive.C:
+				if _, err := fmt.Fprint (response, ": keepalive\n\n"); err != nil { return }
+			case <- request.Context ().Done ():
+				return
+		}
+
+		flusher.Flush ()
+	}
+}
+
+// returns false if the client is gone
+func writeEvent (response http.ResponseWriter, e event) bool {
+
+	dataBytes, err := json.Marshal (e.data)
+	if err != nil { fmt.Println (err.Error ()); return true }
+
+	_, err = fmt.Fprintf (response, "event: %s\ndata: %s\n\n", e.name, dataBytes)
+	return err == nil
+}
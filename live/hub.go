@@ -0,0 +1,76 @@
+package live
+
+import (
+	"sync"
+)
+
+// every subscriber has its own buffered channel of events
+// a subscriber that falls too far behind is dropped, and its client is expected to reconnect
+
+const SUBSCRIBER_BUFFER_SIZE = 4096
+
+type event struct {
+	name string
+	data interface {}
+}
+
+type subscriber struct {
+	events chan event
+	includeTxs bool
+}
+
+var subscribers = make (map [*subscriber] bool)
+var subscribersMutex sync.Mutex
+
+// transaction events are only sent to subscribers that asked for them
+func subscribe (includeTxs bool) *subscriber {
+
+	s := &subscriber { events: make (chan event, SUBSCRIBER_BUFFER_SIZE), includeTxs: includeTxs }
+
+	subscribersMutex.Lock ()
+	subscribers [s] = true
+	subscribersMutex.Unlock ()
+
+	return s
+}
+
+func unsubscribe (s *subscriber) {
+
+	subscribersMutex.Lock ()
+	defer subscribersMutex.Unlock ()
+
+	if subscribers [s] {
+		delete (subscribers, s)
+		close (s.events)
+	}
+}
+
+func hasSubscribers (txSubscribers bool) bool {
+
+	subscribersMutex.Lock ()
+	defer subscribersMutex.Unlock ()
+
+	for s, _ := range subscribers {
+		if !txSubscribers || s.includeTxs { return true }
+	}
+
+	return false
+}
+
+// never blocks, a subscriber whose buffer is full is dropped
+func publish (e event, isTxEvent bool) {
+
+	subscribersMutex.Lock ()
+	defer subscribersMutex.Unlock ()
+
+	for s, _ := range subscribers {
+		if isTxEvent && !s.includeTxs { continue }
+
+		select {
+			case s.events <- e:
+			default:
+				delete (subscribers, s)
+				close (s.events)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package live
+
+import (
+	"fmt"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+// the watcher follows the chain tip with the tip follower and pushes every new block to the subscribers
+// the block header is pushed as soon as the block is found, and its summary is pushed once every transaction has been classified
+// subscribers that asked for transactions also get every classified transaction of the block in between
+// and, if the node publishes them over zmq, every new mempool transaction
+
+const EVENT_BLOCK = "block"
+const EVENT_BLOCK_SUMMARY = "block_summary"
+const EVENT_TX = "tx"
+const EVENT_MEMPOOL_TX = "mempool_tx"
+
+type blockHeader struct {
+	Hash string `json:"hash"`
+	PreviousHash string `json:"previous_hash"`
+	Height uint32 `json:"height"`
+	Timestamp int64 `json:"timestamp"`
+	TxCount uint32 `json:"tx_count"`
+	Size uint32 `json:"size"`
+	Weight uint32 `json:"weight"`
+}
+
+type typeStats struct {
+	Count uint32 `json:"count"`
+	Value uint64 `json:"value"`
+}
+
+type blockSummary struct {
+	Hash string `json:"hash"`
+	Height uint32 `json:"height"`
+	TxCount uint32 `json:"tx_count"`
+	InputCount uint32 `json:"input_count"`
+	OutputCount uint32 `json:"output_count"`
+	SpendTypes map [string] typeStats `json:"spend_types"`
+	OutputTypes map [string] typeStats `json:"output_types"`
+}
+
+type classifiedTx struct {
+	Id string `json:"id"`
+	BlockHash string `json:"block_hash,omitempty"`
+	BlockHeight uint32 `json:"block_height,omitempty"`
+	SpendTypes [] string `json:"spend_types"`
+	OutputTypes [] string `json:"output_types"`
+	OutputValue uint64 `json:"output_value"`
+}
+
+func Start () {
+	node.FollowTip (node.TipHandlers { NewBlock: publishBlock, MempoolTx: publishMempoolTx })
+}
+
+// returns the header of the most recent block the tip follower has seen
+func getCurrentTip () blockHeader {
+	tip := node.GetTip ()
+	if tip.IsNil () { return blockHeader {} }
+	return makeBlockHeader (tip)
+}
+
+// the transactions are only requested if someone is subscribed, because it takes a while for large blocks
+func publishBlock (tipBlock *node.TipBlock) {
+
+	if !hasSubscribers (false) { return }
+
+	block := tipBlock.GetBlock ()
+	publish (event { name: EVENT_BLOCK, data: makeBlockHeader (block) }, false)
+
+	blockStats := btc.NewBlockStats ()
+	err := tipBlock.ForEachTx (func (tx btc.Tx) {
+		blockStats.AddTx (tx)
+		if hasSubscribers (true) { publish (event { name: EVENT_TX, data: makeClassifiedTx (tx, block) }, true) }
+	})
+
+	// the summary would be missing transactions
+	if err != nil { fmt.Println (err.Error ()); return }
+
+	publish (event { name: EVENT_BLOCK_SUMMARY, data: makeBlockSummary (block, blockStats) }, false)
+}
+
+// the previous outputs are requested so that the inputs can be classified
+func publishMempoolTx (tipTx *node.TipMempoolTx) {
+
+	if !hasSubscribers (true) { return }
+
+	publish (event { name: EVENT_MEMPOOL_TX, data: makeClassifiedTx (tipTx.GetTx (), btc.Block {}) }, true)
+}
+
+func makeBlockHeader (block btc.Block) blockHeader {
+	return blockHeader {	Hash: block.GetHash (),
+							PreviousHash: block.GetPreviousHash (),
+							Height: block.GetHeight (),
+							Timestamp: block.GetTimestamp (),
+							TxCount: block.GetTxCount (),
+							Size: block.GetSize (),
+							Weight: block.GetWeight () }
+}
+
+func makeTypeStats (stats map [string] btc.TypeStats) map [string] typeStats {
+	json := make (map [string] typeStats)
+	for typeName, s := range stats { json [typeName] = typeStats { Count: s.GetCount (), Value: s.GetValue () } }
+	return json
+}
+
+func makeBlockSummary (block btc.Block, blockStats btc.BlockStats) blockSummary {
+	return blockSummary {	Hash: block.GetHash (),
+							Height: block.GetHeight (),
+							TxCount: blockStats.GetTxCount (),
+							InputCount: blockStats.GetInputCount (),
+							OutputCount: blockStats.GetOutputCount (),
+							SpendTypes: makeTypeStats (blockStats.GetSpendTypes ()),
+							OutputTypes: makeTypeStats (blockStats.GetOutputTypes ()) }
+}
+
+// the spend types and output types are in the same order as the inputs and outputs
+func makeClassifiedTx (tx btc.Tx, block btc.Block) classifiedTx {
+
+	spendTypes := make ([] string, tx.GetInputCount ())
+	for i, input := range tx.GetInputs () { spendTypes [i] = input.GetSpendType () }
+
+	outputValue := uint64 (0)
+	outputTypes := make ([] string, tx.GetOutputCount ())
+	for o, output := range tx.GetOutputs () {
+		outputTypes [o] = output.GetOutputType ()
+		outputValue += output.GetValue ()
+	}
+
+	return classifiedTx { Id: tx.GetTxId (), BlockHash: block.GetHash (), BlockHeight: block.GetHeight (), SpendTypes: spendTypes, OutputTypes: outputTypes, OutputValue: outputValue }
+}
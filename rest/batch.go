@@ -0,0 +1,142 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+	"github.com/btc-script-explorer/scantool/index"
+)
+
+// batch functions look up many transactions, inputs or outputs in one request
+// the results are returned in the order they were requested, and an item that fails has an error instead of a result
+// every transaction is requested from the node once per batch, so inputs that spend outputs of the same transaction share the lookup
+
+const MAX_BATCH_SIZE = 1000
+
+type batchLookup struct {
+	nodeProxy *node.NodeProxy
+	txs map [string] btc.Tx
+}
+
+func newBatchLookup (nodeProxy *node.NodeProxy) batchLookup {
+	return batchLookup { nodeProxy: nodeProxy, txs: make (map [string] btc.Tx) }
+}
+
+// transactions that are not found are remembered too
+func (bl *batchLookup) getTx (txId string) btc.Tx {
+
+	tx, found := bl.txs [txId]
+	if !found {
+		tx = bl.nodeProxy.GetTx (node.TxRequest { TxId: txId })
+		bl.txs [txId] = tx
+	}
+
+	return tx
+}
+
+func (bl *batchLookup) getOutput (txId string, outputIndex uint16) btc.Output {
+
+	tx := bl.getTx (txId)
+	if tx.IsNil () || outputIndex >= tx.GetOutputCount () { return btc.Output {} }
+
+	return tx.GetOutput (outputIndex)
+}
+
+func (bl *batchLookup) getTxWithInputDetail (txId string) btc.Tx {
+
+	// the lookup and the cache share the inputs of the transaction, so the previous outputs are set on a copy
+	tx := bl.getTx (txId)
+	if tx.IsNil () { return tx }
+	tx = tx.Copy ()
+
+	for i, input := range tx.GetInputs () {
+		if input.IsCoinbase () { continue }
+		tx.SetPreviousOutput (uint16 (i), bl.getOutput (input.GetPreviousOutputTxId (), input.GetPreviousOutputIndex ()))
+	}
+
+	return tx
+}
+
+// returns the items of a batch request or an error
+func getBatchItems (requestParams map [string] interface {}, name string) ([] interface {}, *apiError) {
+
+	if requestParams [name] == nil { return nil, missingParameter (name) }
+
+	items, ok := requestParams [name].([] interface {})
+	if !ok { return nil, invalidParameter (name, name + " must be an array") }
+	if len (items) > MAX_BATCH_SIZE { return nil, &apiError { code: ERROR_INVALID_PARAMETER, message: fmt.Sprintf ("no more than %d %s can be requested at a time", MAX_BATCH_SIZE, name), parameter: name } }
+
+	return items, nil
+}
+
+// returns the tx id and index of an item in an inputs or outputs batch, or an error
+func getBatchOutpoint (item interface {}, indexName string) (string, uint16, *apiError) {
+
+	params, ok := item.(map [string] interface {})
+	if !ok { return "", 0, invalidParameter ("", "item must be an object") }
+
+	txId, ok := params ["tx_id"].(string)
+	if !ok || len (txId) != 64 { return "", 0, invalidParameter ("tx_id", "tx_id is not a valid transaction id") }
+
+	index, ok := params [indexName].(float64)
+	if !ok || index < 0 { return "", 0, invalidParameter (indexName, indexName + " must be a numeric index") }
+
+	return txId, uint16 (index), nil
+}
+
+// v1 results have the error message, v2 results have the whole error
+func batchError (err *apiError, version uint16) map [string] interface {} {
+	if version == 1 { return map [string] interface {} { "error": err.message } }
+	return map [string] interface {} { "error": err.toV2 () }
+}
+
+func batchTxToJson (lookup *batchLookup, item interface {}, includeInputDetail bool, version uint16) map [string] interface {} {
+
+	txId, ok := item.(string)
+	if !ok || len (txId) != 64 { return batchError (&apiError { code: ERROR_INVALID_PARAMETER, message: "id is not a valid transaction id", parameter: "ids" }, version) }
+
+	tx := btc.Tx {}
+	if includeInputDetail {
+		tx = lookup.getTxWithInputDetail (txId)
+	} else {
+		tx = lookup.getTx (txId)
+	}
+	if tx.IsNil () { return batchError (newApiError (ERROR_TX_NOT_FOUND, "transaction not found"), version) }
+
+	return map [string] interface {} { "tx": txToJson (tx) }
+}
+
+func batchInputToJson (lookup *batchLookup, item interface {}, version uint16) map [string] interface {} {
+
+	txId, inputIndex, paramError := getBatchOutpoint (item, "input_index")
+	if paramError != nil { return batchError (paramError, version) }
+
+	tx := lookup.getTx (txId)
+	if tx.IsNil () || inputIndex >= tx.GetInputCount () { return batchError (newApiError (ERROR_INPUT_NOT_FOUND, "input not found"), version) }
+
+	tx = tx.Copy ()
+	input := tx.GetInput (inputIndex)
+	if !input.IsCoinbase () {
+		input.SetPreviousOutput (lookup.getOutput (input.GetPreviousOutputTxId (), input.GetPreviousOutputIndex ()))
+		if len (input.GetSpendType ()) == 0 { return batchError (newApiError (ERROR_INPUT_NOT_FOUND, "input not found"), version) }
+	}
+
+	return map [string] interface {} { "input": inputToJson (input) }
+}
+
+func batchOutputToJson (lookup *batchLookup, item interface {}, version uint16) map [string] interface {} {
+
+	txId, outputIndex, paramError := getBatchOutpoint (item, "output_index")
+	if paramError != nil { return batchError (paramError, version) }
+
+	output := lookup.getOutput (txId, outputIndex)
+	if len (output.GetOutputType ()) == 0 { return batchError (newApiError (ERROR_OUTPUT_NOT_FOUND, "output not found"), version) }
+
+	outputJson := outputToJson (output)
+
+	outputSpend := index.GetOutputSpend (node.OutputRequest { TxId: txId, OutputIndex: outputIndex })
+	if !outputSpend.IsNil () { outputJson ["spent_by"] = outputSpendToJson (outputSpend) }
+
+	return map [string] interface {} { "output": outputJson }
+}
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// the error codes returned by v2, clients can rely on these but not on the messages
+const ERROR_INVALID_JSON = "invalid_json"
+const ERROR_MISSING_PARAMETER = "missing_parameter"
+const ERROR_INVALID_PARAMETER = "invalid_parameter"
+const ERROR_METHOD_NOT_ALLOWED = "method_not_allowed"
+const ERROR_UNKNOWN_FUNCTION = "unknown_function"
+const ERROR_BLOCK_NOT_FOUND = "block_not_found"
+const ERROR_TX_NOT_FOUND = "tx_not_found"
+const ERROR_INPUT_NOT_FOUND = "input_not_found"
+const ERROR_OUTPUT_NOT_FOUND = "output_not_found"
+const ERROR_JOB_NOT_FOUND = "job_not_found"
+const ERROR_EXPORT_NOT_FOUND = "export_not_found"
+const ERROR_WATCH_NOT_FOUND = "watch_not_found"
+const ERROR_REQUEST_REJECTED = "request_rejected"
+const ERROR_INDEX_DISABLED = "index_disabled"
+const ERROR_NODE_UNAVAILABLE = "node_unavailable"
+const ERROR_INTERNAL = "internal_error"
+
+var errorStatusCodes = map [string] int {	ERROR_INVALID_JSON: http.StatusBadRequest,
+											ERROR_MISSING_PARAMETER: http.StatusBadRequest,
+											ERROR_INVALID_PARAMETER: http.StatusBadRequest,
+											ERROR_METHOD_NOT_ALLOWED: http.StatusMethodNotAllowed,
+											ERROR_UNKNOWN_FUNCTION: http.StatusNotFound,
+											ERROR_BLOCK_NOT_FOUND: http.StatusNotFound,
+											ERROR_TX_NOT_FOUND: http.StatusNotFound,
+											ERROR_INPUT_NOT_FOUND: http.StatusNotFound,
+											ERROR_OUTPUT_NOT_FOUND: http.StatusNotFound,
+											ERROR_JOB_NOT_FOUND: http.StatusNotFound,
+											ERROR_EXPORT_NOT_FOUND: http.StatusNotFound,
+											ERROR_WATCH_NOT_FOUND: http.StatusNotFound,
+											ERROR_REQUEST_REJECTED: http.StatusUnprocessableEntity,
+											ERROR_INDEX_DISABLED: http.StatusServiceUnavailable,
+											ERROR_NODE_UNAVAILABLE: http.StatusServiceUnavailable,
+											ERROR_INTERNAL: http.StatusInternalServerError }
+
+var errorCodes = [] string {	ERROR_INVALID_JSON, ERROR_MISSING_PARAMETER, ERROR_INVALID_PARAMETER, ERROR_METHOD_NOT_ALLOWED, ERROR_UNKNOWN_FUNCTION,
+								ERROR_BLOCK_NOT_FOUND, ERROR_TX_NOT_FOUND, ERROR_INPUT_NOT_FOUND, ERROR_OUTPUT_NOT_FOUND, ERROR_JOB_NOT_FOUND, ERROR_EXPORT_NOT_FOUND, ERROR_WATCH_NOT_FOUND,
+								ERROR_REQUEST_REJECTED, ERROR_INDEX_DISABLED, ERROR_NODE_UNAVAILABLE, ERROR_INTERNAL }
+
+// the functions share their errors between versions
+// v1 returns the message by itself, v2 returns the whole error with a status code
+type apiError struct {
+	code string
+	message string
+	parameter string
+}
+
+func newApiError (code string, message string) *apiError {
+	return &apiError { code: code, message: message }
+}
+
+func missingParameter (name string) *apiError {
+	return &apiError { code: ERROR_MISSING_PARAMETER, message: name + " parameter is required", parameter: name }
+}
+
+// the message is the same one v1 has always returned
+func invalidParameter (name string, message string) *apiError {
+	return &apiError { code: ERROR_INVALID_PARAMETER, message: "malformed request: " + message, parameter: name }
+}
+
+func methodNotAllowed (functionName string, method string) *apiError {
+	return &apiError { code: ERROR_METHOD_NOT_ALLOWED, message: fmt.Sprintf ("%s must be sent as a %s request.", functionName, method) }
+}
+
+func (e *apiError) GetStatusCode () int {
+	return errorStatusCodes [e.code]
+}
+
+// v1 returns request format errors as a RestError and all other errors as bare strings
+func (e *apiError) toV1 () string {
+	switch e.code {
+		case ERROR_METHOD_NOT_ALLOWED, ERROR_INVALID_JSON, ERROR_UNKNOWN_FUNCTION:
+			fmt.Println (e.message)
+			return marshalWithOptions (RestError { Error: e.message }, nil)
+		case ERROR_NODE_UNAVAILABLE:
+			return ""
+	}
+
+	return e.message
+}
+
+// the parameter is only included when the error was caused by a specific request parameter
+func (e *apiError) toV2 () map [string] interface {} {
+
+	json := make (map [string] interface {})
+	json ["code"] = e.code
+	json ["message"] = e.message
+	if len (e.parameter) > 0 { json ["parameter"] = e.parameter }
+
+	return json
+}
@@ -0,0 +1,152 @@
+package rest
+
+import (
+	"encoding/hex"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/index"
+)
+
+// the parameters shared by the index query functions
+type indexQueryParams struct {
+	startHeight uint32
+	endHeight uint32
+	opcodes [] string
+	fingerprint string
+	limit int
+	continueFrom string
+}
+
+// returns the query parameters or an error
+func getIndexQueryParams (requestParams map [string] interface {}) (indexQueryParams, *apiError) {
+
+	params := indexQueryParams {}
+
+	if requestParams ["start_height"] == nil { return params, missingParameter ("start_height") }
+	startHeight, ok := requestParams ["start_height"].(float64)
+	if !ok || startHeight < 0 { return params, invalidParameter ("start_height", "parameter start_height is not a valid block height") }
+	params.startHeight = uint32 (startHeight)
+
+	if requestParams ["end_height"] == nil { return params, missingParameter ("end_height") }
+	endHeight, ok := requestParams ["end_height"].(float64)
+	if !ok || endHeight < 0 { return params, invalidParameter ("end_height", "parameter end_height is not a valid block height") }
+	params.endHeight = uint32 (endHeight)
+
+	if requestParams ["opcodes"] != nil {
+		opcodeParams, ok := requestParams ["opcodes"].([] interface {})
+		if !ok { return params, invalidParameter ("opcodes", "opcodes must be an array") }
+		for _, opcodeParam := range opcodeParams {
+			opcode, ok := opcodeParam.(string)
+			if !ok { return params, invalidParameter ("opcodes", "opcodes must contain strings") }
+			params.opcodes = append (params.opcodes, opcode)
+		}
+	}
+
+	var paramError *apiError
+	if params.fingerprint, paramError = getOptionalString (requestParams, "fingerprint"); paramError != nil { return params, paramError }
+	if params.continueFrom, paramError = getOptionalString (requestParams, "continue_from"); paramError != nil { return params, paramError }
+
+	if requestParams ["limit"] != nil {
+		limit, ok := requestParams ["limit"].(float64)
+		if !ok { return params, invalidParameter ("limit", "parameter limit is not a number") }
+		params.limit = int (limit)
+	}
+
+	return params, nil
+}
+
+func getOptionalString (requestParams map [string] interface {}, name string) (string, *apiError) {
+	if requestParams [name] == nil { return "", nil }
+
+	value, ok := requestParams [name].(string)
+	if !ok { return "", invalidParameter (name, "parameter " + name + " is not a string") }
+
+	return value, nil
+}
+
+func getOptionalStrings (requestParams map [string] interface {}, name string) ([] string, *apiError) {
+	if requestParams [name] == nil { return nil, nil }
+
+	valueParams, ok := requestParams [name].([] interface {})
+	if !ok { return nil, invalidParameter (name, name + " must be an array") }
+
+	values := make ([] string, 0, len (valueParams))
+	for _, valueParam := range valueParams {
+		value, ok := valueParam.(string)
+		if !ok { return nil, invalidParameter (name, name + " must contain strings") }
+		values = append (values, value)
+	}
+
+	return values, nil
+}
+
+func indexResultsToJson (results interface {}, continueFrom string) map [string] interface {} {
+
+	json := make (map [string] interface {})
+	json ["results"] = results
+	if len (continueFrom) > 0 { json ["continue_from"] = continueFrom }
+
+	return json
+}
+
+func indexStatusToJson (currentHeight int32) map [string] interface {} {
+
+	json := make (map [string] interface {})
+	json ["enabled"] = index.IsOpen ()
+	if !index.IsOpen () { return json }
+
+	status := index.GetStatus ()
+	json ["file"] = status.File
+	json ["start_height"] = status.StartHeight
+	json ["indexed_height"] = int64 (status.NextHeight) - 1
+	json ["current_block_height"] = currentHeight
+	if len (status.Error) > 0 { json ["error"] = status.Error }
+
+	return json
+}
+
+// either an address or an output script can be used to look up a history
+// returns the output script or an error
+func getAddressScriptFromParams (requestParams map [string] interface {}) (btc.Script, *apiError) {
+
+	address, paramError := getOptionalString (requestParams, "address")
+	if paramError != nil { return btc.Script {}, paramError }
+
+	outputScriptHex, paramError := getOptionalString (requestParams, "output_script")
+	if paramError != nil { return btc.Script {}, paramError }
+
+	if len (address) > 0 {
+		scriptBytes, err := btc.GetOutputScriptFromAddress (address)
+		if err != nil { return btc.Script {}, invalidParameter ("address", err.Error ()) }
+		return btc.NewScript (scriptBytes), nil
+	}
+
+	if len (outputScriptHex) > 0 {
+		scriptBytes, err := hex.DecodeString (outputScriptHex)
+		if err != nil || len (scriptBytes) == 0 { return btc.Script {}, invalidParameter ("output_script", "parameter output_script is not valid hex") }
+		return btc.NewScript (scriptBytes), nil
+	}
+
+	return btc.Script {}, newApiError (ERROR_MISSING_PARAMETER, "address or output_script parameter is required")
+}
+
+func addressHistoryToJson (outputScript btc.Script, history index.AddressHistory) map [string] interface {} {
+
+	json := make (map [string] interface {})
+	json ["address"] = btc.GetAddress (outputScript)
+	json ["output_script"] = outputScript.AsHex ()
+	json ["script_hash"] = history.ScriptHash
+	json ["funding_count"] = history.FundingCount
+	json ["spending_count"] = history.SpendingCount
+	json ["received"] = history.Received
+	json ["spent"] = history.Spent
+	json ["balance"] = history.Balance
+	json ["spend_types"] = history.SpendTypes
+	json ["history"] = history.Events
+
+	status := index.GetStatus ()
+	json ["start_height"] = status.StartHeight
+	json ["indexed_height"] = int64 (status.NextHeight) - 1
+
+	return json
+}
@@ -0,0 +1,484 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btc-script-explorer/scantool/app"
+	"github.com/btc-script-explorer/scantool/watch"
+)
+
+// the v2 functions and the json they accept and return
+// the openapi document is generated from these, and every v2 response is checked against its schema before it is sent
+// a function that is added to handleFunction must be added here too before it can be used in v2
+
+type apiFunction struct {
+	name string
+	method string
+	summary string
+	request string
+	response string
+	streaming bool
+	resources [] string
+}
+
+var apiFunctions = [] apiFunction {	{ name: "block", method: "POST", summary: "Returns a block.", request: "BlockRequest", response: "Block", resources: [] string { "/block", "/block/{block}" } },
+									{ name: "block_stats", method: "POST", summary: "Returns the input, output and script type statistics of a block.", request: "BlockRequest", response: "BlockStats", resources: [] string { "/block/{block}/stats" } },
+									{ name: "opcode_stats", method: "POST", summary: "Returns opcode counts by script type for a range of blocks.", request: "OpcodeStatsRequest", response: "OpcodeStats" },
+									{ name: "tx", method: "POST", summary: "Returns a transaction.", request: "TxRequest", response: "Tx", resources: [] string { "/tx/{id}" } },
+									{ name: "input", method: "POST", summary: "Returns an input with its previous output.", request: "InputRequest", response: "Input", resources: [] string { "/tx/{tx_id}/input/{input_index}" } },
+									{ name: "output", method: "POST", summary: "Returns an output and the input that spent it.", request: "OutputRequest", response: "Output", resources: [] string { "/tx/{tx_id}/output/{output_index}" } },
+									{ name: "txs", method: "POST", summary: "Returns a list of transactions.", request: "TxsRequest", response: "BatchResponse" },
+									{ name: "inputs", method: "POST", summary: "Returns a list of inputs.", request: "InputsRequest", response: "BatchResponse" },
+									{ name: "outputs", method: "POST", summary: "Returns a list of outputs.", request: "OutputsRequest", response: "BatchResponse" },
+									{ name: "psbt", method: "POST", summary: "Decodes a partially signed transaction.", request: "PsbtRequest", response: "Psbt" },
+									{ name: "decode_tx", method: "POST", summary: "Decodes a raw transaction.", request: "DecodeTxRequest", response: "Tx" },
+									{ name: "job_start", method: "POST", summary: "Starts a job that analyzes a range of blocks.", request: "JobStartRequest", response: "Job" },
+									{ name: "job", method: "POST", summary: "Returns the progress and results of a job.", request: "IdRequest", response: "Job" },
+									{ name: "job_stop", method: "POST", summary: "Stops a job.", request: "IdRequest", response: "Job" },
+									{ name: "job_resume", method: "POST", summary: "Resumes a stopped or failed job.", request: "IdRequest", response: "Job" },
+									{ name: "jobs", method: "GET", summary: "Returns every job without its results.", response: "JobList" },
+									{ name: "export_start", method: "POST", summary: "Starts an export of a range of blocks.", request: "ExportStartRequest", response: "Export" },
+									{ name: "export", method: "POST", summary: "Returns the progress of an export.", request: "IdRequest", response: "Export" },
+									{ name: "export_stop", method: "POST", summary: "Stops an export.", request: "IdRequest", response: "Export" },
+									{ name: "exports", method: "GET", summary: "Returns every export.", response: "ExportList" },
+									{ name: "watch_add", method: "POST", summary: "Adds a watch that posts matching transactions to a webhook.", request: "WatchAddRequest", response: "Watch" },
+									{ name: "watch_remove", method: "POST", summary: "Removes a watch.", request: "IdRequest", response: "Watch" },
+									{ name: "watches", method: "GET", summary: "Returns every watch.", response: "WatchList" },
+									{ name: "watch_deliveries", method: "POST", summary: "Returns the most recent webhook delivery attempts.", request: "WatchDeliveriesRequest", response: "WatchDeliveryList" },
+									{ name: "index_status", method: "GET", summary: "Returns the status of the index.", response: "IndexStatus" },
+									{ name: "index_inputs", method: "POST", summary: "Queries the indexed inputs.", request: "IndexInputsRequest", response: "IndexInputResults" },
+									{ name: "index_outputs", method: "POST", summary: "Queries the indexed outputs.", request: "IndexOutputsRequest", response: "IndexOutputResults" },
+									{ name: "address", method: "POST", summary: "Returns the history of an address or output script.", request: "AddressRequest", response: "AddressHistory" },
+									{ name: "current_block_height", method: "GET", summary: "Returns the current block height.", response: "CurrentBlockHeight" },
+									{ name: "script_search", method: "POST", summary: "Streams the script fields that match a pattern, one per line, followed by a summary.", request: "ScriptSearchRequest", response: "ScriptSearchLine", streaming: true },
+									{ name: "block_txs", method: "POST", summary: "Streams every transaction in a block, one per line, followed by a summary.", request: "BlockRequest", response: "BlockTxsLine", streaming: true } }
+
+var apiSchemas = getApiSchemas ()
+
+func getApiFunction (name string) (apiFunction, bool) {
+	for _, function := range apiFunctions {
+		if function.name == name { return function, true }
+	}
+
+	return apiFunction {}, false
+}
+
+func typeSchema (schemaType string) map [string] interface {} {
+	return map [string] interface {} { "type": schemaType }
+}
+
+func nullable (schema map [string] interface {}) map [string] interface {} {
+	schema ["nullable"] = true
+	return schema
+}
+
+func schemaRef (name string) map [string] interface {} {
+	return map [string] interface {} { "$ref": "#/components/schemas/" + name }
+}
+
+func arraySchema (items map [string] interface {}) map [string] interface {} {
+	return map [string] interface {} { "type": "array", "items": items }
+}
+
+// an object with any keys, each one having a value of the given schema
+func mapSchema (values map [string] interface {}) map [string] interface {} {
+	return map [string] interface {} { "type": "object", "additionalProperties": values }
+}
+
+func objectSchema (required [] string, properties map [string] interface {}) map [string] interface {} {
+	schema := map [string] interface {} { "type": "object", "properties": properties }
+	if len (required) > 0 { schema ["required"] = required }
+	return schema
+}
+
+func enumSchema (values [] string) map [string] interface {} {
+	return map [string] interface {} { "type": "string", "enum": values }
+}
+
+func getApiSchemas () map [string] interface {} {
+
+	str := func () map [string] interface {} { return typeSchema ("string") }
+	integer := func () map [string] interface {} { return typeSchema ("integer") }
+	boolean := func () map [string] interface {} { return typeSchema ("boolean") }
+	stringArray := func () map [string] interface {} { return arraySchema (str ()) }
+	options := schemaRef ("Options")
+	heightRange := func (properties map [string] interface {}) map [string] interface {} {
+		properties ["start_height"] = integer ()
+		properties ["end_height"] = integer ()
+		properties ["options"] = options
+		return properties
+	}
+
+	schemas := make (map [string] interface {})
+
+	// errors
+	schemas ["Error"] = objectSchema ([] string { "code", "message" }, map [string] interface {} {	"code": enumSchema (errorCodes),
+																									"message": str (),
+																									"parameter": str () })
+	schemas ["ErrorResponse"] = objectSchema ([] string { "error" }, map [string] interface {} { "error": schemaRef ("Error") })
+
+	// requests
+	schemas ["Options"] = objectSchema (nil, map [string] interface {} { "human_readable": boolean (), "include_input_detail": boolean () })
+	schemas ["IdRequest"] = objectSchema ([] string { "id" }, map [string] interface {} { "id": str (), "options": options })
+	schemas ["BlockRequest"] = objectSchema (nil, map [string] interface {} { "hash": str (), "height": integer (), "options": options })
+	schemas ["OpcodeStatsRequest"] = objectSchema ([] string { "start_height" }, heightRange (map [string] interface {} {	"script_types": stringArray (),
+																															"opcodes": stringArray (),
+																															"per_block": boolean () }))
+	schemas ["TxRequest"] = objectSchema ([] string { "id" }, map [string] interface {} { "id": str (), "options": options })
+	schemas ["InputRequest"] = objectSchema ([] string { "tx_id", "input_index" }, map [string] interface {} { "tx_id": str (), "input_index": integer (), "options": options })
+	schemas ["OutputRequest"] = objectSchema ([] string { "tx_id", "output_index" }, map [string] interface {} { "tx_id": str (), "output_index": integer (), "options": options })
+	schemas ["TxsRequest"] = objectSchema ([] string { "ids" }, map [string] interface {} { "ids": stringArray (), "options": options })
+	schemas ["InputsRequest"] = objectSchema ([] string { "inputs" }, map [string] interface {} { "inputs": arraySchema (schemaRef ("InputRequest")), "options": options })
+	schemas ["OutputsRequest"] = objectSchema ([] string { "outputs" }, map [string] interface {} { "outputs": arraySchema (schemaRef ("OutputRequest")), "options": options })
+	schemas ["PsbtRequest"] = objectSchema ([] string { "psbt" }, map [string] interface {} { "psbt": str (), "options": options })
+	schemas ["PreviousOutput"] = objectSchema ([] string { "tx_id", "output_index", "value", "output_script" }, map [string] interface {} {	"tx_id": str (),
+																																			"output_index": integer (),
+																																			"value": integer (),
+																																			"output_script": str () })
+	schemas ["DecodeTxRequest"] = objectSchema ([] string { "hex" }, map [string] interface {} { "hex": str (), "previous_outputs": arraySchema (schemaRef ("PreviousOutput")), "options": options })
+	schemas ["JobStartRequest"] = objectSchema ([] string { "start_height", "end_height", "analyzers" }, heightRange (map [string] interface {} { "analyzers": stringArray () }))
+	schemas ["WatchAddRequest"] = objectSchema ([] string { "type", "value", "url" }, map [string] interface {} { "type": enumSchema (watch.GetRuleTypes ()), "value": str (), "url": str (), "options": options })
+	schemas ["WatchDeliveriesRequest"] = objectSchema (nil, map [string] interface {} { "watch_id": str (), "options": options })
+	schemas ["ExportStartRequest"] = objectSchema ([] string { "start_height", "end_height", "format" }, heightRange (map [string] interface {} {	"format": str (),
+																																					"tables": stringArray () }))
+	schemas ["IndexInputsRequest"] = objectSchema ([] string { "start_height", "end_height" }, heightRange (map [string] interface {} {	"spend_type": str (),
+																																			"opcodes": stringArray (),
+																																			"fingerprint": str (),
+																																			"inscriptions_only": boolean (),
+																																			"content_type": str (),
+																																			"limit": integer (),
+																																			"continue_from": str () }))
+	schemas ["IndexOutputsRequest"] = objectSchema ([] string { "start_height", "end_height" }, heightRange (map [string] interface {} {	"output_type": str (),
+																																			"opcodes": stringArray (),
+																																			"fingerprint": str (),
+																																			"limit": integer (),
+																																			"continue_from": str () }))
+	schemas ["AddressRequest"] = objectSchema (nil, map [string] interface {} { "address": str (), "output_script": str (), "limit": integer (), "options": options })
+	schemas ["ScriptSearchRequest"] = objectSchema ([] string { "pattern", "start_height", "end_height" }, map [string] interface {} {	"pattern": str (),
+																																		"start_height": integer (),
+																																		"end_height": integer (),
+																																		"script_types": stringArray (),
+																																		"limit": integer () })
+
+	// blocks
+	schemas ["Block"] = objectSchema ([] string { "hash", "height", "tx_count", "tx_ids" }, map [string] interface {} {	"hash": str (),
+																														"previous_hash": str (),
+																														"next_hash": str (),
+																														"height": integer (),
+																														"version": integer (),
+																														"timestamp": integer (),
+																														"median_time": integer (),
+																														"merkle_root": str (),
+																														"merkle_root_valid": boolean (),
+																														"bits": str (),
+																														"nonce": integer (),
+																														"difficulty": typeSchema ("number"),
+																														"chainwork": str (),
+																														"size": integer (),
+																														"stripped_size": integer (),
+																														"weight": integer (),
+																														"tx_count": integer (),
+																														"tx_ids": stringArray () })
+	schemas ["TypeStats"] = mapSchema (objectSchema ([] string { "count", "value" }, map [string] interface {} { "count": integer (), "value": integer () }))
+	schemas ["BlockStats"] = objectSchema ([] string { "hash", "height", "tx_count" }, map [string] interface {} {	"hash": str (),
+																													"height": integer (),
+																													"tx_count": integer (),
+																													"input_count": integer (),
+																													"output_count": integer (),
+																													"input_value": integer (),
+																													"output_value": integer (),
+																													"spend_types": schemaRef ("TypeStats"),
+																													"output_types": schemaRef ("TypeStats"),
+																													"serialized_script_types": schemaRef ("TypeStats"),
+																													"data_types": schemaRef ("TypeStats") })
+	schemas ["OpcodeCounts"] = mapSchema (mapSchema (integer ()))
+	schemas ["OpcodeStats"] = objectSchema ([] string { "start_height", "end_height", "opcodes" }, map [string] interface {} {	"start_height": integer (),
+																																"end_height": integer (),
+																																"opcodes": schemaRef ("OpcodeCounts"),
+																																"blocks": arraySchema (objectSchema ([] string { "height", "hash", "opcodes" }, map [string] interface {} {	"height": integer (),
+																																																											"hash": str (),
+																																																											"opcodes": schemaRef ("OpcodeCounts") })) })
+
+	// transactions
+	schemas ["Field"] = objectSchema ([] string { "hex" }, map [string] interface {} { "hex": str (), "type": str () })
+	schemas ["Script"] = objectSchema ([] string { "hex", "fields", "parse_error" }, map [string] interface {} {	"hex": str (),
+																												"fields": arraySchema (schemaRef ("Field")),
+																												"is_ordinal": boolean (),
+																												"is_multisig": boolean (),
+																												"parse_error": boolean () })
+	schemas ["Segwit"] = objectSchema ([] string { "fields" }, map [string] interface {} {	"fields": arraySchema (objectSchema ([] string { "hex" }, map [string] interface {} {	"hex": str (),
+																																											"type": str (),
+																																											"leaf_version": integer (),
+																																											"parity": integer (),
+																																											"tap_leaf_hash": stringArray () })),
+																							"witness_script": schemaRef ("Script"),
+																							"tap_script": schemaRef ("Script") })
+	schemas ["OutputSpend"] = objectSchema ([] string { "spent" }, map [string] interface {} {	"spent": boolean (),
+																								"tx_id": str (),
+																								"input_index": integer (),
+																								"spend_type": str (),
+																								"in_mempool": boolean (),
+																								"block_height": integer () })
+	schemas ["Output"] = objectSchema ([] string { "value", "output_script", "output_type" }, map [string] interface {} {	"value": integer (),
+																															"output_script": schemaRef ("Script"),
+																															"output_type": str (),
+																															"address": str (),
+																															"spent_by": schemaRef ("OutputSpend") })
+	schemas ["Input"] = objectSchema ([] string { "coinbase", "input_script", "sequence" }, map [string] interface {} {	"coinbase": boolean (),
+																														"previous_output_tx_id": str (),
+																														"previous_output_index": integer (),
+																														"input_script": schemaRef ("Script"),
+																														"segwit": schemaRef ("Segwit"),
+																														"sequence": integer (),
+																														"previous_output": schemaRef ("Output"),
+																														"redeem_script": schemaRef ("Script"),
+																														"spend_type": str () })
+	schemas ["MempoolEntry"] = objectSchema (nil, map [string] interface {} {	"time_first_seen": integer (),
+																				"fee": integer (),
+																				"vsize": integer (),
+																				"ancestor_count": integer (),
+																				"ancestor_size": integer (),
+																				"descendant_count": integer (),
+																				"descendant_size": integer (),
+																				"depends": nullable (stringArray ()),
+																				"spent_by": nullable (stringArray ()) })
+	schemas ["Tx"] = objectSchema ([] string { "id", "inputs", "outputs", "status" }, map [string] interface {} {	"id": str (),
+																													"version": integer (),
+																													"inputs": arraySchema (schemaRef ("Input")),
+																													"outputs": arraySchema (schemaRef ("Output")),
+																													"locktime": integer (),
+																													"coinbase": boolean (),
+																													"bip141": boolean (),
+																													"blockhash": str (),
+																													"blocktime": integer (),
+																													"status": enumSchema ([] string { "confirmed", "unconfirmed" }),
+																													"mempool": schemaRef ("MempoolEntry") })
+	schemas ["BatchResponse"] = objectSchema ([] string { "results" }, map [string] interface {} {	"results": arraySchema (objectSchema (nil, map [string] interface {} {	"tx": schemaRef ("Tx"),
+																																											"input": schemaRef ("Input"),
+																																											"output": schemaRef ("Output"),
+																																											"error": schemaRef ("Error") })) })
+
+	// psbt, the bip174 fields are only included when they are in the psbt
+	schemas ["Bip32Derivation"] = objectSchema ([] string { "public_key", "fingerprint", "path" }, map [string] interface {} {	"public_key": str (),
+																																"fingerprint": str (),
+																																"path": str (),
+																																"leaf_hashes": stringArray () })
+	schemas ["PsbtInput"] = objectSchema ([] string { "finalized" }, map [string] interface {} {	"finalized": boolean (),
+																									"sighash_type": integer (),
+																									"redeem_script": schemaRef ("Script"),
+																									"witness_script": schemaRef ("Script"),
+																									"bip32_derivations": arraySchema (schemaRef ("Bip32Derivation")),
+																									"tap_key_signature": schemaRef ("Field"),
+																									"tap_bip32_derivations": arraySchema (schemaRef ("Bip32Derivation")),
+																									"tap_internal_key": str (),
+																									"tap_merkle_root": str () })
+	schemas ["PsbtOutput"] = objectSchema (nil, map [string] interface {} {	"redeem_script": schemaRef ("Script"),
+																			"witness_script": schemaRef ("Script"),
+																			"bip32_derivations": arraySchema (schemaRef ("Bip32Derivation")),
+																			"tap_internal_key": str (),
+																			"tap_bip32_derivations": arraySchema (schemaRef ("Bip32Derivation")) })
+	schemas ["Psbt"] = objectSchema ([] string { "version", "tx", "inputs", "outputs" }, map [string] interface {} {	"version": integer (),
+																														"tx": schemaRef ("Tx"),
+																														"fee": integer (),
+																														"xpubs": arraySchema (schemaRef ("Bip32Derivation")),
+																														"inputs": arraySchema (schemaRef ("PsbtInput")),
+																														"outputs": arraySchema (schemaRef ("PsbtOutput")) })
+
+	// jobs and exports
+	schemas ["Job"] = objectSchema ([] string { "id", "status", "start_height", "end_height" }, map [string] interface {} {	"id": str (),
+																																"status": str (),
+																																"start_height": integer (),
+																																"end_height": integer (),
+																																"block_count": integer (),
+																																"blocks_scanned": integer (),
+																																"percent_complete": typeSchema ("number"),
+																																"created": integer (),
+																																"finished": integer (),
+																																"analyzers": stringArray (),
+																																"error": str (),
+																																"results": typeSchema ("object") })
+	schemas ["JobList"] = objectSchema ([] string { "jobs" }, map [string] interface {} { "jobs": arraySchema (schemaRef ("Job")) })
+	schemas ["Export"] = objectSchema ([] string { "id", "status", "start_height", "end_height", "files" }, map [string] interface {} {	"id": str (),
+																																			"status": str (),
+																																			"start_height": integer (),
+																																			"end_height": integer (),
+																																			"next_height": integer (),
+																																			"block_count": integer (),
+																																			"blocks_exported": integer (),
+																																			"percent_complete": typeSchema ("number"),
+																																			"created": integer (),
+																																			"finished": integer (),
+																																			"format": str (),
+																																			"files": arraySchema (objectSchema ([] string { "table", "file", "row_count" }, map [string] interface {} {	"table": str (),
+																																																														"file": str (),
+																																																														"row_count": integer () })),
+																																			"error": str () })
+	schemas ["ExportList"] = objectSchema ([] string { "exports" }, map [string] interface {} { "exports": arraySchema (schemaRef ("Export")) })
+
+	// watches
+	schemas ["Watch"] = objectSchema ([] string { "id", "type", "value", "url", "created" }, map [string] interface {} {	"id": str (),
+																															"type": enumSchema (watch.GetRuleTypes ()),
+																															"value": str (),
+																															"url": str (),
+																															"created": integer () })
+	schemas ["WatchList"] = objectSchema ([] string { "watches" }, map [string] interface {} { "watches": arraySchema (schemaRef ("Watch")) })
+	schemas ["WatchDelivery"] = objectSchema ([] string { "delivery_id", "watch_id", "url", "event", "tx_id", "attempt", "status", "time" }, map [string] interface {} {	"delivery_id": str (),
+																																										"watch_id": str (),
+																																										"url": str (),
+																																										"event": str (),
+																																										"tx_id": str (),
+																																										"attempt": integer (),
+																																										"status": str (),
+																																										"status_code": integer (),
+																																										"error": str (),
+																																										"time": integer () })
+	schemas ["WatchDeliveryList"] = objectSchema ([] string { "deliveries" }, map [string] interface {} { "deliveries": arraySchema (schemaRef ("WatchDelivery")) })
+
+	// index
+	schemas ["IndexStatus"] = objectSchema ([] string { "enabled" }, map [string] interface {} {	"enabled": boolean (),
+																									"file": str (),
+																									"start_height": integer (),
+																									"indexed_height": integer (),
+																									"current_block_height": integer (),
+																									"error": str () })
+	schemas ["InputRecord"] = objectSchema ([] string { "height", "tx_id", "input_index" }, map [string] interface {} {	"height": integer (),
+																														"tx_id": str (),
+																														"input_index": integer (),
+																														"spend_type": str (),
+																														"value": integer (),
+																														"fingerprint": str (),
+																														"opcodes": nullable (stringArray ()),
+																														"inscription": boolean (),
+																														"content_type": str (),
+																														"script_hash": str (),
+																														"previous_output_tx_id": str (),
+																														"previous_output_index": integer () })
+	schemas ["OutputRecord"] = objectSchema ([] string { "height", "tx_id", "output_index" }, map [string] interface {} {	"height": integer (),
+																															"tx_id": str (),
+																															"output_index": integer (),
+																															"output_type": str (),
+																															"value": integer (),
+																															"fingerprint": str (),
+																															"opcodes": nullable (stringArray ()),
+																															"script_hash": str () })
+	schemas ["IndexInputResults"] = objectSchema ([] string { "results" }, map [string] interface {} { "results": arraySchema (schemaRef ("InputRecord")), "continue_from": str () })
+	schemas ["IndexOutputResults"] = objectSchema ([] string { "results" }, map [string] interface {} { "results": arraySchema (schemaRef ("OutputRecord")), "continue_from": str () })
+	schemas ["AddressEvent"] = objectSchema ([] string { "height", "tx_id", "type", "index", "value", "balance" }, map [string] interface {} {	"height": integer (),
+																																				"tx_id": str (),
+																																				"type": str (),
+																																				"index": integer (),
+																																				"value": integer (),
+																																				"spend_type": str (),
+																																				"balance": integer () })
+	schemas ["AddressHistory"] = objectSchema ([] string { "output_script", "script_hash", "balance", "history" }, map [string] interface {} {	"address": str (),
+																																				"output_script": str (),
+																																				"script_hash": str (),
+																																				"funding_count": integer (),
+																																				"spending_count": integer (),
+																																				"received": integer (),
+																																				"spent": integer (),
+																																				"balance": integer (),
+																																				"spend_types": nullable (mapSchema (integer ())),
+																																				"history": nullable (arraySchema (schemaRef ("AddressEvent"))),
+																																				"start_height": integer (),
+																																				"indexed_height": integer () })
+	schemas ["CurrentBlockHeight"] = objectSchema ([] string { "current_block_height" }, map [string] interface {} { "current_block_height": integer () })
+
+	// streaming lines, only one of the properties is included in each line
+	schemas ["ScriptSearchLine"] = objectSchema (nil, map [string] interface {} {	"height": integer (),
+																					"tx_id": str (),
+																					"input": boolean (),
+																					"index": integer (),
+																					"type": str (),
+																					"script_type": str (),
+																					"position": integer (),
+																					"script": str (),
+																					"template": str (),
+																					"summary": objectSchema (nil, map [string] interface {} {	"start_height": integer (),
+																																				"next_height": integer (),
+																																				"match_count": integer (),
+																																				"complete": boolean () }),
+																					"error": schemaRef ("Error") })
+	schemas ["BlockTxsLine"] = objectSchema (nil, map [string] interface {} {	"id": str (),
+																				"block_index": integer (),
+																				"inputs": arraySchema (schemaRef ("Input")),
+																				"outputs": arraySchema (schemaRef ("Output")),
+																				"summary": objectSchema (nil, map [string] interface {} {	"hash": str (),
+																																			"height": integer (),
+																																			"tx_count": integer () }),
+																				"error": schemaRef ("Error") })
+
+	return schemas
+}
+
+// the openapi 3.0 document for v2
+func getOpenApiDocument () map [string] interface {} {
+
+	paths := make (map [string] interface {})
+	for _, function := range apiFunctions {
+
+		contentType := "application/json"
+		if function.streaming { contentType = "application/x-ndjson" }
+
+		operation := make (map [string] interface {})
+		operation ["operationId"] = function.name
+		operation ["summary"] = function.summary
+		if len (function.request) > 0 {
+			operation ["requestBody"] = map [string] interface {} {	"required": true,
+																	"content": map [string] interface {} { "application/json": map [string] interface {} { "schema": schemaRef (function.request) } } }
+		}
+		operation ["responses"] = map [string] interface {} {	"200": map [string] interface {} {	"description": "OK",
+																									"content": map [string] interface {} { contentType: map [string] interface {} { "schema": schemaRef (function.response) } } },
+																"default": map [string] interface {} { "$ref": "#/components/responses/Error" } }
+
+		addOperation (paths, "/" + function.name, strings.ToLower (function.method), operation)
+
+		// the same function requested with GET, with the keys in the path and the options in the query string
+		for _, resource := range function.resources {
+
+			parameters := make ([] map [string] interface {}, 0)
+			for _, part := range strings.Split (resource, "/") {
+				if !strings.HasPrefix (part, "{") { continue }
+
+				name := strings.Trim (part, "{}")
+				schema := typeSchema ("string")
+				if strings.HasSuffix (name, "_index") { schema = typeSchema ("integer") }
+
+				parameter := map [string] interface {} { "name": name, "in": "path", "required": true, "schema": schema }
+				if name == "block" { parameter ["description"] = "a block hash or height" }
+				parameters = append (parameters, parameter)
+			}
+			for _, option := range [] string { "human_readable", "include_input_detail" } {
+				parameters = append (parameters, map [string] interface {} { "name": option, "in": "query", "required": false, "schema": typeSchema ("boolean") })
+			}
+
+			resourceOperation := make (map [string] interface {})
+			for key, value := range operation { resourceOperation [key] = value }
+			// a resource without keys is the most recent one
+			resourceOperation ["operationId"] = "get_" + function.name
+			if !strings.Contains (resource, "{") { resourceOperation ["operationId"] = "get_latest_" + function.name }
+			resourceOperation ["parameters"] = parameters
+			delete (resourceOperation, "requestBody")
+
+			addOperation (paths, resource, "get", resourceOperation)
+		}
+	}
+
+	document := make (map [string] interface {})
+	document ["openapi"] = "3.0.3"
+	document ["info"] = map [string] interface {} { "title": "Scantool REST API", "version": fmt.Sprintf ("2 (scantool %s)", app.GetVersion ()) }
+	document ["servers"] = [] map [string] interface {} { { "url": app.Settings.GetFullUrl () + "/rest/v2" } }
+	document ["paths"] = paths
+	document ["components"] = map [string] interface {} {	"schemas": apiSchemas,
+															"responses": map [string] interface {} { "Error": map [string] interface {} {	"description": "The request failed, the code identifies the error.",
+																																			"content": map [string] interface {} { "application/json": map [string] interface {} { "schema": schemaRef ("ErrorResponse") } } } } }
+
+	return document
+}
+
+// functions and resources can share a path, with a different method
+func addOperation (paths map [string] interface {}, path string, method string, operation map [string] interface {}) {
+	if paths [path] == nil { paths [path] = make (map [string] interface {}) }
+	paths [path].(map [string] interface {}) [method] = operation
+}
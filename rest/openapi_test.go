@@ -0,0 +1,186 @@
+package rest
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// a p2wpkh spend with one p2wpkh output
+const testSegwitTxHex = "02000000000101" + "1111111111111111111111111111111111111111111111111111111111111111" + "00000000" + "00" + "ffffffff" +
+						"01" + "e803000000000000" + "160014" + "2222222222222222222222222222222222222222" +
+						"02" + "47" + "3044022011111111111111111111111111111111111111111111111111111111111111110220222222222222222222222222222222222222222222222222222222222222222201" +
+						"21" + "023333333333333333333333333333333333333333333333333333333333333333" + "00000000"
+
+// the schemas are taken from openapi.json the way a client would read them, not from the maps they are built from
+func getServedSchemas (t *testing.T) (map [string] interface {}, map [string] interface {}) {
+
+	var document map [string] interface {}
+	if err := json.Unmarshal ([] byte (marshalWithOptions (getOpenApiDocument (), nil)), &document); err != nil { t.Fatal (err) }
+
+	return document, document ["components"].(map [string] interface {}) ["schemas"].(map [string] interface {})
+}
+
+func checkResponse (t *testing.T, schemaName string, response interface {}) {
+
+	_, schemas := getServedSchemas (t)
+
+	var decoded interface {}
+	if err := json.Unmarshal ([] byte (marshalWithOptions (response, nil)), &decoded); err != nil { t.Fatal (err) }
+
+	if mismatch := checkSchema (decoded, map [string] interface {} { "$ref": "#/components/schemas/" + schemaName }, schemas, schemaName); len (mismatch) > 0 {
+		t.Errorf ("%s response does not match the schema: %s", schemaName, mismatch)
+	}
+}
+
+// only the parts of json schema that are used by the api schemas are checked
+func checkSchema (value interface {}, schema map [string] interface {}, schemas map [string] interface {}, path string) string {
+
+	if ref, isRef := schema ["$ref"].(string); isRef {
+		return checkSchema (value, schemas [strings.TrimPrefix (ref, "#/components/schemas/")].(map [string] interface {}), schemas, path)
+	}
+
+	if value == nil {
+		if schema ["nullable"] == true { return "" }
+		return path + " is null"
+	}
+
+	switch schema ["type"] {
+
+		case "object":
+			object, ok := value.(map [string] interface {})
+			if !ok { return path + " is not an object" }
+
+			if required, hasRequired := schema ["required"].([] interface {}); hasRequired {
+				for _, name := range required {
+					if _, found := object [name.(string)]; !found { return path + "." + name.(string) + " is missing" }
+				}
+			}
+
+			properties, _ := schema ["properties"].(map [string] interface {})
+			additional, _ := schema ["additionalProperties"].(map [string] interface {})
+			for name, propertyValue := range object {
+				propertySchema, found := properties [name].(map [string] interface {})
+				if !found { propertySchema = additional }
+				if propertySchema == nil { continue }
+
+				if mismatch := checkSchema (propertyValue, propertySchema, schemas, path + "." + name); len (mismatch) > 0 { return mismatch }
+			}
+
+		case "array":
+			array, ok := value.([] interface {})
+			if !ok { return path + " is not an array" }
+
+			for i, item := range array {
+				if mismatch := checkSchema (item, schema ["items"].(map [string] interface {}), schemas, fmt.Sprintf ("%s[%d]", path, i)); len (mismatch) > 0 { return mismatch }
+			}
+
+		case "string":
+			s, ok := value.(string)
+			if !ok { return path + " is not a string" }
+
+			if values, hasEnum := schema ["enum"].([] interface {}); hasEnum {
+				for _, v := range values { if v == s { return "" } }
+				return path + " is not one of the allowed values"
+			}
+
+		case "integer":
+			n, ok := value.(float64)
+			if !ok || n != math.Trunc (n) { return path + " is not an integer" }
+
+		case "number":
+			if _, ok := value.(float64); !ok { return path + " is not a number" }
+
+		case "boolean":
+			if _, ok := value.(bool); !ok { return path + " is not a boolean" }
+	}
+
+	return ""
+}
+
+// every $ref in the document must point to a schema in the document
+func findRefs (value interface {}, refs map [string] bool) {
+	switch v := value.(type) {
+		case map [string] interface {}:
+			for key, item := range v {
+				if ref, isString := item.(string); key == "$ref" && isString { refs [ref] = true }
+				findRefs (item, refs)
+			}
+		case [] interface {}:
+			for _, item := range v { findRefs (item, refs) }
+	}
+}
+
+func TestOpenApiRefsResolve (t *testing.T) {
+
+	document, schemas := getServedSchemas (t)
+
+	refs := make (map [string] bool)
+	findRefs (document, refs)
+	if len (refs) == 0 { t.Fatal ("the document has no refs") }
+
+	for ref := range refs {
+		if !strings.HasPrefix (ref, "#/components/") { t.Errorf ("%s is not a local ref", ref); continue }
+		if strings.HasPrefix (ref, "#/components/schemas/") && schemas [strings.TrimPrefix (ref, "#/components/schemas/")] == nil { t.Errorf ("%s does not exist", ref) }
+	}
+}
+
+func TestOpenApiFunctionSchemas (t *testing.T) {
+
+	_, schemas := getServedSchemas (t)
+
+	for _, function := range apiFunctions {
+		if len (function.request) > 0 && schemas [function.request] == nil { t.Errorf ("%s has no request schema %s", function.name, function.request) }
+		if schemas [function.response] == nil { t.Errorf ("%s has no response schema %s", function.name, function.response) }
+	}
+}
+
+func TestTxResponseMatchesSchema (t *testing.T) {
+
+	network := btc.GetNetwork ()
+	for _, txHex := range [] string { network.GetGenesisTxHex (), testSegwitTxHex } {
+		rawBytes, _ := hex.DecodeString (txHex)
+		tx, err := btc.DecodeRawTx (rawBytes)
+		if err != nil { t.Fatal (err) }
+
+		checkResponse (t, "Tx", txToJson (tx))
+
+		// with the previous output, the input has a spend type and its segwit fields have types
+		if !tx.IsCoinbase () {
+			previousOutputScript, _ := hex.DecodeString ("0014" + strings.Repeat ("33", 20))
+			script := btc.NewScript (previousOutputScript)
+			tx.SetPreviousOutput (0, btc.NewOutput (2000, script, btc.GetAddress (script)))
+
+			input := tx.GetInput (0)
+			if input.GetSpendType () != btc.OUTPUT_TYPE_P2WPKH { t.Errorf ("spend type is %s", input.GetSpendType ()) }
+
+			checkResponse (t, "Tx", txToJson (tx))
+		}
+	}
+}
+
+func TestErrorResponseMatchesSchema (t *testing.T) {
+	checkResponse (t, "ErrorResponse", map [string] interface {} { "error": missingParameter ("hex").toV2 () })
+	checkResponse (t, "ErrorResponse", map [string] interface {} { "error": newApiError (ERROR_UNKNOWN_FUNCTION, "Unknown REST v2 function: x").toV2 () })
+}
+
+// the check has to find real differences for the other tests to mean anything
+func TestCheckSchemaFindsMismatches (t *testing.T) {
+
+	_, schemas := getServedSchemas (t)
+	txSchema := map [string] interface {} { "$ref": "#/components/schemas/Tx" }
+
+	missing := map [string] interface {} { "id": "00", "inputs": [] interface {} {}, "outputs": [] interface {} {} }
+	if checkSchema (missing, txSchema, schemas, "Tx") != "Tx.status is missing" { t.Error ("missing status was not found") }
+
+	wrongEnum := map [string] interface {} { "id": "00", "inputs": [] interface {} {}, "outputs": [] interface {} {}, "status": "pending" }
+	if checkSchema (wrongEnum, txSchema, schemas, "Tx") != "Tx.status is not one of the allowed values" { t.Error ("invalid status was not found") }
+
+	wrongType := map [string] interface {} { "id": "00", "inputs": [] interface {} {}, "outputs": [] interface {} {}, "status": "confirmed", "locktime": 1.5 }
+	if checkSchema (wrongType, txSchema, schemas, "Tx") != "Tx.locktime is not an integer" { t.Error ("invalid locktime was not found") }
+}
@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"encoding/hex"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+func bip32DerivationsToJson (derivations [] btc.Bip32Derivation) [] map [string] interface {} {
+
+	json := make ([] map [string] interface {}, len (derivations))
+	for d, derivation := range derivations {
+		json [d] = make (map [string] interface {})
+		json [d] ["public_key"] = hex.EncodeToString (derivation.GetPublicKey ())
+		json [d] ["fingerprint"] = hex.EncodeToString (derivation.GetFingerprint ())
+		json [d] ["path"] = derivation.GetPath ()
+
+		leafHashes := derivation.GetLeafHashes ()
+		if len (leafHashes) > 0 {
+			leafHashesHex := make ([] string, len (leafHashes))
+			for h, leafHash := range leafHashes { leafHashesHex [h] = hex.EncodeToString (leafHash) }
+			json [d] ["leaf_hashes"] = leafHashesHex
+		}
+	}
+
+	return json
+}
+
+func psbtSignaturesToJson (signatures [] btc.PsbtSignature, schnorr bool) [] map [string] interface {} {
+
+	json := make ([] map [string] interface {}, len (signatures))
+	for s, signature := range signatures {
+		json [s] = make (map [string] interface {})
+		json [s] ["public_key"] = hex.EncodeToString (signature.GetPublicKey ())
+		json [s] ["signature"] = binaryFieldJson { Hex: hex.EncodeToString (signature.GetSignature ()), Type: btc.GetStackItemType (signature.GetSignature (), schnorr) }
+
+		leafHash := signature.GetLeafHash ()
+		if len (leafHash) > 0 { json [s] ["leaf_hash"] = hex.EncodeToString (leafHash) }
+	}
+
+	return json
+}
+
+func tapLeafScriptsToJson (leafScripts [] btc.TapLeafScript, includeControlBlock bool) [] map [string] interface {} {
+
+	json := make ([] map [string] interface {}, len (leafScripts))
+	for l, leafScript := range leafScripts {
+		json [l] = make (map [string] interface {})
+		json [l] ["leaf_version"] = leafScript.GetLeafVersion ()
+		json [l] ["script"] = scriptToJson (leafScript.GetScript ())
+		if includeControlBlock {
+			json [l] ["control_block"] = hex.EncodeToString (leafScript.GetControlBlock ())
+		} else {
+			json [l] ["depth"] = leafScript.GetDepth ()
+		}
+	}
+
+	return json
+}
+
+func psbtInputToJson (psbtInput btc.PsbtInput) map [string] interface {} {
+
+	json := make (map [string] interface {})
+
+	json ["finalized"] = psbtInput.IsFinalized ()
+
+	if len (psbtInput.GetPartialSignatures ()) > 0 { json ["partial_signatures"] = psbtSignaturesToJson (psbtInput.GetPartialSignatures (), false) }
+
+	sighashType, hasSighashType := psbtInput.GetSighashType ()
+	if hasSighashType { json ["sighash_type"] = sighashType }
+
+	redeemScript := psbtInput.GetRedeemScript ()
+	if !redeemScript.IsNil () { json ["redeem_script"] = scriptToJson (redeemScript) }
+
+	witnessScript := psbtInput.GetWitnessScript ()
+	if !witnessScript.IsNil () { json ["witness_script"] = scriptToJson (witnessScript) }
+
+	if len (psbtInput.GetBip32Derivations ()) > 0 { json ["bip32_derivations"] = bip32DerivationsToJson (psbtInput.GetBip32Derivations ()) }
+
+	// taproot
+	tapKeySignature := psbtInput.GetTapKeySignature ()
+	if len (tapKeySignature) > 0 { json ["tap_key_signature"] = binaryFieldJson { Hex: hex.EncodeToString (tapKeySignature), Type: btc.GetStackItemType (tapKeySignature, true) } }
+
+	if len (psbtInput.GetTapScriptSignatures ()) > 0 { json ["tap_script_signatures"] = psbtSignaturesToJson (psbtInput.GetTapScriptSignatures (), true) }
+	if len (psbtInput.GetTapLeafScripts ()) > 0 { json ["tap_leaf_scripts"] = tapLeafScriptsToJson (psbtInput.GetTapLeafScripts (), true) }
+	if len (psbtInput.GetTapBip32Derivations ()) > 0 { json ["tap_bip32_derivations"] = bip32DerivationsToJson (psbtInput.GetTapBip32Derivations ()) }
+	if len (psbtInput.GetTapInternalKey ()) > 0 { json ["tap_internal_key"] = hex.EncodeToString (psbtInput.GetTapInternalKey ()) }
+	if len (psbtInput.GetTapMerkleRoot ()) > 0 { json ["tap_merkle_root"] = hex.EncodeToString (psbtInput.GetTapMerkleRoot ()) }
+
+	return json
+}
+
+func psbtOutputToJson (psbtOutput btc.PsbtOutput) map [string] interface {} {
+
+	json := make (map [string] interface {})
+
+	redeemScript := psbtOutput.GetRedeemScript ()
+	if !redeemScript.IsNil () { json ["redeem_script"] = scriptToJson (redeemScript) }
+
+	witnessScript := psbtOutput.GetWitnessScript ()
+	if !witnessScript.IsNil () { json ["witness_script"] = scriptToJson (witnessScript) }
+
+	if len (psbtOutput.GetBip32Derivations ()) > 0 { json ["bip32_derivations"] = bip32DerivationsToJson (psbtOutput.GetBip32Derivations ()) }
+	if len (psbtOutput.GetTapInternalKey ()) > 0 { json ["tap_internal_key"] = hex.EncodeToString (psbtOutput.GetTapInternalKey ()) }
+	if len (psbtOutput.GetTapTree ()) > 0 { json ["tap_tree"] = tapLeafScriptsToJson (psbtOutput.GetTapTree (), false) }
+	if len (psbtOutput.GetTapBip32Derivations ()) > 0 { json ["tap_bip32_derivations"] = bip32DerivationsToJson (psbtOutput.GetTapBip32Derivations ()) }
+
+	return json
+}
+
+func psbtToJson (psbt btc.Psbt) map [string] interface {} {
+
+	json := make (map [string] interface {})
+
+	json ["version"] = psbt.GetVersion ()
+	json ["tx"] = txToJson (psbt.GetTx ())
+
+	fee, feeKnown := psbt.GetFee ()
+	if feeKnown { json ["fee"] = fee }
+
+	if len (psbt.GetXpubs ()) > 0 { json ["xpubs"] = bip32DerivationsToJson (psbt.GetXpubs ()) }
+
+	psbtInputs := psbt.GetInputs ()
+	inputs := make ([] map [string] interface {}, len (psbtInputs))
+	for i, psbtInput := range psbtInputs {
+		inputs [i] = psbtInputToJson (psbtInput)
+	}
+	json ["inputs"] = inputs
+
+	psbtOutputs := psbt.GetOutputs ()
+	outputs := make ([] map [string] interface {}, len (psbtOutputs))
+	for o, psbtOutput := range psbtOutputs {
+		outputs [o] = psbtOutputToJson (psbtOutput)
+	}
+	json ["outputs"] = outputs
+
+	return json
+}
@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"io"
+	"fmt"
+	"strconv"
+	"net/url"
+	"net/http"
+	"encoding/json"
+)
+
+// blocks, transactions, inputs and outputs can also be requested with GET, with the keys in the path and the options in the query string
+//     /block/{hash or height}
+//     /block/{hash or height}/stats
+//     /tx/{tx id}
+//     /tx/{tx id}/input/{input index}
+//     /tx/{tx id}/output/{output index}
+// these are turned into the same parameters as the POST requests, so the functions do not need to know how they were requested
+
+// blocks found by their hash and confirmed transactions only change if there is a reorg, so they are only cached briefly
+// everything else can change at any time
+const RESOURCE_MAX_AGE = 60
+
+func IsResourceFunction (functionName string) bool {
+	return functionName == "block" || functionName == "tx"
+}
+
+func setResourceCacheHeader (response http.ResponseWriter, cacheable bool) {
+	if !cacheable { response.Header ().Set ("Cache-Control", "no-cache"); return }
+	response.Header ().Set ("Cache-Control", fmt.Sprintf ("public, max-age=%d", RESOURCE_MAX_AGE))
+}
+
+// returns the function that handles the resource, its request parameters or an error
+func getResourceRequest (functionName string, getParams [] string, queryParams url.Values, version uint16) (string, map [string] interface {}, *apiError) {
+
+	requestParams := make (map [string] interface {})
+
+	options, paramError := getQueryOptions (queryParams)
+	if paramError != nil { return "", nil, paramError }
+	requestParams ["options"] = options
+
+	unknownResource := newApiError (ERROR_UNKNOWN_FUNCTION, fmt.Sprintf ("Unknown REST v%d resource: %s", version, getResourcePath (functionName, getParams)))
+
+	switch functionName {
+
+		// without a hash or height, the most recent block is returned
+		case "block":
+			if len (getParams) == 0 { return "block", requestParams, nil }
+
+			// heights are all digits, anything else is treated as a hash and checked by the block function
+			if height, err := strconv.ParseUint (getParams [0], 10, 32); err == nil {
+				requestParams ["height"] = float64 (height)
+			} else {
+				requestParams ["hash"] = getParams [0]
+			}
+
+			if len (getParams) == 1 { return "block", requestParams, nil }
+			if len (getParams) == 2 && getParams [1] == "stats" { return "block_stats", requestParams, nil }
+
+		case "tx":
+			if len (getParams) == 0 { return "", nil, missingParameter ("id") }
+
+			if len (getParams) == 1 {
+				requestParams ["id"] = getParams [0]
+				return "tx", requestParams, nil
+			}
+
+			if len (getParams) == 3 && (getParams [1] == "input" || getParams [1] == "output") {
+				indexName := getParams [1] + "_index"
+				index, err := strconv.ParseUint (getParams [2], 10, 16)
+				if err != nil { return "", nil, invalidParameter (indexName, indexName + " must be a numeric index") }
+
+				requestParams ["tx_id"] = getParams [0]
+				requestParams [indexName] = float64 (index)
+				return getParams [1], requestParams, nil
+			}
+	}
+
+	return "", nil, unknownResource
+}
+
+// every option is a bool, and an option without a value is true
+// other query parameters, such as the ones added to get around caches, are ignored
+func getQueryOptions (queryParams url.Values) (map [string] interface {}, *apiError) {
+
+	options := make (map [string] interface {})
+	for _, name := range boolOptions {
+		values := queryParams [name]
+		if len (values) == 0 { continue }
+
+		value := values [len (values) - 1]
+		if len (value) == 0 { options [name] = true; continue }
+
+		option, err := strconv.ParseBool (value)
+		if err != nil { return nil, invalidParameter (name, "option " + name + " is not a bool") }
+		options [name] = option
+	}
+
+	return options, nil
+}
+
+func getResourcePath (functionName string, getParams [] string) string {
+	path := "/" + functionName
+	for _, param := range getParams { path += "/" + param }
+	return path
+}
+
+// returns the parameters of a resource request if there are any, otherwise the parameters in the json body of a POST request
+func getRequestParams (httpMethod string, functionName string, requestBody io.Reader, resourceParams map [string] interface {}) (map [string] interface {}, *apiError) {
+
+	if resourceParams != nil { return resourceParams, nil }
+	if httpMethod != "POST" {
+		if IsResourceFunction (functionName) { return nil, methodNotAllowed (functionName, "GET or POST") }
+		return nil, methodNotAllowed (functionName, "POST")
+	}
+
+	var requestParams map [string] interface {}
+	err := json.NewDecoder (requestBody).Decode (&requestParams)
+	if err != nil { return nil, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+	return requestParams, nil
+}
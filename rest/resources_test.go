@@ -0,0 +1,82 @@
+package rest
+
+import (
+	"strings"
+	"testing"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node/nodetest"
+)
+
+func getTestResource (t *testing.T, path string) *httptest.ResponseRecorder {
+	t.Helper ()
+
+	nodetest.Start ()
+
+	recorder := httptest.NewRecorder ()
+	RestHandler (recorder, httptest.NewRequest ("GET", path, nil))
+	return recorder
+}
+
+func TestResourceCacheHeaders (t *testing.T) {
+
+	chain, _ := nodetest.Start ()
+	network := btc.GetNetwork ()
+
+	fundingTxHex := nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: strings.Repeat ("66", 32), Index: 0 } }, [] nodetest.TxOutput { { Value: 2000, ScriptHex: testP2wpkhScriptHex } })
+	spendingTxHex := nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: nodetest.GetTxId (fundingTxHex), Index: 0 } }, [] nodetest.TxOutput { { Value: 1000, ScriptHex: testP2wpkhScriptHex } })
+	blockHash := chain.AddBlock (fundingTxHex, spendingTxHex)
+	spendingTxId := nodetest.GetTxId (spendingTxHex)
+
+	mempoolTxHex := nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: spendingTxId, Index: 0 } }, [] nodetest.TxOutput { { Value: 500, ScriptHex: testP2wpkhScriptHex } })
+	mempoolTxId := chain.AddMempoolTx (mempoolTxHex)
+
+	cacheable := "public, max-age=60"
+	for name, test := range map [string] struct {
+		path string
+		cacheControl string
+	} {	"tip": { "/rest/v2/block", "no-cache" },
+		"block by height": { "/rest/v2/block/0", "no-cache" },
+		"block by hash": { "/rest/v2/block/" + blockHash, cacheable },
+		"genesis block by hash": { "/rest/v2/block/" + network.GetGenesisBlockHash (), cacheable },
+		"confirmed tx": { "/rest/v2/tx/" + spendingTxId, cacheable },
+		"unconfirmed tx": { "/rest/v2/tx/" + mempoolTxId, "no-cache" },
+		"input of a confirmed tx": { "/rest/v2/tx/" + spendingTxId + "/input/0", cacheable },
+		"input of an unconfirmed tx": { "/rest/v2/tx/" + mempoolTxId + "/input/0", "no-cache" },
+		"output that can be spent": { "/rest/v2/tx/" + spendingTxId + "/output/0", "no-cache" },
+		"v1 tip": { "/rest/v1/block", "no-cache" },
+		"v1 confirmed tx": { "/rest/v1/tx/" + spendingTxId, cacheable },
+		"v1 unconfirmed tx": { "/rest/v1/tx/" + mempoolTxId, "no-cache" } } {
+		recorder := getTestResource (t, test.path)
+		if recorder.Code != http.StatusOK { t.Errorf ("%s: status %d, %s", name, recorder.Code, recorder.Body.String ()); continue }
+		if cacheControl := recorder.Header ().Get ("Cache-Control"); cacheControl != test.cacheControl { t.Errorf ("%s: cache control %q, expected %q", name, cacheControl, test.cacheControl) }
+	}
+
+	// errors are not cached at all
+	recorder := getTestResource (t, "/rest/v2/tx/" + strings.Repeat ("ab", 32))
+	if recorder.Code != http.StatusNotFound || len (recorder.Header ().Get ("Cache-Control")) > 0 { t.Errorf ("unknown tx: status %d, cache control %q", recorder.Code, recorder.Header ().Get ("Cache-Control")) }
+}
+
+func TestResourceQueryOptions (t *testing.T) {
+
+	network := btc.GetNetwork ()
+	path := "/rest/v2/tx/" + network.GetGenesisTxId ()
+
+	for name, test := range map [string] struct {
+		query string
+		statusCode int
+		indented bool
+	} {	"no options": { "", http.StatusOK, false },
+		"option without a value": { "?human_readable", http.StatusOK, true },
+		"option with a value": { "?human_readable=false", http.StatusOK, false },
+		"the last value is used": { "?human_readable=false&human_readable=true", http.StatusOK, true },
+		"cache buster": { "?_=1700000000", http.StatusOK, false },
+		"unknown parameter with an option": { "?human_readable=1&callback=x", http.StatusOK, true },
+		"option that is not a bool": { "?human_readable=maybe", http.StatusBadRequest, false } } {
+		recorder := getTestResource (t, path + test.query)
+		if recorder.Code != test.statusCode { t.Errorf ("%s: status %d, %s", name, recorder.Code, recorder.Body.String ()); continue }
+		if recorder.Code == http.StatusOK && strings.Contains (recorder.Body.String (), "\n\t") != test.indented { t.Errorf ("%s: expected indented %t", name, test.indented) }
+	}
+}
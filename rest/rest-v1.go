@@ -4,15 +4,25 @@ import (
 	"fmt"
 	"strconv"
 	"io"
+	"net/url"
+	"net/http"
+	"encoding/hex"
 	"encoding/json"
 
 	"github.com/btc-script-explorer/scantool/btc"
 	"github.com/btc-script-explorer/scantool/btc/node"
+	"github.com/btc-script-explorer/scantool/index"
+	"github.com/btc-script-explorer/scantool/jobs"
+	"github.com/btc-script-explorer/scantool/export"
+	"github.com/btc-script-explorer/scantool/watch"
 )
 
 type RestApiV1 struct {
 }
 
+// every transaction in the range is requested, so larger ranges should be analyzed with a job
+const MAX_OPCODE_STATS_BLOCKS = 10
+
 type binaryFieldJson struct {	Hex string `json:"hex"`
 								Type string `json:"type"` }
 
@@ -95,6 +105,23 @@ func outputToJson (output btc.Output) map [string] interface {} {
 	return json
 }
 
+// block_height is only included when the spending transaction is in a block
+func outputSpendToJson (outputSpend btc.OutputSpend) map [string] interface {} {
+
+	json := make (map [string] interface {})
+
+	json ["spent"] = outputSpend.IsSpent ()
+	if len (outputSpend.GetTxId ()) > 0 {
+		json ["tx_id"] = outputSpend.GetTxId ()
+		json ["input_index"] = outputSpend.GetInputIndex ()
+		json ["spend_type"] = outputSpend.GetSpendType ()
+		json ["in_mempool"] = outputSpend.IsInMempool ()
+		if !outputSpend.IsInMempool () { json ["block_height"] = outputSpend.GetBlockHeight () }
+	}
+
+	return json
+}
+
 func inputToJson (input btc.Input) map [string] interface {} {
 
 	json := make (map [string] interface {})
@@ -136,6 +163,79 @@ func inputToJson (input btc.Input) map [string] interface {} {
 	return json
 }
 
+func mempoolEntryToJson (mempoolEntry btc.MempoolEntry) map [string] interface {} {
+
+	json := make (map [string] interface {})
+
+	json ["time_first_seen"] = mempoolEntry.GetTimeFirstSeen ()
+	json ["fee"] = mempoolEntry.GetFee ()
+	json ["vsize"] = mempoolEntry.GetVsize ()
+	json ["ancestor_count"] = mempoolEntry.GetAncestorCount ()
+	json ["ancestor_size"] = mempoolEntry.GetAncestorSize ()
+	json ["descendant_count"] = mempoolEntry.GetDescendantCount ()
+	json ["descendant_size"] = mempoolEntry.GetDescendantSize ()
+	json ["depends"] = mempoolEntry.GetDepends ()
+	json ["spent_by"] = mempoolEntry.GetSpentBy ()
+
+	return json
+}
+
+// each type maps to an object with its count and value
+func typeStatsToJson (typeStats map [string] btc.TypeStats) map [string] interface {} {
+
+	json := make (map [string] interface {})
+	for typeName, stats := range typeStats {
+		json [typeName] = map [string] interface {} { "count": stats.GetCount (), "value": stats.GetValue () }
+	}
+
+	return json
+}
+
+func blockStatsToJson (block btc.Block, blockStats btc.BlockStats) map [string] interface {} {
+
+	json := make (map [string] interface {})
+
+	json ["hash"] = block.GetHash ()
+	json ["height"] = block.GetHeight ()
+	json ["tx_count"] = blockStats.GetTxCount ()
+	json ["input_count"] = blockStats.GetInputCount ()
+	json ["output_count"] = blockStats.GetOutputCount ()
+	json ["input_value"] = blockStats.GetInputValue ()
+	json ["output_value"] = blockStats.GetOutputValue ()
+	json ["spend_types"] = typeStatsToJson (blockStats.GetSpendTypes ())
+	json ["output_types"] = typeStatsToJson (blockStats.GetOutputTypes ())
+	json ["serialized_script_types"] = typeStatsToJson (blockStats.GetSerializedScriptTypes ())
+	json ["data_types"] = typeStatsToJson (blockStats.GetDataTypes ())
+
+	return json
+}
+
+// only the requested script types and opcodes are included, or all of them if none were requested
+func opcodeCountsToJson (opcodeStats btc.OpcodeStats, scriptTypes [] string, opcodes [] string) map [string] map [string] uint64 {
+
+	includeScriptType := func (scriptType string) bool {
+		if len (scriptTypes) == 0 { return true }
+		for _, t := range scriptTypes { if t == scriptType { return true } }
+		return false
+	}
+
+	json := make (map [string] map [string] uint64)
+	for scriptType, counts := range opcodeStats.GetCounts () {
+		if !includeScriptType (scriptType) { continue }
+
+		json [scriptType] = make (map [string] uint64)
+		if len (opcodes) == 0 {
+			for opcode, count := range counts { json [scriptType] [opcode] = count }
+		} else {
+			for _, opcode := range opcodes {
+				if counts [opcode] > 0 { json [scriptType] [opcode] = counts [opcode] }
+			}
+		}
+	}
+
+	return json
+}
+
 func txToJson (tx btc.Tx) map [string] interface {} {
 
 	inputs := make ([] map [string] interface {}, tx.GetInputCount ())
@@ -160,68 +260,213 @@ func txToJson (tx btc.Tx) map [string] interface {} {
 	json ["blockhash"] = tx.GetBlockHash ()
 	json ["blocktime"] = tx.GetBlockTime ()
 
+	if tx.IsConfirmed () {
+		json ["status"] = "confirmed"
+	} else {
+		json ["status"] = "unconfirmed"
+
+		mempoolEntry := tx.GetMempoolEntry ()
+		if !mempoolEntry.IsNil () { json ["mempool"] = mempoolEntryToJson (mempoolEntry) }
+	}
+
 	return json
 }
 
+// webhook payloads contain transactions in the same format as the tx function
+func TxToJson (tx btc.Tx) map [string] interface {} {
+	return txToJson (tx)
+}
+
+// returns the outpoint of a client-supplied previous output as "tx_id:output_index", the output and an error message
+func parsePreviousOutput (previousOutputParam interface {}) (string, btc.Output, string) {
+
+	params, ok := previousOutputParam.(map [string] interface {})
+	if !ok { return "", btc.Output {}, "previous_outputs must contain objects" }
+
+	txId, ok := params ["tx_id"].(string)
+	if !ok || len (txId) != 64 { return "", btc.Output {}, "previous output tx_id is not a valid transaction id" }
+
+	outputIndex, ok := params ["output_index"].(float64)
+	if !ok { return "", btc.Output {}, "previous output output_index must be a numeric index" }
+	if outputIndex < 0 || outputIndex > 0xffff || outputIndex != float64 (uint16 (outputIndex)) { return "", btc.Output {}, "previous output output_index is not a valid output index" }
+
+	value, ok := params ["value"].(float64)
+	if !ok { return "", btc.Output {}, "previous output value must be a number of satoshis" }
+
+	outputScriptHex, ok := params ["output_script"].(string)
+	if !ok { return "", btc.Output {}, "previous output output_script must be a hex string" }
+	outputScriptBytes, err := hex.DecodeString (outputScriptHex)
+	if err != nil { return "", btc.Output {}, "previous output output_script is not a valid hex string" }
+
+	outputScript := btc.NewScript (outputScriptBytes)
+	output := btc.NewOutput (uint64 (value), outputScript, btc.GetAddress (outputScript))
+
+	return fmt.Sprintf ("%s:%d", txId, uint16 (outputIndex)), output, ""
+}
+
+// returns the request for the block identified by the hash or height parameter or an error
+// if neither parameter is included, the request is for the most recent block
+func getBlockRequestFromParams (requestParams map [string] interface {}) (node.BlockRequest, *apiError) {
+
+	blockRequest := node.BlockRequest {}
+	if requestParams ["hash"] != nil {
+		switch requestParams ["hash"].(type) {
+			case float64:
+				return blockRequest, invalidParameter ("hash", "parameter hash is formatted as a number")
+			case string:
+				blockRequest.BlockKey = requestParams ["hash"].(string)
+				if len (blockRequest.BlockKey) != 64 {
+					return blockRequest, invalidParameter ("hash", "parameter hash is not a valid block hash")
+				}
+		}
+	} else if requestParams ["height"] != nil {
+		switch requestParams ["height"].(type) {
+			case float64:
+				blockRequest.BlockKey = strconv.Itoa (int (requestParams ["height"].(float64)))
+			case string:
+				return blockRequest, invalidParameter ("height", "parameter height is formatted as a string")
+		}
+	}
+
+	return blockRequest, nil
+}
+
+// returns the job identified by the id parameter or an error
+func getJobFromParams (requestParams map [string] interface {}) (*jobs.Job, *apiError) {
+
+	if requestParams ["id"] == nil { return nil, missingParameter ("id") }
+
+	id, ok := requestParams ["id"].(string)
+	if !ok { return nil, invalidParameter ("id", "parameter id is not a string") }
+
+	job := jobs.GetJob (id)
+	if job == nil { return nil, newApiError (ERROR_JOB_NOT_FOUND, "job not found") }
+
+	return job, nil
+}
+
+// returns the watch identified by the id parameter or an error
+func getWatchFromParams (requestParams map [string] interface {}) (*watch.Watch, *apiError) {
+
+	if requestParams ["id"] == nil { return nil, missingParameter ("id") }
+
+	id, ok := requestParams ["id"].(string)
+	if !ok { return nil, invalidParameter ("id", "parameter id is not a string") }
+
+	w := watch.GetWatch (id)
+	if w == nil { return nil, newApiError (ERROR_WATCH_NOT_FOUND, "watch not found") }
+
+	return w, nil
+}
+
+// returns the export identified by the id parameter or an error
+func getExportFromParams (requestParams map [string] interface {}) (*export.Export, *apiError) {
+
+	if requestParams ["id"] == nil { return nil, missingParameter ("id") }
+
+	id, ok := requestParams ["id"].(string)
+	if !ok { return nil, invalidParameter ("id", "parameter id is not a string") }
+
+	exp := export.GetExport (id)
+	if exp == nil { return nil, newApiError (ERROR_EXPORT_NOT_FOUND, "export not found") }
+
+	return exp, nil
+}
+
+// the options every function reads as bools
+var boolOptions = [] string { "human_readable", "include_input_detail" }
+
+// returns the options of a request, or no options if the request does not have any
+// the bool options are checked here, so the functions can read them without checking them again
+func getOptions (requestParams map [string] interface {}) (map [string] interface {}, *apiError) {
+
+	if requestParams ["options"] == nil { return map [string] interface {} {}, nil }
+
+	options, ok := requestParams ["options"].(map [string] interface {})
+	if !ok { return nil, invalidParameter ("options", "options must be an object") }
+
+	for _, name := range boolOptions {
+		if _, isBool := options [name].(bool); options [name] != nil && !isBool { return nil, invalidParameter (name, "option " + name + " is not a bool") }
+	}
+
+	return options, nil
+}
+
+func marshalWithOptions (jsonData interface {}, options map [string] interface {}) string {
+
+	var jsonBytes [] byte
+	var err error
+	if options ["human_readable"] != nil && options ["human_readable"].(bool) {
+		jsonBytes, err = json.MarshalIndent (jsonData, "", "\t")
+	} else {
+		jsonBytes, err = json.Marshal (jsonData)
+	}
+	if err != nil { fmt.Println (err.Error ()) }
+
+	return string (jsonBytes)
+}
+
 func (api *RestApiV1) GetVersion () uint16 {
 	return 1
 }
 
-func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, getParams [] string, requestBody io.ReadCloser) string {
+// returns the response json, v1 errors are returned in place of the response
+func (api *RestApiV1) HandleRequest (response http.ResponseWriter, httpMethod string, functionName string, getParams [] string, queryParams url.Values, requestBody io.ReadCloser) string {
+
+	responseJson, cacheable, apiErr := handleFunction (httpMethod, functionName, getParams, queryParams, requestBody, api.GetVersion ())
+	if apiErr != nil { return apiErr.toV1 () }
+
+	if httpMethod == "GET" && IsResourceFunction (functionName) { setResourceCacheHeader (response, cacheable) }
+
+	return responseJson
+}
+
+// the functions are the same in every version, only the errors are returned differently
+// returns the response json and whether a resource with the same path would always get the same response, or an error
+func handleFunction (httpMethod string, functionName string, getParams [] string, queryParams url.Values, requestBody io.Reader, version uint16) (string, bool, *apiError) {
 
 	nodeProxy, err := node.GetNodeProxy ()
 	if err != nil {
 		fmt.Println (err.Error ())
-		return ""
+		return "", false, newApiError (ERROR_NODE_UNAVAILABLE, "node is not available")
+	}
+
+	// resource requests are handled by the function for the resource
+	var resourceParams map [string] interface {}
+	if httpMethod == "GET" && IsResourceFunction (functionName) {
+		var resourceError *apiError
+		functionName, resourceParams, resourceError = getResourceRequest (functionName, getParams, queryParams, version)
+		if resourceError != nil { return "", false, resourceError }
 	}
 
-	errorMessage := ""
 	responseJson := ""
 
+	// blocks found by their height and unconfirmed transactions can change, so they are not cacheable
+	cacheable := false
+
 	switch functionName {
 
 		case "block":
 
-			if httpMethod != "POST" { errorMessage = fmt.Sprintf ("%s must be sent as a POST request.", functionName); break }
-
-			// unpack the json
-
-			var requestParams map [string] interface {}
-			err := json.NewDecoder (requestBody).Decode (&requestParams)
-			if err != nil { errorMessage = err.Error (); break }
+			requestParams, paramError := getRequestParams (httpMethod, functionName, requestBody, resourceParams)
+			if paramError != nil { return "", false, paramError }
 
 			// get the block request options
 			// get the request options
-			blockRequestOptions := map [string] interface {} {}
-			if requestParams ["options"] != nil { blockRequestOptions = requestParams ["options"].(map [string] interface {}) }
+			blockRequestOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
 
 			// try to determine whether the hash or height parameters are the right type
-			blockRequest := node.BlockRequest {}
-			if requestParams ["hash"] != nil {
-				switch requestParams ["hash"].(type) {
-					case float64:
-						return "malformed request: parameter hash is formatted as a number"
-					case string:
-						blockRequest.BlockKey = requestParams ["hash"].(string)
-						if len (blockRequest.BlockKey) != 64 {
-							return "malformed request: parameter hash is not a valid block hash"
-						}
-				}
-			} else if requestParams ["height"] != nil {
-				switch requestParams ["height"].(type) {
-					case float64:
-						blockRequest.BlockKey = strconv.Itoa (int (requestParams ["height"].(float64)))
-					case string:
-						return "malformed request: parameter height is formatted as a string"
-				}
-			}
+			blockRequest, paramError := getBlockRequestFromParams (requestParams)
+			if paramError != nil { return "", false, paramError }
 
 			// request the block from the node proxy
 
 			block := nodeProxy.GetBlock (blockRequest)
 			if block.IsNil () {
-				return "block not found"
+				return "", false, newApiError (ERROR_BLOCK_NOT_FOUND, "block not found")
 			}
+			cacheable = requestParams ["hash"] != nil
 
 			// create the JSON response
 
@@ -232,6 +477,17 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 				Height uint32 `json:"height"`
 				Version int32 `json:"version"`
 				Timestamp int64 `json:"timestamp"`
+				MedianTime int64 `json:"median_time"`
+				MerkleRoot string `json:"merkle_root"`
+				MerkleRootValid bool `json:"merkle_root_valid"`
+				Bits string `json:"bits"`
+				Nonce uint32 `json:"nonce"`
+				Difficulty float64 `json:"difficulty"`
+				Chainwork string `json:"chainwork"`
+				Size uint32 `json:"size"`
+				StrippedSize uint32 `json:"stripped_size"`
+				Weight uint32 `json:"weight"`
+				TxCount uint32 `json:"tx_count"`
 				TxIds [] string `json:"tx_ids"`
 			} {
 				Hash: block.GetHash (),
@@ -240,6 +496,17 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 				Height: block.GetHeight (),
 				Version: block.GetVersion (),
 				Timestamp: block.GetTimestamp (),
+				MedianTime: block.GetMedianTime (),
+				MerkleRoot: block.GetMerkleRoot (),
+				MerkleRootValid: block.IsMerkleRootValid (),
+				Bits: block.GetBits (),
+				Nonce: block.GetNonce (),
+				Difficulty: block.GetDifficulty (),
+				Chainwork: block.GetChainwork (),
+				Size: block.GetSize (),
+				StrippedSize: block.GetStrippedSize (),
+				Weight: block.GetWeight (),
+				TxCount: block.GetTxCount (),
 				TxIds: block.GetTxIds () }
 
 			var blockBytes [] byte
@@ -253,17 +520,110 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 			responseJson = string (blockBytes)
 
 
-		case "tx":
+		case "block_stats":
+
+			requestParams, paramError := getRequestParams (httpMethod, functionName, requestBody, resourceParams)
+			if paramError != nil { return "", false, paramError }
+
+			// get the request options
+			blockStatsOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			// the block is identified the same way as in the block function
+			blockRequest, paramError := getBlockRequestFromParams (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			block := nodeProxy.GetBlock (blockRequest)
+			if block.IsNil () {
+				return "", false, newApiError (ERROR_BLOCK_NOT_FOUND, "block not found")
+			}
+			cacheable = requestParams ["hash"] != nil
 
-			if httpMethod != "POST" { errorMessage = fmt.Sprintf ("%s must be sent as a POST request.", functionName); break }
+			blockStatsJson := blockStatsToJson (block, nodeProxy.GetBlockStats (block))
+
+			var blockStatsBytes [] byte
+			if blockStatsOptions ["human_readable"] != nil && blockStatsOptions ["human_readable"].(bool) {
+				blockStatsBytes, err = json.MarshalIndent (blockStatsJson, "", "\t")
+			} else {
+				blockStatsBytes, err = json.Marshal (blockStatsJson)
+			}
+			if err != nil { fmt.Println (err.Error ()) }
+
+			responseJson = string (blockStatsBytes)
+
+
+		case "opcode_stats":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
 
 			// unpack the json
 			var requestParams map [string] interface {}
 			err := json.NewDecoder (requestBody).Decode (&requestParams)
-			if err != nil { errorMessage = err.Error (); break }
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			opcodeStatsOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			if requestParams ["start_height"] == nil { return "", false, missingParameter ("start_height") }
+			startHeight, ok := requestParams ["start_height"].(float64)
+			if !ok || startHeight < 0 { return "", false, invalidParameter ("start_height", "parameter start_height is not a valid block height") }
+
+			endHeight := startHeight
+			if requestParams ["end_height"] != nil {
+				endHeight, ok = requestParams ["end_height"].(float64)
+				if !ok || endHeight < 0 { return "", false, invalidParameter ("end_height", "parameter end_height is not a valid block height") }
+			}
+
+			if startHeight > endHeight { return "", false, &apiError { code: ERROR_INVALID_PARAMETER, message: "start_height is greater than end_height", parameter: "end_height" } }
+			if endHeight - startHeight + 1 > MAX_OPCODE_STATS_BLOCKS { return "", false, &apiError { code: ERROR_INVALID_PARAMETER, message: fmt.Sprintf ("no more than %d blocks can be requested, use a job for larger ranges", MAX_OPCODE_STATS_BLOCKS), parameter: "end_height" } }
+
+			scriptTypes, paramError := getOptionalStrings (requestParams, "script_types")
+			if paramError != nil { return "", false, paramError }
+			for _, scriptType := range scriptTypes {
+				if !btc.IsScriptType (scriptType) { return "", false, invalidParameter ("script_types", scriptType + " is not a valid script type") }
+			}
+
+			opcodes, paramError := getOptionalStrings (requestParams, "opcodes")
+			if paramError != nil { return "", false, paramError }
+
+			perBlock := false
+			if requestParams ["per_block"] != nil {
+				perBlock, ok = requestParams ["per_block"].(bool)
+				if !ok { return "", false, invalidParameter ("per_block", "parameter per_block is not a bool") }
+			}
+
+			totals := btc.NewOpcodeStats ()
+			blocksJson := make ([] map [string] interface {}, 0)
+			for height := uint32 (startHeight); height <= uint32 (endHeight); height++ {
+				block := nodeProxy.GetBlock (node.BlockRequest { BlockKey: strconv.FormatUint (uint64 (height), 10) })
+				if block.IsNil () { return "", false, newApiError (ERROR_BLOCK_NOT_FOUND, fmt.Sprintf ("block %d not found", height)) }
+
+				blockStats := nodeProxy.GetOpcodeStats (block)
+				totals.AddCounts (blockStats.GetCounts ())
+
+				if perBlock {
+					blocksJson = append (blocksJson, map [string] interface {} {	"height": height,
+																					"hash": block.GetHash (),
+																					"opcodes": opcodeCountsToJson (blockStats, scriptTypes, opcodes) })
+				}
+			}
+
+			opcodeStatsJson := make (map [string] interface {})
+			opcodeStatsJson ["start_height"] = uint32 (startHeight)
+			opcodeStatsJson ["end_height"] = uint32 (endHeight)
+			opcodeStatsJson ["opcodes"] = opcodeCountsToJson (totals, scriptTypes, opcodes)
+			if perBlock { opcodeStatsJson ["blocks"] = blocksJson }
+
+			responseJson = marshalWithOptions (opcodeStatsJson, opcodeStatsOptions)
+
+
+		case "tx":
+
+			requestParams, paramError := getRequestParams (httpMethod, functionName, requestBody, resourceParams)
+			if paramError != nil { return "", false, paramError }
 
 			if requestParams ["id"] == nil {
-				return "id parameter is required"
+				return "", false, missingParameter ("id")
 			}
 
 			// get the request options
@@ -272,21 +632,22 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 			switch requestParams ["id"].(type) {
 				case string:
 					txRequest.TxId = requestParams ["id"].(string)
-					if len (txRequest.TxId) != 64 { return "malformed request: parameter id is not a valid transaction id" }
+					if len (txRequest.TxId) != 64 { return "", false, invalidParameter ("id", "parameter id is not a valid transaction id") }
 				default:
-					return "malformed request: id must be a hex string"
+					return "", false, invalidParameter ("id", "id must be a hex string")
 			}
 
-			txRequestOptions := map [string] interface {} {}
-			if requestParams ["options"] != nil { txRequestOptions = requestParams ["options"].(map [string] interface {}) }
+			txRequestOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
 
 			txRequest.IncludeInputDetail = txRequestOptions ["include_input_detail"] != nil && txRequestOptions ["include_input_detail"].(bool)
 
 			// get the tx from the node proxy
 			tx := nodeProxy.GetTx (txRequest)
 			if tx.IsNil () {
-				return "transaction not found"
+				return "", false, newApiError (ERROR_TX_NOT_FOUND, "transaction not found")
 			}
+			cacheable = tx.IsConfirmed ()
 
 			txJsonObj := txToJson (tx)
 
@@ -303,19 +664,15 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 
 		case "output":
 
-			if httpMethod != "POST" { errorMessage = fmt.Sprintf ("%s must be sent as a POST request.", functionName); break }
-
-			// unpack the json
-			var requestParams map [string] interface {}
-			err := json.NewDecoder (requestBody).Decode (&requestParams)
-			if err != nil { errorMessage = err.Error (); break }
+			requestParams, paramError := getRequestParams (httpMethod, functionName, requestBody, resourceParams)
+			if paramError != nil { return "", false, paramError }
 
 			if requestParams ["tx_id"] == nil {
-				return "tx_id parameter is required"
+				return "", false, missingParameter ("tx_id")
 			}
 
 			if requestParams ["output_index"] == nil {
-				return "output_index parameter is required"
+				return "", false, missingParameter ("output_index")
 			}
 
 			// get the request options
@@ -324,27 +681,32 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 			switch requestParams ["tx_id"].(type) {
 				case string:
 					outputRequest.TxId = requestParams ["tx_id"].(string)
-					if len (outputRequest.TxId) != 64 { return "malformed request: parameter tx_id is not a valid transaction id" }
+					if len (outputRequest.TxId) != 64 { return "", false, invalidParameter ("tx_id", "parameter tx_id is not a valid transaction id") }
 				default:
-					return "malformed request: tx_id must be a hex string"
+					return "", false, invalidParameter ("tx_id", "tx_id must be a hex string")
 			}
 
 			switch requestParams ["output_index"].(type) {
 				case float64:
 					outputRequest.OutputIndex = uint16 (requestParams ["output_index"].(float64))
 				default:
-					return "malformed request: output_index must be a numeric index"
+					return "", false, invalidParameter ("output_index", "output_index must be a numeric index")
 			}
 
-			outputRequestOptions := map [string] interface {} {}
-			if requestParams ["options"] != nil { outputRequestOptions = requestParams ["options"].(map [string] interface {}) }
+			outputRequestOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
 
 			// get the output from the node proxy
 			output := nodeProxy.GetOutput (outputRequest)
-			if len (output.GetOutputType ()) == 0 { return "output not found" }
+			if len (output.GetOutputType ()) == 0 { return "", false, newApiError (ERROR_OUTPUT_NOT_FOUND, "output not found") }
 
 			outputJsonObj := outputToJson (output)
 
+			// the spending input is found in the index, or in the mempool if it has not been confirmed
+			// so the output is never cacheable
+			outputSpend := index.GetOutputSpend (outputRequest)
+			if !outputSpend.IsNil () { outputJsonObj ["spent_by"] = outputSpendToJson (outputSpend) }
+
 			var outputBytes [] byte
 			if outputRequestOptions ["human_readable"] != nil && outputRequestOptions ["human_readable"].(bool) {
 				outputBytes, err = json.MarshalIndent (outputJsonObj, "", "\t")
@@ -358,19 +720,15 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 
 		case "input":
 
-			if httpMethod != "POST" { errorMessage = fmt.Sprintf ("%s must be sent as a POST request.", functionName); break }
-
-			// unpack the json
-			var requestParams map [string] interface {}
-			err := json.NewDecoder (requestBody).Decode (&requestParams)
-			if err != nil { errorMessage = err.Error (); break }
+			requestParams, paramError := getRequestParams (httpMethod, functionName, requestBody, resourceParams)
+			if paramError != nil { return "", false, paramError }
 
 			if requestParams ["tx_id"] == nil {
-				return "tx_id parameter is required"
+				return "", false, missingParameter ("tx_id")
 			}
 
 			if requestParams ["input_index"] == nil {
-				return "input_index parameter is required"
+				return "", false, missingParameter ("input_index")
 			}
 
 			// get the request options
@@ -379,30 +737,31 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 			switch requestParams ["tx_id"].(type) {
 				case string:
 					txRequest.TxId = requestParams ["tx_id"].(string)
-					if len (txRequest.TxId) != 64 { return "malformed request: parameter tx_id is not a valid transaction id" }
-				default: return "malformed request: tx_id must be a hex string"
+					if len (txRequest.TxId) != 64 { return "", false, invalidParameter ("tx_id", "parameter tx_id is not a valid transaction id") }
+				default: return "", false, invalidParameter ("tx_id", "tx_id must be a hex string")
 			}
 
 			input_index := uint16 (0xffff)
 			switch requestParams ["input_index"].(type) {
 				case float64:
 					input_index = uint16 (requestParams ["input_index"].(float64))
-				default: return "malformed request: input_index must be a numeric index"
+				default: return "", false, invalidParameter ("input_index", "input_index must be a numeric index")
 			}
 
-			inputRequestOptions := map [string] interface {} {}
-			if requestParams ["options"] != nil { inputRequestOptions = requestParams ["options"].(map [string] interface {}) }
+			inputRequestOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
 
 			// get the input from the node proxy
 			tx := nodeProxy.GetTx (txRequest)
-			if tx.IsNil () || input_index >= tx.GetInputCount () { return "input not found" }
+			if tx.IsNil () || input_index >= tx.GetInputCount () { return "", false, newApiError (ERROR_INPUT_NOT_FOUND, "input not found") }
+			cacheable = tx.IsConfirmed ()
 
 			input := tx.GetInput (input_index)
 			if !input.IsCoinbase () {
 				previousOutput := nodeProxy.GetOutput (node.OutputRequest { TxId: input.GetPreviousOutputTxId (), OutputIndex: input.GetPreviousOutputIndex () })
 				input.SetPreviousOutput (previousOutput)
 
-				if len (input.GetSpendType ()) == 0 { return "input not found" }
+				if len (input.GetSpendType ()) == 0 { return "", false, newApiError (ERROR_INPUT_NOT_FOUND, "input not found") }
 			}
 
 			inputJsonObj := inputToJson (input)
@@ -418,9 +777,485 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 			responseJson = string (inputBytes)
 
 
+		// the batch functions return a result or an error for each item, in the order they were requested
+		case "txs", "inputs", "outputs":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			// unpack the json
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			batchOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			itemsName := functionName
+			if functionName == "txs" { itemsName = "ids" }
+
+			items, paramError := getBatchItems (requestParams, itemsName)
+			if paramError != nil { return "", false, paramError }
+
+			includeInputDetail := batchOptions ["include_input_detail"] != nil && batchOptions ["include_input_detail"].(bool)
+
+			lookup := newBatchLookup (nodeProxy)
+			results := make ([] map [string] interface {}, len (items))
+			for i, item := range items {
+				switch functionName {
+					case "txs": results [i] = batchTxToJson (&lookup, item, includeInputDetail, version)
+					case "inputs": results [i] = batchInputToJson (&lookup, item, version)
+					case "outputs": results [i] = batchOutputToJson (&lookup, item, version)
+				}
+			}
+
+			responseJson = marshalWithOptions (map [string] interface {} { "results": results }, batchOptions)
+
+
+		case "psbt":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			// unpack the json
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			if requestParams ["psbt"] == nil {
+				return "", false, missingParameter ("psbt")
+			}
+
+			psbtStr := ""
+			switch requestParams ["psbt"].(type) {
+				case string:
+					psbtStr = requestParams ["psbt"].(string)
+				default:
+					return "", false, invalidParameter ("psbt", "psbt must be a base64 or hex string")
+			}
+
+			psbtRequestOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			// the psbt contains everything we need, so the node is not used
+			psbt, err := btc.DecodePsbtString (psbtStr)
+			if err != nil { return "", false, invalidParameter ("psbt", err.Error ()) }
+
+			psbtJsonObj := psbtToJson (psbt)
+
+			var psbtBytes [] byte
+			if psbtRequestOptions ["human_readable"] != nil && psbtRequestOptions ["human_readable"].(bool) {
+				psbtBytes, err = json.MarshalIndent (psbtJsonObj, "", "\t")
+			} else {
+				psbtBytes, err = json.Marshal (psbtJsonObj)
+			}
+			if err != nil { fmt.Println (err.Error ()) }
+
+			responseJson = string (psbtBytes)
+
+
+		case "decode_tx":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			// unpack the json
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			if requestParams ["hex"] == nil {
+				return "", false, missingParameter ("hex")
+			}
+
+			var rawBytes [] byte
+			switch requestParams ["hex"].(type) {
+				case string:
+					rawBytes, err = hex.DecodeString (requestParams ["hex"].(string))
+					if err != nil { return "", false, invalidParameter ("hex", "hex is not a valid hex string") }
+				default:
+					return "", false, invalidParameter ("hex", "hex must be a hex string")
+			}
+
+			// previous outputs supplied by the client are only used when the node does not know about them
+			suppliedOutputs := make (map [string] btc.Output)
+			if requestParams ["previous_outputs"] != nil {
+				previousOutputs, ok := requestParams ["previous_outputs"].([] interface {})
+				if !ok { return "", false, invalidParameter ("previous_outputs", "previous_outputs must be an array") }
+
+				for _, previousOutputParam := range previousOutputs {
+					outputKey, output, errStr := parsePreviousOutput (previousOutputParam)
+					if len (errStr) > 0 { return "", false, invalidParameter ("previous_outputs", errStr) }
+					suppliedOutputs [outputKey] = output
+				}
+			}
+
+			decodeRequestOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			tx, err := btc.DecodeRawTx (rawBytes)
+			if err != nil { return "", false, invalidParameter ("hex", err.Error ()) }
+
+			for i, input := range tx.GetInputs () {
+				if input.IsCoinbase () { continue }
+
+				previousOutput := nodeProxy.GetOutput (node.OutputRequest { TxId: input.GetPreviousOutputTxId (), OutputIndex: input.GetPreviousOutputIndex () })
+				if len (previousOutput.GetOutputType ()) == 0 {
+					previousOutput = suppliedOutputs [fmt.Sprintf ("%s:%d", input.GetPreviousOutputTxId (), input.GetPreviousOutputIndex ())]
+				}
+
+				// inputs without a previous output are returned without a spend type
+				if len (previousOutput.GetOutputType ()) > 0 { tx.SetPreviousOutput (uint16 (i), previousOutput) }
+			}
+
+			txJsonObj := txToJson (tx)
+
+			var txBytes [] byte
+			if decodeRequestOptions ["human_readable"] != nil && decodeRequestOptions ["human_readable"].(bool) {
+				txBytes, err = json.MarshalIndent (txJsonObj, "", "\t")
+			} else {
+				txBytes, err = json.Marshal (txJsonObj)
+			}
+			if err != nil { fmt.Println (err.Error ()) }
+
+			responseJson = string (txBytes)
+
+
+		case "job_start":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			// unpack the json
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			jobOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			if requestParams ["start_height"] == nil { return "", false, missingParameter ("start_height") }
+			startHeight, ok := requestParams ["start_height"].(float64)
+			if !ok || startHeight < 0 { return "", false, invalidParameter ("start_height", "parameter start_height is not a valid block height") }
+
+			if requestParams ["end_height"] == nil { return "", false, missingParameter ("end_height") }
+			endHeight, ok := requestParams ["end_height"].(float64)
+			if !ok || endHeight < 0 { return "", false, invalidParameter ("end_height", "parameter end_height is not a valid block height") }
+
+			if requestParams ["analyzers"] == nil { return "", false, missingParameter ("analyzers") }
+			analyzerParams, ok := requestParams ["analyzers"].([] interface {})
+			if !ok { return "", false, invalidParameter ("analyzers", "analyzers must be an array") }
+
+			analyzerNames := make ([] string, len (analyzerParams))
+			for a, analyzerParam := range analyzerParams {
+				analyzerNames [a], ok = analyzerParam.(string)
+				if !ok { return "", false, invalidParameter ("analyzers", "analyzers must contain strings") }
+			}
+
+			job, err := jobs.StartJob (uint32 (startHeight), uint32 (endHeight), analyzerNames)
+			if err != nil { return "", false, newApiError (ERROR_REQUEST_REJECTED, err.Error ()) }
+
+			responseJson = marshalWithOptions (job.GetReport (), jobOptions)
+
+
+		// returns the progress of the job and its results so far
+		case "job":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			jobOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			job, jobError := getJobFromParams (requestParams)
+			if job == nil { return "", false, jobError }
+
+			responseJson = marshalWithOptions (job.GetReport (), jobOptions)
+
+
+		case "job_stop", "job_resume":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			jobOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			job, jobError := getJobFromParams (requestParams)
+			if job == nil { return "", false, jobError }
+
+			if functionName == "job_stop" {
+				job.Stop ()
+			} else {
+				if err := job.Resume (); err != nil { return "", false, newApiError (ERROR_REQUEST_REJECTED, err.Error ()) }
+			}
+
+			responseJson = marshalWithOptions (job.GetReport (), jobOptions)
+
+
+		// the results are not included in the list
+		case "jobs":
+
+			if httpMethod != "GET" { return "", false, methodNotAllowed (functionName, "GET") }
+
+			jobList := make ([] map [string] interface {}, 0)
+			for _, job := range jobs.GetJobs () {
+				report := job.GetReport ()
+				delete (report, "results")
+				jobList = append (jobList, report)
+			}
+
+			jsonBytes, err := json.Marshal (map [string] interface {} { "jobs": jobList })
+			if err != nil { fmt.Println (err) }
+
+			responseJson = string (jsonBytes)
+
+
+		case "export_start":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			// unpack the json
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			exportOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			if requestParams ["start_height"] == nil { return "", false, missingParameter ("start_height") }
+			startHeight, ok := requestParams ["start_height"].(float64)
+			if !ok || startHeight < 0 { return "", false, invalidParameter ("start_height", "parameter start_height is not a valid block height") }
+
+			if requestParams ["end_height"] == nil { return "", false, missingParameter ("end_height") }
+			endHeight, ok := requestParams ["end_height"].(float64)
+			if !ok || endHeight < 0 { return "", false, invalidParameter ("end_height", "parameter end_height is not a valid block height") }
+
+			if requestParams ["format"] == nil { return "", false, missingParameter ("format") }
+			format, ok := requestParams ["format"].(string)
+			if !ok { return "", false, invalidParameter ("format", "parameter format is not a string") }
+
+			tables, paramError := getOptionalStrings (requestParams, "tables")
+			if paramError != nil { return "", false, paramError }
+
+			exp, err := export.StartExport (uint32 (startHeight), uint32 (endHeight), format, tables)
+			if err != nil { return "", false, newApiError (ERROR_REQUEST_REJECTED, err.Error ()) }
+
+			responseJson = marshalWithOptions (exp.GetReport (), exportOptions)
+
+
+		case "export", "export_stop":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			exportOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			exp, exportError := getExportFromParams (requestParams)
+			if exp == nil { return "", false, exportError }
+
+			if functionName == "export_stop" { exp.Stop () }
+
+			responseJson = marshalWithOptions (exp.GetReport (), exportOptions)
+
+
+		case "exports":
+
+			if httpMethod != "GET" { return "", false, methodNotAllowed (functionName, "GET") }
+
+			exportList := make ([] map [string] interface {}, 0)
+			for _, exp := range export.GetExports () {
+				exportList = append (exportList, exp.GetReport ())
+			}
+
+			jsonBytes, err := json.Marshal (map [string] interface {} { "exports": exportList })
+			if err != nil { fmt.Println (err) }
+
+			responseJson = string (jsonBytes)
+
+
+		case "watch_add":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			watchOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			params := make (map [string] string)
+			for _, name := range [] string { "type", "value", "url" } {
+				if requestParams [name] == nil { return "", false, missingParameter (name) }
+				value, ok := requestParams [name].(string)
+				if !ok { return "", false, invalidParameter (name, "parameter " + name + " is not a string") }
+				params [name] = value
+			}
+
+			w, err := watch.AddWatch (params ["type"], params ["value"], params ["url"])
+			if err != nil { return "", false, newApiError (ERROR_REQUEST_REJECTED, err.Error ()) }
+
+			responseJson = marshalWithOptions (w.GetReport (), watchOptions)
+
+
+		case "watch_remove":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			watchOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			w, watchError := getWatchFromParams (requestParams)
+			if w == nil { return "", false, watchError }
+
+			watch.RemoveWatch (w.GetId ())
+
+			responseJson = marshalWithOptions (w.GetReport (), watchOptions)
+
+
+		case "watches":
+
+			if httpMethod != "GET" { return "", false, methodNotAllowed (functionName, "GET") }
+
+			watchList := make ([] map [string] interface {}, 0)
+			for _, w := range watch.GetWatches () {
+				watchList = append (watchList, w.GetReport ())
+			}
+
+			jsonBytes, err := json.Marshal (map [string] interface {} { "watches": watchList })
+			if err != nil { fmt.Println (err) }
+
+			responseJson = string (jsonBytes)
+
+
+		// the most recent delivery attempts, for every watch or only one
+		case "watch_deliveries":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			watchOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			watchId, paramError := getOptionalString (requestParams, "watch_id")
+			if paramError != nil { return "", false, paramError }
+
+			responseJson = marshalWithOptions (map [string] interface {} { "deliveries": watch.GetDeliveryLog (watchId) }, watchOptions)
+
+
+		case "index_status":
+
+			if httpMethod != "GET" { return "", false, methodNotAllowed (functionName, "GET") }
+
+			jsonBytes, err := json.Marshal (indexStatusToJson (nodeProxy.GetCurrentBlockHeight ()))
+			if err != nil { fmt.Println (err) }
+
+			responseJson = string (jsonBytes)
+
+
+		case "index_inputs", "index_outputs":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			if !index.IsOpen () { return "", false, newApiError (ERROR_INDEX_DISABLED, "index is not enabled") }
+
+			// unpack the json
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			indexOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			queryParams, paramError := getIndexQueryParams (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			var results interface {}
+			continueFrom := ""
+			if functionName == "index_inputs" {
+
+				inputQuery := index.InputQuery {	StartHeight: queryParams.startHeight,
+													EndHeight: queryParams.endHeight,
+													Opcodes: queryParams.opcodes,
+													Fingerprint: queryParams.fingerprint,
+													Limit: queryParams.limit,
+													ContinueFrom: queryParams.continueFrom }
+
+				if inputQuery.SpendType, paramError = getOptionalString (requestParams, "spend_type"); paramError != nil { return "", false, paramError }
+				if inputQuery.ContentType, paramError = getOptionalString (requestParams, "content_type"); paramError != nil { return "", false, paramError }
+				if requestParams ["inscriptions_only"] != nil {
+					inscriptionsOnly, ok := requestParams ["inscriptions_only"].(bool)
+					if !ok { return "", false, invalidParameter ("inscriptions_only", "parameter inscriptions_only is not a bool") }
+					inputQuery.InscriptionsOnly = inscriptionsOnly
+				}
+
+				results, continueFrom, err = index.QueryInputs (inputQuery)
+
+			} else {
+
+				outputQuery := index.OutputQuery {	StartHeight: queryParams.startHeight,
+													EndHeight: queryParams.endHeight,
+													Opcodes: queryParams.opcodes,
+													Fingerprint: queryParams.fingerprint,
+													Limit: queryParams.limit,
+													ContinueFrom: queryParams.continueFrom }
+
+				if outputQuery.OutputType, paramError = getOptionalString (requestParams, "output_type"); paramError != nil { return "", false, paramError }
+
+				results, continueFrom, err = index.QueryOutputs (outputQuery)
+			}
+			if err != nil { return "", false, newApiError (ERROR_REQUEST_REJECTED, err.Error ()) }
+
+			responseJson = marshalWithOptions (indexResultsToJson (results, continueFrom), indexOptions)
+
+
+		case "address":
+
+			if httpMethod != "POST" { return "", false, methodNotAllowed (functionName, "POST") }
+
+			if !index.IsOpen () { return "", false, newApiError (ERROR_INDEX_DISABLED, "index is not enabled") }
+
+			// unpack the json
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (requestBody).Decode (&requestParams)
+			if err != nil { return "", false, newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			addressOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			outputScript, paramError := getAddressScriptFromParams (requestParams)
+			if paramError != nil { return "", false, paramError }
+
+			limit := 0
+			if requestParams ["limit"] != nil {
+				limitParam, ok := requestParams ["limit"].(float64)
+				if !ok { return "", false, invalidParameter ("limit", "parameter limit is not a number") }
+				limit = int (limitParam)
+			}
+
+			history, err := index.GetAddressHistory (outputScript, limit)
+			if err != nil { return "", false, newApiError (ERROR_REQUEST_REJECTED, err.Error ()) }
+
+			responseJson = marshalWithOptions (addressHistoryToJson (outputScript, history), addressOptions)
+
+
 		case "current_block_height":
 
-			if httpMethod != "GET" { errorMessage = fmt.Sprintf ("%s must be sent as a GET request.", functionName); break }
+			if httpMethod != "GET" { return "", false, methodNotAllowed (functionName, "GET") }
 
 			height := nodeProxy.GetCurrentBlockHeight ()
 
@@ -431,15 +1266,9 @@ func (api *RestApiV1) HandleRequest (httpMethod string, functionName string, get
 			responseJson = string (jsonBytes)
 
 		default:
-			errorMessage = fmt.Sprintf ("Unknown REST v%d function: %s", api.GetVersion (), functionName)
+			return "", false, newApiError (ERROR_UNKNOWN_FUNCTION, fmt.Sprintf ("Unknown REST v%d function: %s", version, functionName))
 	}
 
-	if len (errorMessage) > 0 {
-		fmt.Println (errorMessage)
-		errBytes, _ := json.Marshal (RestError { Error: errorMessage })
-		responseJson = string (errBytes)
-	}
-
-	return responseJson
+	return responseJson, cacheable, nil
 }
 
@@ -0,0 +1,171 @@
+package rest
+
+import (
+	"strings"
+	"testing"
+	"net/http/httptest"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node/nodetest"
+)
+
+// a p2pkh spend of output 1 of a transaction the node does not know, with one p2pkh output
+const testLegacyTxHex = "01000000" + "01" + "1111111111111111111111111111111111111111111111111111111111111111" + "01000000" +
+						"6a" + "47" + "3044022011111111111111111111111111111111111111111111111111111111111111110220222222222222222222222222222222222222222222222222222222222222222201" +
+						"21" + "023333333333333333333333333333333333333333333333333333333333333333" + "feffffff" +
+						"01" + "e803000000000000" + "19" + "76a914" + "2222222222222222222222222222222222222222" + "88ac" + "00000000"
+
+const testP2wpkhScriptHex = "0014" + "4444444444444444444444444444444444444444"
+
+// calls a function with a json request body and returns the decoded response
+func callTestFunction (t *testing.T, httpMethod string, functionName string, requestBody string) (map [string] interface {}, *apiError) {
+	t.Helper ()
+
+	nodetest.Start ()
+
+	responseJson, _, apiErr := handleFunction (httpMethod, functionName, nil, nil, strings.NewReader (requestBody), 2)
+	if apiErr != nil { return nil, apiErr }
+
+	var response map [string] interface {}
+	if err := json.Unmarshal ([] byte (responseJson), &response); err != nil { t.Fatal (err) }
+	return response, nil
+}
+
+func getTestInput (t *testing.T, tx map [string] interface {}, inputIndex int) map [string] interface {} {
+	t.Helper ()
+
+	inputs, _ := tx ["inputs"].([] interface {})
+	if inputIndex >= len (inputs) { t.Fatalf ("%d inputs", len (inputs)) }
+	return inputs [inputIndex].(map [string] interface {})
+}
+
+func TestDecodeTxSegwit (t *testing.T) {
+
+	// the previous output is found by the node
+	chain, _ := nodetest.Start ()
+	fundingTxHex := nodetest.NewTxHex ([] nodetest.Outpoint { { TxId: strings.Repeat ("55", 32), Index: 0 } }, [] nodetest.TxOutput { { Value: 2000, ScriptHex: testP2wpkhScriptHex } })
+	chain.AddBlock (fundingTxHex)
+
+	fundingTxId := nodetest.GetTxId (fundingTxHex)
+	spendingTxHex := testSegwitTxHex [: 14] + hexReversed (t, fundingTxId) + testSegwitTxHex [78:]
+
+	tx, apiErr := callTestFunction (t, "POST", "decode_tx", `{ "hex": "` + spendingTxHex + `" }`)
+	if apiErr != nil { t.Fatal (apiErr.message) }
+	checkResponse (t, "Tx", tx)
+
+	if tx ["bip141"] != true || tx ["coinbase"] != false || tx ["status"] != "unconfirmed" { t.Errorf ("bip141 %v, coinbase %v, status %v", tx ["bip141"], tx ["coinbase"], tx ["status"]) }
+
+	input := getTestInput (t, tx, 0)
+	if input ["previous_output_tx_id"] != fundingTxId || input ["segwit"] == nil { t.Errorf ("input %v", input) }
+	if input ["spend_type"] != btc.OUTPUT_TYPE_P2WPKH { t.Errorf ("spend type %v", input ["spend_type"]) }
+}
+
+func TestDecodeTxLegacy (t *testing.T) {
+
+	// the node does not know the previous output, so the supplied one is used
+	previousOutputs := `[ { "tx_id": "` + strings.Repeat ("11", 32) + `", "output_index": 1, "value": 5000, "output_script": "76a914` + strings.Repeat ("22", 20) + `88ac" } ]`
+
+	for name, test := range map [string] struct {
+		requestBody string
+		spendType interface {}
+	} {	"without previous outputs": { `{ "hex": "` + testLegacyTxHex + `" }`, nil },
+		"with previous outputs": { `{ "hex": "` + testLegacyTxHex + `", "previous_outputs": ` + previousOutputs + ` }`, btc.OUTPUT_TYPE_P2PKH } } {
+		tx, apiErr := callTestFunction (t, "POST", "decode_tx", test.requestBody)
+		if apiErr != nil { t.Errorf ("%s: %s", name, apiErr.message); continue }
+
+		input := getTestInput (t, tx, 0)
+		if tx ["bip141"] != false || input ["previous_output_index"] != float64 (1) || input ["segwit"] != nil { t.Errorf ("%s: tx %v", name, tx) }
+		if input ["spend_type"] != test.spendType { t.Errorf ("%s: spend type %v", name, input ["spend_type"]) }
+	}
+}
+
+func TestDecodeTxCoinbase (t *testing.T) {
+
+	network := btc.GetNetwork ()
+	tx, apiErr := callTestFunction (t, "POST", "decode_tx", `{ "hex": "` + network.GetGenesisTxHex () + `", "options": { "human_readable": true } }`)
+	if apiErr != nil { t.Fatal (apiErr.message) }
+
+	input := getTestInput (t, tx, 0)
+	if tx ["id"] != network.GetGenesisTxId () || tx ["coinbase"] != true || input ["coinbase"] != true || input ["previous_output_tx_id"] != nil { t.Errorf ("tx %v", tx) }
+}
+
+func TestDecodeTxErrors (t *testing.T) {
+
+	// output 65536 of the unknown transaction
+	largeIndexTxHex := testLegacyTxHex [: 74] + "00000100" + testLegacyTxHex [82:]
+	largeIndexOutput := `[ { "tx_id": "` + strings.Repeat ("11", 32) + `", "output_index": 65536, "value": 5000, "output_script": "51" } ]`
+
+	for name, test := range map [string] struct {
+		httpMethod string
+		requestBody string
+		code string
+		parameter string
+	} {	"trailing bytes": { "POST", `{ "hex": "` + testLegacyTxHex + `00" }`, ERROR_INVALID_PARAMETER, "hex" },
+		"truncated": { "POST", `{ "hex": "` + testSegwitTxHex [: len (testSegwitTxHex) - 40] + `" }`, ERROR_INVALID_PARAMETER, "hex" },
+		"output index above 0xffff": { "POST", `{ "hex": "` + largeIndexTxHex + `" }`, ERROR_INVALID_PARAMETER, "hex" },
+		"previous output index above 0xffff": { "POST", `{ "hex": "` + testLegacyTxHex + `", "previous_outputs": ` + largeIndexOutput + ` }`, ERROR_INVALID_PARAMETER, "previous_outputs" },
+		"not hex": { "POST", `{ "hex": "xyz" }`, ERROR_INVALID_PARAMETER, "hex" },
+		"hex is a number": { "POST", `{ "hex": 1 }`, ERROR_INVALID_PARAMETER, "hex" },
+		"no hex": { "POST", `{}`, ERROR_MISSING_PARAMETER, "hex" },
+		"invalid json": { "POST", `{ "hex": `, ERROR_INVALID_JSON, "" },
+		"get": { "GET", `{ "hex": "` + testLegacyTxHex + `" }`, ERROR_METHOD_NOT_ALLOWED, "" } } {
+		_, apiErr := callTestFunction (t, test.httpMethod, "decode_tx", test.requestBody)
+		if apiErr == nil { t.Errorf ("%s: no error", name); continue }
+		if apiErr.code != test.code || apiErr.parameter != test.parameter { t.Errorf ("%s: error %s for %q, expected %s for %q", name, apiErr.code, apiErr.parameter, test.code, test.parameter) }
+	}
+}
+
+// tx ids are displayed in the opposite order from how they are serialized
+func hexReversed (t *testing.T, hexStr string) string {
+	t.Helper ()
+
+	rawBytes, err := hex.DecodeString (hexStr)
+	if err != nil { t.Fatal (err) }
+	return hex.EncodeToString (btc.ReverseBytes (rawBytes))
+}
+
+func TestGetOptions (t *testing.T) {
+
+	for name, test := range map [string] struct {
+		requestBody string
+		parameter string
+	} {	"no options": { `{}`, "" },
+		"null options": { `{ "options": null }`, "" },
+		"options": { `{ "options": { "human_readable": true, "include_input_detail": false, "unknown": 1 } }`, "" },
+		"number": { `{ "options": 1 }`, "options" },
+		"array": { `{ "options": [ true ] }`, "options" },
+		"string": { `{ "options": "human_readable" }`, "options" },
+		"human_readable is not a bool": { `{ "options": { "human_readable": 1 } }`, "human_readable" },
+		"include_input_detail is not a bool": { `{ "options": { "include_input_detail": "true" } }`, "include_input_detail" } } {
+		var requestParams map [string] interface {}
+		if err := json.Unmarshal ([] byte (test.requestBody), &requestParams); err != nil { t.Fatal (err) }
+
+		options, apiErr := getOptions (requestParams)
+		if len (test.parameter) == 0 {
+			if apiErr != nil { t.Errorf ("%s: %s", name, apiErr.message) } else if options == nil { t.Errorf ("%s: no options", name) }
+			continue
+		}
+		if apiErr == nil || apiErr.code != ERROR_INVALID_PARAMETER || apiErr.parameter != test.parameter { t.Errorf ("%s: expected an invalid %s parameter", name, test.parameter) }
+	}
+}
+
+// options that are not an object are rejected by every function instead of panicking
+func TestInvalidOptions (t *testing.T) {
+
+	network := btc.GetNetwork ()
+	for functionName, requestBody := range map [string] string {	"block": `{ "height": 0, "options": 1 }`,
+																	"block_stats": `{ "height": 0, "options": 1 }`,
+																	"tx": `{ "id": "` + network.GetGenesisTxId () + `", "options": 1 }`,
+																	"output": `{ "tx_id": "` + network.GetGenesisTxId () + `", "output_index": 0, "options": 1 }`,
+																	"decode_tx": `{ "hex": "` + network.GetGenesisTxHex () + `", "options": 1 }`,
+																	"job": `{ "id": "unknown", "options": 1 }` } {
+		_, apiErr := callTestFunction (t, "POST", functionName, requestBody)
+		if apiErr == nil || apiErr.code != ERROR_INVALID_PARAMETER || apiErr.parameter != "options" { t.Errorf ("%s: expected an invalid options parameter", functionName) }
+	}
+
+	request := httptest.NewRequest ("POST", "/rest/v2/block_txs", strings.NewReader (`{ "height": 0, "options": 1 }`))
+	apiErr := handleStreamingFunction (request, "block_txs", func (interface {}) bool { return true })
+	if apiErr == nil || apiErr.code != ERROR_INVALID_PARAMETER || apiErr.parameter != "options" { t.Error ("block_txs: expected an invalid options parameter") }
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// v2 has the same functions as v1, but errors are returned as objects with a code and an http status code
+// the responses are described by the schemas in the openapi document, which is returned by /rest/v2/openapi.json
+type RestApiV2 struct {
+}
+
+func (api *RestApiV2) GetVersion () uint16 {
+	return 2
+}
+
+func (api *RestApiV2) HandleRequest (response http.ResponseWriter, request *http.Request, functionName string, getParams [] string) {
+
+	if functionName == "openapi.json" {
+		if request.Method != "GET" { writeV2Error (response, methodNotAllowed (functionName, "GET"), "GET"); return }
+		writeV2Json (response, http.StatusOK, marshalWithOptions (getOpenApiDocument (), map [string] interface {} { "human_readable": true }))
+		return
+	}
+
+	// only functions that are described in the openapi document can be used
+	function, found := getApiFunction (functionName)
+	if !found {
+		writeV2Error (response, newApiError (ERROR_UNKNOWN_FUNCTION, fmt.Sprintf ("Unknown REST v%d function: %s", api.GetVersion (), functionName)), "")
+		return
+	}
+
+	if function.streaming {
+		api.handleStreamingRequest (response, request, function)
+		return
+	}
+
+	// blocks and transactions can also be requested with GET
+	allowedMethod := function.method
+	isResource := request.Method == "GET" && IsResourceFunction (functionName)
+	if IsResourceFunction (functionName) { allowedMethod = "GET, POST" }
+
+	responseJson, cacheable, apiErr := handleFunction (request.Method, functionName, getParams, request.URL.Query (), request.Body, api.GetVersion ())
+	if apiErr != nil { writeV2Error (response, apiErr, allowedMethod); return }
+
+	if isResource { setResourceCacheHeader (response, cacheable) }
+
+	writeV2Json (response, http.StatusOK, responseJson)
+}
+
+// an error that occurs before the first line is returned with its status code
+// after that, the status code has already been sent, so the error is written as the last line
+func (api *RestApiV2) handleStreamingRequest (response http.ResponseWriter, request *http.Request, function apiFunction) {
+
+	lineCount := 0
+	lineWriter := getLineWriter (response, request)
+	writeLine := func (lineData interface {}) bool {
+		if lineCount == 0 { response.Header ().Set ("Content-Type", "application/x-ndjson") }
+		lineCount++
+		return lineWriter (lineData)
+	}
+
+	apiErr := handleStreamingFunction (request, function.name, writeLine)
+	if apiErr == nil { return }
+
+	fmt.Println (apiErr.message)
+	if lineCount == 0 {
+		writeV2Error (response, apiErr, function.method)
+	} else {
+		writeLine (map [string] interface {} { "error": apiErr.toV2 () })
+	}
+}
+
+func writeV2Json (response http.ResponseWriter, statusCode int, responseJson string) {
+	response.Header ().Set ("Content-Type", "application/json")
+	response.WriteHeader (statusCode)
+	fmt.Fprint (response, responseJson)
+}
+
+// the allowed method is sent in the Allow header, which is required with a 405
+func writeV2Error (response http.ResponseWriter, apiErr *apiError, allowedMethod string) {
+
+	if apiErr.code == ERROR_METHOD_NOT_ALLOWED { response.Header ().Set ("Allow", allowedMethod) }
+
+	writeV2Json (response, apiErr.GetStatusCode (), marshalWithOptions (map [string] interface {} { "error": apiErr.toV2 () }, nil))
+}
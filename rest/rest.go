@@ -26,7 +26,7 @@ func RestHandler (response http.ResponseWriter, request *http.Request) {
 	responseJson := ""
 	if !formatError {
 		requestParts := strings.Split (modifiedPath, "/")
-		formatError = requestParts [0] != "rest"
+		formatError = requestParts [0] != "rest" || len (requestParts) < 3
 		if !formatError {
 			restAPIVersion := requestParts [1]
 			restAPIEndpoint := requestParts [2]
@@ -34,7 +34,15 @@ func RestHandler (response http.ResponseWriter, request *http.Request) {
 			switch restAPIVersion {
 				case "v1":
 					restApiV1 := RestApiV1 {}
-					responseJson = restApiV1.HandleRequest (request.Method, restAPIEndpoint, restAPIParamString, request.Body)
+					if restApiV1.IsStreamingFunction (restAPIEndpoint) {
+						restApiV1.HandleStreamingRequest (response, request, restAPIEndpoint)
+						return
+					}
+					responseJson = restApiV1.HandleRequest (response, request.Method, restAPIEndpoint, restAPIParamString, request.URL.Query (), request.Body)
+				case "v2":
+					restApiV2 := RestApiV2 {}
+					restApiV2.HandleRequest (response, request, restAPIEndpoint, restAPIParamString)
+					return
 			}
 		}
 	}
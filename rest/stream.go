@@ -0,0 +1,157 @@
+package rest
+
+import (
+	"fmt"
+	"encoding/json"
+	"net/http"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+	"github.com/btc-script-explorer/scantool/search"
+)
+
+// streaming functions write newline delimited json as results are found instead of returning a single response
+// each line is a json object, and the last line is either a summary or an error
+
+func (api *RestApiV1) IsStreamingFunction (functionName string) bool {
+	return functionName == "script_search" || functionName == "block_txs"
+}
+
+func (api *RestApiV1) HandleStreamingRequest (response http.ResponseWriter, request *http.Request, functionName string) {
+
+	response.Header ().Set ("Content-Type", "application/x-ndjson")
+	writeLine := getLineWriter (response, request)
+
+	apiErr := handleStreamingFunction (request, functionName, writeLine)
+	if apiErr != nil {
+		fmt.Println (apiErr.message)
+		writeLine (RestError { Error: apiErr.message })
+	}
+}
+
+// returns a function that writes one line of json and returns false if the client is gone
+func getLineWriter (response http.ResponseWriter, request *http.Request) func (interface {}) bool {
+
+	flusher, canFlush := response.(http.Flusher)
+
+	return func (lineData interface {}) bool {
+		lineBytes, err := json.Marshal (lineData)
+		if err != nil { fmt.Println (err.Error ()); return false }
+
+		if _, err := response.Write (append (lineBytes, '\n')); err != nil { return false }
+		if canFlush { flusher.Flush () }
+
+		// stop if the client is gone
+		return request.Context ().Err () == nil
+	}
+}
+
+// the streaming functions are the same in every version, only the errors are written differently
+func handleStreamingFunction (request *http.Request, functionName string, writeLine func (interface {}) bool) *apiError {
+
+	switch functionName {
+
+		case "script_search":
+
+			if request.Method != "POST" { return methodNotAllowed (functionName, "POST") }
+
+			// unpack the json
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (request.Body).Decode (&requestParams)
+			if err != nil { return newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			searchRequest, paramError := getSearchRequestFromParams (requestParams)
+			if paramError != nil { return paramError }
+
+			summary, err := search.Search (searchRequest, func (match search.Match) bool { return writeLine (match) })
+			if err != nil { return newApiError (ERROR_REQUEST_REJECTED, err.Error ()) }
+
+			writeLine (map [string] interface {} { "summary": summary })
+
+
+		// every tx in the block, one line per tx, with the previous outputs of the inputs unless include_input_detail is false
+		// only one tx is held in memory at a time
+		case "block_txs":
+
+			if request.Method != "POST" { return methodNotAllowed (functionName, "POST") }
+
+			// unpack the json
+			var requestParams map [string] interface {}
+			err := json.NewDecoder (request.Body).Decode (&requestParams)
+			if err != nil { return newApiError (ERROR_INVALID_JSON, err.Error ()) }
+
+			blockTxsOptions, paramError := getOptions (requestParams)
+			if paramError != nil { return paramError }
+
+			includeInputDetail := true
+			if blockTxsOptions ["include_input_detail"] != nil {
+				var ok bool
+				includeInputDetail, ok = blockTxsOptions ["include_input_detail"].(bool)
+				if !ok { return invalidParameter ("include_input_detail", "option include_input_detail is not a bool") }
+			}
+
+			blockRequest, paramError := getBlockRequestFromParams (requestParams)
+			if paramError != nil { return paramError }
+
+			nodeProxy, err := node.GetNodeProxy ()
+			if err != nil { return newApiError (ERROR_NODE_UNAVAILABLE, err.Error ()) }
+
+			block := nodeProxy.GetBlock (blockRequest)
+			if block.IsNil () { return newApiError (ERROR_BLOCK_NOT_FOUND, "block not found") }
+
+			for t, txId := range block.GetTxIds () {
+				tx := nodeProxy.GetTx (node.TxRequest { TxId: txId, IncludeInputDetail: includeInputDetail })
+				if tx.IsNil () { return newApiError (ERROR_TX_NOT_FOUND, fmt.Sprintf ("tx %s not found", txId)) }
+
+				txJson := txToJson (tx)
+				txJson ["block_index"] = t
+				if !writeLine (txJson) { return nil }
+			}
+
+			writeLine (map [string] interface {} { "summary": map [string] interface {} { "hash": block.GetHash (), "height": block.GetHeight (), "tx_count": block.GetTxCount () } })
+	}
+
+	return nil
+}
+
+// returns the search request or an error
+func getSearchRequestFromParams (requestParams map [string] interface {}) (search.Request, *apiError) {
+
+	searchRequest := search.Request {}
+
+	patternParam, paramError := getOptionalString (requestParams, "pattern")
+	if paramError != nil { return searchRequest, paramError }
+	if len (patternParam) == 0 { return searchRequest, missingParameter ("pattern") }
+
+	pattern, err := btc.ParseScriptPattern (patternParam)
+	if err != nil { return searchRequest, invalidParameter ("pattern", err.Error ()) }
+	searchRequest.Pattern = pattern
+
+	if requestParams ["start_height"] == nil { return searchRequest, missingParameter ("start_height") }
+	startHeight, ok := requestParams ["start_height"].(float64)
+	if !ok || startHeight < 0 { return searchRequest, invalidParameter ("start_height", "parameter start_height is not a valid block height") }
+	searchRequest.StartHeight = uint32 (startHeight)
+
+	if requestParams ["end_height"] == nil { return searchRequest, missingParameter ("end_height") }
+	endHeight, ok := requestParams ["end_height"].(float64)
+	if !ok || endHeight < 0 { return searchRequest, invalidParameter ("end_height", "parameter end_height is not a valid block height") }
+	searchRequest.EndHeight = uint32 (endHeight)
+
+	if requestParams ["script_types"] != nil {
+		scriptTypeParams, ok := requestParams ["script_types"].([] interface {})
+		if !ok { return searchRequest, invalidParameter ("script_types", "script_types must be an array") }
+		for _, scriptTypeParam := range scriptTypeParams {
+			scriptType, ok := scriptTypeParam.(string)
+			if !ok { return searchRequest, invalidParameter ("script_types", "script_types must contain strings") }
+			searchRequest.ScriptTypes = append (searchRequest.ScriptTypes, scriptType)
+		}
+	}
+
+	if requestParams ["limit"] != nil {
+		limit, ok := requestParams ["limit"].(float64)
+		if !ok { return searchRequest, invalidParameter ("limit", "parameter limit is not a number") }
+		searchRequest.Limit = int (limit)
+	}
+
+	return searchRequest, nil
+}
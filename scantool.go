@@ -14,8 +14,16 @@ import (
 	"path/filepath"
 
 	"github.com/btc-script-explorer/scantool/app"
+	"github.com/btc-script-explorer/scantool/btc"
 	"github.com/btc-script-explorer/scantool/btc/node"
+	"github.com/btc-script-explorer/scantool/index"
+	"github.com/btc-script-explorer/scantool/jobs"
+	"github.com/btc-script-explorer/scantool/export"
+	"github.com/btc-script-explorer/scantool/graphql"
+	"github.com/btc-script-explorer/scantool/grpc"
+	"github.com/btc-script-explorer/scantool/live"
 	"github.com/btc-script-explorer/scantool/rest"
+	"github.com/btc-script-explorer/scantool/watch"
 	"github.com/btc-script-explorer/scantool/web"
 )
 
@@ -50,6 +58,10 @@ func printListeningMessage () {
 	messageLines = append (messageLines, "      " + app.Settings.GetNodeFullUrl ())
 	messageLines = append (messageLines, "")
 
+	network := btc.GetNetwork ()
+	messageLines = append (messageLines, "Network: " + network.GetDisplayName ())
+	messageLines = append (messageLines, "")
+
 	webLine := " Web: "; if app.Settings.IsWebOn () { webLine += app.Settings.GetFullUrl () + "/web/" } else { webLine += "Off" }
 	messageLines = append (messageLines, webLine)
 	messageLines = append (messageLines, "")
@@ -58,10 +70,27 @@ func printListeningMessage () {
 	messageLines = append (messageLines, "      curl -X POST -d '{}' " + app.Settings.GetFullUrl () + "/rest/v1/block")
 	messageLines = append (messageLines, "")
 
+	grpcLine := "gRPC: "; if app.Settings.IsGrpcOn () { grpcLine += app.Settings.GetGrpcAddr () } else { grpcLine += "Off" }
+	messageLines = append (messageLines, grpcLine)
+	messageLines = append (messageLines, "")
+
 	settingsLineCaching := "Caching: O"; if app.Settings.IsCachingOn () { settingsLineCaching += "n" } else { settingsLineCaching += "ff" }
 	messageLines = append (messageLines, settingsLineCaching)
 	messageLines = append (messageLines, "")
 
+	messageLines = append (messageLines, "Jobs: " + jobs.GetPath ())
+	messageLines = append (messageLines, "")
+
+	messageLines = append (messageLines, "Exports: " + export.GetPath ())
+	messageLines = append (messageLines, "")
+
+	messageLines = append (messageLines, "Watches: " + watch.GetPath ())
+	messageLines = append (messageLines, "")
+
+	indexLine := "Index: "; if index.IsOpen () { indexStatus := index.GetStatus (); indexLine += indexStatus.File } else { indexLine += "Off" }
+	messageLines = append (messageLines, indexLine)
+	messageLines = append (messageLines, "")
+
 	// first make sure every line is an even number of characters
 	for l := 0; l < len (messageLines); l++ {
 		if len (messageLines [l]) % 2 != 0 {
@@ -150,6 +179,20 @@ func main () {
 		return
 	}
 
+	// a node type that is not supported would otherwise only show up as a node that can not be reached
+	if _, err := app.Settings.GetNodeType (); err != nil {
+		fmt.Println (err.Error ())
+		fmt.Println ("Invalid node-type setting. Aborting.")
+		return
+	}
+
+	// a mistyped network would otherwise only show up when the node is first used
+	if err := app.Settings.CheckNetwork (); err != nil {
+		fmt.Println (err.Error ())
+		fmt.Println ("Invalid network setting. Aborting.")
+		return
+	}
+
 	// make sure the node is connected and start the cache if it is being used
 	_, err := node.GetNodeProxy ()
 	if err != nil {
@@ -157,8 +200,81 @@ func main () {
 		return
 	}
 
+	// job checkpoints and reports are saved in the jobs directory
+	jobsDirPath := app.Settings.GetJobsDir ()
+	if len (jobsDirPath) == 0 { jobsDirPath = filepath.Join (filepath.Dir (executablePath), "job-data") }
+	err = os.MkdirAll (jobsDirPath, 0755)
+	if err != nil {
+		fmt.Println (err.Error ())
+		fmt.Println (fmt.Sprintf ("Failed to create %s. Aborting.", jobsDirPath))
+		return
+	}
+
+	jobs.SetJobsPath (jobsDirPath)
+
+	// exported files are written to the exports directory
+	exportsDirPath := app.Settings.GetExportsDir ()
+	if len (exportsDirPath) == 0 { exportsDirPath = filepath.Join (filepath.Dir (executablePath), "export-data") }
+	err = os.MkdirAll (exportsDirPath, 0755)
+	if err != nil {
+		fmt.Println (err.Error ())
+		fmt.Println (fmt.Sprintf ("Failed to create %s. Aborting.", exportsDirPath))
+		return
+	}
+
+	export.SetExportsPath (exportsDirPath)
+
+	// the watchlist and the webhook delivery log are saved in the watch directory
+	watchDirPath := app.Settings.GetWatchDir ()
+	if len (watchDirPath) == 0 { watchDirPath = filepath.Join (filepath.Dir (executablePath), "watch-data") }
+	err = os.MkdirAll (watchDirPath, 0755)
+	if err != nil {
+		fmt.Println (err.Error ())
+		fmt.Println (fmt.Sprintf ("Failed to create %s. Aborting.", watchDirPath))
+		return
+	}
+
+	watch.SetWatchPath (watchDirPath)
+
+	// open the index if it is being used
+	if app.Settings.IsIndexOn () {
+		indexFile := app.Settings.GetIndexFile ()
+		if len (indexFile) == 0 { indexFile = filepath.Join (filepath.Dir (executablePath), "index.db") }
+		err = index.Open (indexFile, app.Settings.GetIndexStartHeight ())
+		if err != nil {
+			fmt.Println (err.Error ())
+			fmt.Println (fmt.Sprintf ("Failed to open index %s. Aborting.", indexFile))
+			return
+		}
+	}
+
 	printListeningMessage ()
 
+	// restart any jobs that were running when scantool was stopped
+	jobs.ResumeJobs ()
+
+	// new blocks and transactions are passed to the index and live events as soon as the node publishes them
+	node.StartZmq ()
+
+	// the index continues from the last block it indexed
+	index.Start ()
+
+	// new blocks are pushed to clients that subscribe to /live/events
+	live.Start ()
+
+	// transactions in new blocks and the mempool that match a watch are posted to its webhook
+	watch.Start (rest.TxToJson)
+
+	// the grpc server has its own port, next to the http server
+	if app.Settings.IsGrpcOn () {
+		err = grpc.Start ()
+		if err != nil {
+			fmt.Println (err.Error ())
+			fmt.Println (fmt.Sprintf ("Failed to start the gRPC server on %s. Aborting.", app.Settings.GetGrpcAddr ()))
+			return
+		}
+	}
+
 	mux := http.NewServeMux ()
 
 	mux.HandleFunc ("/", homeHandler)
@@ -171,6 +287,8 @@ func main () {
 	}
 
 	mux.HandleFunc ("/rest/", rest.RestHandler)
+	mux.HandleFunc ("/graphql", graphql.GraphqlHandler)
+	mux.HandleFunc ("/live/events", live.EventsHandler)
 
 	log.Fatal (http.ListenAndServe (app.Settings.GetBaseUrl (true), mux))
 }
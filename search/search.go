@@ -0,0 +1,134 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+// searches every script in a range of blocks for a script pattern
+// results are returned through a callback as they are found, so they can be streamed to the client
+
+// if ScriptTypes is empty, every type of script is searched
+// a limit of 0 returns every match in the range
+type Request struct {
+	StartHeight uint32
+	EndHeight uint32
+	Pattern btc.ScriptPattern
+	ScriptTypes [] string
+	Limit int
+}
+
+// for inputs, Type is the spend type and Index is the input index
+// for outputs, Type is the output type and Index is the output index
+// Position is the index of the first matching field in the script
+type Match struct {
+	Height uint32 `json:"height"`
+	TxId string `json:"tx_id"`
+	Input bool `json:"input"`
+	Index uint16 `json:"index"`
+	Type string `json:"type"`
+	ScriptType string `json:"script_type"`
+	Position int `json:"position"`
+	Script string `json:"script"`
+	Template string `json:"template"`
+}
+
+// a search that stops before the end height can be continued from the next height
+type Summary struct {
+	StartHeight uint32 `json:"start_height"`
+	NextHeight uint32 `json:"next_height"`
+	MatchCount int `json:"match_count"`
+	Complete bool `json:"complete"`
+}
+
+// found is called for every match, and the search stops if it returns false
+// blocks are always searched completely, so the limit can be exceeded by the matches in the last block
+func Search (request Request, found func (Match) bool) (Summary, error) {
+
+	summary := Summary { StartHeight: request.StartHeight, NextHeight: request.StartHeight }
+
+	if request.Pattern.IsNil () { return summary, fmt.Errorf ("pattern is required") }
+	if request.StartHeight > request.EndHeight { return summary, fmt.Errorf ("start_height is greater than end_height") }
+
+	searchTypes := make (map [string] bool)
+	for _, scriptType := range request.ScriptTypes {
+		if !btc.IsScriptType (scriptType) { return summary, fmt.Errorf ("%s is not a valid script type", scriptType) }
+		searchTypes [scriptType] = true
+	}
+	if len (searchTypes) == 0 {
+		for _, scriptType := range btc.GetScriptTypes () { searchTypes [scriptType] = true }
+	}
+
+	nodeProxy, err := node.GetNodeProxy ()
+	if err != nil { return summary, err }
+
+	// the previous outputs are needed for the spend types and to identify the serialized scripts in inputs
+	includeInputDetail := searchTypes [btc.SCRIPT_TYPE_INPUT] || searchTypes [btc.SCRIPT_TYPE_REDEEM] || searchTypes [btc.SCRIPT_TYPE_WITNESS] || searchTypes [btc.SCRIPT_TYPE_TAP]
+
+	for height := request.StartHeight; height <= request.EndHeight; height++ {
+
+		block := nodeProxy.GetBlock (node.BlockRequest { BlockKey: strconv.FormatUint (uint64 (height), 10) })
+		if block.IsNil () { return summary, fmt.Errorf ("block %d not found", height) }
+
+		for _, txId := range block.GetTxIds () {
+			tx := nodeProxy.GetTx (node.TxRequest { TxId: txId, IncludeInputDetail: includeInputDetail })
+			if tx.IsNil () { return summary, fmt.Errorf ("tx %s in block %d not found", txId, height) }
+
+			for _, match := range searchTx (tx, height, request.Pattern, searchTypes) {
+				if !found (match) { return summary, nil }
+				summary.MatchCount++
+			}
+		}
+
+		summary.NextHeight = height + 1
+		if request.Limit > 0 && summary.MatchCount >= request.Limit { break }
+	}
+
+	summary.Complete = summary.NextHeight > request.EndHeight
+	return summary, nil
+}
+
+func searchTx (tx btc.Tx, height uint32, pattern btc.ScriptPattern, searchTypes map [string] bool) [] Match {
+
+	matches := make ([] Match, 0)
+	txId := tx.GetTxId ()
+
+	check := func (script btc.Script, scriptType string, input bool, index uint16, typeName string) {
+		if !searchTypes [scriptType] || script.IsNil () { return }
+
+		position := pattern.Match (script)
+		if position < 0 { return }
+
+		matches = append (matches, Match {	Height: height,
+											TxId: txId,
+											Input: input,
+											Index: index,
+											Type: typeName,
+											ScriptType: scriptType,
+											Position: position,
+											Script: script.AsHex (),
+											Template: script.GetTemplate () })
+	}
+
+	for i, input := range tx.GetInputs () {
+		if input.IsCoinbase () { continue }
+
+		spendType := input.GetSpendType ()
+		check (input.GetInputScript (), btc.SCRIPT_TYPE_INPUT, true, uint16 (i), spendType)
+		if input.HasRedeemScript () { check (input.GetRedeemScript (), btc.SCRIPT_TYPE_REDEEM, true, uint16 (i), spendType) }
+
+		segwit := input.GetSegwit ()
+		check (segwit.GetWitnessScript (), btc.SCRIPT_TYPE_WITNESS, true, uint16 (i), spendType)
+		tapScript, _ := segwit.GetTapScript ()
+		check (tapScript, btc.SCRIPT_TYPE_TAP, true, uint16 (i), spendType)
+	}
+
+	for o, output := range tx.GetOutputs () {
+		check (output.GetOutputScript (), btc.SCRIPT_TYPE_OUTPUT, false, uint16 (o), output.GetOutputType ())
+	}
+
+	return matches
+}
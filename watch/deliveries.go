@@ -0,0 +1,182 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"sync"
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"encoding/hex"
+	"encoding/json"
+	"crypto/rand"
+)
+
+// every matching transaction is posted to the webhook of the watch
+// a delivery that fails is retried with a delay that doubles after every attempt, 10s, 20s, 40s, 80s and 160s
+// every attempt is written to the delivery log, which is kept in memory and appended to a file in the watch directory
+
+const DELIVERY_WORKERS = 4
+const DELIVERY_TIMEOUT = 10 * time.Second
+const MAX_DELIVERY_ATTEMPTS = 6
+const FIRST_RETRY_DELAY = 10 * time.Second
+
+// deliveries are dropped when the queue is full, which only happens if the webhooks are much slower than the blocks
+const MAX_QUEUED_DELIVERIES = 10000
+
+const MAX_LOGGED_DELIVERIES = 1000
+const DELIVERY_LOG_FILE_NAME = "deliveries.log"
+
+const DELIVERY_STATUS_DELIVERED = "delivered"
+const DELIVERY_STATUS_RETRYING = "retrying"
+const DELIVERY_STATUS_FAILED = "failed"
+const DELIVERY_STATUS_DROPPED = "dropped"
+
+// the id is the same for every attempt, so the receiver can tell when it gets the same delivery twice
+type delivery struct {
+	id string
+	watchId string
+	url string
+	event string
+	txId string
+	payload [] byte
+	attempt int
+}
+
+type DeliveryLogEntry struct {
+	DeliveryId string `json:"delivery_id"`
+	WatchId string `json:"watch_id"`
+	Url string `json:"url"`
+	Event string `json:"event"`
+	TxId string `json:"tx_id"`
+	Attempt int `json:"attempt"`
+	Status string `json:"status"`
+	StatusCode int `json:"status_code,omitempty"`
+	Error string `json:"error,omitempty"`
+	Time int64 `json:"time"`
+}
+
+type deliveryPayload struct {
+	DeliveryId string `json:"delivery_id"`
+	Event string `json:"event"`
+	Watch map [string] interface {} `json:"watch"`
+	Matches [] Match `json:"matches"`
+	BlockHeight uint32 `json:"block_height,omitempty"`
+	Tx map [string] interface {} `json:"tx"`
+}
+
+var deliveryQueue = make (chan *delivery, MAX_QUEUED_DELIVERIES)
+
+var deliveryLog [] DeliveryLogEntry
+var deliveryLogMutex sync.Mutex
+
+var deliveryClient = &http.Client { Timeout: DELIVERY_TIMEOUT }
+
+// retries are scheduled with this, so that the delays can be checked without waiting for them
+var scheduleRetry = time.AfterFunc
+
+func newDelivery (w *Watch, event string, payload deliveryPayload) (*delivery, error) {
+
+	idBytes := make ([] byte, 8)
+	if _, err := rand.Read (idBytes); err != nil { return nil, err }
+
+	payload.DeliveryId = hex.EncodeToString (idBytes)
+	payload.Event = event
+	payloadBytes, err := json.Marshal (payload)
+	if err != nil { return nil, err }
+
+	txId, _ := payload.Tx ["id"].(string)
+	return &delivery { id: payload.DeliveryId, watchId: w.id, url: w.url, event: event, txId: txId, payload: payloadBytes }, nil
+}
+
+// never blocks
+func queueDelivery (d *delivery) {
+	select {
+		case deliveryQueue <- d:
+		default:
+			logDelivery (d, DELIVERY_STATUS_DROPPED, 0, "the delivery queue is full")
+	}
+}
+
+func runDeliveryWorker () {
+	for d := range deliveryQueue { deliver (d) }
+}
+
+func deliver (d *delivery) {
+
+	if GetWatch (d.watchId) == nil { return }
+
+	d.attempt++
+
+	statusCode := 0
+	errorMessage := ""
+	response, err := deliveryClient.Post (d.url, "application/json", bytes.NewReader (d.payload))
+	if err != nil {
+		errorMessage = err.Error ()
+	} else {
+		response.Body.Close ()
+		statusCode = response.StatusCode
+		if statusCode < 200 || statusCode > 299 { errorMessage = response.Status }
+	}
+
+	if len (errorMessage) == 0 {
+		logDelivery (d, DELIVERY_STATUS_DELIVERED, statusCode, "")
+		return
+	}
+
+	if d.attempt >= MAX_DELIVERY_ATTEMPTS {
+		logDelivery (d, DELIVERY_STATUS_FAILED, statusCode, errorMessage)
+		return
+	}
+
+	logDelivery (d, DELIVERY_STATUS_RETRYING, statusCode, errorMessage)
+	scheduleRetry (FIRST_RETRY_DELAY << (d.attempt - 1), func () { queueDelivery (d) })
+}
+
+func logDelivery (d *delivery, status string, statusCode int, errorMessage string) {
+
+	entry := DeliveryLogEntry {	DeliveryId: d.id,
+								WatchId: d.watchId,
+								Url: d.url,
+								Event: d.event,
+								TxId: d.txId,
+								Attempt: d.attempt,
+								Status: status,
+								StatusCode: statusCode,
+								Error: errorMessage,
+								Time: time.Now ().Unix () }
+
+	deliveryLogMutex.Lock ()
+	defer deliveryLogMutex.Unlock ()
+
+	deliveryLog = append (deliveryLog, entry)
+	if len (deliveryLog) > MAX_LOGGED_DELIVERIES { deliveryLog = deliveryLog [len (deliveryLog) - MAX_LOGGED_DELIVERIES :] }
+
+	if len (path) == 0 { return }
+
+	entryBytes, err := json.Marshal (entry)
+	if err != nil { fmt.Println (err.Error ()); return }
+
+	logFile, err := os.OpenFile (filepath.Join (path, DELIVERY_LOG_FILE_NAME), os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0644)
+	if err != nil { fmt.Println (err.Error ()); return }
+	defer logFile.Close ()
+
+	if _, err := logFile.Write (append (entryBytes, '\n')); err != nil { fmt.Println (err.Error ()) }
+}
+
+// returns the most recent attempts, newest first
+// if a watch id is given, only the attempts for that watch are returned
+func GetDeliveryLog (watchId string) [] DeliveryLogEntry {
+
+	deliveryLogMutex.Lock ()
+	defer deliveryLogMutex.Unlock ()
+
+	entries := make ([] DeliveryLogEntry, 0)
+	for e := len (deliveryLog) - 1; e >= 0; e-- {
+		if len (watchId) > 0 && deliveryLog [e].WatchId != watchId { continue }
+		entries = append (entries, deliveryLog [e])
+	}
+
+	return entries
+}
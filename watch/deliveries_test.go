@@ -0,0 +1,213 @@
+package watch
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+	"strings"
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"encoding/json"
+)
+
+// the queue is shared by every test in the package, and there are no workers in tests
+func drainDeliveryQueue () {
+	for {
+		select {
+			case <-deliveryQueue:
+			default:
+				return
+		}
+	}
+}
+
+// the delivery log is written to a temporary watch directory
+func setTestDeliveryLog (t *testing.T) {
+	path = t.TempDir ()
+
+	deliveryLogMutex.Lock ()
+	deliveryLog = nil
+	deliveryLogMutex.Unlock ()
+
+	t.Cleanup (func () { path = "" })
+}
+
+// retries are queued right away, and the delays are returned instead of waited for
+func recordRetryDelays (t *testing.T) *[] time.Duration {
+	delays := [] time.Duration {}
+	scheduleRetry = func (delay time.Duration, f func ()) *time.Timer {
+		delays = append (delays, delay)
+		f ()
+		return nil
+	}
+
+	t.Cleanup (func () { scheduleRetry = time.AfterFunc })
+	return &delays
+}
+
+// a webhook that answers with the status codes it is given, and 200 after that
+type testWebhook struct {
+	server *httptest.Server
+	statusCodes [] int
+	bodies [] string
+	mutex sync.Mutex
+}
+
+func newTestWebhook (t *testing.T, statusCodes ...int) *testWebhook {
+
+	h := &testWebhook { statusCodes: statusCodes }
+	h.server = httptest.NewServer (http.HandlerFunc (func (response http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll (request.Body)
+
+		h.mutex.Lock ()
+		statusCode := http.StatusOK
+		if len (h.bodies) < len (h.statusCodes) { statusCode = h.statusCodes [len (h.bodies)] }
+		h.bodies = append (h.bodies, string (body))
+		h.mutex.Unlock ()
+
+		response.WriteHeader (statusCode)
+	}))
+	t.Cleanup (h.server.Close)
+
+	return h
+}
+
+// delivers the queued deliveries the way a worker would, until there are none left
+func runTestDeliveries (t *testing.T, maxDeliveries int) {
+	t.Helper ()
+
+	for delivered := 0; ; delivered++ {
+		select {
+			case d := <-deliveryQueue:
+				if delivered == maxDeliveries { t.Fatalf ("more than %d deliveries", maxDeliveries) }
+				deliver (d)
+			default:
+				return
+		}
+	}
+}
+
+func readTestDeliveryLogFile (t *testing.T) [] DeliveryLogEntry {
+	t.Helper ()
+
+	logBytes, err := os.ReadFile (filepath.Join (path, DELIVERY_LOG_FILE_NAME))
+	if err != nil { t.Fatal (err) }
+
+	entries := make ([] DeliveryLogEntry, 0)
+	for _, line := range strings.Split (strings.TrimSpace (string (logBytes)), "\n") {
+		var entry DeliveryLogEntry
+		if err := json.Unmarshal ([] byte (line), &entry); err != nil { t.Fatal (err) }
+		entries = append (entries, entry)
+	}
+
+	return entries
+}
+
+func queueTestDelivery (t *testing.T, webhookUrl string) (*Watch, *delivery) {
+	t.Helper ()
+
+	w := newTestWatch (t, RULE_SPEND_TYPE, "P2WPKH", webhookUrl)
+	addTestWatch (t, w)
+
+	d, err := newDelivery (w, EVENT_MEMPOOL_TX, deliveryPayload { Watch: w.GetReport (), Matches: [] Match {}, Tx: map [string] interface {} { "id": "ab" } })
+	if err != nil { t.Fatal (err) }
+
+	queueDelivery (d)
+	return w, d
+}
+
+func TestDeliverySucceedsAfterRetry (t *testing.T) {
+
+	drainDeliveryQueue ()
+	setTestDeliveryLog (t)
+	delays := recordRetryDelays (t)
+
+	webhook := newTestWebhook (t, http.StatusServiceUnavailable)
+	w, d := queueTestDelivery (t, webhook.server.URL)
+	runTestDeliveries (t, MAX_DELIVERY_ATTEMPTS)
+
+	// the receiver can tell it is the same delivery
+	if len (webhook.bodies) != 2 || webhook.bodies [0] != webhook.bodies [1] || webhook.bodies [0] != string (d.payload) { t.Fatalf ("%d requests", len (webhook.bodies)) }
+	if len (*delays) != 1 || (*delays) [0] != FIRST_RETRY_DELAY { t.Errorf ("retry delays %v", *delays) }
+
+	entries := readTestDeliveryLogFile (t)
+	if len (entries) != 2 { t.Fatalf ("%d log entries", len (entries)) }
+
+	retrying, delivered := entries [0], entries [1]
+	if retrying.Status != DELIVERY_STATUS_RETRYING || retrying.Attempt != 1 || retrying.StatusCode != http.StatusServiceUnavailable || retrying.Error != "503 Service Unavailable" { t.Errorf ("first entry %+v", retrying) }
+	if delivered.Status != DELIVERY_STATUS_DELIVERED || delivered.Attempt != 2 || delivered.StatusCode != http.StatusOK || len (delivered.Error) > 0 { t.Errorf ("second entry %+v", delivered) }
+	if delivered.DeliveryId != d.id || delivered.WatchId != w.id || delivered.Url != webhook.server.URL || delivered.Event != EVENT_MEMPOOL_TX || delivered.TxId != "ab" { t.Errorf ("second entry %+v", delivered) }
+
+	// the log in memory is newest first
+	memoryLog := GetDeliveryLog (w.id)
+	if len (memoryLog) != 2 || memoryLog [0] != delivered || memoryLog [1] != retrying { t.Errorf ("delivery log %+v", memoryLog) }
+	if len (GetDeliveryLog ("another watch")) != 0 { t.Error ("the delivery log has entries for another watch") }
+}
+
+func TestDeliveryFailsAfterTheLastAttempt (t *testing.T) {
+
+	drainDeliveryQueue ()
+	setTestDeliveryLog (t)
+	delays := recordRetryDelays (t)
+
+	statusCodes := make ([] int, MAX_DELIVERY_ATTEMPTS + 1)
+	for s := range statusCodes { statusCodes [s] = http.StatusInternalServerError }
+	webhook := newTestWebhook (t, statusCodes...)
+
+	_, d := queueTestDelivery (t, webhook.server.URL)
+	runTestDeliveries (t, MAX_DELIVERY_ATTEMPTS)
+
+	if len (webhook.bodies) != MAX_DELIVERY_ATTEMPTS { t.Errorf ("%d requests", len (webhook.bodies)) }
+
+	// the delay doubles after every attempt, and there is no retry after the last one
+	expectedDelays := [] time.Duration { 10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, 160 * time.Second }
+	if len (*delays) != len (expectedDelays) { t.Fatalf ("retry delays %v", *delays) }
+	for r, delay := range *delays {
+		if delay != FIRST_RETRY_DELAY << r || delay != expectedDelays [r] { t.Errorf ("retry %d was after %s", r + 1, delay) }
+	}
+
+	entries := readTestDeliveryLogFile (t)
+	if len (entries) != MAX_DELIVERY_ATTEMPTS { t.Fatalf ("%d log entries", len (entries)) }
+	for e, entry := range entries {
+		expectedStatus := DELIVERY_STATUS_RETRYING
+		if e == MAX_DELIVERY_ATTEMPTS - 1 { expectedStatus = DELIVERY_STATUS_FAILED }
+		if entry.Status != expectedStatus || entry.Attempt != e + 1 || entry.StatusCode != http.StatusInternalServerError || entry.DeliveryId != d.id { t.Errorf ("entry %d: %+v", e, entry) }
+	}
+}
+
+func TestDeliveryErrorIsLogged (t *testing.T) {
+
+	drainDeliveryQueue ()
+	setTestDeliveryLog (t)
+	recordRetryDelays (t)
+
+	// nothing is listening
+	webhook := newTestWebhook (t)
+	webhook.server.Close ()
+
+	queueTestDelivery (t, webhook.server.URL)
+	runTestDeliveries (t, MAX_DELIVERY_ATTEMPTS)
+
+	entries := readTestDeliveryLogFile (t)
+	failed := entries [len (entries) - 1]
+	if len (entries) != MAX_DELIVERY_ATTEMPTS || failed.Status != DELIVERY_STATUS_FAILED || failed.StatusCode != 0 || len (failed.Error) == 0 { t.Errorf ("last entry %+v", failed) }
+}
+
+func TestDeliveryForRemovedWatch (t *testing.T) {
+
+	drainDeliveryQueue ()
+	setTestDeliveryLog (t)
+
+	webhook := newTestWebhook (t)
+	w, _ := queueTestDelivery (t, webhook.server.URL)
+
+	watchMapMutex.Lock ()
+	delete (watchMap, w.id)
+	watchMapMutex.Unlock ()
+
+	runTestDeliveries (t, 1)
+	if len (webhook.bodies) != 0 || len (GetDeliveryLog ("")) != 0 { t.Error ("the delivery was made for a removed watch") }
+}
@@ -0,0 +1,208 @@
+package watch
+
+import (
+	"fmt"
+	"bytes"
+	"strings"
+	"net/url"
+	"encoding/hex"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// a watch is a rule and the url of the webhook that is called when a transaction matches it
+//
+//	output_script    outputs with this script, and inputs that spend them
+//	address          the same as output_script, with the script of the address
+//	fingerprint      outputs whose script has this fingerprint, and inputs whose serialized script has it (see the index)
+//	spend_type       inputs of this spend type
+//	content_type     inputs with an inscription of this content type, image/* matches every image
+//	pattern          any script that matches this script pattern (see script_search)
+
+const RULE_OUTPUT_SCRIPT = "output_script"
+const RULE_ADDRESS = "address"
+const RULE_FINGERPRINT = "fingerprint"
+const RULE_SPEND_TYPE = "spend_type"
+const RULE_CONTENT_TYPE = "content_type"
+const RULE_PATTERN = "pattern"
+
+var ruleTypes = [] string { RULE_OUTPUT_SCRIPT, RULE_ADDRESS, RULE_FINGERPRINT, RULE_SPEND_TYPE, RULE_CONTENT_TYPE, RULE_PATTERN }
+
+// fingerprints are the first 8 bytes of a hash
+const FINGERPRINT_LENGTH = 16
+
+type Watch struct {
+	id string
+	ruleType string
+	value string
+	url string
+	created int64
+
+	// parsed from the value when the watch is created or loaded
+	outputScript [] byte
+	pattern btc.ScriptPattern
+}
+
+// for inputs, Type is the spend type and Index is the input index
+// for outputs, Type is the output type and Index is the output index
+type Match struct {
+	Input bool `json:"input"`
+	Index uint16 `json:"index"`
+	Type string `json:"type"`
+}
+
+func GetRuleTypes () [] string {
+	return ruleTypes
+}
+
+// returns a watch with a valid rule or an error
+func newWatch (id string, ruleType string, value string, webhookUrl string, created int64) (*Watch, error) {
+
+	w := &Watch { id: id, ruleType: ruleType, value: strings.TrimSpace (value), url: webhookUrl, created: created }
+	if len (w.value) == 0 { return nil, fmt.Errorf ("value is empty") }
+
+	parsedUrl, err := url.Parse (webhookUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || len (parsedUrl.Host) == 0 {
+		return nil, fmt.Errorf ("%s is not a valid http or https url", webhookUrl)
+	}
+
+	switch ruleType {
+
+		case RULE_OUTPUT_SCRIPT:
+			w.value = strings.ToLower (w.value)
+			w.outputScript, err = hex.DecodeString (w.value)
+			if err != nil { return nil, fmt.Errorf ("%s is not a valid hex string", value) }
+
+		case RULE_ADDRESS:
+			w.outputScript, err = btc.GetOutputScriptFromAddress (w.value)
+			if err != nil { return nil, err }
+
+		case RULE_FINGERPRINT:
+			w.value = strings.ToLower (w.value)
+			if _, err := hex.DecodeString (w.value); err != nil || len (w.value) != FINGERPRINT_LENGTH {
+				return nil, fmt.Errorf ("%s is not a valid fingerprint", value)
+			}
+
+		case RULE_CONTENT_TYPE:
+			w.value = strings.ToLower (w.value)
+
+		case RULE_PATTERN:
+			w.pattern, err = btc.ParseScriptPattern (w.value)
+			if err != nil { return nil, err }
+
+		case RULE_SPEND_TYPE:
+
+		default:
+			return nil, fmt.Errorf ("%s is not a valid rule type", ruleType)
+	}
+
+	return w, nil
+}
+
+func (w *Watch) GetId () string {
+	return w.id
+}
+
+func (w *Watch) GetRuleType () string {
+	return w.ruleType
+}
+
+func (w *Watch) GetValue () string {
+	return w.value
+}
+
+func (w *Watch) GetUrl () string {
+	return w.url
+}
+
+func (w *Watch) GetReport () map [string] interface {} {
+
+	report := make (map [string] interface {})
+	report ["id"] = w.id
+	report ["type"] = w.ruleType
+	report ["value"] = w.value
+	report ["url"] = w.url
+	report ["created"] = w.created
+
+	return report
+}
+
+// the inputs must have their previous outputs
+func (w *Watch) matchTx (tx btc.Tx) [] Match {
+
+	matches := make ([] Match, 0)
+
+	for i, input := range tx.GetInputs () {
+		if input.IsCoinbase () { continue }
+		if w.matchInput (input) { matches = append (matches, Match { Input: true, Index: uint16 (i), Type: input.GetSpendType () }) }
+	}
+
+	for o, output := range tx.GetOutputs () {
+		if w.matchOutput (output) { matches = append (matches, Match { Input: false, Index: uint16 (o), Type: output.GetOutputType () }) }
+	}
+
+	return matches
+}
+
+func (w *Watch) matchInput (input btc.Input) bool {
+
+	segwit := input.GetSegwit ()
+	tapScript, _ := segwit.GetTapScript ()
+	witnessScript := segwit.GetWitnessScript ()
+	redeemScript := input.GetRedeemScript ()
+
+	switch w.ruleType {
+
+		case RULE_OUTPUT_SCRIPT, RULE_ADDRESS:
+			previousOutput := input.GetPreviousOutput ()
+			previousOutputScript := previousOutput.GetOutputScript ()
+			return bytes.Equal (previousOutputScript.AsBytes (), w.outputScript)
+
+		// the same script the index takes the fingerprint from
+		case RULE_FINGERPRINT:
+			if !tapScript.IsNil () { return tapScript.GetFingerprint () == w.value }
+			if !witnessScript.IsNil () { return witnessScript.GetFingerprint () == w.value }
+			if input.HasRedeemScript () { return redeemScript.GetFingerprint () == w.value }
+
+		case RULE_SPEND_TYPE:
+			return input.GetSpendType () == w.value
+
+		case RULE_CONTENT_TYPE:
+			if !tapScript.IsOrdinal () { return false }
+			contentType, _ := tapScript.GetOrdinalContent ()
+			return matchContentType (contentType, w.value)
+
+		case RULE_PATTERN:
+			inputScript := input.GetInputScript ()
+			for _, script := range [] btc.Script { inputScript, redeemScript, witnessScript, tapScript } {
+				if !script.IsNil () && w.pattern.Match (script) >= 0 { return true }
+			}
+	}
+
+	return false
+}
+
+func (w *Watch) matchOutput (output btc.Output) bool {
+
+	outputScript := output.GetOutputScript ()
+
+	switch w.ruleType {
+		case RULE_OUTPUT_SCRIPT, RULE_ADDRESS:
+			return bytes.Equal (outputScript.AsBytes (), w.outputScript)
+		case RULE_FINGERPRINT:
+			return outputScript.GetFingerprint () == w.value
+		case RULE_PATTERN:
+			return !outputScript.IsNil () && w.pattern.Match (outputScript) >= 0
+	}
+
+	return false
+}
+
+// content types are compared without their parameters, so text/plain matches text/plain;charset=utf-8
+func matchContentType (contentType string, value string) bool {
+
+	contentType = strings.ToLower (strings.TrimSpace (strings.Split (contentType, ";") [0]))
+
+	if strings.HasSuffix (value, "/*") { return strings.HasPrefix (contentType, strings.TrimSuffix (value, "*")) }
+	return contentType == value
+}
@@ -0,0 +1,157 @@
+package watch
+
+import (
+	"strings"
+	"testing"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// a p2wpkh spend with one p2wpkh output
+const testSegwitTxHex = "02000000000101" + "1111111111111111111111111111111111111111111111111111111111111111" + "00000000" + "00" + "ffffffff" +
+						"01" + "e803000000000000" + "160014" + "2222222222222222222222222222222222222222" +
+						"02" + "47" + "3044022011111111111111111111111111111111111111111111111111111111111111110220222222222222222222222222222222222222222222222222222222222222222201" +
+						"21" + "023333333333333333333333333333333333333333333333333333333333333333" + "00000000"
+
+var testOutputScriptHex = "0014" + strings.Repeat ("22", 20)
+var testPreviousOutputScriptHex = "0014" + strings.Repeat ("33", 20)
+
+const testWebhookUrl = "http://127.0.0.1/webhook"
+
+// the transaction with the previous output of its input, the way the watcher gets it
+func newTestTx (t *testing.T) btc.Tx {
+	t.Helper ()
+
+	rawBytes, err := hex.DecodeString (testSegwitTxHex)
+	if err != nil { t.Fatal (err) }
+
+	tx, err := btc.DecodeRawTx (rawBytes)
+	if err != nil { t.Fatal (err) }
+
+	previousOutputScript, _ := hex.DecodeString (testPreviousOutputScriptHex)
+	script := btc.NewScript (previousOutputScript)
+	tx.SetPreviousOutput (0, btc.NewOutput (2000, script, btc.GetAddress (script)))
+
+	return tx
+}
+
+func newTestWatch (t *testing.T, ruleType string, value string, webhookUrl string) *Watch {
+	t.Helper ()
+
+	w, err := newWatch (ruleType + ":" + value, ruleType, value, webhookUrl, 0)
+	if err != nil { t.Fatal (err) }
+	return w
+}
+
+// the watch is removed when the test is finished
+func addTestWatch (t *testing.T, w *Watch) {
+	watchMapMutex.Lock ()
+	watchMap [w.id] = w
+	watchMapMutex.Unlock ()
+
+	t.Cleanup (func () {
+		watchMapMutex.Lock ()
+		delete (watchMap, w.id)
+		watchMapMutex.Unlock ()
+	})
+}
+
+func TestNewWatchErrors (t *testing.T) {
+
+	for name, rule := range map [string] [3] string {	"empty value": { RULE_SPEND_TYPE, " ", testWebhookUrl },
+														"unknown rule type": { "txid", "00", testWebhookUrl },
+														"not a webhook url": { RULE_SPEND_TYPE, btc.OUTPUT_TYPE_P2WPKH, "ftp://127.0.0.1/webhook" },
+														"no host": { RULE_SPEND_TYPE, btc.OUTPUT_TYPE_P2WPKH, "http:///webhook" },
+														"output script is not hex": { RULE_OUTPUT_SCRIPT, "00zz", testWebhookUrl },
+														"invalid address": { RULE_ADDRESS, "not an address", testWebhookUrl },
+														"short fingerprint": { RULE_FINGERPRINT, "0011223344", testWebhookUrl },
+														"fingerprint is not hex": { RULE_FINGERPRINT, "zz11223344556677", testWebhookUrl },
+														"unknown opcode": { RULE_PATTERN, "OP_NOTANOPCODE", testWebhookUrl } } {
+		if _, err := newWatch ("id", rule [0], rule [1], rule [2], 0); err == nil { t.Errorf ("%s: no error", name) }
+	}
+
+	// hex values are compared in lower case
+	w := newTestWatch (t, RULE_OUTPUT_SCRIPT, " " + strings.ToUpper (testOutputScriptHex) + " ", testWebhookUrl)
+	if w.GetValue () != testOutputScriptHex { t.Errorf ("value is %s", w.GetValue ()) }
+}
+
+func TestMatchTx (t *testing.T) {
+
+	tx := newTestTx (t)
+
+	outputScriptBytes, _ := hex.DecodeString (testOutputScriptHex)
+	outputScript := btc.NewScript (outputScriptBytes)
+
+	outputMatch := [] Match { { Input: false, Index: 0, Type: btc.OUTPUT_TYPE_P2WPKH } }
+	inputMatch := [] Match { { Input: true, Index: 0, Type: btc.OUTPUT_TYPE_P2WPKH } }
+
+	tests := [] struct {
+		ruleType string
+		value string
+		matches [] Match
+	} {
+		{ RULE_OUTPUT_SCRIPT, testOutputScriptHex, outputMatch },
+		{ RULE_OUTPUT_SCRIPT, testPreviousOutputScriptHex, inputMatch },
+		{ RULE_OUTPUT_SCRIPT, "0014" + strings.Repeat ("44", 20), [] Match {} },
+		{ RULE_ADDRESS, btc.GetAddress (outputScript), outputMatch },
+		{ RULE_SPEND_TYPE, btc.OUTPUT_TYPE_P2WPKH, inputMatch },
+		{ RULE_SPEND_TYPE, "P2TR", [] Match {} },
+		{ RULE_FINGERPRINT, outputScript.GetFingerprint (), outputMatch },
+		{ RULE_PATTERN, "^ 0 <20> $", outputMatch },
+		{ RULE_PATTERN, "OP_CHECKSIG", [] Match {} },
+		{ RULE_CONTENT_TYPE, "text/plain", [] Match {} },
+	}
+
+	for _, test := range tests {
+		w := newTestWatch (t, test.ruleType, test.value, testWebhookUrl)
+
+		matches, _ := json.Marshal (w.matchTx (tx))
+		expected, _ := json.Marshal (test.matches)
+		if string (matches) != string (expected) { t.Errorf ("%s %s: matches %s, expected %s", test.ruleType, test.value, matches, expected) }
+	}
+}
+
+func TestMatchContentType (t *testing.T) {
+
+	for _, test := range [] struct {
+		contentType string
+		value string
+		matches bool
+	} {
+		{ "text/plain", "text/plain", true },
+		{ "text/plain;charset=utf-8", "text/plain", true },
+		{ " Text/Plain ; charset=utf-8", "text/plain", true },
+		{ "image/png", "image/*", true },
+		{ "image/png", "image/jpeg", false },
+		{ "text/plain", "image/*", false },
+		{ "", "text/plain", false },
+	} {
+		if matchContentType (test.contentType, test.value) != test.matches { t.Errorf ("%s %s: expected %t", test.contentType, test.value, test.matches) }
+	}
+}
+
+// only the watches that match get a delivery, and the payload has the matches and the transaction
+func TestCheckTxQueuesDeliveries (t *testing.T) {
+
+	drainDeliveryQueue ()
+	serializeTx = func (tx btc.Tx) map [string] interface {} { return map [string] interface {} { "id": tx.GetTxId () } }
+
+	matching := newTestWatch (t, RULE_OUTPUT_SCRIPT, testOutputScriptHex, testWebhookUrl)
+	other := newTestWatch (t, RULE_SPEND_TYPE, "P2TR", testWebhookUrl)
+	addTestWatch (t, matching)
+	addTestWatch (t, other)
+
+	tx := newTestTx (t)
+	checkTx (tx, EVENT_TX, 800000)
+
+	if len (deliveryQueue) != 1 { t.Fatalf ("%d deliveries", len (deliveryQueue)) }
+	d := <-deliveryQueue
+	if d.watchId != matching.id || d.event != EVENT_TX || d.txId != tx.GetTxId () || d.url != testWebhookUrl { t.Errorf ("delivery for watch %s, event %s, tx %s", d.watchId, d.event, d.txId) }
+
+	var payload deliveryPayload
+	if err := json.Unmarshal (d.payload, &payload); err != nil { t.Fatal (err) }
+	if payload.DeliveryId != d.id || payload.Event != EVENT_TX || payload.BlockHeight != 800000 || payload.Watch ["id"] != matching.id { t.Errorf ("payload %s", d.payload) }
+	if len (payload.Matches) != 1 || payload.Matches [0].Input || payload.Tx ["id"] != tx.GetTxId () { t.Errorf ("payload %s", d.payload) }
+}
@@ -0,0 +1,54 @@
+package watch
+
+import (
+	"fmt"
+
+	"github.com/btc-script-explorer/scantool/btc"
+	"github.com/btc-script-explorer/scantool/btc/node"
+)
+
+// the watcher follows the chain tip with the tip follower, and checks every transaction in each new block against the watchlist
+// mempool transactions are only checked if the node publishes them over zmq
+
+const EVENT_TX = "tx"
+const EVENT_MEMPOOL_TX = "mempool_tx"
+
+func followTip () {
+	node.FollowTip (node.TipHandlers { NewBlock: checkBlock, MempoolTx: checkMempoolTx })
+}
+
+// the transactions are only requested if there are watches, because it takes a while for large blocks
+func checkBlock (tipBlock *node.TipBlock) {
+
+	if !hasWatches () { return }
+
+	block := tipBlock.GetBlock ()
+	err := tipBlock.ForEachTx (func (tx btc.Tx) { checkTx (tx, EVENT_TX, block.GetHeight ()) })
+	if err != nil { fmt.Println (err.Error ()) }
+}
+
+// the previous outputs are requested so that the inputs can be matched
+func checkMempoolTx (tipTx *node.TipMempoolTx) {
+
+	if !hasWatches () { return }
+
+	checkTx (tipTx.GetTx (), EVENT_MEMPOOL_TX, 0)
+}
+
+// a transaction that matches more than one watch is delivered to each of them
+// it is only serialized once, and only if it matches
+func checkTx (tx btc.Tx, event string, blockHeight uint32) {
+
+	var txJson map [string] interface {}
+	for _, w := range GetWatches () {
+		matches := w.matchTx (tx)
+		if len (matches) == 0 { continue }
+
+		if txJson == nil { txJson = serializeTx (tx) }
+
+		d, err := newDelivery (w, event, deliveryPayload { Watch: w.GetReport (), Matches: matches, BlockHeight: blockHeight, Tx: txJson })
+		if err != nil { fmt.Println (err.Error ()); continue }
+
+		queueDelivery (d)
+	}
+}
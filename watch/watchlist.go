@@ -0,0 +1,158 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"path/filepath"
+	"encoding/hex"
+	"encoding/json"
+	"crypto/rand"
+
+	"github.com/btc-script-explorer/scantool/btc"
+)
+
+// the watchlist is saved in the watch directory every time a watch is added or removed, and loaded when scantool starts
+// matching transactions are posted to the webhooks as they are found in new blocks and in the mempool
+
+const WATCHLIST_FILE_NAME = "watchlist.json"
+
+// every transaction is checked against every watch, so the list is kept short
+const MAX_WATCHES = 1000
+
+// the payloads contain transactions in the same format the rest api returns them
+type TxSerializer func (btc.Tx) map [string] interface {}
+
+type watchRecord struct {
+	Id string `json:"id"`
+	Type string `json:"type"`
+	Value string `json:"value"`
+	Url string `json:"url"`
+	Created int64 `json:"created"`
+}
+
+var watchMap = make (map [string] *Watch)
+var watchMapMutex sync.Mutex
+
+var path string
+var serializeTx TxSerializer
+
+func SetWatchPath (watchPath string) {
+	path = watchPath
+}
+
+func GetPath () string {
+	return path
+}
+
+// loads the watchlist and starts following new blocks and delivering webhooks
+func Start (txSerializer TxSerializer) {
+
+	serializeTx = txSerializer
+	loadWatchlist ()
+
+	for w := 0; w < DELIVERY_WORKERS; w++ { go runDeliveryWorker () }
+	followTip ()
+}
+
+func AddWatch (ruleType string, value string, webhookUrl string) (*Watch, error) {
+
+	idBytes := make ([] byte, 8)
+	if _, err := rand.Read (idBytes); err != nil { return nil, err }
+
+	w, err := newWatch (hex.EncodeToString (idBytes), ruleType, value, webhookUrl, time.Now ().Unix ())
+	if err != nil { return nil, err }
+
+	watchMapMutex.Lock ()
+	if len (watchMap) >= MAX_WATCHES {
+		watchMapMutex.Unlock ()
+		return nil, fmt.Errorf ("there are already %d watches", MAX_WATCHES)
+	}
+	watchMap [w.id] = w
+	watchMapMutex.Unlock ()
+
+	saveWatchlist ()
+	return w, nil
+}
+
+// returns the watch that was removed, or nil if it was not found
+// deliveries that are waiting to be retried are dropped
+func RemoveWatch (id string) *Watch {
+
+	watchMapMutex.Lock ()
+	w := watchMap [id]
+	delete (watchMap, id)
+	watchMapMutex.Unlock ()
+
+	if w != nil { saveWatchlist () }
+	return w
+}
+
+func GetWatch (id string) *Watch {
+	watchMapMutex.Lock ()
+	defer watchMapMutex.Unlock ()
+	return watchMap [id]
+}
+
+// returns every watch, oldest first
+func GetWatches () [] *Watch {
+
+	watchMapMutex.Lock ()
+	watches := make ([] *Watch, 0, len (watchMap))
+	for _, w := range watchMap { watches = append (watches, w) }
+	watchMapMutex.Unlock ()
+
+	sort.Slice (watches, func (a, b int) bool {
+		if watches [a].created != watches [b].created { return watches [a].created < watches [b].created }
+		return watches [a].id < watches [b].id
+	})
+
+	return watches
+}
+
+func hasWatches () bool {
+	watchMapMutex.Lock ()
+	defer watchMapMutex.Unlock ()
+	return len (watchMap) > 0
+}
+
+func saveWatchlist () {
+
+	if len (path) == 0 { return }
+
+	records := make ([] watchRecord, 0)
+	for _, w := range GetWatches () {
+		records = append (records, watchRecord { Id: w.id, Type: w.ruleType, Value: w.value, Url: w.url, Created: w.created })
+	}
+
+	watchlistBytes, err := json.Marshal (records)
+	if err != nil { fmt.Println (err.Error ()); return }
+
+	fileName := filepath.Join (path, WATCHLIST_FILE_NAME)
+	tempFileName := fileName + ".tmp"
+	if err := os.WriteFile (tempFileName, watchlistBytes, 0644); err != nil { fmt.Println (err.Error ()); return }
+	if err := os.Rename (tempFileName, fileName); err != nil { fmt.Println (err.Error ()) }
+}
+
+func loadWatchlist () {
+
+	if len (path) == 0 { return }
+
+	watchlistBytes, err := os.ReadFile (filepath.Join (path, WATCHLIST_FILE_NAME))
+	if os.IsNotExist (err) { return }
+	if err != nil { fmt.Println (err.Error ()); return }
+
+	var records [] watchRecord
+	if err := json.Unmarshal (watchlistBytes, &records); err != nil { fmt.Println (fmt.Sprintf ("Failed to load watchlist: %s", err.Error ())); return }
+
+	watchMapMutex.Lock ()
+	defer watchMapMutex.Unlock ()
+
+	for _, record := range records {
+		w, err := newWatch (record.Id, record.Type, record.Value, record.Url, record.Created)
+		if err != nil { fmt.Println (fmt.Sprintf ("Failed to load watch %s: %s", record.Id, err.Error ())); continue }
+		watchMap [w.id] = w
+	}
+}
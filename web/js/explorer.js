@@ -34,10 +34,29 @@ console.log (block_tx_ids);
 	$ ('#block-load-status').css ('display', 'none')
 }
 
+async function get_mempool_txs ()
+{
+	var tx_count = mempool_tx_ids.length;
+	for (var t = 0; t < tx_count; t++)
+	{
+		const response = await fetch (base_url_web + '/mempool-tx/' + mempool_tx_ids [t]);
+		const data = await response.json ();
+		$ ('#tx-count').html (t + 1);
+
+		if (typeof data.tx_html != 'undefined')
+			$ ('#txs').append (data.tx_html);
+
+		var mempool_load_percent = Number (((t + 1) * 100) / tx_count).toFixed (2);
+		$ ('#block-load-status-bar').css ('width', mempool_load_percent + '%');
+		$ ('#block-load-status-percent').html (mempool_load_percent + '%');
+	}
+
+	$ ('#block-load-status').css ('display', 'none')
+}
+
 async function get_tx_inputs ()
 {
 	var input_count = tx_inputs.length;
-	var tx_value_out = Number ($ ('#tx-value-out').html ());
 	for (var i = 0; i < input_count; i++)
 	{
 		const headers = new Headers ();
@@ -46,25 +65,47 @@ async function get_tx_inputs ()
 		const response = await fetch (base_url_web + '/input', request_data);
 		const data = await response.json ();
 
-		$ ('#input-minimized-' + i + '-spend-type').html (data.spend_type)
-		$ ('#input-minimized-' + i + '-value').html (get_value_html (data.value_in))
-		$ ('#input-minimized-' + i + '-address').html (data.address)
-		$ ('#input-maximized-' + i).html (data.input_html)
+		show_tx_input (i, input_count, data);
+	}
 
-		if (data.spend_type != 'COINBASE')
-		{
-			var tx_value_in = Number ($ ('#tx-value-in').text ()) + Number (data.value_in);
-			$ ('#tx-value-in').text (tx_value_in);
-			var tx_fee = Number ($ ('#tx-fee').text ());
-			if (tx_value_in >= tx_value_out)
-				$ ('#tx-fee').html (tx_value_in - tx_value_out);
-		}
+	finish_tx_inputs ();
+}
 
-		var tx_load_percent = Number (((i + 1) * 100) / input_count).toFixed (2);
-		$ ('#tx-load-status-bar').css ('width', tx_load_percent + '%');
-		$ ('#tx-load-status-percent').html (tx_load_percent + '%');
+// for transactions that are not known by the node, the inputs are computed when the page is created
+function show_precomputed_inputs ()
+{
+	var input_count = precomputed_inputs.length;
+	for (var i = 0; i < input_count; i++)
+		show_tx_input (i, input_count, precomputed_inputs [i]);
+
+	finish_tx_inputs ();
+}
+
+function show_tx_input (i, input_count, data)
+{
+	var tx_value_out = Number ($ ('#tx-value-out').html ());
+
+	$ ('#input-minimized-' + i + '-spend-type').html (data.spend_type)
+	$ ('#input-minimized-' + i + '-value').html (get_value_html (data.value_in))
+	$ ('#input-minimized-' + i + '-address').html (data.address)
+	$ ('#input-maximized-' + i).html (data.input_html)
+
+	if (data.spend_type != 'COINBASE')
+	{
+		var tx_value_in = Number ($ ('#tx-value-in').text ()) + Number (data.value_in);
+		$ ('#tx-value-in').text (tx_value_in);
+		var tx_fee = Number ($ ('#tx-fee').text ());
+		if (tx_value_in >= tx_value_out)
+			$ ('#tx-fee').html (tx_value_in - tx_value_out);
 	}
 
+	var tx_load_percent = Number (((i + 1) * 100) / input_count).toFixed (2);
+	$ ('#tx-load-status-bar').css ('width', tx_load_percent + '%');
+	$ ('#tx-load-status-percent').html (tx_load_percent + '%');
+}
+
+function finish_tx_inputs ()
+{
 	$ ('#tx-load-status').css ('display', 'none')
 
 	$ ('#tx-value-in').html (get_value_html ($ ('#tx-value-in').text ()));
@@ -74,8 +115,9 @@ async function get_tx_inputs ()
 
 function check_query_id_format (query_id)
 {
+	// block ids, transaction ids and bech32 addresses are lower case, base58 addresses are mixed case
 	query_id = query_id.toLowerCase ();
-	var allowed_chars = '0123456789abcdef';
+	var allowed_chars = '0123456789abcdefghijklmnopqrstuvwxyz';
 	for (var i = 0; i < query_id.length; i++)
 	{
 		if (allowed_chars.indexOf (query_id.charAt (i)) == -1)
@@ -96,23 +138,65 @@ function handle_search (query_id)
 	window.location.href = base_url_web + '/search/' + query_id;
 }
 
-function get_pending_block_spend_types ()
+// the spending input is only looked up the first time an output is opened
+async function get_output_spend (tx_id, output_index)
 {
-	var pending_spend_types = {};
+	var spent_by = $ ('#output-' + output_index + '-spent-by');
+	if (spent_by.attr ('data-loaded'))
+		return;
+	spent_by.attr ('data-loaded', 'true');
+
+	const response = await fetch (base_url_web + '/output_spend/' + tx_id + '/' + output_index);
+	const data = await response.json ();
 
-	// get the next set to request from the server
-	if (typeof pending_block_spend_types != 'undefined')
+	spent_by.html (data.spent_by_html);
+}
+
+// the page is reloaded so that the url always describes the heat map being shown
+function show_opcode_heatmap ()
+{
+	var start_height = $ ('#opcodes-start-height').val ().trim ();
+	var end_height = $ ('#opcodes-end-height').val ().trim ();
+	var script_type = $ ('#opcodes-script-type').val ();
+
+	window.location.href = base_url_web + '/opcodes/' + start_height + '/' + end_height + '/' + script_type;
+}
+
+async function get_opcode_heatmap (start_height, end_height, script_type)
+{
+	$ ('#opcode-heatmap-status').css ('display', 'block');
+
+	const response = await fetch (base_url_web + '/opcode_heatmap/' + start_height + '/' + end_height + '/' + script_type);
+	const data = await response.json ();
+
+	$ ('#opcode-heatmap-status').css ('display', 'none');
+
+	if (typeof data.ErrorMessage != 'undefined')
+		$ ('#opcode-heatmap').html ('<span style="color:red;">' + data.ErrorMessage + '</span>');
+	else
+		$ ('#opcode-heatmap').html (data.HeatMapChart);
+}
+
+// the statistics are computed by the server, which requests every input's previous output
+async function get_block_charts (block_hash)
+{
+	$ ('#toggle-charts-link').css ('display', 'none');
+	$ ('#block-charts-status').css ('display', 'block');
+
+	const response = await fetch (base_url_web + '/block_charts/' + block_hash);
+	const data = await response.json ();
+
+	$ ('#block-charts-status').css ('display', 'none');
+
+	var charts = { 'spend-types': data.SpendTypeChart, 'output-types': data.OutputTypeChart, 'serialized-script-types': data.SerializedScriptTypeChart, 'data-types': data.DataTypeChart };
+	for (var html_id in charts)
 	{
-		var num = 0;
-		for (var tx_id in pending_block_spend_types)
-		{
-			pending_spend_types [tx_id] = pending_block_spend_types [tx_id];
-			if (++num >= 3)
-				break;
-		}
-	}
+		if (typeof charts [html_id] == 'undefined')
+			continue;
 
-	return pending_spend_types;
+		$ ('#' + html_id + '-box').css ('display', 'inline-block');
+		$ ('#' + html_id).html (charts [html_id]);
+	}
 }
 
 function get_value_html (value)
@@ -204,13 +288,34 @@ async function check_for_new_block ()
 	$ ('#current-block').html (data.current_block_height);
 }
 
+// new blocks are pushed by the server, browsers without server-sent events poll for them instead
+var current_block_events = null;
+function listen_for_new_blocks ()
+{
+	check_for_new_block ();
+
+	if (typeof EventSource === 'undefined')
+	{
+		current_block_interval = setInterval (check_for_new_block, 60000);
+		return;
+	}
+
+	current_block_events = new EventSource (base_url_live + '/events');
+	current_block_events.addEventListener ('tip', function (e) { $ ('#current-block').html (JSON.parse (e.data).height); });
+	current_block_events.addEventListener ('block', function (e) { $ ('#current-block').html (JSON.parse (e.data).height); });
+}
+
 $ (document).ready (
 function ()
 {
 	if (typeof block_tx_ids !== 'undefined')
 		get_block_txs ();
+	else if (typeof mempool_tx_ids !== 'undefined')
+		get_mempool_txs ();
 	else if (typeof tx_inputs !== 'undefined')
 		get_tx_inputs ()
+	else if (typeof precomputed_inputs !== 'undefined')
+		show_precomputed_inputs ()
 	else
 	{
 		// for coinbase transactions
@@ -222,7 +327,6 @@ function ()
 	// set up the Enter key handler
 	$ ('#query-box').on ('keypress', function (e) { if (e.which == 0x0d) handle_search ($ ('#query-box').val ()); })
 
-	check_for_new_block ();
-	current_block_interval = setInterval (check_for_new_block, 60000);
+	listen_for_new_blocks ();
 });
 
@@ -5,7 +5,7 @@ import (
 	"net/http"
 	"time"
 	"io"
-//	"sort"
+	"sort"
 	"strings"
 	"strconv"
 	"encoding/json"
@@ -13,12 +13,13 @@ import (
 	"bytes"
 	"html/template"
 
-//	"github.com/go-echarts/go-echarts/v2/charts"
-//	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
 
 	"github.com/btc-script-explorer/scantool/app"
 	"github.com/btc-script-explorer/scantool/btc"
 	"github.com/btc-script-explorer/scantool/btc/node"
+	"github.com/btc-script-explorer/scantool/index"
 	"github.com/btc-script-explorer/scantool/rest"
 )
 
@@ -26,7 +27,7 @@ import (
 
 type ElementTypeHTML struct {
 	Label string
-	Count uint16
+	Count uint32
 	Percent string
 }
 
@@ -79,7 +80,9 @@ type OutputHtmlData struct {
 	OutputType string
 	Value template.HTML
 	Address string
+	AddressUrl string
 	OutputScript ScriptHtmlData
+	TxId string
 }
 
 type SegwitHtmlData struct {
@@ -89,6 +92,20 @@ type SegwitHtmlData struct {
 	IsEmpty bool
 }
 
+type LabeledValueHtmlData struct {
+	Label string
+	Value string
+}
+
+type PsbtInputHtmlData struct {
+	Finalized bool
+	SighashType string
+	TapInternalKey string
+	Signatures [] LabeledValueHtmlData
+	Bip32Derivations [] LabeledValueHtmlData
+	TapLeafScripts [] ScriptHtmlData
+}
+
 func WebHandler (response http.ResponseWriter, request *http.Request) {
 
 	modifiedPath := request.URL.Path
@@ -126,6 +143,7 @@ func WebHandler (response http.ResponseWriter, request *http.Request) {
 	html := ""
 	customJavascript := fmt.Sprintf ("var base_url_web = '%s/web';\n", app.Settings.GetFullUrl ())
 	customJavascript += fmt.Sprintf ("var base_url_rest = '%s/rest/v%d';\n", app.Settings.GetFullUrl (), restApi.GetVersion ())
+	customJavascript += fmt.Sprintf ("var base_url_live = '%s/live';\n", app.Settings.GetFullUrl ())
 
 	// about page
 	if paramCount >= 1 && params [0] == "about" {
@@ -133,6 +151,31 @@ func WebHandler (response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	// decode page
+	if paramCount >= 1 && params [0] == "decode" {
+		fmt.Fprint (response, getDecodePageHtml (customJavascript))
+		return
+	}
+
+	// opcode heat map page, the range and script type can be given as /web/opcodes/<start height>/<end height>/<script type>
+	if paramCount >= 1 && params [0] == "opcodes" {
+		endHeight := uint64 (nodeProxy.GetCurrentBlockHeight ())
+		startHeight := uint64 (0)
+		if endHeight >= DEFAULT_OPCODE_HEATMAP_BLOCKS { startHeight = endHeight - DEFAULT_OPCODE_HEATMAP_BLOCKS + 1 }
+		scriptType := btc.SCRIPT_TYPE_TAP
+
+		if paramCount >= 3 {
+			startHeight, err = strconv.ParseUint (params [1], 10, 32)
+			if err != nil { fmt.Println (fmt.Sprintf ("start height (%s) not formatted correctly, error: %s", params [1], err.Error ())) }
+			endHeight, err = strconv.ParseUint (params [2], 10, 32)
+			if err != nil { fmt.Println (fmt.Sprintf ("end height (%s) not formatted correctly, error: %s", params [2], err.Error ())) }
+		}
+		if paramCount >= 4 && btc.IsScriptType (params [3]) { scriptType = params [3] }
+
+		fmt.Fprint (response, getOpcodesPageHtml (uint32 (startHeight), uint32 (endHeight), scriptType, customJavascript))
+		return
+	}
+
 	// here, a determination is made as to what the user is requesting by examining the parameters received
 
 	possibleQueryTypes := make ([] string, 0)
@@ -161,14 +204,20 @@ func WebHandler (response http.ResponseWriter, request *http.Request) {
 
 				possibleQueryTypes = append (possibleQueryTypes, "tx")
 				possibleQueryTypes = append (possibleQueryTypes, "block")
-			} else {
-				// it could be a block height
-				_, err := strconv.ParseUint (params [1], 10, 32)
+			} else if paramLen > 64 {
+				// it could be a serialized transaction
+				_, err := hex.DecodeString (searchParam)
 				if err != nil {
-					fmt.Println (params [1] + " is not a valid block height.")
+					fmt.Println (searchParam + " is not a valid hex string.")
 				}
 
+				possibleQueryTypes = append (possibleQueryTypes, "decode_tx")
+			} else if _, err := strconv.ParseUint (params [1], 10, 32); err == nil {
+				// it is a block height
 				possibleQueryTypes = append (possibleQueryTypes, "block")
+			} else {
+				// it could be an address
+				possibleQueryTypes = append (possibleQueryTypes, "address")
 			}
 		}
 	}
@@ -231,6 +280,49 @@ func WebHandler (response http.ResponseWriter, request *http.Request) {
 				return
 
 
+			// returns html
+			case "mempool":
+
+				if request.Method != "GET" { fmt.Println (fmt.Sprintf ("%s must be sent as a GET request.", queryType)); break }
+
+				txIds := nodeProxy.GetMempoolTxIds ()
+				txCount := len (txIds)
+				if len (txIds) > MEMPOOL_PAGE_TX_COUNT { txIds = txIds [: MEMPOOL_PAGE_TX_COUNT] }
+
+				var txIdsBytes [] byte
+				txIdsBytes, err = json.Marshal (txIds)
+				if err != nil { fmt.Println (err.Error ()) }
+
+				customJavascript += fmt.Sprintf ("var mempool_tx_ids = JSON.parse ('%s');\n", string (txIdsBytes))
+				html = getMempoolHtml (txCount, customJavascript)
+
+
+			// mempool-tx is for the web interface to get HTML segments in real time
+			// returns json
+			case "mempool-tx":
+
+				if request.Method != "GET" { fmt.Println (fmt.Sprintf ("%s must be sent as a GET request.", queryType)); break }
+
+				// check the parameters
+				if paramCount < 2 { fmt.Println ("No id provided for tx. Request ignored."); break }
+				if len (params [1]) != 64 { fmt.Println (fmt.Sprintf ("%s is not a valid tx id", params [1])); break }
+
+				// the previous outputs are required for the spend types
+				// if the transaction was included in a block since the page was loaded, it is still displayed
+				txRequest := node.TxRequest { TxId: params [1], IncludeInputDetail: true }
+				tx := nodeProxy.GetTx (txRequest)
+
+				mempoolTxResponse := MempoolTxResponse {}
+				if !tx.IsNil () { mempoolTxResponse = getMempoolTxResponse (tx) }
+
+				jsonBytes, err := json.Marshal (mempoolTxResponse)
+				if err != nil { fmt.Println (err.Error ()) }
+
+				fmt.Fprint (response, string (jsonBytes))
+
+				return
+
+
 			// returns html
 			case "tx":
 
@@ -251,10 +343,82 @@ func WebHandler (response http.ResponseWriter, request *http.Request) {
 				}
 
 				customJavascript += fmt.Sprintf ("var tx_inputs = [%s];", javascriptInputs)
-				html = getTxHtml (tx, customJavascript)
+				html = getTxHtml (tx, "", customJavascript)
+
+
+			// returns html
+			case "psbt":
+
+				if request.Method != "POST" { fmt.Println (fmt.Sprintf ("%s must be sent as a POST request.", queryType)); break }
+
+				psbt, err := btc.DecodePsbtString (request.FormValue ("psbt"))
+				if err != nil { fmt.Println (err.Error ()); break }
+
+				// the psbt contains the previous outputs, so the inputs can be displayed without asking the node for anything
+				tx := psbt.GetTx ()
+				precomputedInputs := make ([] map [string] interface {}, tx.GetInputCount ())
+				for i, psbtInput := range psbt.GetInputs () {
+					precomputedInputs [i] = getInputResponseJson (psbtInput.GetInput (), uint16 (i), tx)
+					precomputedInputs [i] ["input_html"] = precomputedInputs [i] ["input_html"].(string) + getPsbtInputHtml (psbtInput, uint16 (i))
+				}
+
+				precomputedInputsBytes, err := json.Marshal (precomputedInputs)
+				if err != nil { fmt.Println (err.Error ()); break }
+
+				customJavascript += fmt.Sprintf ("var precomputed_inputs = %s;\n", string (precomputedInputsBytes))
+				html = getTxHtml (tx, fmt.Sprintf ("Version %d PSBT", psbt.GetVersion ()), customJavascript)
+
+
+			// returns html
+			case "decode_tx":
+
+				// raw transactions can be posted from the decode page or entered in the search box
+				rawHex := request.FormValue ("hex")
+				if len (rawHex) == 0 && paramCount >= 2 { rawHex = params [1] }
+
+				rawBytes, err := hex.DecodeString (strings.TrimSpace (rawHex))
+				if err != nil { fmt.Println (err.Error ()); break }
+
+				tx, err := btc.DecodeRawTx (rawBytes)
+				if err != nil { fmt.Println (err.Error ()); break }
+
+				// the transaction might not be known to the node, so the inputs are sent with the page
+				precomputedInputs := make ([] map [string] interface {}, tx.GetInputCount ())
+				for i, input := range tx.GetInputs () {
+					if !input.IsCoinbase () {
+						outputRequest := node.OutputRequest { TxId: input.GetPreviousOutputTxId (), OutputIndex: input.GetPreviousOutputIndex () }
+						previousOutput := nodeProxy.GetOutput (outputRequest)
 
+						// inputs without a previous output are displayed without a spend type
+						if len (previousOutput.GetOutputType ()) > 0 { input.SetPreviousOutput (previousOutput) }
+					}
+					precomputedInputs [i] = getInputResponseJson (input, uint16 (i), tx)
+				}
+
+				precomputedInputsBytes, err := json.Marshal (precomputedInputs)
+				if err != nil { fmt.Println (err.Error ()); break }
 
-//			case "address": // would probably require an electrum server for implementation
+				customJavascript += fmt.Sprintf ("var precomputed_inputs = %s;\n", string (precomputedInputsBytes))
+				html = getTxHtml (tx, "", customJavascript)
+
+
+			// the history comes from the index, so only blocks that have been indexed are included
+			// returns html
+			case "address":
+
+				if request.Method != "GET" { fmt.Println (fmt.Sprintf ("%s must be sent as a GET request.", queryType)); break }
+
+				if paramCount < 2 || len (params [1]) == 0 { fmt.Println ("No address provided. Request ignored."); break }
+
+				// scripts without an address format can be entered as hex
+				outputScriptBytes, err := btc.GetOutputScriptFromAddress (params [1])
+				if err != nil {
+					scriptBytes, hexErr := hex.DecodeString (params [1])
+					if hexErr != nil { fmt.Println (err.Error ()); break }
+					outputScriptBytes = scriptBytes
+				}
+
+				html = getAddressHtml (btc.NewScript (outputScriptBytes), customJavascript)
 
 
 			// returns json
@@ -320,34 +484,13 @@ func WebHandler (response http.ResponseWriter, request *http.Request) {
 
 				// get the input
 				input := tx.GetInput (inputIndex)
-				var valueIn uint64
-				var address string
-				if input.IsCoinbase () {
-					// value in is the total of all outputs for coinbase inputs
-					valueIn = 0
-					for _, output := range tx.GetOutputs () {
-						valueIn += output.GetValue ()
-					}
-				} else {
+				if !input.IsCoinbase () {
 					outputRequest := node.OutputRequest { TxId: input.GetPreviousOutputTxId (), OutputIndex: input.GetPreviousOutputIndex () }
-					previousOutput := nodeProxy.GetOutput (outputRequest)
-					input.SetPreviousOutput (previousOutput)
-					address = previousOutput.GetAddress ()
-					if len (address) == 0 { address = "No Address Format" }
-
-					// value in comes from the previous output for non-coinbase inputs
-					valueIn = previousOutput.GetValue ()
+					input.SetPreviousOutput (nodeProxy.GetOutput (outputRequest))
 				}
 
 				// return the response
-				inputHtmlData := getInputHtmlData (input, inputIndex, valueIn, tx.SupportsBip141 ())
-				inputHtml := getInputHtml (inputHtmlData)
-
-				jsonInput := make (map [string] interface {})
-				jsonInput ["spend_type"] = input.GetSpendType ()
-				jsonInput ["address"] = address
-				jsonInput ["value_in"] = valueIn
-				jsonInput ["input_html"] = inputHtml
+				jsonInput := getInputResponseJson (input, inputIndex, tx)
 
 				jsonBytes, err := json.Marshal (jsonInput)
 				if err != nil { fmt.Println (err) }
@@ -356,24 +499,85 @@ func WebHandler (response http.ResponseWriter, request *http.Request) {
 				return
 
 
-/*
+			// finds the input that spent an output
+			// returns json
+			case "output_spend":
+
+				if request.Method != "GET" { fmt.Println (fmt.Sprintf ("%s must be sent as a GET request.", queryType)); break }
+
+				// check the parameters
+				if paramCount < 3 { fmt.Println ("No output provided for output spend. Request ignored."); break }
+				if len (params [1]) != 64 { fmt.Println (fmt.Sprintf ("%s is not a valid tx id", params [1])); break }
+
+				outputIndex, err := strconv.ParseUint (params [2], 10, 16)
+				if err != nil { fmt.Println (fmt.Sprintf ("output index (%s) not formatted correctly, error: %s", params [2], err.Error ())); break }
+
+				outputSpend := index.GetOutputSpend (node.OutputRequest { TxId: params [1], OutputIndex: uint16 (outputIndex) })
+
+				jsonBytes, err := json.Marshal (getOutputSpendResponse (outputSpend))
+				if err != nil { fmt.Println (err.Error ()) }
+
+				fmt.Fprint (response, string (jsonBytes))
+				return
+
+
+			// computes the block statistics and returns the charts as html segments
+			// returns json
 			case "block_charts":
 
-				if request.Method != "POST" { fmt.Println (fmt.Sprintf ("%s must be sent as a POST request.", queryType)); return }
+				if request.Method != "GET" { fmt.Println (fmt.Sprintf ("%s must be sent as a GET request.", queryType)); break }
 
-				// unpack the json
-				var blockChartData BlockChartData
-				err := json.NewDecoder (request.Body).Decode (&blockChartData)
-				if err != nil { fmt.Println (err.Error()) }
+				if paramCount < 2 || len (params [1]) != 64 { fmt.Println ("No valid block hash provided for block charts. Request ignored."); break }
 
-				blockCharts := getBlockCharts (blockChartData.NonCoinbaseInputCount, blockChartData.OutputCount, blockChartData.SpendTypes, blockChartData.OutputTypes)
+				block := nodeProxy.GetBlock (node.BlockRequest { BlockKey: params [1] })
+				if block.IsNil () { break }
+
+				blockCharts := getBlockCharts (nodeProxy.GetBlockStats (block))
 
 				chartsBytes, err := json.Marshal (blockCharts)
 				if err != nil { fmt.Println (err.Error ()); return }
 
 				fmt.Fprint (response, string (chartsBytes))
 				return
-*/
+
+
+			// counts the opcodes in a range of blocks and returns the heat map as an html segment
+			// returns json
+			case "opcode_heatmap":
+
+				if request.Method != "GET" { fmt.Println (fmt.Sprintf ("%s must be sent as a GET request.", queryType)); break }
+
+				if paramCount < 4 { fmt.Println ("No block range provided for opcode heat map. Request ignored."); break }
+
+				startHeight, err := strconv.ParseUint (params [1], 10, 32)
+				if err != nil { fmt.Println (fmt.Sprintf ("start height (%s) not formatted correctly, error: %s", params [1], err.Error ())); break }
+				endHeight, err := strconv.ParseUint (params [2], 10, 32)
+				if err != nil { fmt.Println (fmt.Sprintf ("end height (%s) not formatted correctly, error: %s", params [2], err.Error ())); break }
+				scriptType := params [3]
+
+				heatMap := make (map [string] string)
+				if !btc.IsScriptType (scriptType) {
+					heatMap ["ErrorMessage"] = fmt.Sprintf ("%s is not a valid script type.", scriptType)
+				} else if startHeight > endHeight {
+					heatMap ["ErrorMessage"] = "The start height is greater than the end height."
+				} else if endHeight - startHeight + 1 > MAX_OPCODE_HEATMAP_BLOCKS {
+					heatMap ["ErrorMessage"] = fmt.Sprintf ("No more than %d blocks can be shown. Use the opcode_stats REST function or an opcodes job for larger ranges.", MAX_OPCODE_HEATMAP_BLOCKS)
+				} else {
+					blockStats := make ([] btc.OpcodeStats, 0, endHeight - startHeight + 1)
+					for height := startHeight; height <= endHeight; height++ {
+						block := nodeProxy.GetBlock (node.BlockRequest { BlockKey: strconv.FormatUint (height, 10) })
+						if block.IsNil () { heatMap ["ErrorMessage"] = fmt.Sprintf ("Block %d not found.", height); break }
+						blockStats = append (blockStats, nodeProxy.GetOpcodeStats (block))
+					}
+
+					if len (heatMap ["ErrorMessage"]) == 0 { heatMap ["HeatMapChart"] = getOpcodeHeatMap (uint32 (startHeight), blockStats, scriptType) }
+				}
+
+				heatMapBytes, err := json.Marshal (heatMap)
+				if err != nil { fmt.Println (err.Error ()); return }
+
+				fmt.Fprint (response, string (heatMapBytes))
+				return
 		}
 
 		if len (html) > 0 { break }
@@ -386,31 +590,42 @@ func WebHandler (response http.ResponseWriter, request *http.Request) {
 	fmt.Fprint (response, html)
 }
 
-func ServeFile (response http.ResponseWriter, request *http.Request) {
+// the input must already have its previous output
+func getInputResponseJson (input btc.Input, inputIndex uint16, tx btc.Tx) map [string] interface {} {
 
-	if request.URL.Path == "/favicon.ico" { return }
-	http.ServeFile (response, request, GetPath () + request.URL.Path)
-}
-
-func getKnownSpendTypesHtmlData (nonCoinbaseInputCount uint16, knownSpendTypeMap map [string] uint16) ([] ElementTypeHTML, uint16) {
+	var valueIn uint64
+	var address string
+	if input.IsCoinbase () {
+		// value in is the total of all outputs for coinbase inputs
+		valueIn = 0
+		for _, output := range tx.GetOutputs () {
+			valueIn += output.GetValue ()
+		}
+	} else {
+		previousOutput := input.GetPreviousOutput ()
+		address = previousOutput.GetAddress ()
+		if len (address) == 0 { address = "No Address Format" }
 
-	var knownSpendTypes [] ElementTypeHTML
-	knownSpendTypeCount := uint16 (1) // starting at 1 because the coinbase input always has a known spend type
-	for spendType, num := range knownSpendTypeMap {
-		knownSpendTypeCount += num
-		knownSpendTypes = append (knownSpendTypes, ElementTypeHTML { Label: spendType, Count: uint16 (num), Percent: fmt.Sprintf ("%9.2f%%", float32 (num * 100) / float32 (nonCoinbaseInputCount)) })
+		// value in comes from the previous output for non-coinbase inputs
+		valueIn = previousOutput.GetValue ()
 	}
 
-	return knownSpendTypes, knownSpendTypeCount
+	inputHtmlData := getInputHtmlData (input, inputIndex, valueIn, tx.SupportsBip141 ())
+	inputHtml := getInputHtml (inputHtmlData)
+
+	jsonInput := make (map [string] interface {})
+	jsonInput ["spend_type"] = input.GetSpendType ()
+	jsonInput ["address"] = address
+	jsonInput ["value_in"] = valueIn
+	jsonInput ["input_html"] = inputHtml
+
+	return jsonInput
 }
-func getOutputTypesHtmlData (outputCount uint16, outputTypeMap map [string] uint16) [] ElementTypeHTML {
 
-	var outputTypes [] ElementTypeHTML
-	for outputType, num := range outputTypeMap {
-		outputTypes = append (outputTypes, ElementTypeHTML { Label: outputType, Count: uint16 (num), Percent: fmt.Sprintf ("%9.2f%%", float32 (num * 100) / float32 (outputCount)) })
-	}
+func ServeFile (response http.ResponseWriter, request *http.Request) {
 
-	return outputTypes
+	if request.URL.Path == "/favicon.ico" { return }
+	http.ServeFile (response, request, GetPath () + request.URL.Path)
 }
 
 func getExplorerPageHtmlData (queryText string, queryResults map [string] interface {}) map [string] interface {} {
@@ -434,6 +649,10 @@ func getLayoutHtmlData (customJavascript string, explorerPageData map [string] i
 	layoutData ["NodeVersion"] = template.HTML (strings.Replace (nodeProxy.GetNodeVersion (), " ", "&nbsp;", -1))
 	layoutData ["NodeUrl"] = template.HTML (app.Settings.GetNodeFullUrl ())
 
+	// the banner is only shown for test networks
+	network := btc.GetNetwork ()
+	if !network.IsMainnet () { layoutData ["Network"] = network.GetDisplayName () }
+
 	return layoutData
 }
 
@@ -470,13 +689,36 @@ func getBlockHtml (block btc.Block, customJavascript string) string {
 	blockHtmlData ["BaseUrl"] = app.Settings.GetFullUrl () + "/web"
 	blockHtmlData ["Height"] = block.GetHeight ()
 	blockHtmlData ["Time"] = time.Unix (block.GetTimestamp (), 0).UTC ()
+	blockHtmlData ["MedianTime"] = time.Unix (block.GetMedianTime (), 0).UTC ()
 	blockHtmlData ["Hash"] = blockHash
 
+	// header
+	blockHtmlData ["Version"] = fmt.Sprintf ("0x%08x", uint32 (block.GetVersion ()))
+	blockHtmlData ["MerkleRoot"] = block.GetMerkleRoot ()
+	blockHtmlData ["MerkleRootValid"] = block.IsMerkleRootValid ()
+	blockHtmlData ["Bits"] = block.GetBits ()
+	blockHtmlData ["Nonce"] = block.GetNonce ()
+	blockHtmlData ["Difficulty"] = strconv.FormatFloat (block.GetDifficulty (), 'f', -1, 64)
+	blockHtmlData ["Chainwork"] = block.GetChainwork ()
+
+	// sizes
+	blockHtmlData ["Size"] = block.GetSize ()
+	blockHtmlData ["StrippedSize"] = block.GetStrippedSize ()
+	blockHtmlData ["Weight"] = block.GetWeight ()
+	blockHtmlData ["TxTotal"] = block.GetTxCount ()
+
 	previousHash := block.GetPreviousHash ()
 	if len (previousHash) > 0 { blockHtmlData ["PreviousHash"] = previousHash }
 	nextHash := block.GetNextHash ()
 	if len (nextHash) > 0 { blockHtmlData ["NextHash"] = nextHash }
 
+	network := btc.GetNetwork ()
+	softForks := make ([] string, 0)
+	if network.IsSegwitActive (block.GetHeight ()) { softForks = append (softForks, "SegWit") }
+	if network.IsTaprootActive (block.GetHeight ()) { softForks = append (softForks, "Taproot") }
+	if len (softForks) == 0 { softForks = append (softForks, "None") }
+	blockHtmlData ["SoftForks"] = strings.Join (softForks, ", ")
+
 	// create the html page
 	explorerPageHtmlData := getExplorerPageHtmlData (blockHash, blockHtmlData)
 	layoutHtmlData := getLayoutHtmlData (customJavascript, explorerPageHtmlData)
@@ -485,7 +727,6 @@ func getBlockHtml (block btc.Block, customJavascript string) string {
 	layoutHtmlFiles := [] string {
 		GetPath () + "html/layout.html",
 		GetPath () + "html/page-explorer.html",
-		GetPath () + "html/type-chart-detail.html",
 		GetPath () + "html/block.html" }
 	templ := template.Must (template.ParseFiles (layoutHtmlFiles...))
 
@@ -537,10 +778,224 @@ func getBlockTxResponse (tx btc.Tx, blockIndex uint16) BlockTxResponse {
 	return blockTxResponse
 }
 
-func getTxHtml (tx btc.Tx, customJavascript string) string {
+const MEMPOOL_PAGE_TX_COUNT = 50
+
+func getMempoolHtml (txCount int, customJavascript string) string {
+
+	// get the data
+	mempoolHtmlData := make (map [string] interface {})
+	mempoolHtmlData ["BaseUrl"] = app.Settings.GetFullUrl () + "/web"
+	mempoolHtmlData ["TxCount"] = txCount
+
+	// create the html page
+	explorerPageHtmlData := getExplorerPageHtmlData ("", mempoolHtmlData)
+	layoutHtmlData := getLayoutHtmlData (customJavascript, explorerPageHtmlData)
+
+	// parse the files
+	layoutHtmlFiles := [] string {
+		GetPath () + "html/layout.html",
+		GetPath () + "html/page-explorer.html",
+		GetPath () + "html/mempool.html" }
+	templ := template.Must (template.ParseFiles (layoutHtmlFiles...))
+
+	// execute the templates
+	var buff bytes.Buffer
+	if err := templ.ExecuteTemplate (&buff, "Layout", layoutHtmlData); err != nil { panic (err) }
+
+	// return the html
+	return buff.String ()
+}
+
+type AddressEventHtmlData struct {
+	Height uint32
+	TxId string
+	Type string
+	Value template.HTML
+	SpendType string
+	Balance template.HTML
+}
+
+func getAddressHtml (outputScript btc.Script, customJavascript string) string {
+
+	// get the data
+	addressHtmlData := make (map [string] interface {})
+	addressHtmlData ["BaseUrl"] = app.Settings.GetFullUrl () + "/web"
+	addressHtmlData ["OutputScript"] = outputScript.AsHex ()
+
+	address := btc.GetAddress (outputScript)
+	if len (address) == 0 { address = "No Address Format" }
+	addressHtmlData ["Address"] = address
+
+	history, err := index.GetAddressHistory (outputScript, index.DEFAULT_QUERY_LIMIT)
+	if err != nil {
+		addressHtmlData ["ErrorMessage"] = err.Error ()
+	} else {
+		status := index.GetStatus ()
+		addressHtmlData ["StartHeight"] = status.StartHeight
+		addressHtmlData ["IndexedHeight"] = int64 (status.NextHeight) - 1
+
+		addressHtmlData ["FundingCount"] = history.FundingCount
+		addressHtmlData ["SpendingCount"] = history.SpendingCount
+		addressHtmlData ["Received"] = template.HTML (getValueHtml (history.Received))
+		addressHtmlData ["Spent"] = template.HTML (getValueHtml (history.Spent))
+		addressHtmlData ["Balance"] = template.HTML (getSignedValueHtml (history.Balance))
+
+		spendTypes := make ([] string, 0, len (history.SpendTypes))
+		for spendType, count := range history.SpendTypes { spendTypes = append (spendTypes, fmt.Sprintf ("%s (%d)", spendType, count)) }
+		sort.Strings (spendTypes)
+		if len (spendTypes) == 0 { spendTypes = append (spendTypes, "None") }
+		addressHtmlData ["SpendTypes"] = strings.Join (spendTypes, ", ")
+
+		events := make ([] AddressEventHtmlData, len (history.Events))
+		for e, event := range history.Events {
+			events [e] = AddressEventHtmlData {	Height: event.Height,
+												TxId: event.TxId,
+												Type: event.Type,
+												Value: template.HTML (getValueHtml (event.Value)),
+												SpendType: event.SpendType,
+												Balance: template.HTML (getSignedValueHtml (event.Balance)) }
+		}
+		addressHtmlData ["Events"] = events
+	}
+
+	// create the html page
+	explorerPageHtmlData := getExplorerPageHtmlData ("", addressHtmlData)
+	layoutHtmlData := getLayoutHtmlData (customJavascript, explorerPageHtmlData)
+
+	// parse the files
+	layoutHtmlFiles := [] string {
+		GetPath () + "html/layout.html",
+		GetPath () + "html/page-explorer.html",
+		GetPath () + "html/address.html" }
+	templ := template.Must (template.ParseFiles (layoutHtmlFiles...))
+
+	// execute the templates
+	var buff bytes.Buffer
+	if err := templ.ExecuteTemplate (&buff, "Layout", layoutHtmlData); err != nil { panic (err) }
+
+	// return the html
+	return buff.String ()
+}
+
+type MempoolTxHtmlData struct {
+	Id string
+	FirstSeen string
+	FeeRate string
+	SpendTypes string
+	OutputTypes string
+	BaseUrl string
+}
+
+type MempoolTxResponse struct {
+	TxHtml string `json:"tx_html"`
+}
+
+func getMempoolTxResponse (tx btc.Tx) MempoolTxResponse {
+
+	mempoolTxData := MempoolTxHtmlData { Id: tx.GetTxId (), FirstSeen: "Confirmed", BaseUrl: app.Settings.GetFullUrl () + "/web" }
+
+	mempoolEntry := tx.GetMempoolEntry ()
+	if !mempoolEntry.IsNil () {
+		mempoolTxData.FirstSeen = time.Unix (mempoolEntry.GetTimeFirstSeen (), 0).UTC ().Format (time.DateTime)
+		mempoolTxData.FeeRate = fmt.Sprintf ("%.1f sat/vB", mempoolEntry.GetFeeRate ())
+	}
+
+	spendTypes := make ([] string, tx.GetInputCount ())
+	for i, input := range tx.GetInputs () { spendTypes [i] = input.GetSpendType () }
+	mempoolTxData.SpendTypes = getTypeSummary (spendTypes)
+
+	outputTypes := make ([] string, tx.GetOutputCount ())
+	for o, output := range tx.GetOutputs () { outputTypes [o] = output.GetOutputType () }
+	mempoolTxData.OutputTypes = getTypeSummary (outputTypes)
+
+	// parse the file
+	htmlFiles := [] string { GetPath () + "html/mempool-tx.html" }
+	templ := template.Must (template.ParseFiles (htmlFiles...))
+
+	// execute the template
+	var buff bytes.Buffer
+	if err := templ.ExecuteTemplate (&buff, "MempoolTx", mempoolTxData); err != nil { panic (err) }
+
+	return MempoolTxResponse { TxHtml: buff.String () }
+}
+
+type OutputSpendHtmlData struct {
+	Status string
+	TxId string
+	InputIndex uint32
+	SpendType string
+	BlockHeight int64
+	InMempool bool
+	IndexEnabled bool
+	BaseUrl string
+}
+
+type OutputSpendResponse struct {
+	SpentByHtml string `json:"spent_by_html"`
+}
+
+func getOutputSpendResponse (outputSpend btc.OutputSpend) OutputSpendResponse {
+
+	outputSpendData := OutputSpendHtmlData {	Status: outputSpend.GetStatus (),
+												TxId: outputSpend.GetTxId (),
+												InputIndex: outputSpend.GetInputIndex (),
+												SpendType: outputSpend.GetSpendType (),
+												BlockHeight: outputSpend.GetBlockHeight (),
+												InMempool: outputSpend.IsInMempool (),
+												IndexEnabled: index.IsOpen (),
+												BaseUrl: app.Settings.GetFullUrl () + "/web" }
+
+	// parse the file
+	htmlFiles := [] string { GetPath () + "html/output-spend.html" }
+	templ := template.Must (template.ParseFiles (htmlFiles...))
+
+	// execute the template
+	var buff bytes.Buffer
+	if err := templ.ExecuteTemplate (&buff, "OutputSpend", outputSpendData); err != nil { panic (err) }
+
+	return OutputSpendResponse { SpentByHtml: buff.String () }
+}
+
+// lists each type once, in order of first appearance, with the number of times it appears
+func getTypeSummary (types [] string) string {
+
+	typeCounts := make (map [string] int)
+	orderedTypes := make ([] string, 0)
+	for _, t := range types {
+		if len (t) == 0 { t = "Unknown" }
+		if typeCounts [t] == 0 { orderedTypes = append (orderedTypes, t) }
+		typeCounts [t]++
+	}
+
+	summary := make ([] string, len (orderedTypes))
+	for t, typeName := range orderedTypes {
+		summary [t] = typeName
+		if typeCounts [typeName] > 1 { summary [t] += fmt.Sprintf (" (%d)", typeCounts [typeName]) }
+	}
+
+	return strings.Join (summary, ", ")
+}
+
+// status is only displayed for transactions that are not in a block
+func getTxHtml (tx btc.Tx, status string, customJavascript string) string {
 
 	txPageHtmlData := make (map [string] interface {})
 
+	mempoolEntry := tx.GetMempoolEntry ()
+	if !mempoolEntry.IsNil () {
+		status = "Unconfirmed (In Mempool)"
+		txPageHtmlData ["Mempool"] = map [string] interface {} {
+			"FirstSeen": time.Unix (mempoolEntry.GetTimeFirstSeen (), 0).UTC (),
+			"FeeRate": fmt.Sprintf ("%.1f sat/vB", mempoolEntry.GetFeeRate ()),
+			"AncestorCount": mempoolEntry.GetAncestorCount (),
+			"AncestorSize": mempoolEntry.GetAncestorSize (),
+			"DescendantCount": mempoolEntry.GetDescendantCount (),
+			"DescendantSize": mempoolEntry.GetDescendantSize () }
+	}
+
+	if len (status) == 0 { status = "Not In A Block" }
+	txPageHtmlData ["Status"] = status
+
 	// transaction data
 	txPageHtmlData ["BaseUrl"] = app.Settings.GetFullUrl () + "/web"
 	txPageHtmlData ["BlockTime"] = time.Unix (tx.GetBlockTime (), 0).UTC ()
@@ -565,6 +1020,9 @@ func getTxHtml (tx btc.Tx, customJavascript string) string {
 		totalOut += output.GetValue ()
 		scriptHtmlId := fmt.Sprintf ("output-script-%d", o)
 		outputHtmlData [o] = getOutputHtmlData (outputs [o], scriptHtmlId, "", uint16 (o))
+
+		// used to look up the input that spent the output
+		outputHtmlData [o].TxId = tx.GetTxId ()
 	}
 	txPageHtmlData ["OutputData"] = outputHtmlData
 
@@ -630,6 +1088,61 @@ func getInputHtml (htmlData InputHtmlData) string {
 	return buff.String ()
 }
 
+func getPsbtInputHtml (psbtInput btc.PsbtInput, inputIndex uint16) string {
+
+	htmlData := PsbtInputHtmlData { Finalized: psbtInput.IsFinalized (), TapInternalKey: hex.EncodeToString (psbtInput.GetTapInternalKey ()) }
+	displayTypeClassPrefix := fmt.Sprintf ("input-%d", inputIndex)
+
+	sighashType, hasSighashType := psbtInput.GetSighashType ()
+	if hasSighashType { htmlData.SighashType = btc.GetSighashName (sighashType) }
+
+	signatures := append (psbtInput.GetPartialSignatures (), psbtInput.GetTapScriptSignatures ()...)
+	for _, signature := range signatures {
+		htmlData.Signatures = append (htmlData.Signatures, LabeledValueHtmlData { Label: shortenField (hex.EncodeToString (signature.GetPublicKey ()), 24, 3), Value: shortenField (hex.EncodeToString (signature.GetSignature ()), 64, FIELD_DOT_COUNT) })
+	}
+	tapKeySignature := psbtInput.GetTapKeySignature ()
+	if len (tapKeySignature) > 0 {
+		htmlData.Signatures = append (htmlData.Signatures, LabeledValueHtmlData { Label: "Key Path", Value: shortenField (hex.EncodeToString (tapKeySignature), 64, FIELD_DOT_COUNT) })
+	}
+
+	derivations := append (psbtInput.GetBip32Derivations (), psbtInput.GetTapBip32Derivations ()...)
+	for _, derivation := range derivations {
+		htmlData.Bip32Derivations = append (htmlData.Bip32Derivations, LabeledValueHtmlData { Label: hex.EncodeToString (derivation.GetFingerprint ()) + " " + derivation.GetPath (), Value: hex.EncodeToString (derivation.GetPublicKey ()) })
+	}
+
+	for l, leafScript := range psbtInput.GetTapLeafScripts () {
+		htmlData.TapLeafScripts = append (htmlData.TapLeafScripts, getScriptHtmlData (leafScript.GetScript (), fmt.Sprintf ("psbt-input-%d-tap-leaf-%d", inputIndex, l), displayTypeClassPrefix))
+	}
+
+	htmlFiles := [] string {
+		GetPath () + "html/psbt-input.html",
+		GetPath () + "html/field-set.html" }
+	templ := template.Must (template.ParseFiles (htmlFiles...))
+
+	var buff bytes.Buffer
+	if err := templ.ExecuteTemplate (&buff, "PsbtInput", htmlData); err != nil { panic (err) }
+
+	// return the html
+	return buff.String ()
+}
+
+func getDecodePageHtml (customJavascript string) string {
+	layoutHtmlData := getLayoutHtmlData (customJavascript, map [string] interface {} { "BaseUrl": app.Settings.GetFullUrl () + "/web" })
+
+	// parse the files
+	layoutHtmlFiles := [] string {
+		GetPath () + "html/layout.html",
+		GetPath () + "html/page-decode.html" }
+	templ := template.Must (template.ParseFiles (layoutHtmlFiles...))
+
+	// execute the templates
+	var buff bytes.Buffer
+	if err := templ.ExecuteTemplate (&buff, "Layout", layoutHtmlData); err != nil { panic (err) }
+
+	// return the html
+	return buff.String ()
+}
+
 func getAboutPageHtml (customJavascript string) string {
 	layoutHtmlData := getLayoutHtmlData (customJavascript, map [string] interface {} { "AppVersion": app.GetVersion () })
 
@@ -647,6 +1160,33 @@ func getAboutPageHtml (customJavascript string) string {
 	return buff.String ()
 }
 
+func getOpcodesPageHtml (startHeight uint32, endHeight uint32, scriptType string, customJavascript string) string {
+
+	scriptTypes := make ([] map [string] interface {}, 0)
+	for _, t := range btc.GetScriptTypes () {
+		scriptTypes = append (scriptTypes, map [string] interface {} { "Name": t, "Selected": t == scriptType })
+	}
+
+	opcodesHtmlData := map [string] interface {} {	"StartHeight": startHeight,
+													"EndHeight": endHeight,
+													"ScriptTypes": scriptTypes,
+													"MaxBlocks": MAX_OPCODE_HEATMAP_BLOCKS }
+	layoutHtmlData := getLayoutHtmlData (customJavascript, opcodesHtmlData)
+
+	// parse the files
+	layoutHtmlFiles := [] string {
+		GetPath () + "html/layout.html",
+		GetPath () + "html/page-opcodes.html" }
+	templ := template.Must (template.ParseFiles (layoutHtmlFiles...))
+
+	// execute the templates
+	var buff bytes.Buffer
+	if err := templ.ExecuteTemplate (&buff, "Layout", layoutHtmlData); err != nil { panic (err) }
+
+	// return the html
+	return buff.String ()
+}
+
 func getInputHtmlData (input btc.Input, txIndex uint16, satoshis uint64, bip141 bool) InputHtmlData {
 
 	displayTypeClassPrefix := fmt.Sprintf ("input-%d", txIndex)
@@ -693,9 +1233,16 @@ func getOutputHtmlData (output btc.Output, scriptHtmlId string, displayTypeClass
 	}
 	outputScriptHtml := getScriptHtmlData (output.GetOutputScript (), scriptHtmlId, displayTypeClassPrefix)
 
+	// the address page is only available when the index is enabled
+	addressUrl := ""
 	address := output.GetAddress ()
-	if len (address) == 0 { address = "No Address Format" }
-	return OutputHtmlData { OutputIndex: outputIndex, DisplayTypeClassPrefix: displayTypeClassPrefix, OutputType: output.GetOutputType (), Value: template.HTML (getValueHtml (output.GetValue ())), Address: address, OutputScript: outputScriptHtml }
+	if len (address) == 0 {
+		address = "No Address Format"
+	} else if index.IsOpen () {
+		addressUrl = app.Settings.GetFullUrl () + "/web/address/" + address
+	}
+
+	return OutputHtmlData { OutputIndex: outputIndex, DisplayTypeClassPrefix: displayTypeClassPrefix, OutputType: output.GetOutputType (), Value: template.HTML (getValueHtml (output.GetValue ())), Address: address, AddressUrl: addressUrl, OutputScript: outputScriptHtml }
 }
 
 func shortenField (fieldText string, length uint, dotCount uint) string {
@@ -869,6 +1416,12 @@ func getValueHtml (satoshis uint64) string {
 	return satoshisStr
 }
 
+// balances can be negative when outputs created before the index start height are spent
+func getSignedValueHtml (satoshis int64) string {
+	if satoshis < 0 { return "-" + getValueHtml (uint64 (-satoshis)) }
+	return getValueHtml (uint64 (satoshis))
+}
+
 func extractBodyFromHTML (html string) string {
 
 	bodyBegin := strings.Index (html, "<body>")
@@ -881,131 +1434,158 @@ func extractBodyFromHTML (html string) string {
 	return body [bodyBegin :]
 }
 
-/*
-func getBlockCharts (nonCoinbaseInputCount uint16, outputCount uint16, spendTypes map [string] uint16, outputTypes map [string] uint16) map [string] string {
-
-	const pieRadius = 90
-	const verticalPadding = 10
-	longestLabel := 0
+func getBlockCharts (blockStats btc.BlockStats) map [string] string {
 
-	// gather data for the spend type and output type charts
-
-	spendTypeNames := [] string { btc.OUTPUT_TYPE_P2PK, btc.OUTPUT_TYPE_MultiSig, btc.OUTPUT_TYPE_P2PKH, btc.OUTPUT_TYPE_P2SH, btc.SPEND_TYPE_P2SH_P2WPKH, btc.SPEND_TYPE_P2SH_P2WSH, btc.OUTPUT_TYPE_P2WPKH, btc.OUTPUT_TYPE_P2WSH, btc.SPEND_TYPE_P2TR_Key, btc.SPEND_TYPE_P2TR_Script, btc.SPEND_TYPE_NonStandard }
+	htmlData := make (map [string] string)
 
-	var spendTypesHTML [] ElementTypeHTML
-	for _, typeName := range spendTypeNames {
-		if spendTypes [typeName] > 0 {
-			if len (typeName) > longestLabel { longestLabel = len (typeName) }
-			spendTypesHTML = append (spendTypesHTML, ElementTypeHTML { Label: typeName, Count: spendTypes [typeName], Percent: fmt.Sprintf ("%9.2f%%", float32 (spendTypes [typeName] * 100) / float32 (nonCoinbaseInputCount)) })
-		}
+	// the coinbase input has no spend type
+	nonCoinbaseInputCount := blockStats.GetInputCount () - 1
+	if nonCoinbaseInputCount > 0 {
+		htmlData ["SpendTypeChart"] = getTypeChart ("Spend Types", blockStats.GetSpendTypes (), nonCoinbaseInputCount)
 	}
 
-	outputTypeNames := [] string { btc.OUTPUT_TYPE_P2PK, btc.OUTPUT_TYPE_MultiSig, btc.OUTPUT_TYPE_P2PKH, btc.OUTPUT_TYPE_P2SH, btc.OUTPUT_TYPE_P2WPKH, btc.OUTPUT_TYPE_P2WSH, btc.OUTPUT_TYPE_TAPROOT, btc.OUTPUT_TYPE_OP_RETURN, btc.OUTPUT_TYPE_WitnessUnknown, btc.OUTPUT_TYPE_NonStandard }
+	htmlData ["OutputTypeChart"] = getTypeChart ("Output Types", blockStats.GetOutputTypes (), blockStats.GetOutputCount ())
 
-	var outputTypesHTML [] ElementTypeHTML
-	for _, typeName := range outputTypeNames {
-		if outputTypes [typeName] > 0 {
-			if len (typeName) > longestLabel { longestLabel = len (typeName) }
-			outputTypesHTML = append (outputTypesHTML, ElementTypeHTML { Label: typeName, Count: outputTypes [typeName], Percent: fmt.Sprintf ("%9.2f%%", float32 (outputTypes [typeName] * 100) / float32 (outputCount)) })
-		}
+	serializedScriptTypes := blockStats.GetSerializedScriptTypes ()
+	if len (serializedScriptTypes) > 0 {
+		htmlData ["SerializedScriptTypeChart"] = getTypeChart ("Serialized Script Types", serializedScriptTypes, getTypeTotal (serializedScriptTypes))
 	}
 
-	outputTypeCount := len (outputTypesHTML)
-	legendHeight := outputTypeCount
-	legendHeight *= 22
-
-	boxDimension := ((pieRadius + verticalPadding) * 2) + legendHeight
-	boxDimensionStr := strconv.Itoa (boxDimension)
-
-	htmlData := make (map [string] string)
-
-	if len (spendTypeNames) > 0 {
-
-		sort.SliceStable (spendTypesHTML, func (i, j int) bool { return spendTypesHTML [i].Count > spendTypesHTML [j].Count })
-
-		// spend type values
-		var spendTypeValues [] opts.PieData
-		for _, elementData := range spendTypesHTML {
-			if spendTypes [elementData.Label] > 0 {
-				fmtStr := fmt.Sprintf ("%%-%ds %%6d %%7s", longestLabel + 3)
-				elementLabel := fmt.Sprintf (fmtStr, elementData.Label, elementData.Count, elementData.Percent)
-				spendTypeValues = append (spendTypeValues, opts.PieData { Name: elementLabel, Value: elementData.Count })
-			}
-		}
-
-		spendTypeCount := len (spendTypesHTML)
-		if spendTypeCount > outputTypeCount {
-			legendHeight = spendTypeCount
-			legendHeight *= 22
+	dataTypes := blockStats.GetDataTypes ()
+	if len (dataTypes) > 0 {
+		htmlData ["DataTypeChart"] = getTypeChart ("Data Types", dataTypes, getTypeTotal (dataTypes))
+	}
 
-			boxDimension = ((pieRadius + verticalPadding) * 2) + legendHeight
-			boxDimensionStr = strconv.Itoa (boxDimension)
-		}
+	return htmlData
+}
 
-		// create the spend type chart
+func getTypeTotal (typeStats map [string] btc.TypeStats) uint32 {
+	total := uint32 (0)
+	for _, stats := range typeStats {
+		total += stats.GetCount ()
+	}
 
-		pie := charts.NewPie ()
-		pie.AddSeries ("Spend Types", spendTypeValues)
-		pie.SetSeriesOptions (charts.WithLabelOpts (opts.Label { Show: false }), charts.WithPieChartOpts (opts.PieChart { Center: [] int { boxDimension / 2, legendHeight + verticalPadding + pieRadius }, Radius: [] int { 0, pieRadius } }))
+	return total
+}
 
-		pie.Legend.Orient = "vertical"
-		pie.Legend.Top = "0"
-		pie.Legend.Height = strconv.Itoa (legendHeight)
-		pie.Legend.Width = boxDimensionStr
-		pie.Legend.ItemWidth = 12
-		pie.Legend.ItemHeight = 12
-		pie.Legend.TextStyle = new (opts.TextStyle)
-		pie.Legend.TextStyle.FontFamily = "monospace"
-		pie.Legend.TextStyle.FontStyle = "bold"
+// returns a pie chart as an html segment, with the types sorted by count in the legend
+func getTypeChart (title string, typeStats map [string] btc.TypeStats, total uint32) string {
 
-		pie.Initialization.PageTitle = "Spend Types"
-		pie.Initialization.Width = boxDimensionStr + "px"
-		pie.Initialization.Height = boxDimensionStr + "px"
+	const pieRadius = 90
+	const verticalPadding = 10
+	longestLabel := 0
 
-		var buff bytes.Buffer
-		pie.Render (&buff)
-		htmlData ["SpendTypeChart"] = extractBodyFromHTML (buff.String ())
+	var typesHTML [] ElementTypeHTML
+	for typeName, stats := range typeStats {
+		if stats.GetCount () == 0 { continue }
+		if len (typeName) > longestLabel { longestLabel = len (typeName) }
+		typesHTML = append (typesHTML, ElementTypeHTML { Label: typeName, Count: stats.GetCount (), Percent: fmt.Sprintf ("%9.2f%%", float32 (stats.GetCount ()) * 100 / float32 (total)) })
 	}
 
-	sort.SliceStable (outputTypesHTML, func (i, j int) bool { return outputTypesHTML [i].Count > outputTypesHTML [j].Count })
+	sort.SliceStable (typesHTML, func (i, j int) bool {
+		if typesHTML [i].Count == typesHTML [j].Count { return typesHTML [i].Label < typesHTML [j].Label }
+		return typesHTML [i].Count > typesHTML [j].Count
+	})
 
-	// output type values
-	var outputTypeValues [] opts.PieData
-	for _, elementData := range outputTypesHTML {
-		if outputTypes [elementData.Label] > 0 {
-			fmtStr := fmt.Sprintf ("%%-%ds %%6d %%7s", longestLabel + 3)
-			elementLabel := fmt.Sprintf (fmtStr, elementData.Label, elementData.Count, elementData.Percent)
-			outputTypeValues = append (outputTypeValues, opts.PieData { Name: elementLabel, Value: elementData.Count })
-		}
+	legendHeight := len (typesHTML) * 22
+	boxDimension := ((pieRadius + verticalPadding) * 2) + legendHeight
+	boxDimensionStr := strconv.Itoa (boxDimension)
+
+	// the box must be wide enough for the longest legend entry
+	boxWidth := boxDimension
+	legendWidth := (longestLabel + 24) * 8
+	if legendWidth > boxWidth { boxWidth = legendWidth }
+	boxWidthStr := strconv.Itoa (boxWidth)
+
+	// chart values
+	var typeValues [] opts.PieData
+	fmtStr := fmt.Sprintf ("%%-%ds %%6d %%7s", longestLabel + 3)
+	for _, elementData := range typesHTML {
+		elementLabel := fmt.Sprintf (fmtStr, elementData.Label, elementData.Count, elementData.Percent)
+		typeValues = append (typeValues, opts.PieData { Name: elementLabel, Value: elementData.Count })
 	}
 
-	// create the output type chart
+	// create the chart
 
 	pie := charts.NewPie ()
-	pie.AddSeries ("Output Types", outputTypeValues)
-	pie.SetSeriesOptions (charts.WithLabelOpts (opts.Label { Show: false }), charts.WithPieChartOpts (opts.PieChart { Center: [] int { boxDimension / 2, legendHeight + verticalPadding + pieRadius }, Radius: [] int { 0, pieRadius } }))
+	pie.AddSeries (title, typeValues)
+	pie.SetSeriesOptions (charts.WithLabelOpts (opts.Label { Show: false }), charts.WithPieChartOpts (opts.PieChart { Center: [] int { boxWidth / 2, legendHeight + verticalPadding + pieRadius }, Radius: [] int { 0, pieRadius } }))
 
 	pie.Legend.Orient = "vertical"
 	pie.Legend.Top = "0"
 	pie.Legend.Height = strconv.Itoa (legendHeight)
-	pie.Legend.Width = boxDimensionStr
+	pie.Legend.Width = boxWidthStr
 	pie.Legend.ItemWidth = 12
 	pie.Legend.ItemHeight = 12
 	pie.Legend.TextStyle = new (opts.TextStyle)
 	pie.Legend.TextStyle.FontFamily = "monospace"
 	pie.Legend.TextStyle.FontStyle = "bold"
 
-	pie.Initialization.PageTitle = "Output Types"
-	pie.Initialization.Width = boxDimensionStr + "px"
+	pie.Initialization.PageTitle = title
+	pie.Initialization.Width = boxWidthStr + "px"
 	pie.Initialization.Height = boxDimensionStr + "px"
 
 	var buff bytes.Buffer
 	pie.Render (&buff)
-	htmlData ["OutputTypeChart"] = extractBodyFromHTML (buff.String ())
+	return extractBodyFromHTML (buff.String ())
+}
 
-	return htmlData
+const DEFAULT_OPCODE_HEATMAP_BLOCKS = 10
+const MAX_OPCODE_HEATMAP_BLOCKS = 100
+
+// returns a heat map of opcode counts per block as an html segment, with the most used opcodes at the top
+func getOpcodeHeatMap (startHeight uint32, blockStats [] btc.OpcodeStats, scriptType string) string {
+
+	totals := make (map [string] uint64)
+	maxCount := uint64 (0)
+	for _, stats := range blockStats {
+		for opcode, count := range stats.GetCounts () [scriptType] {
+			totals [opcode] += count
+			if count > maxCount { maxCount = count }
+		}
+	}
+
+	opcodes := make ([] string, 0, len (totals))
+	for opcode, _ := range totals { opcodes = append (opcodes, opcode) }
+	sort.SliceStable (opcodes, func (i, j int) bool {
+		if totals [opcodes [i]] == totals [opcodes [j]] { return opcodes [i] > opcodes [j] }
+		return totals [opcodes [i]] < totals [opcodes [j]]
+	})
+
+	heights := make ([] string, len (blockStats))
+	var values [] opts.HeatMapData
+	for b, stats := range blockStats {
+		heights [b] = strconv.FormatUint (uint64 (startHeight) + uint64 (b), 10)
+		for o, opcode := range opcodes {
+			count := stats.GetCount (scriptType, opcode)
+			if count == 0 { continue }
+			values = append (values, opts.HeatMapData { Value: [3] interface {} { b, o, count } })
+		}
+	}
+
+	const rowHeight = 20
+	chartHeight := strconv.Itoa (len (opcodes) * rowHeight + 160)
+	chartWidth := strconv.Itoa (len (blockStats) * 24 + 320)
+
+	// create the chart
+
+	heatMap := charts.NewHeatMap ()
+	heatMap.SetGlobalOptions (
+		charts.WithTitleOpts (opts.Title { Title: "Opcode Usage", Subtitle: scriptType }),
+		charts.WithGridOpts (opts.Grid { Left: "0", Right: "100", Top: "60", ContainLabel: true }),
+		charts.WithYAxisOpts (opts.YAxis { Type: "category", Data: opcodes, SplitArea: &opts.SplitArea { Show: true } }),
+		charts.WithVisualMapOpts (opts.VisualMap { Show: true, Calculable: true, Min: 0, Max: float32 (maxCount), Right: "0", Top: "center",
+													InRange: &opts.VisualMapInRange { Color: [] string { "#f0f0f0", "#ff8c00", "#b00000" } } }))
+	heatMap.SetXAxis (heights).AddSeries (scriptType, values)
+
+	heatMap.Initialization.PageTitle = "Opcode Usage"
+	heatMap.Initialization.Width = chartWidth + "px"
+	heatMap.Initialization.Height = chartHeight + "px"
+
+	var buff bytes.Buffer
+	heatMap.Render (&buff)
+	return extractBodyFromHTML (buff.String ())
 }
-*/
 
 var path string
 